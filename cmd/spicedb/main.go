@@ -56,6 +56,15 @@ func main() {
 	cmd.RegisterDatastoreRootFlags(datastoreCmd)
 	rootCmd.AddCommand(datastoreCmd)
 
+	// Add schema commands
+	schemaCmd, err := cmd.NewSchemaCommand(rootCmd.Use)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to register schema command")
+	}
+
+	cmd.RegisterSchemaRootFlags(schemaCmd)
+	rootCmd.AddCommand(schemaCmd)
+
 	// Add head command.
 	headCmd := cmd.NewHeadCommand(rootCmd.Use)
 	cmd.RegisterHeadFlags(headCmd)
@@ -73,6 +82,16 @@ func main() {
 	cmd.RegisterDevtoolsFlags(devtoolsCmd)
 	rootCmd.AddCommand(devtoolsCmd)
 
+	// Add validate command
+	validateCmd := cmd.NewValidateCommand(rootCmd.Use)
+	cmd.RegisterValidateFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+
+	// Add language server command
+	lspCmd := cmd.NewLSPCommand(rootCmd.Use)
+	cmd.RegisterLSPFlags(lspCmd)
+	rootCmd.AddCommand(lspCmd)
+
 	var testServerConfig testserver.Config
 	testingCmd := cmd.NewTestingCommand(rootCmd.Use, &testServerConfig)
 	cmd.RegisterTestingFlags(testingCmd, &testServerConfig)
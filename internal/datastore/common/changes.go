@@ -18,9 +18,12 @@ type Changes[R datastore.Revision, K comparable] struct {
 }
 
 type changeRecord[R datastore.Revision] struct {
-	rev          R
-	tupleTouches map[string]*core.RelationTuple
-	tupleDeletes map[string]*core.RelationTuple
+	rev                R
+	tupleTouches       map[string]*core.RelationTuple
+	tupleDeletes       map[string]*core.RelationTuple
+	definitionsChanged map[string]datastore.SchemaDefinition
+	namespacesDeleted  map[string]struct{}
+	caveatsDeleted     map[string]struct{}
 }
 
 // NewChanges creates a new Changes object for change tracking and de-duplication.
@@ -38,16 +41,7 @@ func (ch Changes[R, K]) AddChange(
 	tpl *core.RelationTuple,
 	op core.RelationTupleUpdate_Operation,
 ) {
-	k := ch.keyFunc(rev)
-	revisionChanges, ok := ch.records[k]
-	if !ok {
-		revisionChanges = changeRecord[R]{
-			rev,
-			make(map[string]*core.RelationTuple),
-			make(map[string]*core.RelationTuple),
-		}
-		ch.records[k] = revisionChanges
-	}
+	revisionChanges := ch.recordFor(rev)
 
 	tplKey := tuple.StringWithoutCaveat(tpl)
 
@@ -68,6 +62,64 @@ func (ch Changes[R, K]) AddChange(
 	}
 }
 
+// AddChangedDefinition adds a namespace or caveat definition written at the revision.
+func (ch Changes[R, K]) AddChangedDefinition(ctx context.Context, rev R, def datastore.SchemaDefinition) {
+	revisionChanges := ch.recordFor(rev)
+
+	switch t := def.(type) {
+	case *core.NamespaceDefinition:
+		delete(revisionChanges.namespacesDeleted, t.Name)
+		revisionChanges.definitionsChanged[nsPrefix+t.Name] = t
+
+	case *core.CaveatDefinition:
+		delete(revisionChanges.caveatsDeleted, t.Name)
+		revisionChanges.definitionsChanged[caveatPrefix+t.Name] = t
+
+	default:
+		log.Ctx(ctx).Fatal().Msg("unknown schema definition kind")
+	}
+}
+
+// AddDeletedNamespace adds a namespace deleted at the revision. If the namespace was also
+// written at the same revision, the delete is dropped.
+func (ch Changes[R, K]) AddDeletedNamespace(_ context.Context, rev R, namespaceName string) {
+	revisionChanges := ch.recordFor(rev)
+	if _, ok := revisionChanges.definitionsChanged[nsPrefix+namespaceName]; !ok {
+		revisionChanges.namespacesDeleted[namespaceName] = struct{}{}
+	}
+}
+
+// AddDeletedCaveat adds a caveat deleted at the revision. If the caveat was also written at
+// the same revision, the delete is dropped.
+func (ch Changes[R, K]) AddDeletedCaveat(_ context.Context, rev R, caveatName string) {
+	revisionChanges := ch.recordFor(rev)
+	if _, ok := revisionChanges.definitionsChanged[caveatPrefix+caveatName]; !ok {
+		revisionChanges.caveatsDeleted[caveatName] = struct{}{}
+	}
+}
+
+const (
+	nsPrefix     = "n$"
+	caveatPrefix = "c$"
+)
+
+func (ch Changes[R, K]) recordFor(rev R) changeRecord[R] {
+	k := ch.keyFunc(rev)
+	revisionChanges, ok := ch.records[k]
+	if !ok {
+		revisionChanges = changeRecord[R]{
+			rev,
+			make(map[string]*core.RelationTuple),
+			make(map[string]*core.RelationTuple),
+			make(map[string]datastore.SchemaDefinition),
+			make(map[string]struct{}),
+			make(map[string]struct{}),
+		}
+		ch.records[k] = revisionChanges
+	}
+	return revisionChanges
+}
+
 // AsRevisionChanges returns the list of changes processed so far as a datastore watch
 // compatible, ordered, changelist.
 func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []datastore.RevisionChanges {
@@ -96,6 +148,15 @@ func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []
 				Tuple:     tpl,
 			})
 		}
+		for _, def := range revisionChangeRecord.definitionsChanged {
+			changes[i].ChangedDefinitions = append(changes[i].ChangedDefinitions, def)
+		}
+		for nsName := range revisionChangeRecord.namespacesDeleted {
+			changes[i].DeletedNamespaces = append(changes[i].DeletedNamespaces, nsName)
+		}
+		for caveatName := range revisionChangeRecord.caveatsDeleted {
+			changes[i].DeletedCaveats = append(changes[i].DeletedCaveats, caveatName)
+		}
 	}
 
 	return changes
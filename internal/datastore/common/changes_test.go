@@ -321,3 +321,38 @@ func canonicalize(in []datastore.RevisionChanges) []datastore.RevisionChanges {
 
 	return out
 }
+
+func TestSchemaChanges(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	ch := NewChanges(revision.DecimalKeyFunc)
+
+	// A definition which is replaced in a transaction is reported as written, not deleted.
+	ch.AddDeletedNamespace(ctx, rev1, "document")
+	ch.AddChangedDefinition(ctx, rev1, &core.NamespaceDefinition{Name: "document"})
+	ch.AddChangedDefinition(ctx, rev1, &core.CaveatDefinition{Name: "somecaveat"})
+	ch.AddDeletedCaveat(ctx, rev1, "somecaveat")
+
+	ch.AddDeletedNamespace(ctx, rev2, "document")
+	ch.AddDeletedCaveat(ctx, rev2, "somecaveat")
+	ch.AddChange(ctx, rev2, tuple.MustParse(tuple1), core.RelationTupleUpdate_DELETE)
+
+	changes := ch.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+	require.Len(changes, 2)
+
+	require.True(changes[0].HasSchemaChanges())
+	require.Empty(changes[0].Changes)
+	require.ElementsMatch([]datastore.SchemaDefinition{
+		&core.NamespaceDefinition{Name: "document"},
+		&core.CaveatDefinition{Name: "somecaveat"},
+	}, changes[0].ChangedDefinitions)
+	require.Empty(changes[0].DeletedNamespaces)
+	require.Empty(changes[0].DeletedCaveats)
+
+	require.True(changes[1].HasSchemaChanges())
+	require.Equal([]*core.RelationTupleUpdate{del(tuple1)}, changes[1].Changes)
+	require.Empty(changes[1].ChangedDefinitions)
+	require.Equal([]string{"document"}, changes[1].DeletedNamespaces)
+	require.Equal([]string{"somecaveat"}, changes[1].DeletedCaveats)
+}
@@ -0,0 +1,358 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Checkpoint records how far a copy has progressed, so that it can be resumed at the same
+// revision of the source datastore.
+type Checkpoint struct {
+	// Revision is the revision of the source datastore being copied.
+	Revision string `json:"revision"`
+
+	// SchemaCopied is whether the namespace and caveat definitions have been copied.
+	SchemaCopied bool `json:"schemaCopied"`
+
+	// Namespace is the namespace whose relationships were last copied, and AfterRelationship
+	// the last relationship copied within it.
+	Namespace         string `json:"namespace,omitempty"`
+	AfterRelationship string `json:"afterRelationship,omitempty"`
+
+	// CopiedRelationships is the number of relationships copied so far.
+	CopiedRelationships uint64 `json:"copiedRelationships"`
+}
+
+// Progress is reported after each batch of relationships has been copied.
+type Progress struct {
+	Namespace           string
+	CopiedRelationships uint64
+}
+
+// Options configures a copy between datastores.
+type Options struct {
+	// BatchSize is the maximum number of relationships written in each transaction of the
+	// destination datastore.
+	BatchSize uint64
+
+	// DryRun reads everything which would be copied, without writing to the destination.
+	DryRun bool
+
+	// Checkpoint, if set, resumes the copy from the point it records.
+	Checkpoint *Checkpoint
+
+	// SaveCheckpoint, if set, is called with the progress of the copy after each write to the
+	// destination datastore.
+	SaveCheckpoint func(Checkpoint) error
+
+	// OnProgress, if set, is called after each batch of relationships has been copied.
+	OnProgress func(Progress)
+}
+
+// Result is the outcome of a copy.
+type Result struct {
+	Revision      datastore.Revision
+	Namespaces    int
+	Caveats       int
+	Relationships uint64
+}
+
+// Copy copies the schema and relationships of the source datastore at a single revision into
+// the destination datastore. Unless it resumes from a checkpoint, the destination must be empty.
+//
+// Relationships are written with TOUCH, so a batch which was written but not checkpointed is
+// written again without error when the copy is resumed.
+func Copy(ctx context.Context, source, destination datastore.Datastore, opts Options) (*Result, error) {
+	if opts.BatchSize == 0 {
+		return nil, errors.New("batch size must be greater than zero")
+	}
+
+	var checkpoint Checkpoint
+	var revision datastore.Revision
+	if opts.Checkpoint != nil {
+		checkpoint = *opts.Checkpoint
+
+		var err error
+		revision, err = source.RevisionFromString(checkpoint.Revision)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint revision: %w", err)
+		}
+
+		if err := source.CheckRevision(ctx, revision); err != nil {
+			return nil, fmt.Errorf("the checkpoint revision can no longer be read from the source datastore, the copy must be restarted into an empty datastore: %w", err)
+		}
+	} else {
+		var err error
+		revision, err = source.HeadRevision(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine source revision: %w", err)
+		}
+		checkpoint.Revision = revision.String()
+
+		if !opts.DryRun {
+			if err := ensureEmpty(ctx, destination); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	save := func() error {
+		if opts.DryRun || opts.SaveCheckpoint == nil {
+			return nil
+		}
+		return opts.SaveCheckpoint(checkpoint)
+	}
+
+	reader := source.SnapshotReader(revision)
+	namespaces, caveats, err := readSchema(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Revision:   revision,
+		Namespaces: len(namespaces),
+		Caveats:    len(caveats),
+	}
+
+	if !checkpoint.SchemaCopied {
+		if !opts.DryRun {
+			if _, err := destination.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+				if len(caveats) > 0 {
+					if err := rwt.WriteCaveats(ctx, caveats); err != nil {
+						return err
+					}
+				}
+				return rwt.WriteNamespaces(ctx, namespaces...)
+			}); err != nil {
+				return nil, fmt.Errorf("unable to write schema: %w", err)
+			}
+		}
+
+		checkpoint.SchemaCopied = true
+		if err := save(); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Relationships = checkpoint.CopiedRelationships
+	for _, ns := range namespaces {
+		if ns.Name < checkpoint.Namespace {
+			continue
+		}
+
+		var after options.Cursor
+		if ns.Name == checkpoint.Namespace && checkpoint.AfterRelationship != "" {
+			afterTuple := tuple.Parse(checkpoint.AfterRelationship)
+			if afterTuple == nil {
+				return nil, fmt.Errorf("invalid checkpoint relationship: %s", checkpoint.AfterRelationship)
+			}
+			after = afterTuple
+		}
+
+		for {
+			batch, err := readBatch(ctx, reader, ns.Name, after, opts.BatchSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(batch) == 0 {
+				break
+			}
+
+			if !opts.DryRun {
+				updates := make([]*core.RelationTupleUpdate, 0, len(batch))
+				for _, tpl := range batch {
+					updates = append(updates, tuple.Touch(tpl))
+				}
+
+				if _, err := destination.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+					return rwt.WriteRelationships(ctx, updates)
+				}); err != nil {
+					return nil, fmt.Errorf("unable to write relationships of %s: %w", ns.Name, err)
+				}
+			}
+
+			result.Relationships += uint64(len(batch))
+			after = batch[len(batch)-1]
+
+			checkpoint.Namespace = ns.Name
+			checkpoint.AfterRelationship = tuple.StringWithoutCaveat(after)
+			checkpoint.CopiedRelationships = result.Relationships
+			if err := save(); err != nil {
+				return nil, err
+			}
+
+			if opts.OnProgress != nil {
+				opts.OnProgress(Progress{Namespace: ns.Name, CopiedRelationships: result.Relationships})
+			}
+
+			if uint64(len(batch)) < opts.BatchSize {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Verify compares the definitions and the number of relationships of each namespace in the
+// source datastore at the revision with those in the destination datastore at its head.
+func Verify(ctx context.Context, source datastore.Datastore, revision datastore.Revision, destination datastore.Datastore) error {
+	sourceReader := source.SnapshotReader(revision)
+
+	destinationRevision, err := destination.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to determine destination revision: %w", err)
+	}
+	destinationReader := destination.SnapshotReader(destinationRevision)
+
+	sourceNamespaces, sourceCaveats, err := readSchema(ctx, sourceReader)
+	if err != nil {
+		return err
+	}
+
+	destinationNamespaces, destinationCaveats, err := readSchema(ctx, destinationReader)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	if got, want := caveatNames(destinationCaveats), caveatNames(sourceCaveats); got != want {
+		mismatches = append(mismatches, fmt.Sprintf("caveats [%s] in the destination, [%s] in the source", got, want))
+	}
+	if got, want := namespaceNames(destinationNamespaces), namespaceNames(sourceNamespaces); got != want {
+		mismatches = append(mismatches, fmt.Sprintf("namespaces [%s] in the destination, [%s] in the source", got, want))
+	}
+
+	for _, ns := range sourceNamespaces {
+		sourceCount, err := countRelationships(ctx, sourceReader, ns.Name)
+		if err != nil {
+			return err
+		}
+
+		destinationCount, err := countRelationships(ctx, destinationReader, ns.Name)
+		if err != nil {
+			return err
+		}
+
+		if sourceCount != destinationCount {
+			mismatches = append(mismatches, fmt.Sprintf("%d relationships of %s in the destination, %d in the source", destinationCount, ns.Name, sourceCount))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("destination datastore does not match the source: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+func ensureEmpty(ctx context.Context, ds datastore.Datastore) error {
+	revision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to determine destination revision: %w", err)
+	}
+
+	namespaces, caveats, err := readSchema(ctx, ds.SnapshotReader(revision))
+	if err != nil {
+		return err
+	}
+
+	if len(namespaces) > 0 || len(caveats) > 0 {
+		return errors.New("the destination datastore must be empty, unless resuming from a checkpoint")
+	}
+	return nil
+}
+
+// readSchema reads the namespace and caveat definitions, in name order.
+func readSchema(ctx context.Context, reader datastore.Reader) ([]*core.NamespaceDefinition, []*core.CaveatDefinition, error) {
+	revisionedNamespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read namespaces: %w", err)
+	}
+
+	namespaces := make([]*core.NamespaceDefinition, 0, len(revisionedNamespaces))
+	for _, ns := range revisionedNamespaces {
+		namespaces = append(namespaces, ns.Definition)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+
+	revisionedCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read caveats: %w", err)
+	}
+
+	caveats := make([]*core.CaveatDefinition, 0, len(revisionedCaveats))
+	for _, caveat := range revisionedCaveats {
+		caveats = append(caveats, caveat.Definition)
+	}
+	sort.Slice(caveats, func(i, j int) bool { return caveats[i].Name < caveats[j].Name })
+
+	return namespaces, caveats, nil
+}
+
+// readBatch reads up to limit relationships of the namespace which sort after the cursor.
+func readBatch(ctx context.Context, reader datastore.Reader, namespace string, after options.Cursor, limit uint64) ([]*core.RelationTuple, error) {
+	iter, err := reader.QueryRelationships(
+		ctx,
+		datastore.RelationshipsFilter{ResourceType: namespace},
+		options.WithLimit(&limit),
+		options.WithAfter(after),
+		options.WithSort(options.ByResource),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read relationships of %s: %w", namespace, err)
+	}
+	defer iter.Close()
+
+	batch := make([]*core.RelationTuple, 0, limit)
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		batch = append(batch, tpl.CloneVT())
+	}
+	if iter.Err() != nil {
+		return nil, fmt.Errorf("unable to read relationships of %s: %w", namespace, iter.Err())
+	}
+
+	return batch, nil
+}
+
+func countRelationships(ctx context.Context, reader datastore.Reader, namespace string) (uint64, error) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: namespace})
+	if err != nil {
+		return 0, fmt.Errorf("unable to count relationships of %s: %w", namespace, err)
+	}
+	defer iter.Close()
+
+	var count uint64
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		count++
+	}
+	if iter.Err() != nil {
+		return 0, fmt.Errorf("unable to count relationships of %s: %w", namespace, iter.Err())
+	}
+
+	return count, nil
+}
+
+func namespaceNames(namespaces []*core.NamespaceDefinition) string {
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func caveatNames(caveats []*core.CaveatDefinition) string {
+	names := make([]string, 0, len(caveats))
+	for _, caveat := range caveats {
+		names = append(names, caveat.Name)
+	}
+	return strings.Join(names, ", ")
+}
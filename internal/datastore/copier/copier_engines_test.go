@@ -0,0 +1,58 @@
+//go:build docker
+// +build docker
+
+package copier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
+	"github.com/authzed/spicedb/internal/testserver/datastore/config"
+	dsconfig "github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func TestCopyBetweenEngines(t *testing.T) {
+	testCases := []struct {
+		source      string
+		destination string
+	}{
+		{"postgres", "mysql"},
+		{"mysql", "postgres"},
+		{"sqlite", "postgres"},
+		{"postgres", "postgres"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s to %s", tc.source, tc.destination), func(t *testing.T) {
+			req := require.New(t)
+			ctx := context.Background()
+
+			source := newEngineDatastore(t, tc.source)
+			source, _ = testfixtures.StandardDatastoreWithCaveatedData(source, req)
+			destination := newEngineDatastore(t, tc.destination)
+
+			result, err := Copy(ctx, source, destination, Options{BatchSize: 3})
+			req.NoError(err)
+			req.Equal(uint64(len(testfixtures.StandardTuples)), result.Relationships)
+			req.Equal(1, result.Caveats)
+
+			req.NoError(Verify(ctx, source, result.Revision, destination))
+		})
+	}
+}
+
+func newEngineDatastore(t *testing.T, engine string) datastore.Datastore {
+	ds := testdatastore.RunDatastoreEngine(t, engine).NewDatastore(t, config.DatastoreConfigInitFunc(t,
+		dsconfig.WithRequestHedgingEnabled(false),
+		dsconfig.WithGCInterval(-1),
+	))
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
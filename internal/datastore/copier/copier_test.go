@@ -0,0 +1,176 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/sqlite"
+	"github.com/authzed/spicedb/internal/datastore/sqlite/migrations"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/migrate"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newDatastore(t *testing.T) datastore.Datastore {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
+
+func newSQLiteDatastore(t *testing.T) datastore.Datastore {
+	path := filepath.Join(t.TempDir(), "spicedb.db")
+
+	driver, err := migrations.NewSQLiteDriver(path)
+	require.NoError(t, err)
+	require.NoError(t, migrations.DatabaseMigrations.Run(context.Background(), driver, migrate.Head, migrate.LiveRun))
+	require.NoError(t, driver.Close(context.Background()))
+
+	ds, err := sqlite.NewSQLiteDatastore(path, sqlite.GCEnabled(false))
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
+
+func newSource(t *testing.T) datastore.Datastore {
+	source, _ := testfixtures.StandardDatastoreWithCaveatedData(newDatastore(t), require.New(t))
+	return source
+}
+
+func TestCopy(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	source := newSource(t)
+	destination := newDatastore(t)
+
+	var checkpoints []Checkpoint
+	var progress []Progress
+	result, err := Copy(ctx, source, destination, Options{
+		BatchSize: 3,
+		SaveCheckpoint: func(checkpoint Checkpoint) error {
+			checkpoints = append(checkpoints, checkpoint)
+			return nil
+		},
+		OnProgress: func(p Progress) {
+			progress = append(progress, p)
+		},
+	})
+	req.NoError(err)
+	req.Equal(uint64(len(testfixtures.StandardTuples)), result.Relationships)
+	req.Equal(1, result.Caveats)
+	req.NotZero(result.Namespaces)
+
+	req.True(checkpoints[0].SchemaCopied)
+	req.Len(checkpoints, len(progress)+1)
+	req.Equal(result.Relationships, progress[len(progress)-1].CopiedRelationships)
+
+	req.NoError(Verify(ctx, source, result.Revision, destination))
+
+	// Writes to the source after the revision being copied are not copied.
+	_, err = common.WriteTuples(ctx, source, core.RelationTupleUpdate_TOUCH, tuple.MustParse("document:newdoc#viewer@user:tom"))
+	req.NoError(err)
+	req.NoError(Verify(ctx, source, result.Revision, destination))
+}
+
+func TestCopyBetweenSQLiteDatastores(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	source, _ := testfixtures.StandardDatastoreWithCaveatedData(newSQLiteDatastore(t), req)
+	destination := newSQLiteDatastore(t)
+
+	result, err := Copy(ctx, source, destination, Options{BatchSize: 3})
+	req.NoError(err)
+	req.Equal(uint64(len(testfixtures.StandardTuples)), result.Relationships)
+	req.Equal(1, result.Caveats)
+
+	req.NoError(Verify(ctx, source, result.Revision, destination))
+}
+
+func TestCopyResume(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	source := newSource(t)
+	destination := newDatastore(t)
+
+	// Interrupt the copy partway through.
+	errInterrupted := errors.New("interrupted")
+	var lastCheckpoint Checkpoint
+	_, err := Copy(ctx, source, destination, Options{
+		BatchSize: 2,
+		SaveCheckpoint: func(checkpoint Checkpoint) error {
+			if checkpoint.CopiedRelationships >= 6 {
+				return errInterrupted
+			}
+			lastCheckpoint = checkpoint
+			return nil
+		},
+	})
+	req.ErrorIs(err, errInterrupted)
+	req.Equal(uint64(4), lastCheckpoint.CopiedRelationships)
+
+	// The batch written before the interruption is written again when resuming.
+	result, err := Copy(ctx, source, destination, Options{
+		BatchSize:  2,
+		Checkpoint: &lastCheckpoint,
+	})
+	req.NoError(err)
+	req.Equal(uint64(len(testfixtures.StandardTuples)), result.Relationships)
+	req.Equal(lastCheckpoint.Revision, result.Revision.String())
+
+	req.NoError(Verify(ctx, source, result.Revision, destination))
+}
+
+func TestCopyDryRun(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	source := newSource(t)
+	destination := newDatastore(t)
+
+	result, err := Copy(ctx, source, destination, Options{
+		BatchSize: 100,
+		DryRun:    true,
+		SaveCheckpoint: func(checkpoint Checkpoint) error {
+			return errors.New("dry runs do not save checkpoints")
+		},
+	})
+	req.NoError(err)
+	req.Equal(uint64(len(testfixtures.StandardTuples)), result.Relationships)
+
+	req.NoError(ensureEmpty(ctx, destination))
+}
+
+func TestCopyIntoNonEmptyDatastore(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Copy(ctx, newSource(t), newSource(t), Options{BatchSize: 100})
+	require.ErrorContains(t, err, "must be empty")
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	source := newSource(t)
+	destination := newDatastore(t)
+
+	result, err := Copy(ctx, source, destination, Options{BatchSize: 100})
+	req.NoError(err)
+
+	_, err = common.WriteTuples(ctx, destination, core.RelationTupleUpdate_DELETE, tuple.MustParse(testfixtures.StandardTuples[0]))
+	req.NoError(err)
+
+	err = Verify(ctx, source, result.Revision, destination)
+	req.ErrorContains(err, "relationships of document in the destination")
+}
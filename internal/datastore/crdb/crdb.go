@@ -58,6 +58,7 @@ const (
 	colCaveatDefinition  = "definition"
 	colCaveatContextName = "caveat_name"
 	colCaveatContext     = "caveat_context"
+	colExpiration        = "expiration"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	errRevision            = "unable to find revision: %w"
@@ -79,12 +80,14 @@ func newCRDBDatastore(url string, options ...Option) (datastore.Datastore, error
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 	config.readPoolOpts.ConfigurePgx(readPoolConfig)
+	pgxcommon.ConfigureSchema(&readPoolConfig.ConnConfig.Config, config.schema)
 
 	writePoolConfig, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 	config.writePoolOpts.ConfigurePgx(writePoolConfig)
+	pgxcommon.ConfigureSchema(&writePoolConfig.ConnConfig.Config, config.schema)
 
 	initCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -172,6 +175,7 @@ func newCRDBDatastore(url string, options ...Option) (datastore.Datastore, error
 		),
 		revision.DecimalDecoder{},
 		url,
+		config.schema,
 		readPool,
 		writePool,
 		config.watchBufferLength,
@@ -202,6 +206,7 @@ type crdbDatastore struct {
 	revision.DecimalDecoder
 
 	dburl               string
+	schema              string
 	readPool, writePool *pgxpool.Pool
 	watchBufferLength   uint16
 	writeOverlapKeyer   overlapKeyer
@@ -304,7 +309,7 @@ func (cds *crdbDatastore) ReadyState(ctx context.Context) (datastore.ReadyState,
 		return datastore.ReadyState{}, fmt.Errorf("invalid head migration found for postgres: %w", err)
 	}
 
-	currentRevision, err := migrations.NewCRDBDriver(cds.dburl)
+	currentRevision, err := migrations.NewCRDBDriver(cds.dburl, cds.schema)
 	if err != nil {
 		return datastore.ReadyState{}, err
 	}
@@ -140,7 +140,7 @@ func TestWatchFeatureDetection(t *testing.T) {
 			adminConn, connStrings := newCRDBWithUser(t, pool)
 			require.NoError(t, err)
 
-			migrationDriver, err := crdbmigrations.NewCRDBDriver(connStrings[testuser])
+			migrationDriver, err := crdbmigrations.NewCRDBDriver(connStrings[testuser], "")
 			require.NoError(t, err)
 			require.NoError(t, crdbmigrations.CRDBMigrations.Run(ctx, migrationDriver, migrate.Head, migrate.LiveRun))
 
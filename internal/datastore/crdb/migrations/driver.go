@@ -28,13 +28,19 @@ type CRDBDriver struct {
 }
 
 // NewCRDBDriver creates a new driver with active connections to the database
-// specified.
-func NewCRDBDriver(url string) (*CRDBDriver, error) {
+// specified. If a schema is given, the tables are read and migrated in that
+// schema rather than in the search path of the server.
+func NewCRDBDriver(url string, schema string) (*CRDBDriver, error) {
+	if err := pgxcommon.ValidateSchemaName(schema); err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
 	connConfig, err := pgx.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 	pgxcommon.ConfigurePGXLogger(connConfig)
+	pgxcommon.ConfigureSchema(&connConfig.Config, schema)
 
 	db, err := pgx.ConnectConfig(context.Background(), connConfig)
 	if err != nil {
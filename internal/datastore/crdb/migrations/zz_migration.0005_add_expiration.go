@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const addRelationshipExpiration = `ALTER TABLE relation_tuple
+	ADD COLUMN expiration TIMESTAMPTZ;`
+
+func init() {
+	err := CRDBMigrations.Register("add-expiration-support", "add-caveats", addExpirationFunc, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
+
+func addExpirationFunc(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, addRelationshipExpiration)
+	return err
+}
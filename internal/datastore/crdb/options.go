@@ -20,6 +20,7 @@ type crdbOptions struct {
 	overlapStrategy             string
 	overlapKey                  string
 	disableStats                bool
+	schema                      string
 
 	enablePrometheusStats bool
 }
@@ -76,6 +77,10 @@ func generateConfig(options []Option) (crdbOptions, error) {
 		)
 	}
 
+	if err := pgxcommon.ValidateSchemaName(computed.schema); err != nil {
+		return computed, err
+	}
+
 	return computed, nil
 }
 
@@ -277,6 +282,15 @@ func DisableStats(disable bool) Option {
 	return func(po *crdbOptions) { po.disableStats = disable }
 }
 
+// Schema is the schema of the database in which the tables of the datastore are read and
+// written, so that several SpiceDB installations can share a database. The schema is created by
+// `spicedb migrate` if it does not exist.
+//
+// By default, the tables are found through the search path of the server.
+func Schema(schema string) Option {
+	return func(po *crdbOptions) { po.schema = schema }
+}
+
 // WithEnablePrometheusStats marks whether Prometheus metrics provided by the Postgres
 // clients being used by the datastore are enabled.
 //
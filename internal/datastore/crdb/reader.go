@@ -33,7 +33,13 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
-	)
+		colExpiration,
+	).Where(unexpiredTuple)
+
+	// unexpiredTuple filters out relationships whose expiration has passed. When reading at a
+	// snapshot via AS OF SYSTEM TIME, now() returns the snapshot's timestamp.
+	unexpiredTuple = sq.Or{sq.Eq{colExpiration: nil}, sq.Expr(colExpiration + " > now()")}
+	expiredTuple   = sq.Expr(colExpiration + " <= now()")
 
 	schema = common.NewSchemaInformation(
 		colNamespace,
@@ -187,6 +193,8 @@ func (cr *crdbReader) ReverseQueryRelationships(
 			ctx,
 			qBuilder,
 			options.WithLimit(queryOpts.ReverseLimit),
+			options.WithSort(queryOpts.SortForReverse),
+			options.WithAfter(queryOpts.AfterForReverse),
 		)
 		return err
 	})
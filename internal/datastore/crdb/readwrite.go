@@ -11,6 +11,7 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -47,7 +48,7 @@ type crdbReadWriteTXN struct {
 
 var (
 	upsertTupleSuffix = fmt.Sprintf(
-		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s",
+		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s, %s = excluded.%s",
 		colNamespace,
 		colObjectID,
 		colRelation,
@@ -59,6 +60,8 @@ var (
 		colCaveatContextName,
 		colCaveatContext,
 		colCaveatContext,
+		colExpiration,
+		colExpiration,
 	)
 
 	queryWriteTuple = psql.Insert(tableTuple).Columns(
@@ -70,6 +73,7 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
 	)
 
 	queryTouchTuple = queryWriteTuple.Suffix(upsertTupleSuffix)
@@ -92,6 +96,10 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 	bulkTouch := queryTouchTuple
 	var bulkTouchCount int64
 
+	// Relationships being created may still exist as expired rows which have not yet been
+	// garbage collected; those rows are removed before the insert.
+	expiredCreates := sq.Or{}
+
 	// Process the actual updates
 	for _, mutation := range mutations {
 		rel := mutation.Tuple
@@ -118,6 +126,7 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				common.ExpirationFor(rel),
 			)
 			bulkTouchCount++
 		case core.RelationTupleUpdate_CREATE:
@@ -131,8 +140,10 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				common.ExpirationFor(rel),
 			)
 			bulkWriteCount++
+			expiredCreates = append(expiredCreates, exactRelationshipClause(rel))
 		case core.RelationTupleUpdate_DELETE:
 			rwt.relCountChange--
 			sql, args, err := queryDeleteTuples.Where(exactRelationshipClause(rel)).ToSql()
@@ -149,6 +160,19 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 		}
 	}
 
+	if len(expiredCreates) > 0 {
+		sql, args, err := queryDeleteTuples.Where(expiredCreates).Where(expiredTuple).ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		modified, err := rwt.tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		rwt.relCountChange -= modified.RowsAffected()
+	}
+
 	bulkUpdateQueries := make([]sq.InsertBuilder, 0, 2)
 	if bulkWriteCount > 0 {
 		bulkUpdateQueries = append(bulkUpdateQueries, bulkWrite)
@@ -177,6 +201,83 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 	return nil
 }
 
+// bulkLoadBatchSize is the number of relationships written by each INSERT statement issued
+// by BulkLoad.
+const bulkLoadBatchSize = 1_000
+
+func (rwt *crdbReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numLoaded uint64
+	var next *core.RelationTuple
+	var err error
+
+	bulkWrite := queryWriteTuple
+	var bulkWriteCount uint64
+
+	flush := func() error {
+		if bulkWriteCount == 0 {
+			return nil
+		}
+
+		sql, args, err := bulkWrite.ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+			if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraint, err); cerr != nil {
+				return cerr
+			}
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		numLoaded += bulkWriteCount
+		bulkWrite = queryWriteTuple
+		bulkWriteCount = 0
+		return nil
+	}
+
+	for next, err = iter.Next(ctx); next != nil && err == nil; next, err = iter.Next(ctx) {
+		var caveatContext map[string]any
+		var caveatName string
+		if next.Caveat != nil {
+			caveatName = next.Caveat.CaveatName
+			caveatContext = next.Caveat.Context.AsMap()
+		}
+
+		rwt.addOverlapKey(next.ResourceAndRelation.Namespace)
+		rwt.addOverlapKey(next.Subject.Namespace)
+		rwt.relCountChange++
+
+		bulkWrite = bulkWrite.Values(
+			next.ResourceAndRelation.Namespace,
+			next.ResourceAndRelation.ObjectId,
+			next.ResourceAndRelation.Relation,
+			next.Subject.Namespace,
+			next.Subject.ObjectId,
+			next.Subject.Relation,
+			caveatName,
+			caveatContext,
+			common.ExpirationFor(next),
+		)
+		bulkWriteCount++
+
+		if bulkWriteCount >= bulkLoadBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	return numLoaded, nil
+}
+
 func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	return sq.Eq{
 		colNamespace:        r.ResourceAndRelation.Namespace,
@@ -2,10 +2,12 @@ package crdb
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -26,6 +28,11 @@ type changeDetails struct {
 	After    *struct {
 		CaveatContext map[string]any `json:"caveat_context"`
 		CaveatName    string         `json:"caveat_name"`
+		Expiration    *time.Time     `json:"expiration"`
+
+		// SerializedConfig and Definition are set for namespace and caveat changes, respectively.
+		SerializedConfig string `json:"serialized_config"`
+		Definition       string `json:"definition"`
 	}
 }
 
@@ -55,7 +62,8 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		return updates, errs
 	}
 
-	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, tableTuple, afterRevision)
+	tables := strings.Join([]string{tableTuple, tableNamespace, tableCaveat}, ", ")
+	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, tables, afterRevision)
 
 	go func() {
 		defer close(updates)
@@ -78,11 +86,11 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		defer func() { go changes.Close() }()
 
 		for changes.Next() {
-			var unused interface{}
+			var tableName string
 			var changeJSON []byte
 			var primaryKeyValuesJSON []byte
 
-			if err := changes.Scan(&unused, &primaryKeyValuesJSON, &changeJSON); err != nil {
+			if err := changes.Scan(&tableName, &primaryKeyValuesJSON, &changeJSON); err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
 				} else {
@@ -130,18 +138,68 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				continue
 			}
 
-			var pkValues [6]string
-			if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
-				errs <- err
-				return
-			}
-
 			revision, err := cds.RevisionFromString(details.Updated)
 			if err != nil {
 				errs <- fmt.Errorf("malformed update timestamp: %w", err)
 				return
 			}
 
+			pending, ok := pendingChanges[details.Updated]
+			if !ok {
+				pending = &datastore.RevisionChanges{
+					Revision: revision,
+				}
+				pendingChanges[details.Updated] = pending
+			}
+
+			switch tableName {
+			case tableNamespace:
+				var pkValues [1]string
+				if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
+					errs <- err
+					return
+				}
+
+				if details.After == nil {
+					pending.DeletedNamespaces = append(pending.DeletedNamespaces, pkValues[0])
+					continue
+				}
+
+				loaded := &core.NamespaceDefinition{}
+				if err := unmarshalChangefeedBytes(details.After.SerializedConfig, loaded); err != nil {
+					errs <- fmt.Errorf("malformed namespace in changefeed: %w", err)
+					return
+				}
+				pending.ChangedDefinitions = append(pending.ChangedDefinitions, loaded)
+				continue
+
+			case tableCaveat:
+				var pkValues [1]string
+				if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
+					errs <- err
+					return
+				}
+
+				if details.After == nil {
+					pending.DeletedCaveats = append(pending.DeletedCaveats, pkValues[0])
+					continue
+				}
+
+				loaded := &core.CaveatDefinition{}
+				if err := unmarshalChangefeedBytes(details.After.Definition, loaded); err != nil {
+					errs <- fmt.Errorf("malformed caveat in changefeed: %w", err)
+					return
+				}
+				pending.ChangedDefinitions = append(pending.ChangedDefinitions, loaded)
+				continue
+			}
+
+			var pkValues [6]string
+			if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
+				errs <- err
+				return
+			}
+
 			var caveatName string
 			var caveatContext map[string]any
 			if details.After != nil && details.After.CaveatName != "" {
@@ -174,15 +232,9 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				oneChange.Operation = core.RelationTupleUpdate_DELETE
 			} else {
 				oneChange.Operation = core.RelationTupleUpdate_TOUCH
+				oneChange.Tuple.OptionalExpirationTime = common.ExpirationFrom(details.After.Expiration)
 			}
 
-			pending, ok := pendingChanges[details.Updated]
-			if !ok {
-				pending = &datastore.RevisionChanges{
-					Revision: revision,
-				}
-				pendingChanges[details.Updated] = pending
-			}
 			pending.Changes = append(pending.Changes, oneChange)
 		}
 
@@ -202,3 +254,13 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 	}()
 	return updates, errs
 }
+
+// unmarshalChangefeedBytes decodes a BYTES column, which changefeeds encode in the JSON as an
+// escaped hex string, into the message.
+func unmarshalChangefeedBytes(encoded string, msg interface{ UnmarshalVT([]byte) error }) error {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(encoded, `\x`))
+	if err != nil {
+		return err
+	}
+	return msg.UnmarshalVT(decoded)
+}
@@ -100,11 +100,11 @@ func (mdb *memdbDatastore) SnapshotReader(revisionRaw datastore.Revision) datast
 	defer mdb.RUnlock()
 
 	if len(mdb.revisions) == 0 {
-		return &memdbReader{nil, nil, fmt.Errorf("memdb datastore is not ready")}
+		return &memdbReader{nil, nil, fmt.Errorf("memdb datastore is not ready"), time.Time{}}
 	}
 
 	if err := mdb.checkRevisionLocalCallerMustLock(dr); err != nil {
-		return &memdbReader{nil, nil, err}
+		return &memdbReader{nil, nil, err, time.Time{}}
 	}
 
 	revIndex := sort.Search(len(mdb.revisions), func(i int) bool {
@@ -118,7 +118,7 @@ func (mdb *memdbDatastore) SnapshotReader(revisionRaw datastore.Revision) datast
 
 	rev := mdb.revisions[revIndex]
 	if rev.db == nil {
-		return &memdbReader{nil, nil, fmt.Errorf("memdb datastore is already closed")}
+		return &memdbReader{nil, nil, fmt.Errorf("memdb datastore is already closed"), time.Time{}}
 	}
 
 	roTxn := rev.db.Txn(false)
@@ -126,7 +126,7 @@ func (mdb *memdbDatastore) SnapshotReader(revisionRaw datastore.Revision) datast
 		return roTxn, nil
 	}
 
-	return &memdbReader{noopTryLocker{}, txSrc, nil}
+	return &memdbReader{noopTryLocker{}, txSrc, nil, timeFromRevision(dr)}
 }
 
 func (mdb *memdbDatastore) ReadWriteTx(
@@ -161,7 +161,7 @@ func (mdb *memdbDatastore) ReadWriteTx(
 		}
 
 		newRevision := mdb.newRevisionID()
-		rwt := &memdbReadWriteTx{memdbReader{&sync.Mutex{}, txSrc, nil}, newRevision}
+		rwt := &memdbReadWriteTx{memdbReader{&sync.Mutex{}, txSrc, nil, timeFromRevision(newRevision)}, newRevision}
 		if err := f(rwt); err != nil {
 			mdb.Lock()
 			if tx != nil {
@@ -194,7 +194,30 @@ func (mdb *memdbDatastore) ReadWriteTx(
 		}
 		if tx != nil {
 			for _, change := range tx.Changes() {
-				if change.Table == tableRelationship {
+				switch change.Table {
+				case tableNamespace:
+					if change.After != nil {
+						loaded := &corev1.NamespaceDefinition{}
+						if err := loaded.UnmarshalVT(change.After.(*namespace).configBytes); err != nil {
+							return datastore.NoRevision, err
+						}
+						newChanges.ChangedDefinitions = append(newChanges.ChangedDefinitions, loaded)
+					} else if change.Before != nil {
+						newChanges.DeletedNamespaces = append(newChanges.DeletedNamespaces, change.Before.(*namespace).name)
+					}
+
+				case tableCaveats:
+					if change.After != nil {
+						loaded, err := change.After.(*caveat).Unwrap()
+						if err != nil {
+							return datastore.NoRevision, err
+						}
+						newChanges.ChangedDefinitions = append(newChanges.ChangedDefinitions, loaded)
+					} else if change.Before != nil {
+						newChanges.DeletedCaveats = append(newChanges.DeletedCaveats, change.Before.(*caveat).name)
+					}
+
+				case tableRelationship:
 					if change.After != nil {
 						rt, err := change.After.(*relationship).RelationTuple()
 						if err != nil {
@@ -0,0 +1,355 @@
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/shopspring/decimal"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+const snapshotFormatVersion = 1
+
+// snapshotFile is the serialized form of the contents of the datastore at a revision.
+type snapshotFile struct {
+	Version       int                    `json:"version"`
+	Revision      string                 `json:"revision"`
+	Namespaces    []snapshotDefinition   `json:"namespaces"`
+	Caveats       []snapshotDefinition   `json:"caveats"`
+	Relationships []snapshotRelationship `json:"relationships"`
+}
+
+type snapshotDefinition struct {
+	Name       string `json:"name"`
+	Definition []byte `json:"definition"`
+	Revision   string `json:"revision"`
+}
+
+type snapshotRelationship struct {
+	Namespace        string         `json:"namespace"`
+	ResourceID       string         `json:"resource_id"`
+	Relation         string         `json:"relation"`
+	SubjectNamespace string         `json:"subject_namespace"`
+	SubjectObjectID  string         `json:"subject_object_id"`
+	SubjectRelation  string         `json:"subject_relation"`
+	CaveatName       string         `json:"caveat_name,omitempty"`
+	CaveatContext    map[string]any `json:"caveat_context,omitempty"`
+	Expiration       *time.Time     `json:"expiration,omitempty"`
+}
+
+// SnapshottableDatastore is a memdb datastore whose contents can be saved to and loaded from
+// snapshots.
+type SnapshottableDatastore interface {
+	datastore.Datastore
+
+	// SaveSnapshot writes the namespaces, caveats and relationships of the datastore at its head
+	// revision, along with the revision itself, and returns the revision written.
+	SaveSnapshot(w io.Writer) (datastore.Revision, error)
+
+	// LoadSnapshot replaces the contents of the datastore with those of the snapshot. The head
+	// revision becomes that of the snapshot, and previous revisions are no longer readable.
+	LoadSnapshot(r io.Reader) error
+}
+
+func (mdb *memdbDatastore) SaveSnapshot(w io.Writer) (datastore.Revision, error) {
+	mdb.RLock()
+	if mdb.db == nil {
+		mdb.RUnlock()
+		return datastore.NoRevision, fmt.Errorf("datastore is closed")
+	}
+
+	// The latest snapshot is immutable, so it can be read after releasing the lock.
+	head := mdb.revisions[len(mdb.revisions)-1]
+	mdb.RUnlock()
+
+	tx := head.db.Txn(false)
+	defer tx.Abort()
+
+	file := snapshotFile{
+		Version:  snapshotFormatVersion,
+		Revision: head.revision.String(),
+	}
+
+	it, err := tx.LowerBound(tableNamespace, indexID)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	for found := it.Next(); found != nil; found = it.Next() {
+		ns := found.(*namespace)
+		file.Namespaces = append(file.Namespaces, snapshotDefinition{
+			Name:       ns.name,
+			Definition: ns.configBytes,
+			Revision:   ns.updated.String(),
+		})
+	}
+
+	it, err = tx.LowerBound(tableCaveats, indexID)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	for found := it.Next(); found != nil; found = it.Next() {
+		c := found.(*caveat)
+		file.Caveats = append(file.Caveats, snapshotDefinition{
+			Name:       c.name,
+			Definition: c.definition,
+			Revision:   c.revision.String(),
+		})
+	}
+
+	it, err = tx.Get(tableRelationship, indexID)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	for found := it.Next(); found != nil; found = it.Next() {
+		rel := found.(*relationship)
+		serialized := snapshotRelationship{
+			Namespace:        rel.namespace,
+			ResourceID:       rel.resourceID,
+			Relation:         rel.relation,
+			SubjectNamespace: rel.subjectNamespace,
+			SubjectObjectID:  rel.subjectObjectID,
+			SubjectRelation:  rel.subjectRelation,
+			Expiration:       rel.expiration,
+		}
+		if rel.caveat != nil {
+			serialized.CaveatName = rel.caveat.caveatName
+			serialized.CaveatContext = rel.caveat.context
+		}
+		file.Relationships = append(file.Relationships, serialized)
+	}
+
+	if err := json.NewEncoder(w).Encode(file); err != nil {
+		return datastore.NoRevision, fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return revision.NewFromDecimal(head.revision), nil
+}
+
+func (mdb *memdbDatastore) LoadSnapshot(r io.Reader) error {
+	var file snapshotFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	if file.Version != snapshotFormatVersion {
+		return fmt.Errorf("unsupported snapshot version %d", file.Version)
+	}
+
+	snapshotRevision, err := decimal.NewFromString(file.Revision)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot revision: %w", err)
+	}
+
+	db, err := memdb.NewMemDB(schema)
+	if err != nil {
+		return err
+	}
+
+	tx := db.Txn(true)
+	defer tx.Abort()
+
+	for _, def := range file.Namespaces {
+		defRevision, err := decimal.NewFromString(def.Revision)
+		if err != nil {
+			return fmt.Errorf("invalid revision for namespace `%s`: %w", def.Name, err)
+		}
+
+		if err := tx.Insert(tableNamespace, &namespace{def.Name, def.Definition, revision.NewFromDecimal(defRevision)}); err != nil {
+			return err
+		}
+	}
+
+	for _, def := range file.Caveats {
+		defRevision, err := decimal.NewFromString(def.Revision)
+		if err != nil {
+			return fmt.Errorf("invalid revision for caveat `%s`: %w", def.Name, err)
+		}
+
+		if err := tx.Insert(tableCaveats, &caveat{def.Name, def.Definition, revision.NewFromDecimal(defRevision)}); err != nil {
+			return err
+		}
+	}
+
+	for _, serialized := range file.Relationships {
+		rel := &relationship{
+			namespace:        serialized.Namespace,
+			resourceID:       serialized.ResourceID,
+			relation:         serialized.Relation,
+			subjectNamespace: serialized.SubjectNamespace,
+			subjectObjectID:  serialized.SubjectObjectID,
+			subjectRelation:  serialized.SubjectRelation,
+			expiration:       serialized.Expiration,
+		}
+		if serialized.CaveatName != "" {
+			rel.caveat = &contextualizedCaveat{serialized.CaveatName, serialized.CaveatContext}
+		}
+
+		if err := tx.Insert(tableRelationship, rel); err != nil {
+			return err
+		}
+	}
+	tx.Commit()
+
+	mdb.Lock()
+	defer mdb.Unlock()
+
+	if mdb.db == nil {
+		return fmt.Errorf("datastore is closed")
+	}
+	if mdb.activeWriteTxn != nil {
+		return errSerialization
+	}
+
+	mdb.db = db
+	mdb.revisions = []snapshot{{snapshotRevision, db.Snapshot()}}
+	return nil
+}
+
+// NewPersistentMemdbDatastore creates a new memdb datastore whose contents are kept in a snapshot
+// file: the file is loaded when it exists, and written whenever the interval elapses after new
+// revisions, as well as when the datastore is closed. An interval of 0 disables the periodic
+// snapshots.
+func NewPersistentMemdbDatastore(
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+	snapshotPath string,
+	snapshotInterval time.Duration,
+) (datastore.Datastore, error) {
+	ds, err := NewMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	pds := &persistentDatastore{
+		memdbDatastore: ds.(*memdbDatastore),
+		path:           snapshotPath,
+		stop:           make(chan struct{}),
+	}
+
+	f, err := os.Open(snapshotPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		log.Info().Str("path", snapshotPath).Msg("no memdb snapshot found, starting empty")
+	case err != nil:
+		return nil, fmt.Errorf("unable to open memdb snapshot: %w", err)
+	default:
+		defer f.Close()
+		if err := pds.LoadSnapshot(f); err != nil {
+			return nil, fmt.Errorf("unable to load memdb snapshot %s: %w", snapshotPath, err)
+		}
+
+		log.Info().Str("path", snapshotPath).Stringer("revision", pds.headRevisionNoLock()).Msg("loaded memdb snapshot")
+	}
+
+	// The datastore is not shared yet, and its current contents are those of the file, if any.
+	pds.lastSaved = pds.headRevisionNoLock()
+
+	if snapshotInterval > 0 {
+		pds.wg.Add(1)
+		go pds.snapshotPeriodically(snapshotInterval)
+	}
+
+	return pds, nil
+}
+
+type persistentDatastore struct {
+	*memdbDatastore
+
+	path      string
+	lastSaved decimal.Decimal
+	saveLock  sync.Mutex
+	stop      chan struct{}
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+func (pds *persistentDatastore) snapshotPeriodically(interval time.Duration) {
+	defer pds.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pds.stop:
+			return
+		case <-ticker.C:
+			if err := pds.saveSnapshotFile(); err != nil {
+				log.Warn().Err(err).Str("path", pds.path).Msg("failed to write memdb snapshot")
+			}
+		}
+	}
+}
+
+// saveSnapshotFile writes the snapshot file if the head revision changed since it was last
+// written. The snapshot is written to a temporary file which then replaces the snapshot file, so
+// that a crash while writing leaves the previous snapshot intact.
+func (pds *persistentDatastore) saveSnapshotFile() error {
+	pds.saveLock.Lock()
+	defer pds.saveLock.Unlock()
+
+	head, err := pds.HeadRevision(context.Background())
+	if err != nil {
+		return err
+	}
+	if head.(revision.Decimal).Decimal.Equal(pds.lastSaved) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(pds.path), filepath.Base(pds.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	saved, err := pds.SaveSnapshot(tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), pds.path); err != nil {
+		return err
+	}
+
+	pds.lastSaved = saved.(revision.Decimal).Decimal
+	log.Debug().Str("path", pds.path).Stringer("revision", saved).Msg("wrote memdb snapshot")
+	return nil
+}
+
+func (pds *persistentDatastore) Close() error {
+	pds.stopOnce.Do(func() {
+		close(pds.stop)
+	})
+	pds.wg.Wait()
+
+	if err := pds.saveSnapshotFile(); err != nil {
+		return fmt.Errorf("failed to write memdb snapshot: %w", err)
+	}
+	return pds.memdbDatastore.Close()
+}
+
+var (
+	_ SnapshottableDatastore = &memdbDatastore{}
+	_ SnapshottableDatastore = &persistentDatastore{}
+)
@@ -0,0 +1,166 @@
+package memdb
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+const testSnapshotContents = `schema: |-
+  definition user {}
+
+  caveat only_on_tuesday(day_of_week string) {
+    day_of_week == 'tuesday'
+  }
+
+  definition document {
+    relation viewer: user | user with only_on_tuesday
+  }
+relationships: |-
+  document:firstdoc#viewer@user:tom
+  document:seconddoc#viewer@user:sarah[only_on_tuesday:{"day_of_week":"tuesday"}]
+`
+
+func readContents(t *testing.T, ds datastore.Datastore) (datastore.Revision, []string, []string, []string) {
+	ctx := context.Background()
+
+	head, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	reader := ds.SnapshotReader(head)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	require.NoError(t, err)
+
+	var namespaceNames []string
+	for _, ns := range namespaces {
+		namespaceNames = append(namespaceNames, ns.Definition.Name)
+	}
+
+	caveats, err := reader.ListAllCaveats(ctx)
+	require.NoError(t, err)
+
+	var caveatNames []string
+	for _, caveat := range caveats {
+		caveatNames = append(caveatNames, caveat.Definition.Name)
+	}
+
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "document"})
+	require.NoError(t, err)
+	defer iter.Close()
+
+	var relationships []string
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		relationships = append(relationships, tuple.MustString(tpl))
+	}
+	require.NoError(t, iter.Err())
+
+	return head, namespaceNames, caveatNames, relationships
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(t, err)
+
+	_, _, err = validationfile.PopulateFromFilesContents(ctx, ds, map[string][]byte{"test": []byte(testSnapshotContents)})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	saved, err := ds.(SnapshottableDatastore).SaveSnapshot(&buf)
+	require.NoError(t, err)
+
+	restored, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(t, err)
+	require.NoError(t, restored.(SnapshottableDatastore).LoadSnapshot(&buf))
+
+	expectedHead, expectedNamespaces, expectedCaveats, expectedRelationships := readContents(t, ds)
+	head, namespaces, caveats, relationships := readContents(t, restored)
+
+	require.True(t, saved.Equal(expectedHead))
+	require.True(t, head.Equal(expectedHead))
+	require.ElementsMatch(t, expectedNamespaces, namespaces)
+	require.ElementsMatch(t, expectedCaveats, caveats)
+	require.ElementsMatch(t, expectedRelationships, relationships)
+	require.Len(t, relationships, 2)
+
+	// Revisions continue from the restored revision.
+	written, err := restored.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Touch(tuple.MustParse("document:thirddoc#viewer@user:tom")),
+		})
+	})
+	require.NoError(t, err)
+	require.True(t, written.GreaterThan(head))
+}
+
+func TestLoadSnapshotInvalidVersion(t *testing.T) {
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(t, err)
+
+	err = ds.(SnapshottableDatastore).LoadSnapshot(bytes.NewBufferString(`{"version": 42}`))
+	require.ErrorContains(t, err, "unsupported snapshot version 42")
+}
+
+func TestPersistentDatastore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	ds, err := NewPersistentMemdbDatastore(0, 0, DisableGC, path, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	_, _, err = validationfile.PopulateFromFilesContents(ctx, ds, map[string][]byte{"test": []byte(testSnapshotContents)})
+	require.NoError(t, err)
+
+	expectedHead, _, _, _ := readContents(t, ds)
+
+	// The periodic snapshot picks up the changes.
+	require.Eventually(t, func() bool {
+		f, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+
+		restored, err := NewMemdbDatastore(0, 0, DisableGC)
+		require.NoError(t, err)
+
+		require.NoError(t, restored.(SnapshottableDatastore).LoadSnapshot(f))
+		head, _, _, _ := readContents(t, restored)
+		return head.Equal(expectedHead)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Touch(tuple.MustParse("document:thirddoc#viewer@user:tom")),
+		})
+	})
+	require.NoError(t, err)
+	expectedHead, _, _, expectedRelationships := readContents(t, ds)
+
+	// Closing writes the final snapshot, which is loaded on startup.
+	require.NoError(t, ds.Close())
+
+	reopened, err := NewPersistentMemdbDatastore(0, 0, DisableGC, path, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, reopened.Close())
+	})
+
+	head, namespaces, caveats, relationships := readContents(t, reopened)
+	require.True(t, head.Equal(expectedHead))
+	require.ElementsMatch(t, []string{"user", "document"}, namespaces)
+	require.ElementsMatch(t, []string{"only_on_tuesday"}, caveats)
+	require.ElementsMatch(t, expectedRelationships, relationships)
+	require.Len(t, relationships, 3)
+}
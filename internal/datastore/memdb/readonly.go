@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
@@ -19,6 +20,9 @@ type memdbReader struct {
 	TryLocker
 	txSource txFactory
 	initErr  error
+
+	// now is the time at which relationship expiration is evaluated.
+	now time.Time
 }
 
 // QueryRelationships reads relationships starting from the resource side.
@@ -57,6 +61,7 @@ func (r *memdbReader) QueryRelationships(
 		filter.OptionalSubjectsSelectors,
 		filter.OptionalCaveatName,
 		queryOpts.Usersets,
+		r.now,
 		makeCursorFilterFn(queryOpts.After, queryOpts.Sort),
 	)
 	filteredIterator := memdb.NewFilterIterator(bestIterator, matchingRelationshipsFilterFunc)
@@ -91,13 +96,8 @@ func (r *memdbReader) ReverseQueryRelationships(
 
 	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
 
-	iterator, err := tx.Get(
-		tableRelationship,
-		indexSubjectNamespace,
-		subjectsFilter.SubjectType,
-	)
-	if err != nil {
-		return nil, err
+	if queryOpts.AfterForReverse != nil && queryOpts.SortForReverse == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
 	}
 
 	filterObjectType, filterRelation := "", ""
@@ -106,6 +106,32 @@ func (r *memdbReader) ReverseQueryRelationships(
 		filterRelation = queryOpts.ResRelation.Relation
 	}
 
+	var iterator memdb.ResultIterator
+	switch {
+	case queryOpts.SortForReverse == options.Unsorted:
+		iterator, err = tx.Get(
+			tableRelationship,
+			indexSubjectNamespace,
+			subjectsFilter.SubjectType,
+		)
+
+	case queryOpts.ResRelation != nil:
+		// The namespace and relation index is ordered by the primary key within each
+		// namespace and relation, which matches the order expected by ByResource.
+		iterator, err = tx.Get(
+			tableRelationship,
+			indexNamespaceAndRelation,
+			filterObjectType,
+			filterRelation,
+		)
+
+	default:
+		return nil, fmt.Errorf("sorted reverse queries require a resource relation")
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	matchingRelationshipsFilterFunc := filterFuncForFilters(
 		filterObjectType,
 		nil,
@@ -113,11 +139,12 @@ func (r *memdbReader) ReverseQueryRelationships(
 		[]datastore.SubjectsSelector{subjectsFilter.AsSelector()},
 		"",
 		nil,
-		noopCursorFilter,
+		r.now,
+		makeCursorFilterFn(queryOpts.AfterForReverse, queryOpts.SortForReverse),
 	)
 	filteredIterator := memdb.NewFilterIterator(iterator, matchingRelationshipsFilterFunc)
 
-	return newMemdbTupleIterator(filteredIterator, queryOpts.ReverseLimit, options.Unsorted), nil
+	return newMemdbTupleIterator(filteredIterator, queryOpts.ReverseLimit, queryOpts.SortForReverse), nil
 }
 
 // ReadNamespace reads a namespace definition and version and returns it, and the revision at
@@ -269,6 +296,7 @@ func filterFuncForFilters(
 	optionalSubjectsSelectors []datastore.SubjectsSelector,
 	optionalCaveatFilter string,
 	usersets []*core.ObjectAndRelation,
+	now time.Time,
 	cursorFilter func(*relationship) bool,
 ) memdb.FilterFunc {
 	return func(tupleRaw interface{}) bool {
@@ -283,6 +311,8 @@ func filterFuncForFilters(
 			return true
 		case optionalCaveatFilter != "" && (tuple.caveat == nil || tuple.caveat.caveatName != optionalCaveatFilter):
 			return true
+		case tuple.isExpiredAt(now):
+			return true
 		}
 
 		applySubjectSelector := func(selector datastore.SubjectsSelector) bool {
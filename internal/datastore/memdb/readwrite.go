@@ -44,6 +44,7 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 			mutation.Tuple.Subject.ObjectId,
 			mutation.Tuple.Subject.Relation,
 			rwt.toCaveatReference(mutation),
+			common.ExpirationFor(mutation.Tuple),
 		}
 
 		found, err := tx.First(
@@ -67,7 +68,7 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 
 		switch mutation.Operation {
 		case core.RelationTupleUpdate_CREATE:
-			if existing != nil {
+			if existing != nil && !existing.isExpiredAt(rwt.now) {
 				rt, err := existing.RelationTuple()
 				if err != nil {
 					return err
@@ -93,6 +94,27 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 	return nil
 }
 
+func (rwt *memdbReadWriteTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numCopied uint64
+	var next *core.RelationTuple
+	var err error
+
+	// The lock is only held while writing, as the source may itself use the transaction, such as
+	// to validate the relationships it returns. The memdb relationship row copies all of the
+	// fields it needs, so the update can be reused even though the source may reuse the same
+	// tuple for each call.
+	updates := []*core.RelationTupleUpdate{{Operation: core.RelationTupleUpdate_CREATE}}
+	for next, err = iter.Next(ctx); next != nil && err == nil; next, err = iter.Next(ctx) {
+		updates[0].Tuple = next
+		if err := rwt.WriteRelationships(ctx, updates); err != nil {
+			return 0, err
+		}
+		numCopied++
+	}
+
+	return numCopied, err
+}
+
 func (rwt *memdbReadWriteTx) toCaveatReference(mutation *core.RelationTupleUpdate) *contextualizedCaveat {
 	var cr *contextualizedCaveat
 	if mutation.Tuple.Caveat != nil {
@@ -10,6 +10,10 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 )
 
+func timeFromRevision(rev revision.Decimal) time.Time {
+	return time.Unix(0, rev.IntPart()).UTC()
+}
+
 func revisionFromTimestamp(t time.Time) revision.Decimal {
 	return revision.NewFromDecimal(decimal.NewFromInt(t.UnixNano()))
 }
@@ -28,11 +32,14 @@ func (mdb *memdbDatastore) newRevisionID() revision.Decimal {
 	// macOS. We therefore check if the created transaction ID matches that
 	// previously created and, if not, add to it.
 	//
+	// The previous revision can also be ahead of the clock when it was loaded
+	// from a snapshot, in which case revisions continue from it.
+	//
 	// See: https://github.com/golang/go/issues/22037 which appeared to fix
 	// this in Go 1.9.2, but there appears to have been a reversion with either
 	// the new version of macOS or Go.
-	if created.Equals(existing) {
-		return revision.NewFromDecimal(created.Add(decimal.NewFromInt(1)))
+	if created.LessThanOrEqual(existing) {
+		return revision.NewFromDecimal(existing.Add(decimal.NewFromInt(1)))
 	}
 	return revision.NewFromDecimal(created)
 }
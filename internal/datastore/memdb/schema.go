@@ -1,12 +1,15 @@
 package memdb
 
 import (
+	"time"
+
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
 	"github.com/rs/zerolog"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
@@ -42,6 +45,7 @@ type relationship struct {
 	subjectObjectID  string
 	subjectRelation  string
 	caveat           *contextualizedCaveat
+	expiration       *time.Time
 }
 
 type contextualizedCaveat struct {
@@ -109,10 +113,15 @@ func (r relationship) RelationTuple() (*core.RelationTuple, error) {
 			ObjectId:  r.subjectObjectID,
 			Relation:  r.subjectRelation,
 		},
-		Caveat: cr,
+		Caveat:                 cr,
+		OptionalExpirationTime: common.ExpirationFrom(r.expiration),
 	}, nil
 }
 
+func (r relationship) isExpiredAt(now time.Time) bool {
+	return r.expiration != nil && !r.expiration.After(now)
+}
+
 type changelog struct {
 	revisionNanos int64
 	changes       datastore.RevisionChanges
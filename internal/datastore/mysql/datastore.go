@@ -47,6 +47,7 @@ const (
 	colCaveatDefinition = "definition"
 	colCaveatName       = "caveat_name"
 	colCaveatContext    = "caveat_context"
+	colExpiration       = "expiration"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	liveDeletedTxnID       = uint64(math.MaxInt64)
@@ -271,6 +272,7 @@ func (mds *Datastore) SnapshotReader(revisionRaw datastore.Revision) datastore.R
 		createTxFunc,
 		querySplitter,
 		buildLivingObjectFilterForRevision(rev),
+		unexpiredAtRevision(mds.driver.RelationTupleTransaction(), rev),
 	}
 }
 
@@ -307,6 +309,7 @@ func (mds *Datastore) ReadWriteTx(
 					longLivedTx,
 					querySplitter,
 					currentlyLivingObjects,
+					unexpiredTuple,
 				},
 				tx,
 				newTxnID,
@@ -376,6 +379,7 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 
 			var caveatName string
 			var caveatContext caveatContextWrapper
+			var expiration *time.Time
 			err := rows.Scan(
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
@@ -385,6 +389,7 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 				&nextTuple.Subject.Relation,
 				&caveatName,
 				&caveatContext,
+				&expiration,
 			)
 			if err != nil {
 				return nil, fmt.Errorf(errUnableToQueryTuples, err)
@@ -394,6 +399,7 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 			if err != nil {
 				return nil, fmt.Errorf(errUnableToQueryTuples, err)
 			}
+			nextTuple.OptionalExpirationTime = common.ExpirationFrom(expiration)
 
 			tuples = append(tuples, nextTuple)
 		}
@@ -83,6 +83,11 @@ func (mds *Datastore) DeleteBeforeTx(
 	return
 }
 
+// DeleteExpiredRels removes relationships which expired before the given time.
+func (mds *Datastore) DeleteExpiredRels(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	return mds.batchDelete(ctx, mds.driver.RelationTuple(), sq.Lt{colExpiration: expiredBefore.UTC()})
+}
+
 // TODO (@vroldanbet) dupe from postgres datastore - need to refactor
 // - query was reworked to make it compatible with Vitess
 // - API differences with PSQL driver
@@ -0,0 +1,19 @@
+package migrations
+
+import "fmt"
+
+func addExpirationToRelationTuplesTable(t *tables) string {
+	return fmt.Sprintf(`ALTER TABLE %s
+		ADD COLUMN expiration DATETIME(6) NULL DEFAULT NULL,
+		ADD INDEX ix_relation_tuple_by_expiration (expiration);`,
+		t.RelationTuple(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_expiration", "extend_object_id", noNonatomicMigration,
+		newStatementBatch(
+			addExpirationToRelationTuplesTable,
+		).execute,
+	)
+}
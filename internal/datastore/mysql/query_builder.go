@@ -12,10 +12,11 @@ type QueryBuilder struct {
 	GetLastRevision  sq.SelectBuilder
 	GetRevisionRange sq.SelectBuilder
 
-	WriteNamespaceQuery        sq.InsertBuilder
-	ReadNamespaceQuery         sq.SelectBuilder
-	DeleteNamespaceQuery       sq.UpdateBuilder
-	DeleteNamespaceTuplesQuery sq.UpdateBuilder
+	WriteNamespaceQuery         sq.InsertBuilder
+	ReadNamespaceQuery          sq.SelectBuilder
+	DeleteNamespaceQuery        sq.UpdateBuilder
+	DeleteNamespaceTuplesQuery  sq.UpdateBuilder
+	QueryChangedNamespacesQuery sq.SelectBuilder
 
 	QueryTupleIdsQuery    sq.SelectBuilder
 	QueryTuplesQuery      sq.SelectBuilder
@@ -25,10 +26,11 @@ type QueryBuilder struct {
 	QueryChangedQuery     sq.SelectBuilder
 	CountTupleQuery       sq.SelectBuilder
 
-	WriteCaveatQuery  sq.InsertBuilder
-	ReadCaveatQuery   sq.SelectBuilder
-	ListCaveatsQuery  sq.SelectBuilder
-	DeleteCaveatQuery sq.UpdateBuilder
+	WriteCaveatQuery         sq.InsertBuilder
+	ReadCaveatQuery          sq.SelectBuilder
+	ListCaveatsQuery         sq.SelectBuilder
+	DeleteCaveatQuery        sq.UpdateBuilder
+	QueryChangedCaveatsQuery sq.SelectBuilder
 }
 
 // NewQueryBuilder returns a new QueryBuilder instance. The migration
@@ -44,6 +46,7 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.WriteNamespaceQuery = writeNamespace(driver.Namespace())
 	builder.ReadNamespaceQuery = readNamespace(driver.Namespace())
 	builder.DeleteNamespaceQuery = deleteNamespace(driver.Namespace())
+	builder.QueryChangedNamespacesQuery = queryChangedDefinitions(driver.Namespace(), colNamespace, colConfig)
 
 	// tuple builders
 	builder.QueryTupleIdsQuery = queryTupleIds(driver.RelationTuple())
@@ -60,6 +63,7 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.ListCaveatsQuery = listCaveats(driver.Caveat())
 	builder.WriteCaveatQuery = writeCaveat(driver.Caveat())
 	builder.DeleteCaveatQuery = deleteCaveat(driver.Caveat())
+	builder.QueryChangedCaveatsQuery = queryChangedDefinitions(driver.Caveat(), colName, colCaveatDefinition)
 
 	return &builder
 }
@@ -128,6 +132,7 @@ func queryTuples(tableTuple string) sq.SelectBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
 	).From(tableTuple)
 }
 
@@ -155,6 +160,7 @@ func writeTuple(tableTuple string) sq.InsertBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
 		colCreatedTxn,
 	)
 }
@@ -169,7 +175,17 @@ func queryChanged(tableTuple string) sq.SelectBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
 		colCreatedTxn,
 		colDeletedTxn,
 	).From(tableTuple)
 }
+
+func queryChangedDefinitions(tableDefinition, colDefinitionName, colDefinition string) sq.SelectBuilder {
+	return sb.Select(
+		colDefinitionName,
+		colDefinition,
+		colCreatedTxn,
+		colDeletedTxn,
+	).From(tableDefinition)
+}
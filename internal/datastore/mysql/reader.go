@@ -26,6 +26,7 @@ type mysqlReader struct {
 	txSource      txFactory
 	querySplitter common.TupleQuerySplitter
 	filterer      queryFilterer
+	unexpired     sq.Sqlizer
 }
 
 type queryFilterer func(original sq.SelectBuilder) sq.SelectBuilder
@@ -48,13 +49,32 @@ var schema = common.NewSchemaInformation(
 	common.TupleComparison,
 )
 
+var (
+	// unexpiredTuple filters out relationships which have expired at the time of the current
+	// statement, for reads within a read-write transaction.
+	unexpiredTuple = sq.Or{sq.Eq{colExpiration: nil}, sq.Expr(colExpiration + " > UTC_TIMESTAMP(6)")}
+	expiredTuple   = sq.Expr(colExpiration + " <= UTC_TIMESTAMP(6)")
+)
+
+// unexpiredAtRevision filters out relationships which had expired at the time of the revision,
+// which is the timestamp of the transaction the revision identifies.
+func unexpiredAtRevision(tableTransaction string, rev revision.Decimal) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{colExpiration: nil},
+		sq.Expr(
+			fmt.Sprintf("%s > (SELECT %s FROM %s WHERE %s = ?)", colExpiration, colTimestamp, tableTransaction, colID),
+			transactionFromRevision(rev),
+		),
+	}
+}
+
 func (mr *mysqlReader) QueryRelationships(
 	ctx context.Context,
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery)).FilterWithRelationshipsFilter(filter)
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery.Where(mr.unexpired))).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +88,7 @@ func (mr *mysqlReader) ReverseQueryRelationships(
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery)).
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery.Where(mr.unexpired))).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -86,6 +106,8 @@ func (mr *mysqlReader) ReverseQueryRelationships(
 		ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
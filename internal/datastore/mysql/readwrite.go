@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
@@ -70,8 +71,13 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		tpl := mut.Tuple
 
 		// Implementation for TOUCH deviates from PostgreSQL datastore to prevent a deadlock in MySQL
-		if mut.Operation == core.RelationTupleUpdate_TOUCH || mut.Operation == core.RelationTupleUpdate_DELETE {
+		switch mut.Operation {
+		case core.RelationTupleUpdate_TOUCH, core.RelationTupleUpdate_DELETE:
 			clauses = append(clauses, exactRelationshipClause(tpl))
+		case core.RelationTupleUpdate_CREATE:
+			// An expired relationship which has not yet been garbage collected must not
+			// prevent the relationship from being created again.
+			clauses = append(clauses, sq.And{exactRelationshipClause(tpl), expiredTuple})
 		}
 
 		var caveatName string
@@ -90,6 +96,7 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 				tpl.Subject.Relation,
 				caveatName,
 				&caveatContext,
+				common.ExpirationFor(tpl),
 				rwt.newTxnID,
 			)
 			bulkWriteHasValues = true
@@ -156,6 +163,71 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	return nil
 }
 
+// bulkInsertRowsLimit is the number of relationships written by each INSERT statement issued
+// by BulkLoad, chosen to stay well below the placeholder limit of MySQL prepared statements.
+const bulkInsertRowsLimit = 1_000
+
+func (rwt *mysqlReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var sqlStmt bytes.Buffer
+
+	baseQuery, _, err := rwt.WriteTupleQuery.Values(1, 2, 3, 4, 5, 6, 7, 8, 9, 10).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var numLoaded uint64
+	var tpl *core.RelationTuple
+	tpl, err = iter.Next(ctx)
+	for tpl != nil && err == nil {
+		sqlStmt.Reset()
+		sqlStmt.WriteString(baseQuery)
+		var args []interface{}
+		var batchLen uint64
+
+		for ; tpl != nil && err == nil && batchLen < bulkInsertRowsLimit; tpl, err = iter.Next(ctx) {
+			if batchLen != 0 {
+				sqlStmt.WriteString(",(?,?,?,?,?,?,?,?,?,?)")
+			}
+
+			var caveatName string
+			var caveatContext caveatContextWrapper
+			if tpl.Caveat != nil {
+				caveatName = tpl.Caveat.CaveatName
+				caveatContext = tpl.Caveat.Context.AsMap()
+			}
+			args = append(args,
+				tpl.ResourceAndRelation.Namespace,
+				tpl.ResourceAndRelation.ObjectId,
+				tpl.ResourceAndRelation.Relation,
+				tpl.Subject.Namespace,
+				tpl.Subject.ObjectId,
+				tpl.Subject.Relation,
+				caveatName,
+				&caveatContext,
+				common.ExpirationFor(tpl),
+				rwt.newTxnID,
+			)
+			batchLen++
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := rwt.tx.ExecContext(ctx, sqlStmt.String(), args...); err != nil {
+			if cerr := convertToWriteConstraintError(err); cerr != nil {
+				return 0, cerr
+			}
+			return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		numLoaded += batchLen
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return numLoaded, nil
+}
+
 func (rwt *mysqlReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
 	// Add clauses for the ResourceFilter
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -17,7 +18,7 @@ const (
 	watchSleep = 100 * time.Millisecond
 )
 
-// Watch notifies the caller about all changes to tuples.
+// Watch notifies the caller about all changes to tuples, namespaces and caveats.
 //
 // All events following afterRevision will be sent to the caller.
 //
@@ -91,7 +92,7 @@ func (mds *Datastore) loadChanges(
 		return
 	}
 
-	sql, args, err := mds.QueryChangedQuery.Where(sq.Or{
+	inRange := sq.Or{
 		sq.And{
 			sq.Gt{colCreatedTxn: afterRevision},
 			sq.LtOrEq{colCreatedTxn: newRevision},
@@ -100,7 +101,9 @@ func (mds *Datastore) loadChanges(
 			sq.Gt{colDeletedTxn: afterRevision},
 			sq.LtOrEq{colDeletedTxn: newRevision},
 		},
-	}).ToSql()
+	}
+
+	sql, args, err := mds.QueryChangedQuery.Where(inRange).ToSql()
 	if err != nil {
 		return
 	}
@@ -126,6 +129,7 @@ func (mds *Datastore) loadChanges(
 		var deletedTxn uint64
 		var caveatName string
 		var caveatContext caveatContextWrapper
+		var expiration *time.Time
 		err = rows.Scan(
 			&nextTuple.ResourceAndRelation.Namespace,
 			&nextTuple.ResourceAndRelation.ObjectId,
@@ -135,6 +139,7 @@ func (mds *Datastore) loadChanges(
 			&nextTuple.Subject.Relation,
 			&caveatName,
 			&caveatContext,
+			&expiration,
 			&createdTxn,
 			&deletedTxn,
 		)
@@ -145,6 +150,7 @@ func (mds *Datastore) loadChanges(
 		if err != nil {
 			return
 		}
+		nextTuple.OptionalExpirationTime = common.ExpirationFrom(expiration)
 
 		if createdTxn > afterRevision && createdTxn <= newRevision {
 			stagedChanges.AddChange(ctx, revisionFromTransaction(createdTxn), nextTuple, core.RelationTupleUpdate_TOUCH)
@@ -158,7 +164,82 @@ func (mds *Datastore) loadChanges(
 		return
 	}
 
+	inWindow := func(txn uint64) bool {
+		return txn > afterRevision && txn <= newRevision
+	}
+
+	err = mds.loadDefinitionChanges(ctx, mds.QueryChangedNamespacesQuery.Where(inRange), func(name string, serialized []byte, createdTxn, deletedTxn uint64) error {
+		if inWindow(createdTxn) {
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed namespace: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+		if inWindow(deletedTxn) {
+			stagedChanges.AddDeletedNamespace(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	err = mds.loadDefinitionChanges(ctx, mds.QueryChangedCaveatsQuery.Where(inRange), func(name string, serialized []byte, createdTxn, deletedTxn uint64) error {
+		if inWindow(createdTxn) {
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+		if inWindow(deletedTxn) {
+			stagedChanges.AddDeletedCaveat(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
 	changes = stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
 
 	return
 }
+
+// loadDefinitionChanges runs the query for the namespace or caveat definitions changed within
+// a range of transactions, invoking the handler for each row found.
+func (mds *Datastore) loadDefinitionChanges(
+	ctx context.Context,
+	query sq.SelectBuilder,
+	handler func(name string, serialized []byte, createdTxn, deletedTxn uint64) error,
+) error {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := mds.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	for rows.Next() {
+		var name string
+		var serialized []byte
+		var createdTxn, deletedTxn uint64
+		if err := rows.Scan(&name, &serialized, &createdTxn, &deletedTxn); err != nil {
+			return err
+		}
+
+		if err := handler(name, serialized, createdTxn, deletedTxn); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// CopyFromRelationshipSource adapts a datastore.BulkWriteRelationshipSource into a pgx.CopyFromSource,
+// producing rows in the column order: namespace, object ID, relation, subject namespace, subject
+// object ID, subject relation, caveat name, caveat context and expiration.
+type CopyFromRelationshipSource struct {
+	Ctx  context.Context
+	Iter datastore.BulkWriteRelationshipSource
+
+	current *core.RelationTuple
+	err     error
+	values  []any
+}
+
+// Next implements pgx.CopyFromSource.
+func (s *CopyFromRelationshipSource) Next() bool {
+	s.current, s.err = s.Iter.Next(s.Ctx)
+	return s.current != nil && s.err == nil
+}
+
+// Values implements pgx.CopyFromSource.
+func (s *CopyFromRelationshipSource) Values() ([]any, error) {
+	var caveatName string
+	var caveatContext map[string]any
+	if s.current.Caveat != nil {
+		caveatName = s.current.Caveat.CaveatName
+		caveatContext = s.current.Caveat.Context.AsMap()
+	}
+
+	// The tuple may be reused by the source, so all values must be copied out before the next
+	// call to Next.
+	s.values = append(s.values[:0],
+		s.current.ResourceAndRelation.Namespace,
+		s.current.ResourceAndRelation.ObjectId,
+		s.current.ResourceAndRelation.Relation,
+		s.current.Subject.Namespace,
+		s.current.Subject.ObjectId,
+		s.current.Subject.Relation,
+		caveatName,
+		caveatContext,
+		common.ExpirationFor(s.current),
+	)
+	return s.values, nil
+}
+
+// Err implements pgx.CopyFromSource.
+func (s *CopyFromRelationshipSource) Err() error {
+	return s.err
+}
+
+var _ pgx.CopyFromSource = &CopyFromRelationshipSource{}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	querySchemaExists = "SELECT EXISTS(SELECT 1 FROM pg_namespace WHERE nspname = $1);"
+	queryCreateSchema = "CREATE SCHEMA IF NOT EXISTS %s;"
+)
+
+// schemaNameRegex matches the schema names which can be used in statements without quoting.
+var schemaNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// ValidateSchemaName returns an error if the schema name cannot be used for SpiceDB's tables. An
+// empty name, which uses the search path of the server, is valid.
+func ValidateSchemaName(schema string) error {
+	if schema != "" && !schemaNameRegex.MatchString(schema) {
+		return fmt.Errorf("invalid schema name %q: must match %s", schema, schemaNameRegex)
+	}
+	return nil
+}
+
+// ConfigureSchema sets the search path of the connections made with the configuration to the
+// schema, so that the unqualified table names used by the datastores and their migrations
+// resolve to the tables in that schema. An empty schema leaves the search path of the server.
+func ConfigureSchema(connConfig *pgconn.Config, schema string) {
+	if schema == "" {
+		return
+	}
+	connConfig.RuntimeParams["search_path"] = schema
+}
+
+// EnsureSchema creates the schema if it does not exist. The existence of the schema is checked
+// first, so that the role only needs the privilege to create schemas when it actually does.
+func EnsureSchema(ctx context.Context, conn *pgx.Conn, schema string) error {
+	if schema == "" {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, querySchemaExists, schema).Scan(&exists); err != nil {
+		return fmt.Errorf("unable to check for schema %s: %w", schema, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf(queryCreateSchema, schema)); err != nil {
+		return fmt.Errorf("unable to create schema %s: %w", schema, err)
+	}
+	return nil
+}
@@ -110,6 +110,16 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 	return
 }
 
+func (pgd *pgDatastore) DeleteExpiredRels(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	// Expiration times are stored without a time zone, in UTC.
+	return pgd.batchDelete(
+		ctx,
+		tableTuple,
+		relationTuplePKCols,
+		sq.Lt{colExpiration: expiredBefore.UTC()},
+	)
+}
+
 func (pgd *pgDatastore) batchDelete(
 	ctx context.Context,
 	tableName string,
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/lib/pq"
 
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/migrate"
 )
 
@@ -23,13 +24,25 @@ type AlembicPostgresDriver struct {
 }
 
 // NewAlembicPostgresDriver creates a new driver with active connections to the database specified.
-func NewAlembicPostgresDriver(url string) (*AlembicPostgresDriver, error) {
+// If a schema is given, the tables are read and migrated in that schema rather than in the
+// search path of the server.
+func NewAlembicPostgresDriver(url string, schema string) (*AlembicPostgresDriver, error) {
+	if err := pgxcommon.ValidateSchemaName(schema); err != nil {
+		return nil, err
+	}
+
 	connectStr, err := pq.ParseURL(url)
 	if err != nil {
 		return nil, err
 	}
 
-	db, err := pgx.Connect(context.Background(), connectStr)
+	connConfig, err := pgx.ParseConfig(connectStr)
+	if err != nil {
+		return nil, err
+	}
+	pgxcommon.ConfigureSchema(&connConfig.Config, schema)
+
+	db, err := pgx.ConnectConfig(context.Background(), connConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Used by the garbage collector to find expired relationships, which are a small fraction of
+// all relationships.
+const createRelationTupleExpirationIndex = `CREATE INDEX CONCURRENTLY
+	IF NOT EXISTS ix_relation_tuple_by_expiration
+	ON relation_tuple (expiration)
+	WHERE (expiration IS NOT NULL);`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-expiration-gc-index", "add-expiration-support",
+		func(ctx context.Context, conn *pgx.Conn) error {
+			if _, err := conn.Exec(ctx, createRelationTupleExpirationIndex); err != nil {
+				return err
+			}
+			return nil
+		},
+		noTxMigration); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -26,6 +26,13 @@ type postgresOptions struct {
 
 	migrationPhase string
 
+	readReplicaURIs            []string
+	replicaHealthCheckInterval time.Duration
+
+	watchMode string
+
+	schema string
+
 	logger *tracingLogger
 
 	queryInterceptor pgxcommon.QueryInterceptor
@@ -45,6 +52,16 @@ var migrationPhases = map[string]migrationPhase{
 	"":                    complete,
 }
 
+const (
+	watchModePolling            = "polling"
+	watchModeLogicalReplication = "logical-replication"
+)
+
+var watchModes = map[string]struct{}{
+	watchModePolling:            {},
+	watchModeLogicalReplication: {},
+}
+
 const (
 	errQuantizationTooLarge = "revision quantization interval (%s) must be less than GC window (%s)"
 
@@ -58,6 +75,7 @@ const (
 	defaultEnablePrometheusStats             = false
 	defaultMaxRetries                        = 10
 	defaultGCEnabled                         = true
+	defaultReplicaHealthCheckInterval        = time.Second
 )
 
 // Option provides the facility to configure how clients within the
@@ -76,6 +94,8 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		enablePrometheusStats:       defaultEnablePrometheusStats,
 		maxRetries:                  defaultMaxRetries,
 		gcEnabled:                   defaultGCEnabled,
+		replicaHealthCheckInterval:  defaultReplicaHealthCheckInterval,
+		watchMode:                   watchModePolling,
 		queryInterceptor:            nil,
 	}
 
@@ -96,6 +116,14 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		return computed, fmt.Errorf("unknown migration phase: %s", computed.migrationPhase)
 	}
 
+	if _, ok := watchModes[computed.watchMode]; !ok {
+		return computed, fmt.Errorf("unknown watch mode: %s", computed.watchMode)
+	}
+
+	if err := pgxcommon.ValidateSchemaName(computed.schema); err != nil {
+		return computed, err
+	}
+
 	return computed, nil
 }
 
@@ -334,3 +362,43 @@ func WithQueryInterceptor(interceptor pgxcommon.QueryInterceptor) Option {
 func MigrationPhase(phase string) Option {
 	return func(po *postgresOptions) { po.migrationPhase = phase }
 }
+
+// ReadReplicaURIs are the connection strings of read replicas of the database, to which snapshot
+// reads are routed once the replica has replayed the transactions visible at the revision being
+// read. Reads fall back to the primary otherwise.
+//
+// Connections to the replicas are configured like the read connections to the primary.
+//
+// No read replicas are used by default.
+func ReadReplicaURIs(uris []string) Option {
+	return func(po *postgresOptions) { po.readReplicaURIs = uris }
+}
+
+// ReplicaHealthCheckInterval is the frequency at which the health and replication lag of each
+// read replica is checked.
+//
+// This value defaults to 1s.
+func ReplicaHealthCheckInterval(interval time.Duration) Option {
+	return func(po *postgresOptions) { po.replicaHealthCheckInterval = interval }
+}
+
+// WatchMode is how the Watch API learns of new transactions: "polling" polls the transaction
+// table of each watcher, which requires track_commit_timestamp=on, and "logical-replication"
+// consumes a logical replication slot once for all of the watchers, which requires
+// wal_level=logical and a role with the REPLICATION attribute.
+//
+// If postgres is not configured for logical replication, the datastore falls back to polling.
+//
+// This value defaults to "polling".
+func WatchMode(mode string) Option {
+	return func(po *postgresOptions) { po.watchMode = mode }
+}
+
+// Schema is the postgres schema in which the tables of the datastore are read and written, so
+// that several SpiceDB installations can share a database. The schema is created by `spicedb
+// migrate` if it does not exist.
+//
+// By default, the tables are found through the search path of the server.
+func Schema(schema string) Option {
+	return func(po *postgresOptions) { po.schema = schema }
+}
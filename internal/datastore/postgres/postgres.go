@@ -54,6 +54,7 @@ const (
 	colCaveatDefinition  = "definition"
 	colCaveatContextName = "caveat_name"
 	colCaveatContext     = "caveat_context"
+	colExpiration        = "expiration"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 
@@ -142,6 +143,7 @@ func newPostgresDatastore(
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 	config.readPoolOpts.ConfigurePgx(readPoolConfig)
+	pgxcommon.ConfigureSchema(&readPoolConfig.ConnConfig.Config, config.schema)
 	readPoolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		RegisterTypes(conn.TypeMap())
 		return nil
@@ -152,6 +154,7 @@ func newPostgresDatastore(
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 	config.writePoolOpts.ConfigurePgx(writePoolConfig)
+	pgxcommon.ConfigureSchema(&writePoolConfig.ConnConfig.Config, config.schema)
 	writePoolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		RegisterTypes(conn.TypeMap())
 		return nil
@@ -178,7 +181,25 @@ func newPostgresDatastore(
 		return nil, fmt.Errorf(errUnableToInstantiate, err)
 	}
 
-	watchEnabled := trackTSOn == "on"
+	trackCommitTimestamps := trackTSOn == "on"
+
+	// Verify that the server supports logical replication, if it is to be used for watch
+	logicalReplicationWatch := config.watchMode == watchModeLogicalReplication
+	if logicalReplicationWatch {
+		var walLevel string
+		if err := readPool.
+			QueryRow(initializationContext, "SHOW wal_level;").
+			Scan(&walLevel); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+
+		if walLevel != "logical" {
+			log.Warn().Str("wal_level", walLevel).Msg("postgres must be run with wal_level=logical for logical replication watch, falling back to polling")
+			logicalReplicationWatch = false
+		}
+	}
+
+	watchEnabled := trackCommitTimestamps || logicalReplicationWatch
 	if !watchEnabled {
 		log.Warn().Msg("watch API disabled, postgres must be run with track_commit_timestamp=on")
 	}
@@ -199,6 +220,19 @@ func newPostgresDatastore(
 		if err := common.RegisterGCMetrics(); err != nil {
 			return nil, fmt.Errorf(errUnableToInstantiate, err)
 		}
+		if len(config.readReplicaURIs) > 0 {
+			if err := registerReplicaMetrics(); err != nil {
+				return nil, fmt.Errorf(errUnableToInstantiate, err)
+			}
+		}
+	}
+
+	var replicas *replicaRouter
+	if len(config.readReplicaURIs) > 0 {
+		replicas, err = newReplicaRouter(initializationContext, config.readReplicaURIs, config)
+		if err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
 	}
 
 	gcCtx, cancelGc := context.WithCancel(context.Background())
@@ -233,6 +267,7 @@ func newPostgresDatastore(
 			maxRevisionStaleness,
 		),
 		dburl:                   url,
+		schema:                  config.schema,
 		readPool:                pgxcommon.MustNewInterceptorPooler(readPool, config.queryInterceptor),
 		writePool:               pgxcommon.MustNewInterceptorPooler(writePool, config.queryInterceptor),
 		watchBufferLength:       config.watchBufferLength,
@@ -244,14 +279,23 @@ func newPostgresDatastore(
 		analyzeBeforeStatistics: config.analyzeBeforeStatistics,
 		usersetBatchSize:        config.splitAtUsersetCount,
 		watchEnabled:            watchEnabled,
+		trackCommitTimestamps:   trackCommitTimestamps,
 		gcCtx:                   gcCtx,
 		cancelGc:                cancelGc,
 		readTxOptions:           pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
 		maxRetries:              config.maxRetries,
+		replicas:                replicas,
 	}
 
 	datastore.SetOptimizedRevisionFunc(datastore.optimizedRevisionFunc)
 
+	if logicalReplicationWatch {
+		datastore.changeStream, err = newChangeStream(datastore, url)
+		if err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+	}
+
 	// Start a goroutine for garbage collection.
 	if datastore.gcInterval > 0*time.Minute && config.gcEnabled {
 		datastore.gcGroup, datastore.gcCtx = errgroup.WithContext(datastore.gcCtx)
@@ -268,6 +312,13 @@ func newPostgresDatastore(
 		log.Warn().Msg("datastore background garbage collection disabled")
 	}
 
+	// Start a goroutine for checking the health and lag of the read replicas.
+	if replicas != nil {
+		var replicasCtx context.Context
+		replicasCtx, datastore.cancelReplicas = context.WithCancel(context.Background())
+		go replicas.monitor(replicasCtx, config.replicaHealthCheckInterval)
+	}
+
 	return datastore, nil
 }
 
@@ -275,6 +326,7 @@ type pgDatastore struct {
 	*revisions.CachedOptimizedRevisions
 
 	dburl                   string
+	schema                  string
 	readPool, writePool     pgxcommon.ConnPooler
 	watchBufferLength       uint16
 	optimizedRevisionQuery  string
@@ -287,17 +339,23 @@ type pgDatastore struct {
 	readTxOptions           pgx.TxOptions
 	maxRetries              uint8
 	watchEnabled            bool
+	trackCommitTimestamps   bool
 
 	gcGroup  *errgroup.Group
 	gcCtx    context.Context
 	cancelGc context.CancelFunc
+
+	replicas       *replicaRouter
+	cancelReplicas context.CancelFunc
+
+	changeStream *changeStream
 }
 
 func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Reader {
 	rev := revRaw.(postgresRevision)
 
 	createTxFunc := func(ctx context.Context) (pgxcommon.DBReader, common.TxCleanupFunc, error) {
-		return pgd.readPool, func(ctx context.Context) {}, nil
+		return pgd.replicas.poolFor(rev, pgd.readPool), func(ctx context.Context) {}, nil
 	}
 
 	querySplitter := common.TupleQuerySplitter{
@@ -309,6 +367,7 @@ func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Read
 		createTxFunc,
 		querySplitter,
 		buildLivingObjectFilterForRevision(rev),
+		unexpiredAtSnapshot(rev.snapshot),
 	}
 }
 
@@ -345,6 +404,7 @@ func (pgd *pgDatastore) ReadWriteTx(
 					longLivedTx,
 					querySplitter,
 					currentlyLivingObjects,
+					unexpiredTuple,
 				},
 				tx,
 				newXID,
@@ -372,6 +432,12 @@ func (pgd *pgDatastore) Close() error {
 		log.Warn().Err(err).Msg("completed shutdown of postgres datastore")
 	}
 
+	if pgd.cancelReplicas != nil {
+		pgd.cancelReplicas()
+	}
+	pgd.replicas.close()
+	pgd.changeStream.close()
+
 	pgd.readPool.Close()
 	pgd.writePool.Close()
 	return nil
@@ -396,7 +462,7 @@ func (pgd *pgDatastore) ReadyState(ctx context.Context) (datastore.ReadyState, e
 		return datastore.ReadyState{}, fmt.Errorf("invalid head migration found for postgres: %w", err)
 	}
 
-	currentRevision, err := migrations.NewAlembicPostgresDriver(pgd.dburl)
+	currentRevision, err := migrations.NewAlembicPostgresDriver(pgd.dburl, pgd.schema)
 	if err != nil {
 		return datastore.ReadyState{}, err
 	}
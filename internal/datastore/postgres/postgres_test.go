@@ -18,6 +18,7 @@ import (
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	pgcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	"github.com/authzed/spicedb/internal/datastore/postgres/migrations"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -125,6 +126,10 @@ func TestPostgresDatastore(t *testing.T) {
 				GCQueriesServedByExpectedIndexes(t, b)
 			})
 
+			t.Run("SchemaIsolation", func(t *testing.T) {
+				SchemaIsolationTest(t, b)
+			})
+
 			if config.migrationPhase == "" {
 				t.Run("RevisionInversion", createDatastoreTest(
 					b,
@@ -838,6 +843,69 @@ func WatchNotEnabledTest(t *testing.T, _ testdatastore.RunningEngineForTest) {
 	require.Contains(err.Error(), "track_commit_timestamp=on")
 }
 
+func SchemaIsolationTest(t *testing.T, b testdatastore.RunningEngineForTest) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	// Two installations are migrated into their own schemas of the same database.
+	uri := b.NewDatabase(t)
+	datastores := make(map[string]datastore.Datastore, 2)
+	for _, schema := range []string{"tenant_a", "tenant_b"} {
+		migrationDriver, err := migrations.NewAlembicPostgresDriver(uri, schema)
+		require.NoError(err)
+		require.NoError(pgcommon.EnsureSchema(ctx, migrationDriver.Conn(), schema))
+
+		migrateCtx := context.WithValue(ctx, migrate.BackfillBatchSize, uint64(1000))
+		require.NoError(migrations.DatabaseMigrations.Run(migrateCtx, migrationDriver, migrate.Head, migrate.LiveRun))
+		require.NoError(migrationDriver.Close(ctx))
+
+		ds, err := newPostgresDatastore(uri,
+			RevisionQuantization(0),
+			GCWindow(time.Millisecond*1),
+			WatchBufferLength(1),
+			Schema(schema),
+		)
+		require.NoError(err)
+		t.Cleanup(func() { ds.Close() })
+
+		ready, err := ds.ReadyState(ctx)
+		require.NoError(err)
+		require.True(ready.IsReady)
+
+		datastores[schema] = ds
+	}
+
+	_, revision := testfixtures.StandardDatastoreWithData(datastores["tenant_a"], require)
+	nsDefs, err := datastores["tenant_a"].SnapshotReader(revision).ListAllNamespaces(ctx)
+	require.NoError(err)
+	require.NotEmpty(nsDefs)
+
+	// The data written to one schema is not visible in the other.
+	headRevision, err := datastores["tenant_b"].HeadRevision(ctx)
+	require.NoError(err)
+	nsDefs, err = datastores["tenant_b"].SnapshotReader(headRevision).ListAllNamespaces(ctx)
+	require.NoError(err)
+	require.Empty(nsDefs)
+
+	statsA, err := datastores["tenant_a"].Statistics(ctx)
+	require.NoError(err)
+	statsB, err := datastores["tenant_b"].Statistics(ctx)
+	require.NoError(err)
+	require.NotEqual(statsA.UniqueID, statsB.UniqueID)
+
+	// A datastore with an unmigrated schema is not ready.
+	ds, err := newPostgresDatastore(uri, Schema("tenant_c"))
+	require.NoError(err)
+	defer ds.Close()
+
+	ready, err := ds.ReadyState(ctx)
+	require.NoError(err)
+	require.False(ready.IsReady)
+
+	_, err = newPostgresDatastore(uri, Schema("Tenant-D"))
+	require.Error(err)
+}
+
 func BenchmarkPostgresQuery(b *testing.B) {
 	req := require.New(b)
 
@@ -18,6 +18,7 @@ type pgReader struct {
 	txSource      pgxcommon.TxFactory
 	querySplitter common.TupleQuerySplitter
 	filterer      queryFilterer
+	unexpired     sq.Sqlizer
 }
 
 type queryFilterer func(original sq.SelectBuilder) sq.SelectBuilder
@@ -32,8 +33,18 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
 	).From(tableTuple)
 
+	// unexpiredTuple filters out relationships which have expired at the start of the current
+	// transaction, for reads within a read-write transaction.
+	unexpiredTuple = sq.Or{
+		sq.Eq{colExpiration: nil},
+		sq.Expr(colExpiration + " > (now() AT TIME ZONE 'UTC')"),
+	}
+
+	expiredTuple = sq.Expr(colExpiration + " <= (now() AT TIME ZONE 'UTC')")
+
 	schema = common.NewSchemaInformation(
 		colNamespace,
 		colObjectID,
@@ -45,11 +56,29 @@ var (
 		common.TupleComparison,
 	)
 
+	// querySnapshotTimestamp selects the timestamp of the latest transaction visible in a
+	// snapshot. The placeholders are both the snapshot.
+	querySnapshotTimestamp = fmt.Sprintf(
+		"SELECT %[1]s FROM %[2]s WHERE %[3]s < pg_snapshot_xmax(?) AND pg_visible_in_snapshot(%[3]s, ?) ORDER BY %[3]s DESC LIMIT 1",
+		colTimestamp,
+		tableTransaction,
+		colXID,
+	)
+
 	readNamespace = psql.
 			Select(colConfig, colCreatedXid).
 			From(tableNamespace)
 )
 
+// unexpiredAtSnapshot filters out relationships which had expired at the time of the snapshot,
+// which is the commit timestamp of the latest transaction visible in it.
+func unexpiredAtSnapshot(snapshot pgSnapshot) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{colExpiration: nil},
+		sq.Expr(colExpiration+" > ("+querySnapshotTimestamp+")", snapshot, snapshot),
+	}
+}
+
 const (
 	errUnableToReadConfig     = "unable to read namespace config: %w"
 	errUnableToListNamespaces = "unable to list namespaces: %w"
@@ -60,7 +89,7 @@ func (r *pgReader) QueryRelationships(
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, r.filterer(queryTuples)).FilterWithRelationshipsFilter(filter)
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, r.filterer(queryTuples.Where(r.unexpired))).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +102,7 @@ func (r *pgReader) ReverseQueryRelationships(
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, r.filterer(queryTuples)).
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, r.filterer(queryTuples.Where(r.unexpired))).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -90,6 +119,8 @@ func (r *pgReader) ReverseQueryRelationships(
 	return r.querySplitter.SplitAndExecuteQuery(ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
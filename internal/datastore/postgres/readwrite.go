@@ -11,6 +11,7 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -42,6 +43,7 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
 	)
 
 	deleteTuple = psql.Update(tableTuple).Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
@@ -62,8 +64,12 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 	for _, mut := range mutations {
 		tpl := mut.Tuple
 
-		if mut.Operation == core.RelationTupleUpdate_TOUCH || mut.Operation == core.RelationTupleUpdate_DELETE {
+		switch mut.Operation {
+		case core.RelationTupleUpdate_TOUCH, core.RelationTupleUpdate_DELETE:
 			deleteClauses = append(deleteClauses, exactRelationshipClause(tpl))
+		case core.RelationTupleUpdate_CREATE:
+			// An expired relationship no longer exists, so it must not conflict with a CREATE.
+			deleteClauses = append(deleteClauses, sq.And{exactRelationshipClause(tpl), expiredTuple})
 		}
 
 		if mut.Operation == core.RelationTupleUpdate_TOUCH || mut.Operation == core.RelationTupleUpdate_CREATE {
@@ -82,6 +88,7 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 				tpl.Subject.Relation,
 				caveatName,
 				caveatContext, // PGX driver serializes map[string]any to JSONB type columns
+				common.ExpirationFor(tpl),
 			}
 
 			bulkWrite = bulkWrite.Values(valuesToWrite...)
@@ -249,6 +256,35 @@ func (rwt *pgReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...stri
 	return nil
 }
 
+var copyCols = []string{
+	colNamespace,
+	colObjectID,
+	colRelation,
+	colUsersetNamespace,
+	colUsersetObjectID,
+	colUsersetRelation,
+	colCaveatContextName,
+	colCaveatContext,
+	colExpiration,
+}
+
+func (rwt *pgReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	// The created transaction ID is filled in by the column default, which is the ID of the
+	// current transaction.
+	copied, err := rwt.tx.CopyFrom(ctx, pgx.Identifier{tableTuple}, copyCols, &pgxcommon.CopyFromRelationshipSource{
+		Ctx:  ctx,
+		Iter: iter,
+	})
+	if err != nil {
+		if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraint, err); cerr != nil {
+			return 0, cerr
+		}
+		return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
+	return uint64(copied), nil
+}
+
 func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	return sq.Eq{
 		colNamespace:        r.ResourceAndRelation.Namespace,
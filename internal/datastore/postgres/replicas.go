@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/pgxpoolprometheus"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// queryReplicaStatus returns the snapshot of the transactions the replica has replayed and
+	// its replication lag in seconds. A replica which has replayed all of the WAL it received
+	// has no lag, even if the last transaction it replayed is old.
+	queryReplicaStatus = `
+	SELECT pg_current_snapshot(),
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0)
+	END;`
+
+	primaryReadTarget = "primary"
+)
+
+var (
+	replicaLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_replica_lag_seconds",
+		Help:      "replication lag in seconds of each postgres read replica, as of its last health check.",
+	}, []string{"replica"})
+
+	replicaHealthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_replica_healthy",
+		Help:      "whether each postgres read replica passed its last health check.",
+	}, []string{"replica"})
+
+	snapshotReadsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_snapshot_reads_total",
+		Help:      "number of postgres snapshot reads, by the primary or read replica they were routed to.",
+	}, []string{"target"})
+)
+
+func registerReplicaMetrics() error {
+	for _, metric := range []prometheus.Collector{
+		replicaLagGauge,
+		replicaHealthyGauge,
+		snapshotReadsCounter,
+	} {
+		if err := prometheus.Register(metric); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readReplica is a read replica of the database, along with the latest known snapshot of the
+// transactions it has replayed.
+type readReplica struct {
+	name string
+	pool pgxcommon.ConnPooler
+
+	sync.RWMutex
+	healthy  bool
+	snapshot pgSnapshot
+}
+
+// replayedPast returns whether the replica is healthy and known to have replayed every
+// transaction visible at the revision.
+func (rr *readReplica) replayedPast(rev postgresRevision) bool {
+	rr.RLock()
+	defer rr.RUnlock()
+
+	if !rr.healthy {
+		return false
+	}
+
+	switch rr.snapshot.compare(rev.snapshot) {
+	case equal, gt:
+		return true
+	default:
+		return false
+	}
+}
+
+// refresh loads the current status of the replica, marking it unhealthy if it cannot be loaded.
+func (rr *readReplica) refresh(ctx context.Context) error {
+	var snapshot pgSnapshot
+	var lagSeconds float64
+	err := rr.pool.QueryRow(ctx, queryReplicaStatus).Scan(&snapshot, &lagSeconds)
+
+	rr.Lock()
+	defer rr.Unlock()
+
+	if err != nil {
+		if rr.healthy {
+			log.Ctx(ctx).Warn().Err(err).Str("replica", rr.name).Msg("postgres read replica is unhealthy, reads will not be routed to it")
+		}
+		rr.healthy = false
+		replicaHealthyGauge.WithLabelValues(rr.name).Set(0)
+		return fmt.Errorf("unable to check read replica %s: %w", rr.name, err)
+	}
+
+	if !rr.healthy {
+		log.Ctx(ctx).Info().Str("replica", rr.name).Msg("postgres read replica is healthy")
+	}
+	rr.healthy = true
+	rr.snapshot = snapshot
+	replicaHealthyGauge.WithLabelValues(rr.name).Set(1)
+	replicaLagGauge.WithLabelValues(rr.name).Set(lagSeconds)
+	return nil
+}
+
+// replicaRouter routes snapshot reads to the read replicas which have replayed past the revision
+// being read.
+type replicaRouter struct {
+	replicas []*readReplica
+	next     atomic.Uint32
+}
+
+// newReplicaRouter connects to the read replicas at the URIs, with the connections configured
+// like the read connections to the primary.
+func newReplicaRouter(ctx context.Context, uris []string, config postgresOptions) (*replicaRouter, error) {
+	router := &replicaRouter{}
+	for index, uri := range uris {
+		poolConfig, err := pgxpool.ParseConfig(uri)
+		if err != nil {
+			router.close()
+			return nil, fmt.Errorf("unable to parse read replica %d: %w", index, err)
+		}
+		config.readPoolOpts.ConfigurePgx(poolConfig)
+		pgxcommon.ConfigureSchema(&poolConfig.ConnConfig.Config, config.schema)
+		poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			RegisterTypes(conn.TypeMap())
+			return nil
+		}
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			router.close()
+			return nil, fmt.Errorf("unable to connect to read replica %d: %w", index, err)
+		}
+
+		name := net.JoinHostPort(poolConfig.ConnConfig.Host, strconv.Itoa(int(poolConfig.ConnConfig.Port)))
+		if config.enablePrometheusStats {
+			if err := prometheus.Register(pgxpoolprometheus.NewCollector(pool, map[string]string{
+				"db_name":    "spicedb",
+				"pool_usage": "read_replica_" + strconv.Itoa(index),
+			})); err != nil {
+				pool.Close()
+				router.close()
+				return nil, err
+			}
+		}
+
+		router.replicas = append(router.replicas, &readReplica{
+			name: name,
+			pool: pgxcommon.MustNewInterceptorPooler(pool, config.queryInterceptor),
+		})
+	}
+
+	// A replica only receives reads after its first successful health check.
+	for _, replica := range router.replicas {
+		if err := replica.refresh(ctx); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("postgres read replica failed its initial health check")
+		}
+	}
+
+	return router, nil
+}
+
+// poolFor returns the pool of a read replica which has replayed past the revision, or the pool of
+// the primary if there is none. The snapshots of the replicas are only as recent as their last
+// health check, as checking a replica on the request path would add its latency to the read.
+func (r *replicaRouter) poolFor(rev postgresRevision, primary pgxcommon.ConnPooler) pgxcommon.ConnPooler {
+	if r == nil || len(r.replicas) == 0 {
+		return primary
+	}
+
+	// Spread the reads over the replicas, starting from a different one each time.
+	start := int(r.next.Add(1))
+	for i := range r.replicas {
+		replica := r.replicas[(start+i)%len(r.replicas)]
+		if replica.replayedPast(rev) {
+			snapshotReadsCounter.WithLabelValues(replica.name).Inc()
+			return replica.pool
+		}
+	}
+
+	snapshotReadsCounter.WithLabelValues(primaryReadTarget).Inc()
+	return primary
+}
+
+// monitor checks the health and lag of the replicas at each interval, until the context is
+// canceled.
+func (r *replicaRouter) monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, replica := range r.replicas {
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				_ = replica.refresh(checkCtx)
+				cancel()
+			}
+		}
+	}
+}
+
+func (r *replicaRouter) close() {
+	if r == nil {
+		return
+	}
+
+	for _, replica := range r.replicas {
+		replica.pool.Close()
+	}
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+// fakeReplicaPool answers the status query of a replica with a fixed snapshot, or error.
+type fakeReplicaPool struct {
+	pgxcommon.ConnPooler
+
+	snapshot pgSnapshot
+	err      error
+}
+
+func (p *fakeReplicaPool) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row {
+	return fakeStatusRow{p.snapshot, p.err}
+}
+
+type fakeStatusRow struct {
+	snapshot pgSnapshot
+	err      error
+}
+
+func (r fakeStatusRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*pgSnapshot) = r.snapshot
+	*dest[1].(*float64) = 0
+	return nil
+}
+
+func TestReplicaReplayedPast(t *testing.T) {
+	testCases := []struct {
+		name     string
+		replica  pgSnapshot
+		revision pgSnapshot
+		expected bool
+	}{
+		{"same snapshot", snap(5, 5), snap(5, 5), true},
+		{"replica ahead", snap(8, 8), snap(5, 5), true},
+		{"replica behind", snap(3, 3), snap(5, 5), false},
+		{"revision sees in progress tx of replica", snap(5, 8, 6), snap(5, 8), false},
+		{"replica sees in progress tx of revision", snap(5, 8), snap(5, 8, 6), true},
+		{"concurrent", snap(5, 8, 6), snap(5, 8, 7), false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			replica := &readReplica{name: "replica", healthy: true, snapshot: tc.replica}
+			require.Equal(t, tc.expected, replica.replayedPast(postgresRevision{tc.revision}))
+
+			replica.healthy = false
+			require.False(t, replica.replayedPast(postgresRevision{tc.revision}))
+		})
+	}
+}
+
+func TestReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeReplicaPool{}
+
+	var noRouter *replicaRouter
+	require.Equal(t, pgxcommon.ConnPooler(primary), noRouter.poolFor(postgresRevision{snap(5, 5)}, primary))
+
+	behind := &fakeReplicaPool{snapshot: snap(3, 3)}
+	caughtUp := &fakeReplicaPool{snapshot: snap(3, 3)}
+	router := &replicaRouter{replicas: []*readReplica{
+		{name: "behind", pool: behind},
+		{name: "caughtUp", pool: caughtUp},
+	}}
+	for _, replica := range router.replicas {
+		require.NoError(t, replica.refresh(ctx))
+	}
+
+	// Neither replica has replayed past the revision, so reads go to the primary.
+	require.Equal(t, pgxcommon.ConnPooler(primary), router.poolFor(postgresRevision{snap(5, 5)}, primary))
+
+	// A replica which has replayed past the revision only receives reads once a health check
+	// has seen it do so, as replicas are not checked on the request path.
+	caughtUp.snapshot = snap(6, 6)
+	require.Equal(t, pgxcommon.ConnPooler(primary), router.poolFor(postgresRevision{snap(5, 5)}, primary))
+
+	require.NoError(t, router.replicas[1].refresh(ctx))
+	for i := 0; i < 4; i++ {
+		require.Equal(t, pgxcommon.ConnPooler(caughtUp), router.poolFor(postgresRevision{snap(5, 5)}, primary))
+	}
+
+	// Reads at older revisions are spread over both replicas.
+	seen := map[pgxcommon.ConnPooler]struct{}{}
+	for i := 0; i < 4; i++ {
+		seen[router.poolFor(postgresRevision{snap(2, 2)}, primary)] = struct{}{}
+	}
+	require.Len(t, seen, 2)
+
+	// An unhealthy replica no longer receives reads.
+	caughtUp.err = errors.New("connection refused")
+	require.Error(t, router.replicas[1].refresh(ctx))
+	require.Equal(t, pgxcommon.ConnPooler(primary), router.poolFor(postgresRevision{snap(5, 5)}, primary))
+}
@@ -16,15 +16,18 @@ const (
 
 	tablePGClass = "pg_class"
 	colReltuples = "reltuples"
-	colRelname   = "relname"
+	colOID       = "oid"
 )
 
 var (
-	queryUniqueID          = psql.Select(colUniqueID).From(tableMetadata)
+	queryUniqueID = psql.Select(colUniqueID).From(tableMetadata)
+
+	// The tuple table is looked up by OID, so that it is the one found through the search path
+	// when tables of the same name exist in several schemas.
 	queryEstimatedRowCount = psql.
 				Select(colReltuples).
 				From(tablePGClass).
-				Where(sq.Eq{colRelname: tableTuple})
+				Where(sq.Expr(colOID+" = ?::regclass", tableTuple))
 )
 
 func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
@@ -34,6 +34,14 @@ var (
 		%[1]s >= pg_snapshot_xmin($1) AND NOT pg_visible_in_snapshot(%[1]s, $1)
 	) ORDER BY pg_xact_commit_timestamp(%[1]s::xid), %[1]s;`, colXID, colSnapshot, tableTransaction)
 
+	// newRevisionsByXIDQuery is used in place of newRevisionsQuery when commit timestamps are not
+	// tracked, in which case the order in which the transactions committed is not known.
+	newRevisionsByXIDQuery = fmt.Sprintf(`
+	SELECT %[1]s, %[2]s FROM %[3]s
+	WHERE %[1]s >= pg_snapshot_xmax($1) OR (
+		%[1]s >= pg_snapshot_xmin($1) AND NOT pg_visible_in_snapshot(%[1]s, $1)
+	) ORDER BY %[1]s;`, colXID, colSnapshot, tableTransaction)
+
 	queryChanged = psql.Select(
 		colNamespace,
 		colObjectID,
@@ -43,9 +51,24 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
 		colCreatedXid,
 		colDeletedXid,
 	).From(tableTuple)
+
+	queryChangedNamespaces = psql.Select(
+		colNamespace,
+		colConfig,
+		colCreatedXid,
+		colDeletedXid,
+	).From(tableNamespace)
+
+	queryChangedCaveats = psql.Select(
+		colCaveatName,
+		colCaveatDefinition,
+		colCreatedXid,
+		colDeletedXid,
+	).From(tableCaveat)
 )
 
 func (pgd *pgDatastore) Watch(
@@ -55,13 +78,17 @@ func (pgd *pgDatastore) Watch(
 	updates := make(chan *datastore.RevisionChanges, pgd.watchBufferLength)
 	errs := make(chan error, 1)
 
+	afterRevision := afterRevisionRaw.(postgresRevision)
+	if pgd.changeStream != nil {
+		go pgd.watchChangeStream(ctx, afterRevision, updates, errs)
+		return updates, errs
+	}
+
 	if !pgd.watchEnabled {
 		errs <- datastore.NewWatchDisabledErr("postgres must be run with track_commit_timestamp=on for watch to be enabled. See https://spicedb.dev/d/enable-watch-api-postgres")
 		return updates, errs
 	}
 
-	afterRevision := afterRevisionRaw.(postgresRevision)
-
 	go func() {
 		defer close(updates)
 		defer close(errs)
@@ -121,9 +148,14 @@ func (pgd *pgDatastore) Watch(
 }
 
 func (pgd *pgDatastore) getNewRevisions(ctx context.Context, afterTX postgresRevision) ([]revisionWithXid, error) {
+	query := newRevisionsQuery
+	if !pgd.trackCommitTimestamps {
+		query = newRevisionsByXIDQuery
+	}
+
 	var ids []revisionWithXid
 	if err := pgx.BeginTxFunc(ctx, pgd.readPool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, func(tx pgx.Tx) error {
-		rows, err := tx.Query(ctx, newRevisionsQuery, afterTX.snapshot)
+		rows, err := tx.Query(ctx, query, afterTX.snapshot)
 		if err != nil {
 			return fmt.Errorf("unable to load new revisions: %w", err)
 		}
@@ -169,7 +201,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		txidToRevision[rev.tx.Uint64] = rev
 	}
 
-	sql, args, err := queryChanged.Where(sq.Or{
+	inRange := sq.Or{
 		sq.And{
 			sq.LtOrEq{colCreatedXid: max},
 			sq.GtOrEq{colCreatedXid: min},
@@ -178,7 +210,9 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 			sq.LtOrEq{colDeletedXid: max},
 			sq.GtOrEq{colDeletedXid: min},
 		},
-	}).ToSql()
+	}
+
+	sql, args, err := queryChanged.Where(inRange).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("unable to prepare changes SQL: %w", err)
 	}
@@ -198,6 +232,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		var createdXID, deletedXID xid8
 		var caveatName string
 		var caveatContext map[string]any
+		var expiration *time.Time
 		if err := changes.Scan(
 			&nextTuple.ResourceAndRelation.Namespace,
 			&nextTuple.ResourceAndRelation.ObjectId,
@@ -207,6 +242,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 			&nextTuple.Subject.Relation,
 			&caveatName,
 			&caveatContext,
+			&expiration,
 			&createdXID,
 			&deletedXID,
 		); err != nil {
@@ -224,6 +260,8 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 			}
 		}
 
+		nextTuple.OptionalExpirationTime = common.ExpirationFrom(expiration)
+
 		if _, found := filter[createdXID.Uint64]; found {
 			tracked.AddChange(ctx, txidToRevision[createdXID.Uint64], nextTuple, core.RelationTupleUpdate_TOUCH)
 		}
@@ -235,8 +273,78 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		return nil, fmt.Errorf("unable to load changes for XID: %w", err)
 	}
 
+	// Namespace and caveat definitions are versioned in the same way as relationships: a
+	// rewritten definition is deleted and created in the same transaction.
+	changedNamespaces, err := pgd.loadDefinitionChanges(ctx, queryChangedNamespaces.Where(inRange))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, changed := range changedNamespaces {
+		if _, found := filter[changed.createdXID.Uint64]; found {
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(changed.serialized); err != nil {
+				return nil, fmt.Errorf("unable to parse changed namespace: %w", err)
+			}
+			tracked.AddChangedDefinition(ctx, txidToRevision[changed.createdXID.Uint64], loaded)
+		}
+		if _, found := filter[changed.deletedXID.Uint64]; found {
+			tracked.AddDeletedNamespace(ctx, txidToRevision[changed.deletedXID.Uint64], changed.name)
+		}
+	}
+
+	changedCaveats, err := pgd.loadDefinitionChanges(ctx, queryChangedCaveats.Where(inRange))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, changed := range changedCaveats {
+		if _, found := filter[changed.createdXID.Uint64]; found {
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(changed.serialized); err != nil {
+				return nil, fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			tracked.AddChangedDefinition(ctx, txidToRevision[changed.createdXID.Uint64], loaded)
+		}
+		if _, found := filter[changed.deletedXID.Uint64]; found {
+			tracked.AddDeletedCaveat(ctx, txidToRevision[changed.deletedXID.Uint64], changed.name)
+		}
+	}
+
 	reconciledChanges := tracked.AsRevisionChanges(func(lhs, rhs uint64) bool {
 		return filter[lhs] < filter[rhs]
 	})
 	return reconciledChanges, nil
 }
+
+type changedDefinition struct {
+	name                   string
+	serialized             []byte
+	createdXID, deletedXID xid8
+}
+
+// loadDefinitionChanges runs the query for the namespace or caveat definitions changed within
+// a range of transactions.
+func (pgd *pgDatastore) loadDefinitionChanges(ctx context.Context, query sq.SelectBuilder) ([]changedDefinition, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare changes SQL: %w", err)
+	}
+
+	rows, err := pgd.readPool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load definition changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changed []changedDefinition
+	for rows.Next() {
+		var def changedDefinition
+		if err := rows.Scan(&def.name, &def.serialized, &def.createdXID, &def.deletedXID); err != nil {
+			return nil, fmt.Errorf("unable to parse changed definition: %w", err)
+		}
+		changed = append(changed, def)
+	}
+
+	return changed, rows.Err()
+}
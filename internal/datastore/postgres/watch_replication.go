@@ -0,0 +1,709 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	watchPublication = "spicedb_watch"
+	watchSlotPrefix  = "spicedb_watch_"
+
+	// The publication only publishes the rows inserted into the transaction table, of which every
+	// read-write transaction inserts exactly one.
+	createWatchPublication = "CREATE PUBLICATION %s FOR TABLE %s WITH (publish = 'insert');"
+
+	// The slot is temporary, and so is dropped by postgres when the replication connection closes.
+	createWatchSlot = "CREATE_REPLICATION_SLOT %s TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT;"
+
+	startWatchReplication = "START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s');"
+
+	// queryTransactionVisible returns whether a transaction is visible to new snapshots. The commit
+	// of a transaction is decoded once it is in the WAL, which can be just before it is visible.
+	queryTransactionVisible = "SELECT pg_visible_in_snapshot($1, pg_current_snapshot());"
+
+	pgDuplicateObject = "42710"
+
+	standbyStatusInterval   = 10 * time.Second
+	visibilityCheckInterval = 2 * time.Millisecond
+
+	// The messages of the streaming replication protocol, carried within CopyData messages.
+	xLogDataMessage         = 'w'
+	primaryKeepaliveMessage = 'k'
+	standbyStatusMessage    = 'r'
+
+	// The pgoutput messages used by the change stream; any other message is ignored.
+	pgoutputBegin    = 'B'
+	pgoutputCommit   = 'C'
+	pgoutputRelation = 'R'
+	pgoutputInsert   = 'I'
+)
+
+// postgresEpoch is the epoch of the timestamps of the streaming replication protocol.
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// changeStream consumes the transactions committed to the database from a logical replication
+// slot, and fans out the changes of each of them to all of the watchers of the datastore.
+//
+// The stream is started by the first subscriber and runs until the datastore is closed, or until
+// it fails, after which it is restarted by the next subscriber.
+type changeStream struct {
+	pgd         *pgDatastore
+	connConfig  *pgconn.Config
+	publication string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	sync.Mutex
+	started     chan struct{}
+	subscribers map[*changeSubscriber]struct{}
+}
+
+// changeSubscriber receives the changes of the stream until it is done, after which err is the
+// reason it was removed from the stream.
+type changeSubscriber struct {
+	changes chan datastore.RevisionChanges
+	done    chan struct{}
+	err     error
+}
+
+func newChangeStream(pgd *pgDatastore, url string) (*changeStream, error) {
+	connConfig, err := pgconn.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	connConfig.RuntimeParams["replication"] = "database"
+
+	// Publications are named per database rather than per schema, so each schema has its own.
+	publication := watchPublication
+	if pgd.schema != "" {
+		publication += "_" + pgd.schema
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &changeStream{
+		pgd:         pgd,
+		connConfig:  connConfig,
+		publication: publication,
+		ctx:         ctx,
+		cancel:      cancel,
+		subscribers: make(map[*changeSubscriber]struct{}),
+	}, nil
+}
+
+// subscribe adds a subscriber to the stream, starting the stream if necessary. It returns once
+// every transaction committed after it was called will be received by the subscriber.
+func (cs *changeStream) subscribe(ctx context.Context) (*changeSubscriber, error) {
+	sub := &changeSubscriber{
+		changes: make(chan datastore.RevisionChanges, cs.pgd.watchBufferLength),
+		done:    make(chan struct{}),
+	}
+
+	cs.Lock()
+	if cs.started == nil {
+		cs.started = make(chan struct{})
+		cs.wg.Add(1)
+		go cs.run(cs.started)
+	}
+	started := cs.started
+	cs.subscribers[sub] = struct{}{}
+	cs.Unlock()
+
+	select {
+	case <-started:
+		// The stream removes its subscribers if it fails to start.
+		select {
+		case <-sub.done:
+			return nil, sub.err
+		default:
+			return sub, nil
+		}
+	case <-ctx.Done():
+		cs.unsubscribe(sub)
+		return nil, ctx.Err()
+	}
+}
+
+func (cs *changeStream) unsubscribe(sub *changeSubscriber) {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.remove(sub, nil)
+}
+
+// remove removes a subscriber from the stream. It must be called with the lock held.
+func (cs *changeStream) remove(sub *changeSubscriber, err error) {
+	if _, ok := cs.subscribers[sub]; !ok {
+		return
+	}
+	delete(cs.subscribers, sub)
+	sub.err = err
+	close(sub.done)
+}
+
+func (cs *changeStream) run(started chan struct{}) {
+	defer cs.wg.Done()
+
+	var once sync.Once
+	markStarted := func() { once.Do(func() { close(started) }) }
+
+	err := cs.stream(cs.ctx, markStarted)
+	if errors.Is(cs.ctx.Err(), context.Canceled) {
+		err = datastore.NewWatchCanceledErr()
+	} else {
+		log.Warn().Err(err).Msg("postgres logical replication watch stream failed")
+	}
+
+	cs.Lock()
+	defer cs.Unlock()
+	for sub := range cs.subscribers {
+		cs.remove(sub, err)
+	}
+	cs.started = nil
+	markStarted()
+}
+
+// stream creates a temporary replication slot and consumes it until the context is canceled or
+// the stream fails, calling markStarted once the slot has been created.
+func (cs *changeStream) stream(ctx context.Context, markStarted func()) error {
+	if err := cs.ensurePublication(ctx); err != nil {
+		return err
+	}
+
+	conn, err := pgconn.ConnectConfig(ctx, cs.connConfig)
+	if err != nil {
+		return fmt.Errorf("unable to open replication connection: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	slot := watchSlotPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := conn.Exec(ctx, fmt.Sprintf(createWatchSlot, slot)).ReadAll(); err != nil {
+		return fmt.Errorf("unable to create replication slot: %w", err)
+	}
+
+	conn.Frontend().Send(&pgproto3.Query{String: fmt.Sprintf(startWatchReplication, slot, cs.publication)})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("unable to start replication: %w", err)
+	}
+
+	msg, err := conn.ReceiveMessage(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to start replication: %w", err)
+	}
+	switch msg := msg.(type) {
+	case *pgproto3.CopyBothResponse:
+	case *pgproto3.ErrorResponse:
+		return fmt.Errorf("unable to start replication: %w", pgconn.ErrorResponseToPgError(msg))
+	default:
+		return fmt.Errorf("unable to start replication: unexpected message %T", msg)
+	}
+
+	log.Ctx(ctx).Info().Str("slot", slot).Msg("started postgres logical replication watch stream")
+	markStarted()
+
+	decoder := newTransactionDecoder()
+	var flushedLSN uint64
+	nextStatus := time.Now().Add(standbyStatusInterval)
+	for {
+		if !time.Now().Before(nextStatus) {
+			if err := sendStandbyStatus(conn, flushedLSN, time.Now()); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(standbyStatusInterval)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return fmt.Errorf("unable to receive from replication stream: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+
+			switch msg.Data[0] {
+			case primaryKeepaliveMessage:
+				walEnd, replyRequested, err := parsePrimaryKeepalive(msg.Data)
+				if err != nil {
+					return err
+				}
+
+				// Between transactions, everything sent so far has been processed.
+				if !decoder.inTransaction && walEnd > flushedLSN {
+					flushedLSN = walEnd
+				}
+				if replyRequested {
+					nextStatus = time.Now()
+				}
+
+			case xLogDataMessage:
+				payload, err := parseXLogData(msg.Data)
+				if err != nil {
+					return err
+				}
+
+				committed, commitEndLSN, err := decoder.decode(payload)
+				if err != nil {
+					return err
+				}
+				if len(committed) > 0 {
+					if err := cs.publish(ctx, committed); err != nil {
+						return err
+					}
+				}
+				if commitEndLSN > flushedLSN {
+					flushedLSN = commitEndLSN
+				}
+			}
+
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("replication stream failed: %w", pgconn.ErrorResponseToPgError(msg))
+
+		case *pgproto3.CopyDone:
+			return errors.New("replication stream ended")
+		}
+	}
+}
+
+// ensurePublication creates the publication of the transaction table, if it does not exist.
+func (cs *changeStream) ensurePublication(ctx context.Context) error {
+	_, err := cs.pgd.writePool.Exec(ctx, fmt.Sprintf(createWatchPublication, cs.publication, tableTransaction))
+
+	var pgErr *pgconn.PgError
+	if err != nil && !(errors.As(err, &pgErr) && pgErr.Code == pgDuplicateObject) {
+		return fmt.Errorf("unable to create publication %s: %w", cs.publication, err)
+	}
+	return nil
+}
+
+// publish loads the changes of the committed transactions and sends them to every subscriber,
+// disconnecting those that are not keeping up.
+func (cs *changeStream) publish(ctx context.Context, committed []revisionWithXid) error {
+	cs.Lock()
+	subscribed := len(cs.subscribers) > 0
+	cs.Unlock()
+	if !subscribed {
+		return nil
+	}
+
+	for _, rev := range committed {
+		if err := cs.waitForVisibility(ctx, rev.tx); err != nil {
+			return err
+		}
+	}
+
+	changes, err := cs.pgd.loadChanges(ctx, committed)
+	if err != nil {
+		return err
+	}
+
+	cs.Lock()
+	defer cs.Unlock()
+
+nextSubscriber:
+	for sub := range cs.subscribers {
+		for _, change := range changes {
+			select {
+			case sub.changes <- change:
+			default:
+				cs.remove(sub, datastore.NewWatchDisconnectedErr())
+				continue nextSubscriber
+			}
+		}
+	}
+	return nil
+}
+
+func (cs *changeStream) waitForVisibility(ctx context.Context, tx xid8) error {
+	for {
+		var visible bool
+		if err := cs.pgd.readPool.QueryRow(ctx, queryTransactionVisible, tx).Scan(&visible); err != nil {
+			return fmt.Errorf("unable to check visibility of transaction: %w", err)
+		}
+		if visible {
+			return nil
+		}
+
+		select {
+		case <-time.After(visibilityCheckInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// close stops the stream, which disconnects all of its subscribers.
+func (cs *changeStream) close() {
+	if cs == nil {
+		return
+	}
+
+	cs.cancel()
+	cs.wg.Wait()
+}
+
+// watchChangeStream implements Watch by subscribing to the change stream of the datastore.
+func (pgd *pgDatastore) watchChangeStream(
+	ctx context.Context,
+	afterRevision postgresRevision,
+	updates chan<- *datastore.RevisionChanges,
+	errs chan<- error,
+) {
+	defer close(updates)
+	defer close(errs)
+
+	sendError := func(err error) {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			errs <- datastore.NewWatchCanceledErr()
+		} else {
+			errs <- err
+		}
+	}
+
+	sub, err := pgd.changeStream.subscribe(ctx)
+	if err != nil {
+		sendError(err)
+		return
+	}
+	defer pgd.changeStream.unsubscribe(sub)
+
+	send := func(change datastore.RevisionChanges) bool {
+		select {
+		case updates <- &change:
+			return true
+		default:
+			errs <- datastore.NewWatchDisconnectedErr()
+			return false
+		}
+	}
+
+	// The transactions committed before the subscription are loaded from the transaction table,
+	// and may also be received from the stream if they were committed after it started.
+	caughtUp := make(map[uint64]struct{})
+	newTxns, err := pgd.getNewRevisions(ctx, afterRevision)
+	if err != nil {
+		sendError(err)
+		return
+	}
+
+	if len(newTxns) > 0 {
+		changes, err := pgd.loadChanges(ctx, newTxns)
+		if err != nil {
+			sendError(err)
+			return
+		}
+
+		for _, change := range changes {
+			if !send(change) {
+				return
+			}
+		}
+		for _, txn := range newTxns {
+			caughtUp[txn.tx.Uint64] = struct{}{}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			errs <- datastore.NewWatchCanceledErr()
+			return
+
+		case <-sub.done:
+			sendError(sub.err)
+			return
+
+		case change := <-sub.changes:
+			xid := change.Revision.(revisionWithXid).tx.Uint64
+			if _, ok := caughtUp[xid]; ok || afterRevision.snapshot.txVisible(xid) {
+				continue
+			}
+
+			if !send(change) {
+				return
+			}
+		}
+	}
+}
+
+// transactionDecoder decodes the pgoutput messages of the change stream into the revisions of
+// the transactions inserted into the transaction table.
+type transactionDecoder struct {
+	relations     map[uint32]transactionRelation
+	inTransaction bool
+	pending       []revisionWithXid
+}
+
+// transactionRelation is the position of the columns of a relation which are needed to decode
+// the revision of a transaction, or -1 if the relation does not have them.
+type transactionRelation struct {
+	xidColumn, snapshotColumn int
+}
+
+func newTransactionDecoder() *transactionDecoder {
+	return &transactionDecoder{relations: make(map[uint32]transactionRelation)}
+}
+
+// decode decodes a pgoutput message. For the commit of a transaction, it returns the revisions
+// the transaction inserted and the LSN of the end of the transaction.
+func (d *transactionDecoder) decode(data []byte) ([]revisionWithXid, uint64, error) {
+	r := &messageReader{data: data}
+
+	switch r.uint8() {
+	case pgoutputBegin:
+		d.inTransaction = true
+		d.pending = nil
+
+	case pgoutputCommit:
+		r.uint8()  // Flags
+		r.uint64() // Commit LSN
+		endLSN := r.uint64()
+		if r.err != nil {
+			return nil, 0, fmt.Errorf("unable to decode commit: %w", r.err)
+		}
+
+		committed := d.pending
+		d.inTransaction = false
+		d.pending = nil
+		return committed, endLSN, nil
+
+	case pgoutputRelation:
+		relationID := r.uint32()
+		r.cstring() // Namespace
+		name := r.cstring()
+		r.uint8() // Replica identity
+		columnCount := int(r.uint16())
+
+		relation := transactionRelation{xidColumn: -1, snapshotColumn: -1}
+		for i := 0; i < columnCount && r.err == nil; i++ {
+			r.uint8() // Flags
+			switch r.cstring() {
+			case colXID:
+				relation.xidColumn = i
+			case colSnapshot:
+				relation.snapshotColumn = i
+			}
+			r.uint32() // Type OID
+			r.uint32() // Type modifier
+		}
+		if r.err != nil {
+			return nil, 0, fmt.Errorf("unable to decode relation: %w", r.err)
+		}
+
+		if name != tableTransaction {
+			relation = transactionRelation{xidColumn: -1, snapshotColumn: -1}
+		}
+		d.relations[relationID] = relation
+
+	case pgoutputInsert:
+		relationID := r.uint32()
+		r.uint8() // New tuple marker
+		values := r.tupleData()
+		if r.err != nil {
+			return nil, 0, fmt.Errorf("unable to decode insert: %w", r.err)
+		}
+
+		relation, ok := d.relations[relationID]
+		if !ok {
+			return nil, 0, fmt.Errorf("insert into unknown relation %d", relationID)
+		}
+		if relation.xidColumn < 0 || relation.snapshotColumn < 0 {
+			return nil, 0, nil
+		}
+		if relation.xidColumn >= len(values) || relation.snapshotColumn >= len(values) {
+			return nil, 0, fmt.Errorf("insert into relation %d is missing columns", relationID)
+		}
+
+		rev, err := decodeTransactionRow(values[relation.xidColumn], values[relation.snapshotColumn])
+		if err != nil {
+			return nil, 0, err
+		}
+		d.pending = append(d.pending, rev)
+	}
+
+	return nil, 0, nil
+}
+
+func decodeTransactionRow(xidText, snapshotText *string) (revisionWithXid, error) {
+	if xidText == nil || snapshotText == nil {
+		return revisionWithXid{}, errors.New("transaction row is missing its xid or snapshot")
+	}
+
+	xid, err := strconv.ParseUint(*xidText, 10, 64)
+	if err != nil {
+		return revisionWithXid{}, fmt.Errorf("unable to decode transaction xid: %w", err)
+	}
+
+	var snapshot pgSnapshot
+	if err := snapshot.ScanText(pgtype.Text{String: *snapshotText, Valid: true}); err != nil {
+		return revisionWithXid{}, fmt.Errorf("unable to decode transaction snapshot: %w", err)
+	}
+
+	return revisionWithXid{
+		postgresRevision{snapshot.markComplete(xid)},
+		newXid8(xid),
+	}, nil
+}
+
+// parseXLogData returns the WAL data of an XLogData message.
+func parseXLogData(data []byte) ([]byte, error) {
+	r := &messageReader{data: data}
+	r.uint8()  // Message type
+	r.uint64() // Start of the WAL data
+	r.uint64() // End of the WAL on the server
+	r.uint64() // Server time
+	if r.err != nil {
+		return nil, fmt.Errorf("unable to decode XLogData: %w", r.err)
+	}
+	return r.data, nil
+}
+
+// parsePrimaryKeepalive returns the end of the WAL on the server, and whether the server asked
+// for a standby status update, from a primary keepalive message.
+func parsePrimaryKeepalive(data []byte) (uint64, bool, error) {
+	r := &messageReader{data: data}
+	r.uint8() // Message type
+	walEnd := r.uint64()
+	r.uint64() // Server time
+	replyRequested := r.uint8() == 1
+	if r.err != nil {
+		return 0, false, fmt.Errorf("unable to decode primary keepalive: %w", r.err)
+	}
+	return walEnd, replyRequested, nil
+}
+
+// encodeStandbyStatus encodes a standby status update, reporting the WAL up to the LSN as
+// written, flushed and applied.
+func encodeStandbyStatus(lsn uint64, now time.Time) []byte {
+	data := make([]byte, 0, 34)
+	data = append(data, standbyStatusMessage)
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, lsn)
+	data = binary.BigEndian.AppendUint64(data, uint64(now.Sub(postgresEpoch).Microseconds()))
+	return append(data, 0)
+}
+
+func sendStandbyStatus(conn *pgconn.PgConn, lsn uint64, now time.Time) error {
+	conn.Frontend().Send(&pgproto3.CopyData{Data: encodeStandbyStatus(lsn, now)})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("unable to send standby status: %w", err)
+	}
+	return nil
+}
+
+var errMessageTooShort = errors.New("message too short")
+
+// messageReader reads the fields of a replication message, recording the first error.
+type messageReader struct {
+	data []byte
+	err  error
+}
+
+func (r *messageReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errMessageTooShort
+		return nil
+	}
+
+	read := r.data[:n]
+	r.data = r.data[n:]
+	return read
+}
+
+func (r *messageReader) uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *messageReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *messageReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *messageReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *messageReader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+
+	end := -1
+	for i, b := range r.data {
+		if b == 0 {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		r.err = errMessageTooShort
+		return ""
+	}
+
+	read := string(r.data[:end])
+	r.data = r.data[end+1:]
+	return read
+}
+
+// tupleData reads the columns of a tuple as text, with nil for null and unchanged values.
+func (r *messageReader) tupleData() []*string {
+	columnCount := int(r.uint16())
+
+	values := make([]*string, 0, columnCount)
+	for i := 0; i < columnCount && r.err == nil; i++ {
+		switch kind := r.uint8(); kind {
+		case 'n', 'u':
+			values = append(values, nil)
+		case 't':
+			length := int(r.uint32())
+			value := string(r.next(length))
+			values = append(values, &value)
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("unsupported tuple data kind %q", kind)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// messageWriter builds replication messages for the decoder tests.
+type messageWriter []byte
+
+func (w messageWriter) uint8(v uint8) messageWriter   { return append(w, v) }
+func (w messageWriter) uint16(v uint16) messageWriter { return binary.BigEndian.AppendUint16(w, v) }
+func (w messageWriter) uint32(v uint32) messageWriter { return binary.BigEndian.AppendUint32(w, v) }
+func (w messageWriter) uint64(v uint64) messageWriter { return binary.BigEndian.AppendUint64(w, v) }
+func (w messageWriter) cstring(v string) messageWriter {
+	return append(append(w, v...), 0)
+}
+
+func (w messageWriter) text(v string) messageWriter {
+	return append(w.uint8('t').uint32(uint32(len(v))), v...)
+}
+
+func relationMessage(id uint32, name string, columns ...string) []byte {
+	w := messageWriter{}.uint8(pgoutputRelation).uint32(id).cstring("public").cstring(name).uint8('d').uint16(uint16(len(columns)))
+	for _, column := range columns {
+		w = w.uint8(0).cstring(column).uint32(25).uint32(0)
+	}
+	return w
+}
+
+func transactionInsertMessage(id uint32, xid, snapshot string) []byte {
+	return messageWriter{}.uint8(pgoutputInsert).uint32(id).uint8('N').uint16(3).
+		text(xid).
+		text(snapshot).
+		text("2023-01-01 00:00:00")
+}
+
+func TestTransactionDecoder(t *testing.T) {
+	req := require.New(t)
+	decoder := newTransactionDecoder()
+
+	decode := func(msg []byte) ([]revisionWithXid, uint64) {
+		committed, endLSN, err := decoder.decode(msg)
+		req.NoError(err)
+		return committed, endLSN
+	}
+
+	decode(relationMessage(1, tableTransaction, colXID, colSnapshot, colTimestamp))
+	decode(relationMessage(2, "other_table", colXID, colSnapshot))
+
+	committed, endLSN := decode(messageWriter{}.uint8(pgoutputBegin).uint64(100).uint64(0).uint32(12))
+	req.Empty(committed)
+	req.Zero(endLSN)
+	req.True(decoder.inTransaction)
+
+	// Only the rows of the transaction table are decoded.
+	decode(transactionInsertMessage(2, "11", "9:12:"))
+	decode(transactionInsertMessage(1, "12", "11:12:11"))
+
+	committed, endLSN = decode(messageWriter{}.uint8(pgoutputCommit).uint8(0).uint64(100).uint64(120).uint64(0))
+	req.Equal(uint64(120), endLSN)
+	req.False(decoder.inTransaction)
+	req.Len(committed, 1)
+	req.Equal(uint64(12), committed[0].tx.Uint64)
+	req.Equal(snap(11, 13, 11), committed[0].snapshot)
+
+	// Messages the decoder does not use are ignored.
+	committed, _ = decode(messageWriter{}.uint8('Y').uint32(1))
+	req.Empty(committed)
+}
+
+func TestTransactionDecoderErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		msg  []byte
+	}{
+		{"truncated relation", relationMessage(1, tableTransaction, colXID, colSnapshot)[:12]},
+		{"unknown relation", transactionInsertMessage(5, "12", "10:12:")},
+		{"truncated insert", transactionInsertMessage(1, "12", "10:12:")[:20]},
+		{"invalid xid", transactionInsertMessage(1, "twelve", "10:12:")},
+		{"invalid snapshot", transactionInsertMessage(1, "12", "10")},
+		{"truncated commit", messageWriter{}.uint8(pgoutputCommit).uint8(0).uint64(100)},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			decoder := newTransactionDecoder()
+			_, _, err := decoder.decode(relationMessage(1, tableTransaction, colXID, colSnapshot, colTimestamp))
+			require.NoError(t, err)
+
+			_, _, err = decoder.decode(tc.msg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestReplicationMessages(t *testing.T) {
+	req := require.New(t)
+
+	payload, err := parseXLogData(messageWriter{}.uint8(xLogDataMessage).uint64(1).uint64(2).uint64(3).uint8(pgoutputBegin))
+	req.NoError(err)
+	req.Equal([]byte{pgoutputBegin}, payload)
+
+	_, err = parseXLogData(messageWriter{}.uint8(xLogDataMessage).uint64(1))
+	req.Error(err)
+
+	walEnd, replyRequested, err := parsePrimaryKeepalive(messageWriter{}.uint8(primaryKeepaliveMessage).uint64(42).uint64(0).uint8(1))
+	req.NoError(err)
+	req.Equal(uint64(42), walEnd)
+	req.True(replyRequested)
+
+	status := encodeStandbyStatus(42, postgresEpoch.Add(time.Second))
+	req.Len(status, 34)
+	req.Equal(byte(standbyStatusMessage), status[0])
+	for _, offset := range []int{1, 9, 17} {
+		req.Equal(uint64(42), binary.BigEndian.Uint64(status[offset:]))
+	}
+	req.Equal(uint64(time.Second.Microseconds()), binary.BigEndian.Uint64(status[25:]))
+	req.Equal(byte(0), status[33])
+}
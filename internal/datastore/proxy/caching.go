@@ -55,6 +55,22 @@ func NewCachingDatastoreProxy(delegate datastore.Datastore, c cache.Cache) datas
 	}
 }
 
+// NewWatchingCachingDatastoreProxy creates a new datastore proxy which caches definitions
+// like NewCachingDatastoreProxy, and which also watches the datastore for schema changes. A
+// definition is then shared between reads at any revision until the watch reports that it
+// changed, so a schema written through any node is picked up by every node. The shared
+// definitions are held in the cache, so they are bounded by its size.
+func NewWatchingCachingDatastoreProxy(delegate datastore.Datastore, c cache.Cache) datastore.Datastore {
+	if c == nil {
+		c = cache.NoopCache()
+	}
+	return &definitionCachingProxy{
+		Datastore: delegate,
+		c:         c,
+		watcher:   newDefinitionWatcher(delegate, c),
+	}
+}
+
 type schemaDefinition interface {
 	compiler.SchemaDefinition
 	SizeVT() int
@@ -64,9 +80,13 @@ type definitionCachingProxy struct {
 	datastore.Datastore
 	c         cache.Cache
 	readGroup singleflight.Group
+	watcher   *definitionWatcher
 }
 
 func (p *definitionCachingProxy) Close() error {
+	if p.watcher != nil {
+		p.watcher.close()
+	}
 	p.c.Close()
 	return p.Datastore.Close()
 }
@@ -158,15 +178,28 @@ func listAndCache[T schemaDefinition](
 	remainingToLoad.Extend(names)
 
 	foundDefs := make([]datastore.RevisionedDefinition[T], 0, len(names))
+	kind := cacheKeyPrefixKinds[prefix]
 	for _, name := range names {
-		cacheRevisionKey := prefix + ":" + name + "@" + r.rev.String()
-		loadedRaw, found := r.p.c.Get(cacheRevisionKey)
+		loaded, found := r.p.watcher.get(prefix, name, r.rev)
 		if !found {
+			cacheRevisionKey := prefix + ":" + name + "@" + r.rev.String()
+			loadedRaw, found := r.p.c.Get(cacheRevisionKey)
+			if !found {
+				definitionCacheMisses.WithLabelValues(kind).Inc()
+				continue
+			}
+			loaded = loadedRaw.(*cacheEntry)
+		}
+
+		// Definitions which were cached as not found are loaded again, as they are not
+		// returned by the lookup.
+		if loaded.notFound != nil {
+			definitionCacheMisses.WithLabelValues(kind).Inc()
 			continue
 		}
 
+		definitionCacheHits.WithLabelValues(kind).Inc()
 		remainingToLoad.Remove(name)
-		loaded := loadedRaw.(*cacheEntry)
 		foundDefs = append(foundDefs, datastore.RevisionedDefinition[T]{
 			Definition:          loaded.definition.(T),
 			LastWrittenRevision: loaded.updated,
@@ -187,6 +220,7 @@ func listAndCache[T schemaDefinition](
 			estimatedDefinitionSize := estimator(def.Definition.SizeVT())
 			entry := &cacheEntry{def.Definition, def.LastWrittenRevision, estimatedDefinitionSize, err}
 			r.p.c.Set(cacheRevisionKey, entry, entry.Size())
+			r.p.watcher.set(prefix, def.Definition.GetName(), r.rev, entry)
 		}
 
 		// We have to call wait here or else Ristretto may not have the key(s)
@@ -205,10 +239,19 @@ func readAndCache[T schemaDefinition](
 	reader func(ctx context.Context, name string) (T, datastore.Revision, error),
 	estimator func(sizeVT int) int64,
 ) (T, datastore.Revision, error) {
+	kind := cacheKeyPrefixKinds[prefix]
+	if loaded, found := r.p.watcher.get(prefix, name, r.rev); found {
+		definitionCacheHits.WithLabelValues(kind).Inc()
+		return loaded.definition.(T), loaded.updated, loaded.notFound
+	}
+
 	// Check the cache.
 	cacheRevisionKey := prefix + ":" + name + "@" + r.rev.String()
 	loadedRaw, found := r.p.c.Get(cacheRevisionKey)
-	if !found {
+	if found {
+		definitionCacheHits.WithLabelValues(kind).Inc()
+	} else {
+		definitionCacheMisses.WithLabelValues(kind).Inc()
 		// We couldn't use the cached entry, load one
 		var err error
 		loadedRaw, err, _ = r.p.readGroup.Do(cacheRevisionKey, func() (any, error) {
@@ -223,6 +266,7 @@ func readAndCache[T schemaDefinition](
 			estimatedDefinitionSize := estimator(loaded.SizeVT())
 			entry := &cacheEntry{loaded, updatedRev, estimatedDefinitionSize, err}
 			r.p.c.Set(cacheRevisionKey, entry, entry.Size())
+			r.p.watcher.set(prefix, name, r.rev, entry)
 
 			// We have to call wait here or else Ristretto may not have the key
 			// available to a subsequent caller.
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
 	"github.com/authzed/spicedb/pkg/caveats"
@@ -21,6 +22,7 @@ import (
 	ns "github.com/authzed/spicedb/pkg/namespace"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/testutil"
+	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/util"
 )
 
@@ -513,3 +515,60 @@ func TestMixedCaching(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchingCachingInvalidation(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	viewer := ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "..."))
+	firstDef := ns.Namespace("document", viewer)
+	_, err = rawDS.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, firstDef, ns.Namespace("user"))
+	})
+	require.NoError(err)
+
+	ds := NewWatchingCachingDatastoreProxy(rawDS, DatastoreProxyTestCache(t))
+	t.Cleanup(func() { require.NoError(ds.Close()) })
+
+	watcher := ds.(*definitionCachingProxy).watcher
+	require.Eventually(func() bool {
+		watcher.RLock()
+		defer watcher.RUnlock()
+		return watcher.startRevision != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	loadedAt, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	_, _, err = ds.SnapshotReader(loadedAt).ReadNamespaceByName(ctx, "document")
+	require.NoError(err)
+
+	// A definition is shared with later revisions which did not change it.
+	relWritten, err := common.WriteTuples(ctx, rawDS, core.RelationTupleUpdate_CREATE, tuple.MustParse("document:doc1#viewer@user:tom"))
+	require.NoError(err)
+	require.Eventually(func() bool {
+		entry, found := watcher.get(namespaceCacheKeyPrefix, "document", relWritten)
+		return found && entry.definition.GetName() == "document"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A schema write made without going through the proxy, as on another node, drops it.
+	secondDef := ns.Namespace("document", viewer, ns.MustRelation("editor", nil, ns.AllowedRelation("user", "...")))
+	schemaWritten, err := rawDS.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, secondDef)
+	})
+	require.NoError(err)
+	require.Eventually(func() bool {
+		_, found := watcher.get(namespaceCacheKeyPrefix, "document", loadedAt)
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+
+	found, _, err := ds.SnapshotReader(schemaWritten).ReadNamespaceByName(ctx, "document")
+	require.NoError(err)
+	testutil.RequireProtoEqual(t, secondDef, found, "found different namespaces")
+
+	found, _, err = ds.SnapshotReader(relWritten).ReadNamespaceByName(ctx, "document")
+	require.NoError(err)
+	testutil.RequireProtoEqual(t, firstDef, found, "found different namespaces")
+}
@@ -0,0 +1,241 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var (
+	definitionCacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "definition_cache_hits_total",
+		Help:      "total number of schema definitions read from the definition cache",
+	}, []string{"kind"})
+
+	definitionCacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "definition_cache_misses_total",
+		Help:      "total number of schema definitions which had to be loaded from the datastore",
+	}, []string{"kind"})
+
+	definitionCacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "definition_cache_invalidations_total",
+		Help:      "total number of cached schema definitions dropped because of a schema change",
+	}, []string{"kind"})
+)
+
+// definitionWatchRetryDelay is the time waited before restarting a failed definition watch.
+const definitionWatchRetryDelay = 1 * time.Second
+
+var cacheKeyPrefixKinds = map[string]string{
+	namespaceCacheKeyPrefix: "namespace",
+	caveatCacheKeyPrefix:    "caveat",
+}
+
+// definitionWatcher holds the latest known version of each definition, shared by reads at
+// any revision. Entries are dropped as soon as the datastore's watch reports a change to the
+// schema, so that every node sees schema writes made through any other node.
+//
+// An entry loaded at revision L is only served for a read at revision R if L <= R and the
+// watch has processed every change up to R without seeing a schema change after L. Only the
+// revision of the latest schema change is tracked, rather than that of each definition, so
+// that the watcher holds nothing beyond the entries themselves: as schema writes usually
+// rewrite every definition, little is lost by dropping all entries on any of them.
+//
+// The entries are stored in the definition cache, so they are bounded by its configured size
+// and evicted along with its other entries.
+type definitionWatcher struct {
+	delegate datastore.Datastore
+	c        cache.Cache
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	sync.RWMutex
+
+	// startRevision is the revision from which the current watch reports changes, and
+	// watermark is the revision through which all changes have been applied. Both are nil
+	// while no watch is running, in which case nothing is cached.
+	startRevision datastore.Revision
+	watermark     datastore.Revision
+
+	// schemaChanged is the revision of the latest schema change seen by the current watch, or
+	// nil if it has seen none.
+	schemaChanged datastore.Revision
+}
+
+type watchedEntry struct {
+	*cacheEntry
+	loadedAt datastore.Revision
+}
+
+func watchedCacheKey(prefix, name string) string {
+	// Unlike the keys of the definitions cached for a single revision, these carry no revision.
+	return prefix + ":" + name
+}
+
+func newDefinitionWatcher(delegate datastore.Datastore, c cache.Cache) *definitionWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &definitionWatcher{
+		delegate: delegate,
+		c:        c,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go w.run(ctx)
+	return w
+}
+
+func (w *definitionWatcher) close() {
+	w.cancel()
+	<-w.done
+}
+
+func (w *definitionWatcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	for {
+		err := w.watch(ctx)
+		w.reset(nil)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.As(err, &datastore.ErrWatchDisabled{}):
+			log.Ctx(ctx).Warn().Err(err).Msg("definition cache will not be invalidated across nodes")
+			return
+		}
+
+		log.Ctx(ctx).Warn().Err(err).Dur("after", definitionWatchRetryDelay).Msg("restarting definition cache watch")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(definitionWatchRetryDelay):
+		}
+	}
+}
+
+func (w *definitionWatcher) watch(ctx context.Context) error {
+	headRevision, err := w.delegate.HeadRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	w.reset(headRevision)
+	changes, errchan := w.delegate.Watch(ctx, headRevision)
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				// The error is delivered on errchan.
+				changes = nil
+				continue
+			}
+			w.apply(change)
+		case err := <-errchan:
+			return err
+		}
+	}
+}
+
+// reset drops all entries and starts accepting new ones loaded at or after startRevision.
+// Entries loaded before are left in the cache to be evicted, as they are never served again.
+func (w *definitionWatcher) reset(startRevision datastore.Revision) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.startRevision = startRevision
+	w.watermark = startRevision
+	w.schemaChanged = nil
+}
+
+func (w *definitionWatcher) apply(change *datastore.RevisionChanges) {
+	w.Lock()
+	defer w.Unlock()
+
+	if len(change.ChangedDefinitions) > 0 || len(change.DeletedNamespaces) > 0 || len(change.DeletedCaveats) > 0 {
+		w.schemaChanged = change.Revision
+	}
+	w.watermark = change.Revision
+}
+
+// get returns the entry for the definition if it is valid at the revision.
+func (w *definitionWatcher) get(prefix, name string, rev datastore.Revision) (*cacheEntry, bool) {
+	if w == nil {
+		return nil, false
+	}
+
+	w.RLock()
+	defer w.RUnlock()
+
+	if w.watermark == nil || rev.GreaterThan(w.watermark) {
+		return nil, false
+	}
+
+	loadedRaw, ok := w.c.Get(watchedCacheKey(prefix, name))
+	if !ok {
+		return nil, false
+	}
+
+	entry := loadedRaw.(watchedEntry)
+	if !w.validSinceLoadedLocked(entry.loadedAt) {
+		if w.schemaChanged != nil && entry.loadedAt.LessThan(w.schemaChanged) {
+			definitionCacheInvalidations.WithLabelValues(cacheKeyPrefixKinds[prefix]).Inc()
+		}
+		return nil, false
+	}
+
+	if rev.LessThan(entry.loadedAt) {
+		return nil, false
+	}
+
+	return entry.cacheEntry, true
+}
+
+// validSinceLoadedLocked returns whether an entry loaded at the revision is known not to have
+// changed since, as the current watch started before it and has seen no schema change after it.
+func (w *definitionWatcher) validSinceLoadedLocked(loadedAt datastore.Revision) bool {
+	if w.startRevision == nil || loadedAt.LessThan(w.startRevision) {
+		return false
+	}
+	return w.schemaChanged == nil || !loadedAt.LessThan(w.schemaChanged)
+}
+
+// set stores the entry for the definition as loaded at the revision.
+func (w *definitionWatcher) set(prefix, name string, rev datastore.Revision, entry *cacheEntry) {
+	if w == nil {
+		return
+	}
+
+	w.RLock()
+	defer w.RUnlock()
+
+	// Changes before the watch started were never seen, and a schema change after the
+	// revision means the entry may already be stale.
+	if !w.validSinceLoadedLocked(rev) {
+		return
+	}
+
+	// A valid entry loaded earlier is kept, as it is shared with more revisions.
+	key := watchedCacheKey(prefix, name)
+	if existingRaw, ok := w.c.Get(key); ok {
+		existing := existingRaw.(watchedEntry)
+		if w.validSinceLoadedLocked(existing.loadedAt) && !rev.LessThan(existing.loadedAt) {
+			return
+		}
+	}
+
+	w.c.Set(key, watchedEntry{entry, rev}, entry.Size())
+}
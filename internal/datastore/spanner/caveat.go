@@ -104,7 +104,7 @@ func (rwt spannerReadWriteTXN) WriteCaveats(_ context.Context, caveats []*core.C
 			tableCaveat,
 			[]string{colName, colCaveatDefinition, colCaveatTS},
 			[]interface{}{caveat.Name, serialized, spanner.CommitTimestamp},
-		))
+		), schemaChangeMutation(colChangeOpTouch, schemaChangeKindCaveat, caveat.Name, serialized))
 	}
 
 	return rwt.spannerRWT.BufferWrite(mutations)
@@ -112,12 +112,14 @@ func (rwt spannerReadWriteTXN) WriteCaveats(_ context.Context, caveats []*core.C
 
 func (rwt spannerReadWriteTXN) DeleteCaveats(_ context.Context, names []string) error {
 	keys := make([]spanner.Key, 0, len(names))
+	mutations := make([]*spanner.Mutation, 0, len(names)+1)
 	for _, n := range names {
 		keys = append(keys, spanner.Key{n})
+		mutations = append(mutations, schemaChangeMutation(colChangeOpDelete, schemaChangeKindCaveat, n, nil))
 	}
-	err := rwt.spannerRWT.BufferWrite([]*spanner.Mutation{
-		spanner.Delete(tableCaveat, spanner.KeySetFromKeys(keys...)),
-	})
+	mutations = append(mutations, spanner.Delete(tableCaveat, spanner.KeySetFromKeys(keys...)))
+
+	err := rwt.spannerRWT.BufferWrite(mutations)
 	if err != nil {
 		return fmt.Errorf(errUnableToDeleteCaveat, err)
 	}
@@ -54,6 +54,40 @@ func (sd spannerDatastore) runGC(ctx context.Context) error {
 
 		log.Ctx(ctx).Info().Int64("removed", numRemoved).Stringer("before", oldestRevision).
 			Msg("garbage collection: removed changelog entries")
+
+		stmt, args, err = sql.Delete(tableSchemaChangelog).Where(sq.Lt{colSchemaChangeTS: oldestRevision}).ToSql()
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error creating schema changelog delete statement")
+		}
+
+		var numSchemaRemoved int64
+		_, err = sd.client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+			numSchemaRemoved, err = rwt.Update(ctx, statementFromSQL(stmt, args))
+			return err
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error deleting schema changelog entries")
+		}
+
+		log.Ctx(ctx).Info().Int64("removed", numSchemaRemoved).Stringer("before", oldestRevision).
+			Msg("garbage collection: removed schema changelog entries")
+
+		stmt, args, err = sql.Delete(tableRelationship).Where(sq.Lt{colExpiration: oldestRevision}).ToSql()
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error creating expired relationships delete statement")
+		}
+
+		var numExpired int64
+		_, err = sd.client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+			numExpired, err = rwt.Update(ctx, statementFromSQL(stmt, args))
+			return err
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error deleting expired relationships")
+		}
+
+		log.Ctx(ctx).Info().Int64("removed", numExpired).Stringer("before", oldestRevision).
+			Msg("garbage collection: removed expired relationships")
 	})
 	if err != nil {
 		return fmt.Errorf("unable to start garbage collection: %w", err)
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+)
+
+const (
+	createSchemaChangelog = `CREATE TABLE schema_changelog (
+		timestamp TIMESTAMP NOT NULL OPTIONS (allow_commit_timestamp=true),
+		uuid STRING(36) NOT NULL,
+		operation INT64 NOT NULL,
+		definition_kind INT64 NOT NULL,
+		name STRING(1024) NOT NULL,
+		definition BYTES(MAX),
+	) PRIMARY KEY (timestamp, uuid)`
+)
+
+func init() {
+	if err := SpannerMigrations.Register("add-schema-changelog", "add-expiration-support", func(ctx context.Context, w Wrapper) error {
+		updateOp, err := w.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database: w.client.DatabaseName(),
+			Statements: []string{
+				createSchemaChangelog,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return updateOp.Wait(ctx)
+	}, nil); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
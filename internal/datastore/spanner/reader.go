@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/spanner"
+	sq "github.com/Masterminds/squirrel"
 	"google.golang.org/grpc/codes"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -30,6 +31,7 @@ type txFactory func() readTX
 type spannerReader struct {
 	querySplitter common.TupleQuerySplitter
 	txSource      txFactory
+	unexpired     sq.Sqlizer
 }
 
 func (sr spannerReader) QueryRelationships(
@@ -37,7 +39,7 @@ func (sr spannerReader) QueryRelationships(
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples).FilterWithRelationshipsFilter(filter)
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples.Where(sr.unexpired)).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +52,7 @@ func (sr spannerReader) ReverseQueryRelationships(
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples).
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples.Where(sr.unexpired)).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -67,6 +69,8 @@ func (sr spannerReader) ReverseQueryRelationships(
 	return sr.querySplitter.SplitAndExecuteQuery(ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
@@ -90,6 +94,7 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 			}
 			var caveatName spanner.NullString
 			var caveatCtx spanner.NullJSON
+			var expiration spanner.NullTime
 			err := row.Columns(
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
@@ -99,6 +104,7 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 				&nextTuple.Subject.Relation,
 				&caveatName,
 				&caveatCtx,
+				&expiration,
 			)
 			if err != nil {
 				return err
@@ -108,6 +114,7 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 			if err != nil {
 				return err
 			}
+			nextTuple.OptionalExpirationTime = expirationFrom(expiration)
 
 			tuples = append(tuples, nextTuple)
 
@@ -226,8 +233,17 @@ var queryTuples = sql.Select(
 	colUsersetRelation,
 	colCaveatName,
 	colCaveatContext,
+	colExpiration,
 ).From(tableRelationship)
 
+// unexpiredAt returns a filter on relationships which have not expired at the given time.
+func unexpiredAt(at time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{colExpiration: nil}, sq.Gt{colExpiration: at}}
+}
+
+// unexpiredNow filters out relationships which have expired at the time of the transaction.
+var unexpiredNow = sq.Or{sq.Eq{colExpiration: nil}, sq.Expr(colExpiration + " > CURRENT_TIMESTAMP()")}
+
 var schema = common.NewSchemaInformation(
 	colNamespace,
 	colObjectID,
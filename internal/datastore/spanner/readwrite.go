@@ -10,7 +10,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -27,6 +29,10 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 
 	var rowCountChange int64
 
+	// Relationships being created may still exist as expired rows which have not yet been
+	// garbage collected; those rows are removed before the insert is applied.
+	expiredCreates := sq.Or{}
+
 	for _, mutation := range mutations {
 		var txnMut *spanner.Mutation
 		var op int
@@ -39,6 +45,7 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 			rowCountChange++
 			txnMut = spanner.Insert(tableRelationship, allRelationshipCols, upsertVals(mutation.Tuple))
 			op = colChangeOpCreate
+			expiredCreates = append(expiredCreates, exactRelationshipClause(mutation.Tuple))
 		case core.RelationTupleUpdate_DELETE:
 			rowCountChange--
 			txnMut = spanner.Delete(tableRelationship, keyFromRelationship(mutation.Tuple))
@@ -57,6 +64,22 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		}
 	}
 
+	if len(expiredCreates) > 0 {
+		delSQL, delArgs, err := sql.Delete(tableRelationship).
+			Where(expiredCreates).
+			Where(sq.Expr(colExpiration + " <= CURRENT_TIMESTAMP()")).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		numDeleted, err := rwt.spannerRWT.Update(ctx, statementFromSQL(delSQL, delArgs))
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		rowCountChange -= numDeleted
+	}
+
 	if !rwt.disableStats {
 		if err := updateCounter(ctx, rwt.spannerRWT, rowCountChange); err != nil {
 			return fmt.Errorf(errUnableToWriteRelationships, err)
@@ -66,6 +89,54 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	return nil
 }
 
+// bulkLoadBatchSize is the number of relationships whose mutations are buffered together by
+// BulkLoad.
+const bulkLoadBatchSize = 1_000
+
+func (rwt spannerReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	changeUUID := uuid.New().String()
+
+	var numLoaded uint64
+	var next *core.RelationTuple
+	var err error
+
+	mutations := make([]*spanner.Mutation, 0, bulkLoadBatchSize*2)
+	for next, err = iter.Next(ctx); next != nil && err == nil; next, err = iter.Next(ctx) {
+		// Mutation values are only encoded when the transaction commits, so the tuple must be
+		// copied in case the source reuses it.
+		tpl := next.CloneVT()
+		mutations = append(mutations,
+			spanner.Insert(tableRelationship, allRelationshipCols, upsertVals(tpl)),
+			spanner.Insert(tableChangelog, allChangelogCols, changeVals(changeUUID, colChangeOpCreate, tpl)),
+		)
+		numLoaded++
+
+		if len(mutations) >= bulkLoadBatchSize*2 {
+			if err := rwt.spannerRWT.BufferWrite(mutations); err != nil {
+				return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+			mutations = mutations[:0]
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(mutations) > 0 {
+		if err := rwt.spannerRWT.BufferWrite(mutations); err != nil {
+			return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+	}
+
+	if !rwt.disableStats {
+		if err := updateCounter(ctx, rwt.spannerRWT, int64(numLoaded)); err != nil {
+			return 0, fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+	}
+
+	return numLoaded, nil
+}
+
 func (rwt spannerReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
 	err := deleteWithFilter(ctx, rwt.spannerRWT, filter, rwt.disableStats)
 	if err != nil {
@@ -124,6 +195,7 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 	}
 	var caveatName spanner.NullString
 	var caveatCtx spanner.NullJSON
+	var expiration spanner.NullTime
 
 	var changelogMutations []*spanner.Mutation
 	if err := toDelete.Do(func(row *spanner.Row) error {
@@ -136,10 +208,12 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 			&rel.Subject.Relation,
 			&caveatName,
 			&caveatCtx,
+			&expiration,
 		)
 		if err != nil {
 			return err
 		}
+		rel.OptionalExpirationTime = expirationFrom(expiration)
 		rel.Caveat, err = ContextualizedCaveatFrom(caveatName, caveatCtx)
 		if err != nil {
 			return err
@@ -182,6 +256,7 @@ func upsertVals(r *core.RelationTuple) []any {
 	key := keyFromRelationship(r)
 	key = append(key, spanner.CommitTimestamp)
 	key = append(key, caveatVals(r)...)
+	key = append(key, expirationVal(r))
 	return key
 }
 
@@ -209,6 +284,7 @@ func changeVals(changeUUID string, op int, r *core.RelationTuple) []any {
 		r.Subject.Relation,
 	}
 	vals = append(vals, caveatVals(r)...)
+	vals = append(vals, expirationVal(r))
 	return vals
 }
 
@@ -225,6 +301,31 @@ func caveatVals(r *core.RelationTuple) []any {
 	return vals
 }
 
+func expirationVal(r *core.RelationTuple) spanner.NullTime {
+	if expiration := common.ExpirationFor(r); expiration != nil {
+		return spanner.NullTime{Time: *expiration, Valid: true}
+	}
+	return spanner.NullTime{}
+}
+
+func expirationFrom(expiration spanner.NullTime) *timestamppb.Timestamp {
+	if !expiration.Valid {
+		return nil
+	}
+	return timestamppb.New(expiration.Time)
+}
+
+func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
+	return sq.Eq{
+		colNamespace:        r.ResourceAndRelation.Namespace,
+		colObjectID:         r.ResourceAndRelation.ObjectId,
+		colRelation:         r.ResourceAndRelation.Relation,
+		colUsersetNamespace: r.Subject.Namespace,
+		colUsersetObjectID:  r.Subject.ObjectId,
+		colUsersetRelation:  r.Subject.Relation,
+	}
+}
+
 func (rwt spannerReadWriteTXN) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
 	mutations := make([]*spanner.Mutation, 0, len(newConfigs))
 	for _, newConfig := range newConfigs {
@@ -237,7 +338,7 @@ func (rwt spannerReadWriteTXN) WriteNamespaces(_ context.Context, newConfigs ...
 			tableNamespace,
 			[]string{colNamespaceName, colNamespaceConfig, colTimestamp},
 			[]interface{}{newConfig.Name, serialized, spanner.CommitTimestamp},
-		))
+		), schemaChangeMutation(colChangeOpTouch, schemaChangeKindNamespace, newConfig.Name, serialized))
 	}
 
 	return rwt.spannerRWT.BufferWrite(mutations)
@@ -253,6 +354,7 @@ func (rwt spannerReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...
 
 		err := rwt.spannerRWT.BufferWrite([]*spanner.Mutation{
 			spanner.Delete(tableNamespace, spanner.KeySetFromKeys(spanner.Key{nsName})),
+			schemaChangeMutation(colChangeOpDelete, schemaChangeKindNamespace, nsName, nil),
 		})
 		if err != nil {
 			return fmt.Errorf(errUnableToDeleteConfig, err)
@@ -262,4 +364,14 @@ func (rwt spannerReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...
 	return nil
 }
 
+// schemaChangeMutation records a write or delete of a namespace or caveat definition in the
+// schema changelog, from which it is read by Watch.
+func schemaChangeMutation(op int64, kind int64, name string, serialized []byte) *spanner.Mutation {
+	return spanner.Insert(
+		tableSchemaChangelog,
+		allSchemaChangelogCols,
+		[]interface{}{spanner.CommitTimestamp, uuid.NewString(), op, kind, name, serialized},
+	)
+}
+
 var _ datastore.ReadWriteTransaction = spannerReadWriteTXN{}
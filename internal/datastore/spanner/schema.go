@@ -20,6 +20,7 @@ const (
 	colTimestamp        = "timestamp"
 	colCaveatName       = "caveat_name"
 	colCaveatContext    = "caveat_context"
+	colExpiration       = "expiration"
 
 	tableChangelog            = "changelog"
 	colChangeUUID             = "uuid"
@@ -33,6 +34,15 @@ const (
 	colChangeUsersetRelation  = "userset_relation"
 	colChangeCaveatName       = "caveat_name"
 	colChangeCaveatContext    = "caveat_context"
+	colChangeExpiration       = "expiration"
+
+	tableSchemaChangelog      = "schema_changelog"
+	colSchemaChangeTS         = "timestamp"
+	colSchemaChangeUUID       = "uuid"
+	colSchemaChangeOp         = "operation"
+	colSchemaChangeKind       = "definition_kind"
+	colSchemaChangeName       = "name"
+	colSchemaChangeDefinition = "definition"
 
 	tableCaveat         = "caveat"
 	colName             = "name"
@@ -49,6 +59,9 @@ const (
 	colChangeOpCreate = 1
 	colChangeOpTouch  = 2
 	colChangeOpDelete = 3
+
+	schemaChangeKindNamespace = 1
+	schemaChangeKindCaveat    = 2
 )
 
 var allRelationshipCols = []string{
@@ -61,6 +74,7 @@ var allRelationshipCols = []string{
 	colTimestamp,
 	colCaveatName,
 	colCaveatContext,
+	colExpiration,
 }
 
 var allChangelogCols = []string{
@@ -75,6 +89,16 @@ var allChangelogCols = []string{
 	colChangeUsersetRelation,
 	colChangeCaveatName,
 	colChangeCaveatContext,
+	colChangeExpiration,
+}
+
+var allSchemaChangelogCols = []string{
+	colSchemaChangeTS,
+	colSchemaChangeUUID,
+	colSchemaChangeOp,
+	colSchemaChangeKind,
+	colSchemaChangeName,
+	colSchemaChangeDefinition,
 }
 
 // Both creates and touches are emitted as touched to match other datastores.
@@ -132,7 +132,7 @@ func (sd spannerDatastore) SnapshotReader(revisionRaw datastore.Revision) datast
 		UsersetBatchSize: usersetBatchsize,
 	}
 
-	return spannerReader{querySplitter, txSource}
+	return spannerReader{querySplitter, txSource, unexpiredAt(timestampFromRevision(revision))}
 }
 
 func (sd spannerDatastore) ReadWriteTx(
@@ -149,7 +149,7 @@ func (sd spannerDatastore) ReadWriteTx(
 			UsersetBatchSize: usersetBatchsize,
 		}
 		rwt := spannerReadWriteTXN{
-			spannerReader{querySplitter, txSource},
+			spannerReader{querySplitter, txSource, unexpiredNow},
 			spannerRWT,
 			sd.config.disableStats,
 		}
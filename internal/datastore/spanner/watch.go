@@ -3,6 +3,7 @@ package spanner
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"cloud.google.com/go/spanner"
@@ -18,7 +19,10 @@ const (
 	watchSleep = 100 * time.Millisecond
 )
 
-var queryChanged = sql.Select(allChangelogCols...).From(tableChangelog)
+var (
+	queryChanged       = sql.Select(allChangelogCols...).From(tableChangelog)
+	querySchemaChanged = sql.Select(allSchemaChangelogCols...).From(tableSchemaChangelog)
+)
 
 func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
 	afterRevision := afterRevisionRaw.(revision.Decimal)
@@ -84,7 +88,12 @@ func (sd spannerDatastore) loadChanges(
 		return nil, afterTimestamp, err
 	}
 
-	rows := sd.client.Single().Query(ctx, statementFromSQL(sql, args))
+	// Both changelogs must be read at the same timestamp, or changes committed between the reads
+	// could be skipped.
+	txn := sd.client.ReadOnlyTransaction()
+	defer txn.Close()
+
+	rows := txn.Query(ctx, statementFromSQL(sql, args))
 	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
 
 	newTimestamp := afterTimestamp
@@ -99,6 +108,7 @@ func (sd spannerDatastore) loadChanges(
 		var colChangeUUID string
 		var caveatName spanner.NullString
 		var caveatCtx spanner.NullJSON
+		var expiration spanner.NullTime
 		err := r.Columns(
 			&timestamp,
 			&colChangeUUID,
@@ -111,6 +121,7 @@ func (sd spannerDatastore) loadChanges(
 			&tpl.Subject.Relation,
 			&caveatName,
 			&caveatCtx,
+			&expiration,
 		)
 		if err != nil {
 			return err
@@ -119,6 +130,7 @@ func (sd spannerDatastore) loadChanges(
 		if err != nil {
 			return err
 		}
+		tpl.OptionalExpirationTime = expirationFrom(expiration)
 
 		newTimestamp = maxTime(newTimestamp, timestamp)
 
@@ -130,6 +142,55 @@ func (sd spannerDatastore) loadChanges(
 		return nil, afterTimestamp, err
 	}
 
+	sql, args, err = querySchemaChanged.Where(sq.Gt{colSchemaChangeTS: afterTimestamp}).ToSql()
+	if err != nil {
+		return nil, afterTimestamp, err
+	}
+
+	err = txn.Query(ctx, statementFromSQL(sql, args)).Do(func(r *spanner.Row) error {
+		var timestamp time.Time
+		var changeUUID string
+		var op, kind int64
+		var name string
+		var serialized []byte
+		if err := r.Columns(&timestamp, &changeUUID, &op, &kind, &name, &serialized); err != nil {
+			return err
+		}
+
+		newTimestamp = maxTime(newTimestamp, timestamp)
+		rev := revisionFromTimestamp(timestamp)
+
+		switch {
+		case kind == schemaChangeKindNamespace && op == colChangeOpDelete:
+			stagedChanges.AddDeletedNamespace(ctx, rev, name)
+
+		case kind == schemaChangeKindNamespace:
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed namespace: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, rev, loaded)
+
+		case kind == schemaChangeKindCaveat && op == colChangeOpDelete:
+			stagedChanges.AddDeletedCaveat(ctx, rev, name)
+
+		case kind == schemaChangeKindCaveat:
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, rev, loaded)
+
+		default:
+			return fmt.Errorf("unknown schema change kind: %d", kind)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, afterTimestamp, err
+	}
+
 	changes := stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
 
 	return changes, newTimestamp, nil
@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/shopspring/decimal"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const (
+	errDeleteCaveat = "unable to delete caveats: %w"
+	errReadCaveat   = "unable to read caveat: %w"
+	errListCaveats  = "unable to list caveats: %w"
+	errWriteCaveats = "unable to write caveats: %w"
+)
+
+var (
+	writeCaveat  = sb.Insert(tableCaveat).Columns(colName, colCaveatDefinition, colCreatedTxn)
+	listCaveat   = sb.Select(colCaveatDefinition, colCreatedTxn).From(tableCaveat).OrderBy(colName)
+	readCaveat   = sb.Select(colCaveatDefinition, colCreatedTxn).From(tableCaveat)
+	deleteCaveat = sb.Update(tableCaveat).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+)
+
+func (sr *sqliteReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	sqlStatement, args, err := sr.filterer(readCaveat).Where(sq.Eq{colName: name}).ToSql()
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	tx, txCleanup, err := sr.txSource(ctx)
+	if err != nil {
+		return nil, datastore.NoRevision, fmt.Errorf(errReadCaveat, err)
+	}
+	defer common.LogOnError(ctx, txCleanup)
+
+	var serializedDef []byte
+	var rev decimal.Decimal
+	err = tx.QueryRowContext(ctx, sqlStatement, args...).Scan(&serializedDef, &rev)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, datastore.NoRevision, datastore.NewCaveatNameNotFoundErr(name)
+		}
+		return nil, datastore.NoRevision, fmt.Errorf(errReadCaveat, err)
+	}
+
+	def := core.CaveatDefinition{}
+	if err := def.UnmarshalVT(serializedDef); err != nil {
+		return nil, datastore.NoRevision, fmt.Errorf(errReadCaveat, err)
+	}
+	return &def, revision.NewFromDecimal(rev), nil
+}
+
+func (sr *sqliteReader) LookupCaveatsWithNames(ctx context.Context, caveatNames []string) ([]datastore.RevisionedCaveat, error) {
+	if len(caveatNames) == 0 {
+		return nil, nil
+	}
+	return sr.lookupCaveats(ctx, caveatNames)
+}
+
+func (sr *sqliteReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
+	return sr.lookupCaveats(ctx, nil)
+}
+
+func (sr *sqliteReader) lookupCaveats(ctx context.Context, caveatNames []string) ([]datastore.RevisionedCaveat, error) {
+	caveatsWithNames := listCaveat
+	if len(caveatNames) > 0 {
+		caveatsWithNames = caveatsWithNames.Where(sq.Eq{colName: caveatNames})
+	}
+
+	listSQL, listArgs, err := sr.filterer(caveatsWithNames).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, txCleanup, err := sr.txSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(errListCaveats, err)
+	}
+	defer common.LogOnError(ctx, txCleanup)
+
+	rows, err := tx.QueryContext(ctx, listSQL, listArgs...)
+	if err != nil {
+		return nil, fmt.Errorf(errListCaveats, err)
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	var caveats []datastore.RevisionedCaveat
+	for rows.Next() {
+		var defBytes []byte
+		var version decimal.Decimal
+		if err := rows.Scan(&defBytes, &version); err != nil {
+			return nil, fmt.Errorf(errListCaveats, err)
+		}
+
+		c := core.CaveatDefinition{}
+		if err := c.UnmarshalVT(defBytes); err != nil {
+			return nil, fmt.Errorf(errListCaveats, err)
+		}
+		caveats = append(caveats, datastore.RevisionedCaveat{
+			Definition:          &c,
+			LastWrittenRevision: revision.NewFromDecimal(version),
+		})
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf(errListCaveats, rows.Err())
+	}
+
+	return caveats, nil
+}
+
+func (rwt *sqliteReadWriteTXN) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	if len(caveats) == 0 {
+		return nil
+	}
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	writeQuery := writeCaveat
+
+	caveatNamesToWrite := make([]string, 0, len(caveats))
+	for _, newCaveat := range caveats {
+		serialized, err := newCaveat.MarshalVT()
+		if err != nil {
+			return fmt.Errorf(errWriteCaveats, err)
+		}
+
+		writeQuery = writeQuery.Values(newCaveat.Name, serialized, rwt.newTxnID)
+		caveatNamesToWrite = append(caveatNamesToWrite, newCaveat.Name)
+	}
+
+	if err := rwt.deleteCaveatsFromNames(ctx, caveatNamesToWrite); err != nil {
+		return fmt.Errorf(errWriteCaveats, err)
+	}
+
+	querySQL, writeArgs, err := writeQuery.ToSql()
+	if err != nil {
+		return fmt.Errorf(errWriteCaveats, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, querySQL, writeArgs...); err != nil {
+		return fmt.Errorf(errWriteCaveats, err)
+	}
+
+	return nil
+}
+
+func (rwt *sqliteReadWriteTXN) DeleteCaveats(ctx context.Context, names []string) error {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	return rwt.deleteCaveatsFromNames(ctx, names)
+}
+
+func (rwt *sqliteReadWriteTXN) deleteCaveatsFromNames(ctx context.Context, names []string) error {
+	delSQL, delArgs, err := deleteCaveat.
+		Set(colDeletedTxn, rwt.newTxnID).
+		Where(sq.Eq{colName: names}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf(errDeleteCaveat, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, delSQL, delArgs...); err != nil {
+		return fmt.Errorf(errDeleteCaveat, err)
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/dlmiddlecote/sqlstats"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/common/revisions"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	"github.com/authzed/spicedb/internal/datastore/sqlite/migrations"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const (
+	Engine = "sqlite"
+
+	tableNamespace   = "namespace_config"
+	tableTransaction = "relation_tuple_transaction"
+	tableTuple       = "relation_tuple"
+	tableCaveat      = "caveat"
+	tableMetadata    = "metadata"
+
+	colID               = "id"
+	colTimestamp        = "timestamp"
+	colNamespace        = "namespace"
+	colConfig           = "serialized_config"
+	colCreatedTxn       = "created_transaction"
+	colDeletedTxn       = "deleted_transaction"
+	colObjectID         = "object_id"
+	colRelation         = "relation"
+	colUsersetNamespace = "userset_namespace"
+	colUsersetObjectID  = "userset_object_id"
+	colUsersetRelation  = "userset_relation"
+	colName             = "name"
+	colCaveatDefinition = "definition"
+	colCaveatName       = "caveat_name"
+	colCaveatContext    = "caveat_context"
+	colExpiration       = "expiration"
+	colUniqueID         = "unique_id"
+
+	errUnableToInstantiate = "unable to instantiate datastore: %w"
+	liveDeletedTxnID       = uint64(math.MaxInt64)
+	batchDeleteSize        = 1000
+)
+
+var (
+	tracer = otel.Tracer("spicedb/internal/datastore/sqlite")
+
+	sb = sq.StatementBuilder.PlaceholderFormat(sq.Question)
+)
+
+func init() {
+	datastore.Engines = append(datastore.Engines, Engine)
+}
+
+// NewSQLiteDatastore creates a new sqlite.Datastore value backed by the SQLite database file at
+// the path or `file:` URI given, which must have been migrated with `spicedb migrate`. Supports
+// customization via the various options available in this package.
+//
+// The datastore requires SpiceDB to be built with cgo.
+func NewSQLiteDatastore(uri string, options ...Option) (datastore.Datastore, error) {
+	ds, err := newSQLiteDatastore(uri, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return proxy.NewSeparatingContextDatastoreProxy(ds), nil
+}
+
+func newSQLiteDatastore(uri string, options ...Option) (*Datastore, error) {
+	config, err := generateConfig(options)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	if uri == "" {
+		return nil, fmt.Errorf(errUnableToInstantiate, errors.New("the path to the database file must be given as the connection URI"))
+	}
+
+	writeDB, err := sql.Open(migrations.DriverName, migrations.ConnectionString(uri, true))
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	readDB, err := sql.Open(migrations.DriverName, migrations.ConnectionString(uri, false))
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	writeDB.SetMaxOpenConns(config.maxOpenConns)
+	writeDB.SetMaxIdleConns(config.maxOpenConns)
+	readDB.SetMaxOpenConns(config.maxOpenConns)
+	readDB.SetMaxIdleConns(config.maxOpenConns)
+
+	if config.enablePrometheusStats {
+		if err := prometheus.Register(sqlstats.NewStatsCollector("spicedb_write", writeDB)); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+		if err := prometheus.Register(sqlstats.NewStatsCollector("spicedb_read", readDB)); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+		if err := common.RegisterGCMetrics(); err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+	}
+
+	gcCtx, cancelGc := context.WithCancel(context.Background())
+
+	maxRevisionStaleness := time.Duration(float64(config.revisionQuantization.Nanoseconds())*
+		config.maxRevisionStalenessPercent) * time.Nanosecond
+
+	store := &Datastore{
+		readDB:               readDB,
+		writeDB:              writeDB,
+		revisionQuantization: config.revisionQuantization,
+		gcWindow:             config.gcWindow,
+		gcInterval:           config.gcInterval,
+		gcTimeout:            config.gcMaxOperationTime,
+		gcCtx:                gcCtx,
+		cancelGc:             cancelGc,
+		watchBufferLength:    config.watchBufferLength,
+		usersetBatchSize:     config.splitAtUsersetCount,
+		maxRetries:           config.maxRetries,
+		CachedOptimizedRevisions: revisions.NewCachedOptimizedRevisions(
+			maxRevisionStaleness,
+		),
+	}
+
+	store.SetOptimizedRevisionFunc(store.optimizedRevisionFunc)
+
+	// Start a goroutine for garbage collection.
+	if store.gcInterval > 0*time.Minute && config.gcEnabled {
+		store.gcGroup, store.gcCtx = errgroup.WithContext(store.gcCtx)
+		store.gcGroup.Go(func() error {
+			return common.StartGarbageCollector(
+				store.gcCtx,
+				store,
+				store.gcInterval,
+				store.gcWindow,
+				store.gcTimeout,
+			)
+		})
+	} else {
+		log.Warn().Msg("datastore background garbage collection disabled")
+	}
+
+	return store, nil
+}
+
+func (sds *Datastore) SnapshotReader(revisionRaw datastore.Revision) datastore.Reader {
+	rev := revisionRaw.(revision.Decimal)
+
+	createTxFunc := func(ctx context.Context) (*sql.Tx, txCleanupFunc, error) {
+		tx, err := sds.readDB.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return tx, tx.Rollback, nil
+	}
+
+	querySplitter := common.TupleQuerySplitter{
+		Executor:         newSQLiteExecutor(sds.readDB),
+		UsersetBatchSize: sds.usersetBatchSize,
+	}
+
+	return &sqliteReader{
+		createTxFunc,
+		querySplitter,
+		buildLivingObjectFilterForRevision(rev),
+		unexpiredAtRevision(rev),
+	}
+}
+
+func noCleanup() error { return nil }
+
+// ReadWriteTx starts a read/write transaction, which will be committed if no error is
+// returned and rolled back if an error is returned.
+func (sds *Datastore) ReadWriteTx(
+	ctx context.Context,
+	fn datastore.TxUserFunc,
+) (datastore.Revision, error) {
+	var err error
+	for i := uint8(0); i <= sds.maxRetries; i++ {
+		if i > 0 {
+			after := retry.BackoffExponentialWithJitter(10*time.Millisecond, 0.5)(uint(i))
+			log.Ctx(ctx).Debug().Err(err).Dur("after", after).Msg("retrying busy database")
+			time.Sleep(after)
+		}
+
+		var rwt *sqliteReadWriteTXN
+		if err = migrations.BeginTxFunc(ctx, sds.writeDB, nil, func(tx *sql.Tx) error {
+			longLivedTx := func(context.Context) (*sql.Tx, txCleanupFunc, error) {
+				return tx, noCleanup, nil
+			}
+
+			querySplitter := common.TupleQuerySplitter{
+				Executor:         newSQLiteExecutor(tx),
+				UsersetBatchSize: sds.usersetBatchSize,
+			}
+
+			rwt = &sqliteReadWriteTXN{
+				sqliteReader: &sqliteReader{
+					longLivedTx,
+					querySplitter,
+					currentlyLivingObjects,
+					unexpiredTuple{},
+				},
+				tx: tx,
+			}
+
+			if err := fn(rwt); err != nil {
+				return err
+			}
+
+			// A transaction which did not write still gets a revision of its own.
+			return rwt.ensureTransaction(ctx)
+		}); err != nil {
+			if isErrorRetryable(err) {
+				continue
+			}
+
+			return datastore.NoRevision, err
+		}
+
+		return revisionFromTransaction(rwt.newTxnID), nil
+	}
+	return datastore.NoRevision, fmt.Errorf("max retries exceeded: %w", err)
+}
+
+// isErrorRetryable returns whether the error was caused by another transaction holding the
+// write lock of the database, either for longer than the busy timeout or when this transaction
+// had already read a snapshot the other transaction has since changed.
+func isErrorRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	// Errors carry extended result codes, of which the primary result code is the low byte.
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+type querier interface {
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}
+
+func newSQLiteExecutor(tx querier) common.ExecuteQueryFunc {
+	return func(ctx context.Context, sqlQuery string, args []interface{}) ([]*core.RelationTuple, error) {
+		span := trace.SpanFromContext(ctx)
+
+		rows, err := tx.QueryContext(ctx, sqlQuery, args...)
+		if err != nil {
+			return nil, fmt.Errorf(errUnableToQueryTuples, err)
+		}
+		defer common.LogOnError(ctx, rows.Close)
+
+		span.AddEvent("Query issued to database")
+
+		var tuples []*core.RelationTuple
+		for rows.Next() {
+			nextTuple := &core.RelationTuple{
+				ResourceAndRelation: &core.ObjectAndRelation{},
+				Subject:             &core.ObjectAndRelation{},
+			}
+
+			var caveatName string
+			var caveatContext caveatContextWrapper
+			var expiration sql.NullInt64
+			err := rows.Scan(
+				&nextTuple.ResourceAndRelation.Namespace,
+				&nextTuple.ResourceAndRelation.ObjectId,
+				&nextTuple.ResourceAndRelation.Relation,
+				&nextTuple.Subject.Namespace,
+				&nextTuple.Subject.ObjectId,
+				&nextTuple.Subject.Relation,
+				&caveatName,
+				&caveatContext,
+				&expiration,
+			)
+			if err != nil {
+				return nil, fmt.Errorf(errUnableToQueryTuples, err)
+			}
+
+			nextTuple.Caveat, err = common.ContextualizedCaveatFrom(caveatName, caveatContext)
+			if err != nil {
+				return nil, fmt.Errorf(errUnableToQueryTuples, err)
+			}
+			nextTuple.OptionalExpirationTime = common.ExpirationFrom(expirationFrom(expiration))
+
+			tuples = append(tuples, nextTuple)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf(errUnableToQueryTuples, err)
+		}
+		span.AddEvent("Tuples loaded", trace.WithAttributes(attribute.Int("tupleCount", len(tuples))))
+		return tuples, nil
+	}
+}
+
+// Datastore is a SQLite-based implementation of the datastore.Datastore interface
+type Datastore struct {
+	readDB  *sql.DB
+	writeDB *sql.DB
+
+	revisionQuantization time.Duration
+	gcWindow             time.Duration
+	gcInterval           time.Duration
+	gcTimeout            time.Duration
+	watchBufferLength    uint16
+	usersetBatchSize     uint16
+	maxRetries           uint8
+
+	gcGroup  *errgroup.Group
+	gcCtx    context.Context
+	cancelGc context.CancelFunc
+
+	*revisions.CachedOptimizedRevisions
+	revision.DecimalDecoder
+}
+
+// Close closes the data store.
+func (sds *Datastore) Close() error {
+	sds.cancelGc()
+	if sds.gcGroup != nil {
+		if err := sds.gcGroup.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+			log.Error().Err(err).Msg("error waiting for garbage collector to shutdown")
+		}
+	}
+	return errors.Join(sds.readDB.Close(), sds.writeDB.Close())
+}
+
+// ReadyState returns whether the datastore is ready to accept data. The database file is ready
+// once it has been migrated to the head migration.
+func (sds *Datastore) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
+	headMigration, err := migrations.DatabaseMigrations.HeadRevision()
+	if err != nil {
+		return datastore.ReadyState{}, fmt.Errorf("invalid head migration found for sqlite: %w", err)
+	}
+
+	if err := sds.readDB.PingContext(ctx); err != nil {
+		return datastore.ReadyState{}, err
+	}
+
+	version, err := migrations.NewSQLiteDriverFromDB(sds.readDB).Version(ctx)
+	if err != nil {
+		return datastore.ReadyState{}, err
+	}
+
+	if version == headMigration {
+		return datastore.ReadyState{IsReady: true}, nil
+	}
+
+	return datastore.ReadyState{
+		Message: fmt.Sprintf(
+			"datastore is not migrated: currently at revision `%s`, but requires `%s`. Please run `spicedb migrate`.",
+			version,
+			headMigration,
+		),
+		IsReady: false,
+	}, nil
+}
+
+func (sds *Datastore) Features(_ context.Context) (*datastore.Features, error) {
+	return &datastore.Features{Watch: datastore.Feature{Enabled: true}}, nil
+}
+
+func buildLivingObjectFilterForRevision(revision revision.Decimal) queryFilterer {
+	return func(original sq.SelectBuilder) sq.SelectBuilder {
+		return original.Where(sq.LtOrEq{colCreatedTxn: transactionFromRevision(revision)}).
+			Where(sq.Or{
+				sq.Eq{colDeletedTxn: liveDeletedTxnID},
+				sq.Gt{colDeletedTxn: transactionFromRevision(revision)},
+			})
+	}
+}
+
+func currentlyLivingObjects(original sq.SelectBuilder) sq.SelectBuilder {
+	return original.Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+}
+
+var _ datastore.Datastore = &Datastore{}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/sqlite/migrations"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/test"
+	"github.com/authzed/spicedb/pkg/migrate"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func migratedDatabase(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "spicedb.db")
+
+	driver, err := migrations.NewSQLiteDriver(path)
+	require.NoError(t, err)
+	defer driver.Close(context.Background())
+
+	require.NoError(t, migrations.DatabaseMigrations.Run(context.Background(), driver, migrate.Head, migrate.LiveRun))
+	return path
+}
+
+func newTestDatastore(t *testing.T, options ...Option) *Datastore {
+	ds, err := newSQLiteDatastore(migratedDatabase(t), options...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, ds.Close())
+	})
+	return ds
+}
+
+func TestSQLiteDatastore(t *testing.T) {
+	test.All(t, test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+		return newSQLiteDatastore(migratedDatabase(t),
+			RevisionQuantization(revisionQuantization),
+			GCWindow(gcWindow),
+			GCInterval(gcInterval),
+			WatchBufferLength(watchBufferLength),
+		)
+	}))
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	driver, err := migrations.NewSQLiteDriver(filepath.Join(t.TempDir(), "spicedb.db"))
+	req.NoError(err)
+	defer driver.Close(ctx)
+
+	version, err := driver.Version(ctx)
+	req.NoError(err)
+	req.Equal("", version)
+
+	req.NoError(migrations.DatabaseMigrations.Run(ctx, driver, migrate.Head, migrate.LiveRun))
+
+	version, err = driver.Version(ctx)
+	req.NoError(err)
+
+	headVersion, err := migrations.DatabaseMigrations.HeadRevision()
+	req.NoError(err)
+	req.Equal(headVersion, version)
+}
+
+func TestSQLiteDatastoreNotMigrated(t *testing.T) {
+	ds, err := NewSQLiteDatastore(filepath.Join(t.TempDir(), "spicedb.db"), GCEnabled(false))
+	require.NoError(t, err)
+	defer ds.Close()
+
+	state, err := ds.ReadyState(context.Background())
+	require.NoError(t, err)
+	require.False(t, state.IsReady)
+}
+
+func TestSQLiteGarbageCollection(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	ds := newTestDatastore(t,
+		RevisionQuantization(0),
+		GCWindow(1*time.Millisecond),
+		GCInterval(0),
+		GCEnabled(false),
+	)
+
+	testfixtures.StandardDatastoreWithData(ds, req)
+
+	tpl := tuple.MustParse("document:gcdoc#viewer@user:tom")
+	_, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_TOUCH, tpl)
+	req.NoError(err)
+
+	deletedAt, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_DELETE, tpl)
+	req.NoError(err)
+
+	// Write one more transaction so that the deletion is no longer the head.
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_TOUCH, tuple.MustParse("document:gcdoc#viewer@user:fred"))
+	req.NoError(err)
+
+	removed, err := ds.DeleteBeforeTx(ctx, deletedAt)
+	req.NoError(err)
+	req.Equal(int64(1), removed.Relationships)
+	req.Positive(removed.Transactions)
+
+	// The living relationships are untouched by the collection.
+	head, err := ds.HeadRevision(ctx)
+	req.NoError(err)
+	iter, err := ds.SnapshotReader(head).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:        "document",
+		OptionalResourceIds: []string{"gcdoc"},
+	})
+	req.NoError(err)
+	defer iter.Close()
+
+	var found []string
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found = append(found, tpl.Subject.ObjectId)
+	}
+	req.NoError(iter.Err())
+	req.Equal([]string{"fred"}, found)
+}
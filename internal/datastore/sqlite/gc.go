@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+var _ common.GarbageCollector = (*Datastore)(nil)
+
+// Now returns the time of the clock of the process, which the timestamps of the transactions
+// are taken from.
+func (sds *Datastore) Now(_ context.Context) (time.Time, error) {
+	return time.Now().UTC(), nil
+}
+
+func (sds *Datastore) TxIDBefore(ctx context.Context, before time.Time) (datastore.Revision, error) {
+	// Find the highest transaction ID before the GC window.
+	query, args, err := getLastRevision.Where(sq.Lt{colTimestamp: before.UnixNano()}).ToSql()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	var value sql.NullInt64
+	if err := sds.readDB.QueryRowContext(ctx, query, args...).Scan(&value); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	if !value.Valid {
+		log.Ctx(ctx).Debug().Time("before", before).Msg("no stale transactions found in the datastore")
+		return datastore.NoRevision, nil
+	}
+	return revisionFromTransaction(uint64(value.Int64)), nil
+}
+
+func (sds *Datastore) DeleteBeforeTx(
+	ctx context.Context,
+	txID datastore.Revision,
+) (removed common.DeletionCounts, err error) {
+	revisionTx := transactionFromRevision(txID.(revision.Decimal))
+
+	// Delete any relationship rows with deleted_transaction <= the transaction ID.
+	removed.Relationships, err = sds.batchDelete(ctx, tableTuple, sq.LtOrEq{colDeletedTxn: revisionTx})
+	if err != nil {
+		return
+	}
+
+	// Delete all transaction rows with ID < the transaction ID.
+	//
+	// We don't delete the transaction itself to ensure there is always at least
+	// one transaction present.
+	removed.Transactions, err = sds.batchDelete(ctx, tableTransaction, sq.Lt{colID: revisionTx})
+	if err != nil {
+		return
+	}
+
+	// Delete any namespace rows with deleted_transaction <= the transaction ID.
+	removed.Namespaces, err = sds.batchDelete(ctx, tableNamespace, sq.LtOrEq{colDeletedTxn: revisionTx})
+	if err != nil {
+		return
+	}
+
+	// Delete any caveat rows with deleted_transaction <= the transaction ID.
+	_, err = sds.batchDelete(ctx, tableCaveat, sq.LtOrEq{colDeletedTxn: revisionTx})
+	return
+}
+
+// DeleteExpiredRels removes relationships which expired before the given time.
+func (sds *Datastore) DeleteExpiredRels(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	return sds.batchDelete(ctx, tableTuple, sq.Lt{colExpiration: expiredBefore.UnixNano()})
+}
+
+// batchDelete deletes the rows of the table matching the filter in batches, each in its own
+// write transaction, so that writes are not blocked for the whole garbage collection.
+func (sds *Datastore) batchDelete(ctx context.Context, tableName string, filter sq.Sqlizer) (int64, error) {
+	// SQLite does not support DELETE ... LIMIT unless built with a specific option, so the
+	// rows of each batch are selected by a subquery.
+	batch := sb.Select("rowid").From(tableName).Where(filter).Limit(batchDeleteSize)
+	query, args, err := sb.Delete(tableName).Where(sq.Expr("rowid IN (?)", batch)).ToSql()
+	if err != nil {
+		return -1, err
+	}
+
+	var deletedCount int64
+	for {
+		cr, err := sds.writeDB.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deletedCount, err
+		}
+
+		rowsDeleted, err := cr.RowsAffected()
+		if err != nil {
+			return deletedCount, err
+		}
+		deletedCount += rowsDeleted
+		if rowsDeleted < batchDeleteSize {
+			break
+		}
+	}
+
+	return deletedCount, nil
+}
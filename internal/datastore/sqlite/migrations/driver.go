@@ -0,0 +1,144 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite" // register the sqlite database/sql driver
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+const (
+	// DriverName is the name of the database/sql driver for SQLite, which is implemented in pure
+	// Go so that SpiceDB can be built without cgo.
+	DriverName = "sqlite"
+
+	// writerParams configure the connections through which the datastore writes. Transactions
+	// only take the write lock of the database on their first write, so that they can read
+	// while another transaction is writing.
+	writerParams = "_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+
+	// readerParams configure the connections through which the datastore reads, which cannot
+	// write to the database.
+	readerParams = "_pragma=busy_timeout(5000)&_pragma=query_only(true)"
+
+	sqliteMissingTableErrorMessage = "no such table"
+)
+
+// ConnectionString returns the connection string to the database file at the path or
+// `file:` URI given, for the connection of the writer of the datastore or those of its readers.
+func ConnectionString(uri string, writer bool) string {
+	params := readerParams
+	if writer {
+		params = writerParams
+	}
+
+	if strings.Contains(uri, "?") {
+		return uri + "&" + params
+	}
+	return uri + "?" + params
+}
+
+// SQLiteDriver implements a schema migration facility for use in SpiceDB's SQLite datastore.
+type SQLiteDriver struct {
+	db *sql.DB
+}
+
+// NewSQLiteDriver creates a new driver with a connection to the database file at the path or
+// `file:` URI given, which is created if it does not exist.
+func NewSQLiteDriver(uri string) (*SQLiteDriver, error) {
+	if uri == "" {
+		return nil, errors.New("the path to the database file must be given as the connection URI")
+	}
+
+	db, err := sql.Open(DriverName, ConnectionString(uri, true))
+	if err != nil {
+		return nil, fmt.Errorf("unable to instantiate SQLiteDriver: %w", err)
+	}
+
+	// SQLite allows a single writer at a time.
+	db.SetMaxOpenConns(1)
+
+	return NewSQLiteDriverFromDB(db), nil
+}
+
+// NewSQLiteDriverFromDB creates a new migration driver with a connection pool specified upfront.
+func NewSQLiteDriverFromDB(db *sql.DB) *SQLiteDriver {
+	return &SQLiteDriver{db}
+}
+
+// Conn returns the underlying sql.DB instance for this driver
+func (sd *SQLiteDriver) Conn() *sql.DB {
+	return sd.db
+}
+
+func (sd *SQLiteDriver) RunTx(ctx context.Context, f migrate.TxMigrationFunc[*sql.Tx]) error {
+	return BeginTxFunc(ctx, sd.db, nil, func(tx *sql.Tx) error {
+		return f(ctx, tx)
+	})
+}
+
+// BeginTxFunc is a polyfill for database/sql which implements a closure style transaction lifecycle.
+// The underlying transaction is aborted if the supplied function returns an error.
+// The underlying transaction is committed if the supplied function returns nil.
+func BeginTxFunc(ctx context.Context, db *sql.DB, txOptions *sql.TxOptions, f func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, txOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := f(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// Version returns the version of the schema to which the connected database
+// has been migrated.
+func (sd *SQLiteDriver) Version(ctx context.Context) (string, error) {
+	var loaded string
+
+	if err := sd.db.QueryRowContext(ctx, "SELECT version_num FROM migration_version").Scan(&loaded); err != nil {
+		if strings.Contains(err.Error(), sqliteMissingTableErrorMessage) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to load migration revision: %w", err)
+	}
+
+	return loaded, nil
+}
+
+// WriteVersion stores the version of the schema to which the database has been migrated.
+func (sd *SQLiteDriver) WriteVersion(ctx context.Context, tx *sql.Tx, version, replaced string) error {
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE migration_version SET version_num=? WHERE version_num=?",
+		version,
+		replaced,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to update version row: %w", err)
+	}
+
+	updatedCount, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to update version row: %w", err)
+	}
+	if updatedCount != 1 {
+		return fmt.Errorf("writing version update affected %d rows, should be 1", updatedCount)
+	}
+
+	return nil
+}
+
+// Close disposes the driver.
+func (sd *SQLiteDriver) Close(_ context.Context) error {
+	return sd.db.Close()
+}
+
+var _ migrate.Driver[*sql.DB, *sql.Tx] = &SQLiteDriver{}
@@ -0,0 +1,12 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+var noNonatomicMigration migrate.MigrationFunc[*sql.DB]
+
+// DatabaseMigrations implements a migration manager for the SQLite Driver.
+var DatabaseMigrations = migrate.NewManager[*SQLiteDriver, *sql.DB, *sql.Tx]()
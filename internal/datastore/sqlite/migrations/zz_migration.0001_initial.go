@@ -0,0 +1,123 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createRelationTupleTransaction = `CREATE TABLE relation_tuple_transaction (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    timestamp INTEGER NOT NULL
+);`
+
+const createTransactionTimestampIndex = `CREATE INDEX ix_relation_tuple_transaction_by_timestamp
+    ON relation_tuple_transaction (timestamp);`
+
+const createNamespaceConfig = `CREATE TABLE namespace_config (
+    namespace TEXT NOT NULL,
+    serialized_config BLOB NOT NULL,
+    created_transaction INTEGER NOT NULL,
+    deleted_transaction INTEGER NOT NULL DEFAULT 9223372036854775807
+);`
+
+const createUniqueLivingNamespaceIndex = `CREATE UNIQUE INDEX uq_namespace_living
+    ON namespace_config (namespace) WHERE deleted_transaction = 9223372036854775807;`
+
+const createRelationTuple = `CREATE TABLE relation_tuple (
+    id INTEGER PRIMARY KEY,
+    namespace TEXT NOT NULL,
+    object_id TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    userset_namespace TEXT NOT NULL,
+    userset_object_id TEXT NOT NULL,
+    userset_relation TEXT NOT NULL,
+    caveat_name TEXT NOT NULL DEFAULT '',
+    caveat_context BLOB,
+    expiration INTEGER,
+    created_transaction INTEGER NOT NULL,
+    deleted_transaction INTEGER NOT NULL DEFAULT 9223372036854775807
+);`
+
+const createUniqueLivingRelationTupleIndex = `CREATE UNIQUE INDEX uq_relation_tuple_living
+    ON relation_tuple (namespace, object_id, relation, userset_namespace, userset_object_id, userset_relation)
+    WHERE deleted_transaction = 9223372036854775807;`
+
+const createRelationTupleByResourceIndex = `CREATE INDEX ix_relation_tuple_by_resource
+    ON relation_tuple (namespace, object_id, relation, userset_namespace, userset_object_id, userset_relation);`
+
+const createRelationTupleBySubjectIndex = `CREATE INDEX ix_relation_tuple_by_subject
+    ON relation_tuple (userset_object_id, userset_namespace, userset_relation, namespace, relation);`
+
+const createRelationTupleBySubjectRelationIndex = `CREATE INDEX ix_relation_tuple_by_subject_relation
+    ON relation_tuple (userset_namespace, userset_relation, namespace, relation);`
+
+const createRelationTupleByCreatedTransactionIndex = `CREATE INDEX ix_relation_tuple_by_created_transaction
+    ON relation_tuple (created_transaction);`
+
+const createRelationTupleByDeletedTransactionIndex = `CREATE INDEX ix_relation_tuple_by_deleted_transaction
+    ON relation_tuple (deleted_transaction);`
+
+const createRelationTupleByExpirationIndex = `CREATE INDEX ix_relation_tuple_by_expiration
+    ON relation_tuple (expiration);`
+
+const createCaveat = `CREATE TABLE caveat (
+    name TEXT NOT NULL,
+    definition BLOB NOT NULL,
+    created_transaction INTEGER NOT NULL,
+    deleted_transaction INTEGER NOT NULL DEFAULT 9223372036854775807
+);`
+
+const createUniqueLivingCaveatIndex = `CREATE UNIQUE INDEX uq_caveat_living
+    ON caveat (name) WHERE deleted_transaction = 9223372036854775807;`
+
+const createMetadata = `CREATE TABLE metadata (
+    id INTEGER PRIMARY KEY,
+    unique_id TEXT NOT NULL
+);`
+
+const insertFirstTransaction = "INSERT INTO relation_tuple_transaction (timestamp) VALUES (0);"
+
+const insertUniqueID = "INSERT INTO metadata (id, unique_id) VALUES (0, ?);"
+
+const createMigrationVersion = `CREATE TABLE migration_version (
+    version_num TEXT NOT NULL
+);`
+
+const insertEmptyVersion = `INSERT INTO migration_version (version_num) VALUES ('');`
+
+func init() {
+	if err := DatabaseMigrations.Register("initial", "", noNonatomicMigration, func(ctx context.Context, tx *sql.Tx) error {
+		statements := []string{
+			createRelationTupleTransaction,
+			createTransactionTimestampIndex,
+			createNamespaceConfig,
+			createUniqueLivingNamespaceIndex,
+			createRelationTuple,
+			createUniqueLivingRelationTupleIndex,
+			createRelationTupleByResourceIndex,
+			createRelationTupleBySubjectIndex,
+			createRelationTupleBySubjectRelationIndex,
+			createRelationTupleByCreatedTransactionIndex,
+			createRelationTupleByDeletedTransactionIndex,
+			createRelationTupleByExpirationIndex,
+			createCaveat,
+			createUniqueLivingCaveatIndex,
+			createMetadata,
+			insertFirstTransaction,
+			createMigrationVersion,
+			insertEmptyVersion,
+		}
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, insertUniqueID, uuid.NewString())
+		return err
+	}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -0,0 +1,277 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/shopspring/decimal"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+type txCleanupFunc func() error
+
+type txFactory func(context.Context) (*sql.Tx, txCleanupFunc, error)
+
+type sqliteReader struct {
+	txSource      txFactory
+	querySplitter common.TupleQuerySplitter
+	filterer      queryFilterer
+	unexpired     sq.Sqlizer
+}
+
+type queryFilterer func(original sq.SelectBuilder) sq.SelectBuilder
+
+const (
+	errUnableToReadConfig     = "unable to read namespace config: %w"
+	errUnableToListNamespaces = "unable to list namespaces: %w"
+	errUnableToQueryTuples    = "unable to query tuples: %w"
+)
+
+var (
+	queryTuples = sb.Select(
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+		colCaveatName,
+		colCaveatContext,
+		colExpiration,
+	).From(tableTuple)
+
+	schema = common.NewSchemaInformation(
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+		colCaveatName,
+		common.TupleComparison,
+	)
+
+	readNamespace = sb.Select(colConfig, colCreatedTxn).From(tableNamespace)
+)
+
+// unexpiredTuple matches the relationships which have not expired, for reads within a read-write
+// transaction. The database is embedded in the process, so expiration is compared against the
+// clock of the process when the query is built.
+type unexpiredTuple struct{}
+
+func (unexpiredTuple) ToSql() (string, []interface{}, error) {
+	return sq.Or{
+		sq.Eq{colExpiration: nil},
+		sq.Gt{colExpiration: time.Now().UnixNano()},
+	}.ToSql()
+}
+
+// unexpiredAtRevision matches the relationships which had not expired at the time of the revision,
+// which is the timestamp of the transaction the revision identifies.
+func unexpiredAtRevision(rev revision.Decimal) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{colExpiration: nil},
+		sq.Expr(
+			fmt.Sprintf("%s > (SELECT %s FROM %s WHERE %s = ?)", colExpiration, colTimestamp, tableTransaction, colID),
+			transactionFromRevision(rev),
+		),
+	}
+}
+
+// expiredTuple matches the relationships which have expired.
+type expiredTuple struct{}
+
+func (expiredTuple) ToSql() (string, []interface{}, error) {
+	return sq.LtOrEq{colExpiration: time.Now().UnixNano()}.ToSql()
+}
+
+// expirationFor returns the optional expiration time of the tuple, as stored in the database in
+// nanoseconds since the Unix epoch.
+func expirationFor(tpl *core.RelationTuple) *int64 {
+	expiration := common.ExpirationFor(tpl)
+	if expiration == nil {
+		return nil
+	}
+	nanos := expiration.UnixNano()
+	return &nanos
+}
+
+// expirationFrom converts an optional expiration time read from the database into a time.
+func expirationFrom(expiration sql.NullInt64) *time.Time {
+	if !expiration.Valid {
+		return nil
+	}
+	expiresAt := time.Unix(0, expiration.Int64).UTC()
+	return &expiresAt
+}
+
+func (sr *sqliteReader) QueryRelationships(
+	ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (iter datastore.RelationshipIterator, err error) {
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, sr.filterer(queryTuples.Where(sr.unexpired))).FilterWithRelationshipsFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.querySplitter.SplitAndExecuteQuery(ctx, qBuilder, opts...)
+}
+
+func (sr *sqliteReader) ReverseQueryRelationships(
+	ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (iter datastore.RelationshipIterator, err error) {
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, sr.filterer(queryTuples.Where(sr.unexpired))).
+		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
+	if err != nil {
+		return nil, err
+	}
+
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+
+	if queryOpts.ResRelation != nil {
+		qBuilder = qBuilder.
+			FilterToResourceType(queryOpts.ResRelation.Namespace).
+			FilterToRelation(queryOpts.ResRelation.Relation)
+	}
+
+	return sr.querySplitter.SplitAndExecuteQuery(
+		ctx,
+		qBuilder,
+		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
+	)
+}
+
+func (sr *sqliteReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	tx, txCleanup, err := sr.txSource(ctx)
+	if err != nil {
+		return nil, datastore.NoRevision, fmt.Errorf(errUnableToReadConfig, err)
+	}
+	defer common.LogOnError(ctx, txCleanup)
+
+	loaded, version, err := loadNamespace(ctx, nsName, tx, sr.filterer(readNamespace))
+	switch {
+	case errors.As(err, &datastore.ErrNamespaceNotFound{}):
+		return nil, datastore.NoRevision, err
+	case err == nil:
+		return loaded, version, nil
+	default:
+		return nil, datastore.NoRevision, fmt.Errorf(errUnableToReadConfig, err)
+	}
+}
+
+func loadNamespace(ctx context.Context, namespace string, tx *sql.Tx, baseQuery sq.SelectBuilder) (*core.NamespaceDefinition, datastore.Revision, error) {
+	ctx, span := tracer.Start(ctx, "loadNamespace")
+	defer span.End()
+
+	query, args, err := baseQuery.Where(sq.Eq{colNamespace: namespace}).ToSql()
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	var config []byte
+	var version decimal.Decimal
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&config, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = datastore.NewNamespaceNotFoundErr(namespace)
+		}
+		return nil, datastore.NoRevision, err
+	}
+
+	loaded := &core.NamespaceDefinition{}
+	if err := loaded.UnmarshalVT(config); err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	return loaded, revision.NewFromDecimal(version), nil
+}
+
+func (sr *sqliteReader) ListAllNamespaces(ctx context.Context) ([]datastore.RevisionedNamespace, error) {
+	tx, txCleanup, err := sr.txSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer common.LogOnError(ctx, txCleanup)
+
+	nsDefs, err := loadAllNamespaces(ctx, tx, sr.filterer(readNamespace))
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToListNamespaces, err)
+	}
+
+	return nsDefs, err
+}
+
+func (sr *sqliteReader) LookupNamespacesWithNames(ctx context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	if len(nsNames) == 0 {
+		return nil, nil
+	}
+
+	tx, txCleanup, err := sr.txSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer common.LogOnError(ctx, txCleanup)
+
+	query := sr.filterer(readNamespace.Where(sq.Eq{colNamespace: nsNames}))
+
+	nsDefs, err := loadAllNamespaces(ctx, tx, query)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToListNamespaces, err)
+	}
+
+	return nsDefs, err
+}
+
+func loadAllNamespaces(ctx context.Context, tx *sql.Tx, queryBuilder sq.SelectBuilder) ([]datastore.RevisionedNamespace, error) {
+	query, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var nsDefs []datastore.RevisionedNamespace
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	for rows.Next() {
+		var config []byte
+		var version decimal.Decimal
+		if err := rows.Scan(&config, &version); err != nil {
+			return nil, err
+		}
+
+		loaded := &core.NamespaceDefinition{}
+		if err := loaded.UnmarshalVT(config); err != nil {
+			return nil, fmt.Errorf(errUnableToReadConfig, err)
+		}
+
+		nsDefs = append(nsDefs, datastore.RevisionedNamespace{
+			Definition:          loaded,
+			LastWrittenRevision: revision.NewFromDecimal(version),
+		})
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return nsDefs, nil
+}
+
+var _ datastore.Reader = &sqliteReader{}
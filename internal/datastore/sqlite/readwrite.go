@@ -0,0 +1,391 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/stringz"
+	"google.golang.org/protobuf/proto"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const (
+	errUnableToWriteRelationships  = "unable to write relationships: %w"
+	errUnableToDeleteRelationships = "unable to delete relationships: %w"
+	errUnableToWriteConfig         = "unable to write namespace config: %w"
+	errUnableToDeleteConfig        = "unable to delete namespace config: %w"
+
+	// clauseBatchSize is the number of relationships matched by each statement marking them
+	// deleted, chosen to stay well below the maximum depth of SQLite expressions.
+	clauseBatchSize = 100
+
+	// bulkInsertRowsLimit is the number of relationships written by each INSERT statement,
+	// chosen to stay well below the maximum number of SQLite statement parameters.
+	bulkInsertRowsLimit = 1_000
+)
+
+var (
+	writeNamespace = sb.Insert(tableNamespace).Columns(
+		colNamespace,
+		colConfig,
+		colCreatedTxn,
+	)
+
+	deleteNamespace = sb.Update(tableNamespace).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+
+	deleteNamespaceTuples = sb.Update(tableTuple).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+
+	writeTuple = sb.Insert(tableTuple).Columns(
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+		colCaveatName,
+		colCaveatContext,
+		colExpiration,
+		colCreatedTxn,
+	)
+
+	deleteTuple = sb.Update(tableTuple).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+)
+
+type sqliteReadWriteTXN struct {
+	*sqliteReader
+
+	tx       *sql.Tx
+	newTxnID uint64
+}
+
+// ensureTransaction creates the transaction row for the writes of the read/write transaction,
+// if not yet created. The row is created on the first write rather than when the transaction
+// begins, because its insertion takes the write lock of the database.
+func (rwt *sqliteReadWriteTXN) ensureTransaction(ctx context.Context) error {
+	if rwt.newTxnID != 0 {
+		return nil
+	}
+
+	newTxnID, err := createNewTransaction(ctx, rwt.tx)
+	if err != nil {
+		return fmt.Errorf("unable to create new txn ID: %w", err)
+	}
+
+	rwt.newTxnID = newTxnID
+	return nil
+}
+
+// caveatContextWrapper is used to marshall maps into JSON stored in the database
+type caveatContextWrapper map[string]any
+
+func (cc *caveatContextWrapper) Scan(val any) error {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, &cc)
+	case string:
+		return json.Unmarshal([]byte(v), &cc)
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+func (cc *caveatContextWrapper) Value() (driver.Value, error) {
+	return json.Marshal(&cc)
+}
+
+// WriteRelationships takes a list of existing relationships that must exist, and a list of
+// tuple mutations and applies it to the datastore for the specified namespace.
+func (rwt *sqliteReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	var deleteClauses []sq.Sqlizer
+	var toWrite []*core.RelationTuple
+
+	for _, mut := range mutations {
+		tpl := mut.Tuple
+
+		switch mut.Operation {
+		case core.RelationTupleUpdate_TOUCH:
+			deleteClauses = append(deleteClauses, exactRelationshipClause(tpl))
+			toWrite = append(toWrite, tpl)
+		case core.RelationTupleUpdate_CREATE:
+			// An expired relationship which has not yet been garbage collected must not
+			// prevent the relationship from being created again.
+			deleteClauses = append(deleteClauses, sq.And{exactRelationshipClause(tpl), expiredTuple{}})
+			toWrite = append(toWrite, tpl)
+		case core.RelationTupleUpdate_DELETE:
+			deleteClauses = append(deleteClauses, exactRelationshipClause(tpl))
+		default:
+			return fmt.Errorf(errUnableToWriteRelationships, fmt.Errorf("unknown mutation operation: %s", mut.Operation))
+		}
+	}
+
+	for len(deleteClauses) > 0 {
+		batch := deleteClauses
+		if len(batch) > clauseBatchSize {
+			batch = batch[:clauseBatchSize]
+		}
+		deleteClauses = deleteClauses[len(batch):]
+
+		query, args, err := deleteTuple.
+			Where(sq.Or(batch)).
+			Set(colDeletedTxn, rwt.newTxnID).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+	}
+
+	for len(toWrite) > 0 {
+		batch := toWrite
+		if len(batch) > bulkInsertRowsLimit {
+			batch = batch[:bulkInsertRowsLimit]
+		}
+		toWrite = toWrite[len(batch):]
+
+		if err := rwt.insertTuples(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rwt *sqliteReadWriteTXN) insertTuples(ctx context.Context, tpls []*core.RelationTuple) error {
+	bulkWrite := writeTuple
+	for _, tpl := range tpls {
+		var caveatName string
+		var caveatContext caveatContextWrapper
+		if tpl.Caveat != nil {
+			caveatName = tpl.Caveat.CaveatName
+			caveatContext = tpl.Caveat.Context.AsMap()
+		}
+
+		bulkWrite = bulkWrite.Values(
+			tpl.ResourceAndRelation.Namespace,
+			tpl.ResourceAndRelation.ObjectId,
+			tpl.ResourceAndRelation.Relation,
+			tpl.Subject.Namespace,
+			tpl.Subject.ObjectId,
+			tpl.Subject.Relation,
+			caveatName,
+			&caveatContext,
+			expirationFor(tpl),
+			rwt.newTxnID,
+		)
+	}
+
+	query, args, err := bulkWrite.ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+		if cerr := convertToWriteConstraintError(err); cerr != nil {
+			return cerr
+		}
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
+	return nil
+}
+
+func (rwt *sqliteReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return 0, err
+	}
+
+	var numLoaded uint64
+	batch := make([]*core.RelationTuple, 0, bulkInsertRowsLimit)
+
+	tpl, err := iter.Next(ctx)
+	for tpl != nil && err == nil {
+		batch = batch[:0]
+		for ; tpl != nil && err == nil && len(batch) < bulkInsertRowsLimit; tpl, err = iter.Next(ctx) {
+			// Sources may reuse the relationship they return, so it is copied until the batch
+			// is inserted.
+			batch = append(batch, tpl.CloneVT())
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if err := rwt.insertTuples(ctx, batch); err != nil {
+			return 0, err
+		}
+		numLoaded += uint64(len(batch))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return numLoaded, nil
+}
+
+func (rwt *sqliteReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	// Add clauses for the ResourceFilter
+	query := deleteTuple.Where(sq.Eq{colNamespace: filter.ResourceType})
+	if filter.OptionalResourceId != "" {
+		query = query.Where(sq.Eq{colObjectID: filter.OptionalResourceId})
+	}
+	if filter.OptionalRelation != "" {
+		query = query.Where(sq.Eq{colRelation: filter.OptionalRelation})
+	}
+
+	// Add clauses for the SubjectFilter
+	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
+		query = query.Where(sq.Eq{colUsersetNamespace: subjectFilter.SubjectType})
+		if subjectFilter.OptionalSubjectId != "" {
+			query = query.Where(sq.Eq{colUsersetObjectID: subjectFilter.OptionalSubjectId})
+		}
+		if relationFilter := subjectFilter.OptionalRelation; relationFilter != nil {
+			query = query.Where(sq.Eq{colUsersetRelation: stringz.DefaultEmpty(relationFilter.Relation, datastore.Ellipsis)})
+		}
+	}
+
+	querySQL, args, err := query.Set(colDeletedTxn, rwt.newTxnID).ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, querySQL, args...); err != nil {
+		return fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	return nil
+}
+
+func (rwt *sqliteReadWriteTXN) WriteNamespaces(ctx context.Context, newNamespaces ...*core.NamespaceDefinition) error {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	writeQuery := writeNamespace
+	nsNames := make([]string, 0, len(newNamespaces))
+
+	for _, newNamespace := range newNamespaces {
+		serialized, err := proto.Marshal(newNamespace)
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteConfig, err)
+		}
+
+		nsNames = append(nsNames, newNamespace.Name)
+		writeQuery = writeQuery.Values(newNamespace.Name, serialized, rwt.newTxnID)
+	}
+
+	delSQL, delArgs, err := deleteNamespace.
+		Set(colDeletedTxn, rwt.newTxnID).
+		Where(sq.Eq{colNamespace: nsNames}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToWriteConfig, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, delSQL, delArgs...); err != nil {
+		return fmt.Errorf(errUnableToWriteConfig, err)
+	}
+
+	query, args, err := writeQuery.ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToWriteConfig, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf(errUnableToWriteConfig, err)
+	}
+
+	return nil
+}
+
+func (rwt *sqliteReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	if err := rwt.ensureTransaction(ctx); err != nil {
+		return err
+	}
+
+	// Check each namespace exists before deleting the namespaces and their relationships.
+	baseQuery := readNamespace.Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+	for _, nsName := range nsNames {
+		_, _, err := loadNamespace(ctx, nsName, rwt.tx, baseQuery)
+		switch {
+		case errors.As(err, &datastore.ErrNamespaceNotFound{}):
+			return err
+		case err == nil:
+			break
+		default:
+			return fmt.Errorf(errUnableToDeleteConfig, err)
+		}
+	}
+
+	delSQL, delArgs, err := deleteNamespace.
+		Set(colDeletedTxn, rwt.newTxnID).
+		Where(sq.Eq{colNamespace: nsNames}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToDeleteConfig, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, delSQL, delArgs...); err != nil {
+		return fmt.Errorf(errUnableToDeleteConfig, err)
+	}
+
+	deleteTupleSQL, deleteTupleArgs, err := deleteNamespaceTuples.
+		Set(colDeletedTxn, rwt.newTxnID).
+		Where(sq.Eq{colNamespace: nsNames}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf(errUnableToDeleteConfig, err)
+	}
+
+	if _, err := rwt.tx.ExecContext(ctx, deleteTupleSQL, deleteTupleArgs...); err != nil {
+		return fmt.Errorf(errUnableToDeleteConfig, err)
+	}
+
+	return nil
+}
+
+// convertToWriteConstraintError returns the error to report for a write which failed because
+// relationships it created already exist. SQLite does not report the conflicting values, so
+// the relationship is not identified.
+func convertToWriteConstraintError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return common.NewCreateRelationshipExistsError(nil)
+	}
+	return nil
+}
+
+func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
+	return sq.Eq{
+		colNamespace:        r.ResourceAndRelation.Namespace,
+		colObjectID:         r.ResourceAndRelation.ObjectId,
+		colRelation:         r.ResourceAndRelation.Relation,
+		colUsersetNamespace: r.Subject.Namespace,
+		colUsersetObjectID:  r.Subject.ObjectId,
+		colUsersetRelation:  r.Subject.Relation,
+	}
+}
+
+var _ datastore.ReadWriteTransaction = &sqliteReadWriteTXN{}
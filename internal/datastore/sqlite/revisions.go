@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+const (
+	errRevision      = "unable to find revision: %w"
+	errCheckRevision = "unable to check revision: %w"
+)
+
+var (
+	// querySelectRevision finds the first transaction at or after the start of the current
+	// quantization period, which is given as a parameter. If there are no transactions newer
+	// than the quantization period, it just picks the latest transaction.
+	querySelectRevision = fmt.Sprintf(`SELECT COALESCE((
+			SELECT MIN(%[1]s)
+			FROM   %[2]s
+			WHERE  %[3]s >= ?
+		), (
+			SELECT MAX(%[1]s)
+			FROM   %[2]s
+		));`,
+		colID,
+		tableTransaction,
+		colTimestamp,
+	)
+
+	// queryValidTransaction will return a single row with two values, one boolean
+	// for whether the specified transaction ID is newer than the garbage collection
+	// window, and one boolean for whether the transaction ID represents a transaction
+	// that will occur in the future.
+	// It treats the current head transaction as always valid even if it falls
+	// outside the GC window.
+	queryValidTransaction = fmt.Sprintf(`SELECT ? >= COALESCE((
+			SELECT MIN(%[1]s)
+			FROM   %[2]s
+			WHERE  %[3]s >= ?
+		), (
+			SELECT MAX(%[1]s)
+			FROM   %[2]s
+		)) AS fresh, ? > (
+			SELECT MAX(%[1]s)
+			FROM   %[2]s
+		) AS unknown;`,
+		colID,
+		tableTransaction,
+		colTimestamp,
+	)
+
+	getLastRevision = sb.Select("MAX(" + colID + ")").From(tableTransaction)
+
+	createTxn = fmt.Sprintf("INSERT INTO %s (%s) VALUES (?)", tableTransaction, colTimestamp)
+)
+
+// optimizedRevisionFunc quantizes revisions on the clock of the process, which is the clock
+// the timestamps of the transactions are taken from.
+func (sds *Datastore) optimizedRevisionFunc(ctx context.Context) (datastore.Revision, time.Duration, error) {
+	quantizationPeriodNanos := sds.revisionQuantization.Nanoseconds()
+	if quantizationPeriodNanos < 1 {
+		quantizationPeriodNanos = 1
+	}
+
+	now := time.Now().UnixNano()
+	periodStart := now - now%quantizationPeriodNanos
+	validForNanos := time.Duration(quantizationPeriodNanos - now%quantizationPeriodNanos)
+
+	var rev uint64
+	if err := sds.readDB.QueryRowContext(ctx, querySelectRevision, periodStart).Scan(&rev); err != nil {
+		return revision.NoRevision, 0, fmt.Errorf(errRevision, err)
+	}
+	return revisionFromTransaction(rev), validForNanos, nil
+}
+
+func (sds *Datastore) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	revision, err := sds.loadRevision(ctx)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	if revision == 0 {
+		return datastore.NoRevision, nil
+	}
+
+	return revisionFromTransaction(revision), nil
+}
+
+func (sds *Datastore) CheckRevision(ctx context.Context, revisionRaw datastore.Revision) error {
+	if revisionRaw == datastore.NoRevision {
+		return datastore.NewInvalidRevisionErr(revisionRaw, datastore.CouldNotDetermineRevision)
+	}
+
+	revision := revisionRaw.(revision.Decimal)
+	revisionTx := transactionFromRevision(revision)
+
+	freshEnough, unknown, err := sds.checkValidTransaction(ctx, revisionTx)
+	if err != nil {
+		return fmt.Errorf(errCheckRevision, err)
+	}
+
+	if !freshEnough {
+		return datastore.NewInvalidRevisionErr(revision, datastore.RevisionStale)
+	}
+	if unknown {
+		return datastore.NewInvalidRevisionErr(revision, datastore.CouldNotDetermineRevision)
+	}
+
+	return nil
+}
+
+func (sds *Datastore) loadRevision(ctx context.Context) (uint64, error) {
+	ctx, span := tracer.Start(ctx, "loadRevision")
+	defer span.End()
+
+	query, args, err := getLastRevision.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf(errRevision, err)
+	}
+
+	var revision sql.NullInt64
+	if err := sds.readDB.QueryRowContext(ctx, query, args...).Scan(&revision); err != nil {
+		return 0, fmt.Errorf(errRevision, err)
+	}
+
+	if !revision.Valid {
+		return 0, nil
+	}
+
+	return uint64(revision.Int64), nil
+}
+
+func (sds *Datastore) checkValidTransaction(ctx context.Context, revisionTx uint64) (bool, bool, error) {
+	ctx, span := tracer.Start(ctx, "checkValidTransaction")
+	defer span.End()
+
+	windowStart := time.Now().Add(-1 * sds.gcWindow).UnixNano()
+
+	var freshEnough, unknown sql.NullBool
+	err := sds.readDB.QueryRowContext(ctx, queryValidTransaction, revisionTx, windowStart, revisionTx).
+		Scan(&freshEnough, &unknown)
+	if err != nil {
+		return false, false, fmt.Errorf(errCheckRevision, err)
+	}
+
+	span.AddEvent("DB returned validTransaction checks")
+
+	return freshEnough.Bool, unknown.Bool, nil
+}
+
+func createNewTransaction(ctx context.Context, tx *sql.Tx) (newTxnID uint64, err error) {
+	ctx, span := tracer.Start(ctx, "createNewTransaction")
+	defer span.End()
+
+	result, err := tx.ExecContext(ctx, createTxn, time.Now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("createNewTransaction: %w", err)
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("createNewTransaction: failed to get last inserted id: %w", err)
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+func revisionFromTransaction(txID uint64) revision.Decimal {
+	return revision.NewFromDecimal(decimal.NewFromBigInt(new(big.Int).SetUint64(txID), 0))
+}
+
+func transactionFromRevision(revision revision.Decimal) uint64 {
+	return uint64(revision.IntPart())
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var (
+	queryUniqueID = sb.Select(colUniqueID).From(tableMetadata)
+
+	// SQLite keeps no estimate of the number of rows of a table, but counting the living
+	// relationships of an embedded database is cheap enough.
+	queryRelationshipCount = sb.Select("COUNT(*)").From(tableTuple).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
+)
+
+func (sds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+	tx, err := sds.readDB.BeginTx(ctx, nil)
+	if err != nil {
+		return datastore.Stats{}, err
+	}
+	defer common.LogOnError(ctx, tx.Rollback)
+
+	uniqueIDSQL, uniqueIDArgs, err := queryUniqueID.ToSql()
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to generate query sql: %w", err)
+	}
+
+	var uniqueID string
+	if err := tx.QueryRowContext(ctx, uniqueIDSQL, uniqueIDArgs...).Scan(&uniqueID); err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to query unique ID: %w", err)
+	}
+
+	countSQL, countArgs, err := queryRelationshipCount.ToSql()
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to generate query sql: %w", err)
+	}
+
+	var count uint64
+	if err := tx.QueryRowContext(ctx, countSQL, countArgs...).Scan(&count); err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to count relationships: %w", err)
+	}
+
+	nsDefs, err := loadAllNamespaces(ctx, tx, currentlyLivingObjects(readNamespace))
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to load namespaces: %w", err)
+	}
+
+	return datastore.Stats{
+		UniqueID:                   uniqueID,
+		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(nsDefs),
+		EstimatedRelationshipCount: count,
+	}, nil
+}
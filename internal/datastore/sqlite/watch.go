@@ -0,0 +1,273 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const (
+	watchSleep = 100 * time.Millisecond
+)
+
+var (
+	queryChanged = sb.Select(
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+		colCaveatName,
+		colCaveatContext,
+		colExpiration,
+		colCreatedTxn,
+		colDeletedTxn,
+	).From(tableTuple)
+
+	queryChangedNamespaces = sb.Select(
+		colNamespace,
+		colConfig,
+		colCreatedTxn,
+		colDeletedTxn,
+	).From(tableNamespace)
+
+	queryChangedCaveats = sb.Select(
+		colName,
+		colCaveatDefinition,
+		colCreatedTxn,
+		colDeletedTxn,
+	).From(tableCaveat)
+)
+
+// Watch notifies the caller about all changes to tuples, namespaces and caveats.
+//
+// All events following afterRevision will be sent to the caller.
+func (sds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+	afterRevision := afterRevisionRaw.(revision.Decimal)
+
+	updates := make(chan *datastore.RevisionChanges, sds.watchBufferLength)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(updates)
+		defer close(errs)
+
+		currentTxn := transactionFromRevision(afterRevision)
+
+		for {
+			var stagedUpdates []datastore.RevisionChanges
+			var err error
+			stagedUpdates, currentTxn, err = sds.loadChanges(ctx, currentTxn)
+			if err != nil {
+				if errors.Is(ctx.Err(), context.Canceled) {
+					errs <- datastore.NewWatchCanceledErr()
+				} else {
+					errs <- err
+				}
+				return
+			}
+
+			// Write the staged updates to the channel
+			for _, changeToWrite := range stagedUpdates {
+				changeToWrite := changeToWrite
+
+				select {
+				case updates <- &changeToWrite:
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
+			// If there were no changes, sleep a bit
+			if len(stagedUpdates) == 0 {
+				sleep := time.NewTimer(watchSleep)
+
+				select {
+				case <-sleep.C:
+					break
+				case <-ctx.Done():
+					errs <- datastore.NewWatchCanceledErr()
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, errs
+}
+
+func (sds *Datastore) loadChanges(
+	ctx context.Context,
+	afterRevision uint64,
+) (changes []datastore.RevisionChanges, newRevision uint64, err error) {
+	newRevision, err = sds.loadRevision(ctx)
+	if err != nil {
+		return
+	}
+
+	if newRevision == afterRevision {
+		return
+	}
+
+	inRange := sq.Or{
+		sq.And{
+			sq.Gt{colCreatedTxn: afterRevision},
+			sq.LtOrEq{colCreatedTxn: newRevision},
+		},
+		sq.And{
+			sq.Gt{colDeletedTxn: afterRevision},
+			sq.LtOrEq{colDeletedTxn: newRevision},
+		},
+	}
+
+	querySQL, args, err := queryChanged.Where(inRange).ToSql()
+	if err != nil {
+		return
+	}
+
+	rows, err := sds.readDB.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = datastore.NewWatchCanceledErr()
+		}
+		return
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
+
+	inWindow := func(txn uint64) bool {
+		return txn > afterRevision && txn <= newRevision
+	}
+
+	for rows.Next() {
+		nextTuple := &core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{},
+			Subject:             &core.ObjectAndRelation{},
+		}
+
+		var createdTxn uint64
+		var deletedTxn uint64
+		var caveatName string
+		var caveatContext caveatContextWrapper
+		var expiration sql.NullInt64
+		err = rows.Scan(
+			&nextTuple.ResourceAndRelation.Namespace,
+			&nextTuple.ResourceAndRelation.ObjectId,
+			&nextTuple.ResourceAndRelation.Relation,
+			&nextTuple.Subject.Namespace,
+			&nextTuple.Subject.ObjectId,
+			&nextTuple.Subject.Relation,
+			&caveatName,
+			&caveatContext,
+			&expiration,
+			&createdTxn,
+			&deletedTxn,
+		)
+		if err != nil {
+			return
+		}
+		nextTuple.Caveat, err = common.ContextualizedCaveatFrom(caveatName, caveatContext)
+		if err != nil {
+			return
+		}
+		nextTuple.OptionalExpirationTime = common.ExpirationFrom(expirationFrom(expiration))
+
+		if inWindow(createdTxn) {
+			stagedChanges.AddChange(ctx, revisionFromTransaction(createdTxn), nextTuple, core.RelationTupleUpdate_TOUCH)
+		}
+
+		if inWindow(deletedTxn) {
+			stagedChanges.AddChange(ctx, revisionFromTransaction(deletedTxn), nextTuple, core.RelationTupleUpdate_DELETE)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	err = sds.loadDefinitionChanges(ctx, queryChangedNamespaces.Where(inRange), func(name string, serialized []byte, createdTxn, deletedTxn uint64) error {
+		if inWindow(createdTxn) {
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed namespace: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+		if inWindow(deletedTxn) {
+			stagedChanges.AddDeletedNamespace(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	err = sds.loadDefinitionChanges(ctx, queryChangedCaveats.Where(inRange), func(name string, serialized []byte, createdTxn, deletedTxn uint64) error {
+		if inWindow(createdTxn) {
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(serialized); err != nil {
+				return fmt.Errorf("unable to parse changed caveat: %w", err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+		if inWindow(deletedTxn) {
+			stagedChanges.AddDeletedCaveat(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	changes = stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+
+	return
+}
+
+// loadDefinitionChanges runs the query for the namespace or caveat definitions changed within
+// a range of transactions, invoking the handler for each row found.
+func (sds *Datastore) loadDefinitionChanges(
+	ctx context.Context,
+	query sq.SelectBuilder,
+	handler func(name string, serialized []byte, createdTxn, deletedTxn uint64) error,
+) error {
+	querySQL, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := sds.readDB.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	for rows.Next() {
+		var name string
+		var serialized []byte
+		var createdTxn, deletedTxn uint64
+		if err := rows.Scan(&name, &serialized, &createdTxn, &deletedTxn); err != nil {
+			return err
+		}
+
+		if err := handler(name, serialized, createdTxn, deletedTxn); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -221,49 +221,91 @@ func (cd *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpand
 	return resp, err
 }
 
-// DispatchLookup implements dispatch.Lookup interface and does not do any caching yet.
-func (cd *Dispatcher) DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
+// cachedLookupResults are the cached results of a lookup, along with the maximum depth
+// required to compute any of them.
+type cachedLookupResults struct {
+	results       [][]byte
+	depthRequired uint32
+}
+
+// DispatchLookup implements dispatch.Lookup interface.
+func (cd *Dispatcher) DispatchLookup(req *v1.DispatchLookupRequest, stream dispatch.LookupStream) error {
 	cd.lookupTotalCounter.Inc()
 
-	requestKey, err := cd.keyHandler.LookupResourcesCacheKey(ctx, req)
+	requestKey, err := cd.keyHandler.LookupResourcesCacheKey(stream.Context(), req)
 	if err != nil {
-		return &v1.DispatchLookupResponse{Metadata: &v1.ResponseMeta{}}, err
+		return err
 	}
 
 	if cachedResultRaw, found := cd.c.Get(requestKey); found {
-		var response v1.DispatchLookupResponse
-		if err := response.UnmarshalVT(cachedResultRaw.([]byte)); err != nil {
-			return &v1.DispatchLookupResponse{Metadata: &v1.ResponseMeta{}}, err
-		}
+		cached := cachedResultRaw.(cachedLookupResults)
 
-		if req.Metadata.DepthRemaining >= response.Metadata.DepthRequired {
-			log.Ctx(ctx).Trace().Object("cachedLookup", req).Int("resultCount", len(response.ResolvedResources)).Send()
+		// The cached results can only be used if they were computed within the depth remaining
+		// for this request; otherwise the lookup must be recomputed so that the max depth error
+		// is raised.
+		if req.Metadata.DepthRemaining >= cached.depthRequired {
+			log.Ctx(stream.Context()).Trace().Object("cachedLookup", req).Int("resultCount", len(cached.results)).Send()
 			cd.lookupFromCacheCounter.Inc()
-			return &response, nil
+			for _, slice := range cached.results {
+				var response v1.DispatchLookupResourcesResponse
+				if err := response.UnmarshalVT(slice); err != nil {
+					return fmt.Errorf("could not publish cached lookup result: %w", err)
+				}
+				if err := stream.Publish(&response); err != nil {
+					// don't wrap error with additional context, as it may be a grpc status.Status.
+					return err
+				}
+			}
+
+			return nil
 		}
 	}
-	computed, err := cd.d.DispatchLookup(ctx, req)
 
-	// We only want to cache the result if there was no error.
-	if err == nil {
-		log.Ctx(ctx).Trace().Object("cachingLookup", req).Int("resultCount", len(computed.ResolvedResources)).Send()
+	var (
+		mu             sync.Mutex
+		toCacheResults [][]byte
+		depthRequired  uint32
+	)
+	wrapped := &dispatch.WrappedDispatchStream[*v1.DispatchLookupResourcesResponse]{
+		Stream: stream,
+		Ctx:    stream.Context(),
+		Processor: func(result *v1.DispatchLookupResourcesResponse) (*v1.DispatchLookupResourcesResponse, bool, error) {
+			adjustedResult := result.CloneVT()
+			adjustedResult.Metadata.CachedDispatchCount = adjustedResult.Metadata.DispatchCount
+			adjustedResult.Metadata.DispatchCount = 0
+			adjustedResult.Metadata.DebugInfo = nil
 
-		adjustedComputed := computed.CloneVT()
-		adjustedComputed.Metadata.CachedDispatchCount = adjustedComputed.Metadata.DispatchCount
-		adjustedComputed.Metadata.DispatchCount = 0
-		adjustedComputed.Metadata.DebugInfo = nil
+			adjustedBytes, err := adjustedResult.MarshalVT()
+			if err != nil {
+				return nil, false, err
+			}
 
-		adjustedBytes, err := adjustedComputed.MarshalVT()
-		if err != nil {
-			return &v1.DispatchLookupResponse{Metadata: &v1.ResponseMeta{}}, err
-		}
+			mu.Lock()
+			toCacheResults = append(toCacheResults, adjustedBytes)
+			if result.Metadata.DepthRequired > depthRequired {
+				depthRequired = result.Metadata.DepthRequired
+			}
+			mu.Unlock()
 
-		cd.c.Set(requestKey, adjustedBytes, sliceSize(adjustedBytes))
+			return result, true, nil
+		},
 	}
 
-	// Return both the computed and err in ALL cases: computed contains resolved
-	// metadata even if there was an error.
-	return computed, err
+	// We only want to cache the results if the stream completed without error, as the
+	// results of a failed or canceled stream are incomplete.
+	if err := cd.d.DispatchLookup(req, wrapped); err != nil {
+		return err
+	}
+
+	log.Ctx(stream.Context()).Trace().Object("cachingLookup", req).Int("resultCount", len(toCacheResults)).Send()
+
+	var size int64
+	for _, slice := range toCacheResults {
+		size += sliceSize(slice)
+	}
+
+	cd.c.Set(requestKey, cachedLookupResults{results: toCacheResults, depthRequired: depthRequired}, size)
+	return nil
 }
 
 // DispatchReachableResources implements dispatch.ReachableResources interface.
@@ -146,6 +146,77 @@ func TestMaxDepthCaching(t *testing.T) {
 	}
 }
 
+func TestMaxDepthLookupCaching(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script []checkRequest
+	}{
+		{"two requests, hit", []checkRequest{
+			{"document:doc1#read", "user:user1#...", decimal.Zero, 1, 50, true},
+			{"document:doc1#read", "user:user1#...", decimal.Zero, 1, 50, false},
+		}},
+		{"insufficient depth", []checkRequest{
+			{"document:doc1#read", "user:user1#...", decimal.Zero, 21, 50, true},
+			{"document:doc1#read", "user:user1#...", decimal.Zero, 21, 40, false},
+			{"document:doc1#read", "user:user1#...", decimal.Zero, 21, 20, true},
+		}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			delegate := delegateDispatchMock{&mock.Mock{}}
+
+			lookupRequest := func(step checkRequest) *v1.DispatchLookupRequest {
+				parsed := tuple.ParseONR(step.start)
+				return &v1.DispatchLookupRequest{
+					ObjectRelation: RR(parsed.Namespace, parsed.Relation),
+					Subject:        tuple.ParseSubjectONR(step.goal),
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     step.atRevision.String(),
+						DepthRemaining: step.depthRemaining,
+					},
+				}
+			}
+
+			for _, step := range tc.script {
+				if step.expectPassthrough {
+					delegate.On("DispatchLookup", lookupRequest(step)).Return([]*v1.DispatchLookupResourcesResponse{
+						{
+							ResolvedResource: &v1.ResolvedResource{
+								ResourceId:     tuple.ParseONR(step.start).ObjectId,
+								Permissionship: v1.ResolvedResource_HAS_PERMISSION,
+							},
+							Metadata: &v1.ResponseMeta{
+								DispatchCount: 1,
+								DepthRequired: step.depthRequired,
+							},
+						},
+					}, nil).Times(1)
+				}
+			}
+
+			dispatcher, err := NewCachingDispatcher(DispatchTestCache(t), false, "", nil)
+			dispatcher.SetDelegate(delegate)
+			require.NoError(err)
+			defer dispatcher.Close()
+
+			for _, step := range tc.script {
+				stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+				require.NoError(dispatcher.DispatchLookup(lookupRequest(step), stream))
+				require.Len(stream.Results(), 1)
+
+				// Let the cache converge, as above.
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			delegate.AssertExpectations(t)
+		})
+	}
+}
+
 type delegateDispatchMock struct {
 	*mock.Mock
 }
@@ -159,8 +230,14 @@ func (ddm delegateDispatchMock) DispatchExpand(_ context.Context, _ *v1.Dispatch
 	return &v1.DispatchExpandResponse{}, nil
 }
 
-func (ddm delegateDispatchMock) DispatchLookup(_ context.Context, _ *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
-	return &v1.DispatchLookupResponse{}, nil
+func (ddm delegateDispatchMock) DispatchLookup(req *v1.DispatchLookupRequest, stream dispatch.LookupStream) error {
+	args := ddm.Called(req)
+	for _, result := range args.Get(0).([]*v1.DispatchLookupResourcesResponse) {
+		if err := stream.Publish(result); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
 }
 
 func (ddm delegateDispatchMock) DispatchReachableResources(_ *v1.DispatchReachableResourcesRequest, _ dispatch.ReachableResourcesStream) error {
@@ -28,8 +28,8 @@ func (fd fakeDelegate) DispatchExpand(_ context.Context, _ *v1.DispatchExpandReq
 	return &v1.DispatchExpandResponse{}, spiceerrors.MustBugf(errMessage)
 }
 
-func (fd fakeDelegate) DispatchLookup(_ context.Context, _ *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
-	return &v1.DispatchLookupResponse{}, spiceerrors.MustBugf(errMessage)
+func (fd fakeDelegate) DispatchLookup(_ *v1.DispatchLookupRequest, _ dispatch.LookupStream) error {
+	return spiceerrors.MustBugf(errMessage)
 }
 
 func (fd fakeDelegate) DispatchReachableResources(_ *v1.DispatchReachableResourcesRequest, _ dispatch.ReachableResourcesStream) error {
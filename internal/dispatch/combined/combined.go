@@ -124,21 +124,7 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 
 	// If an upstream is specified, create a cluster dispatcher.
 	if opts.upstreamAddr != "" {
-		if opts.upstreamCAPath != "" {
-			// Ensure that the CA path exists.
-			if _, err := os.Stat(opts.upstreamCAPath); err != nil {
-				return nil, err
-			}
-			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithCustomCerts(opts.upstreamCAPath, grpcutil.VerifyCA))
-			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
-		} else {
-			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithInsecureBearerToken(opts.grpcPresharedKey))
-			opts.grpcDialOpts = append(opts.grpcDialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		}
-
-		opts.grpcDialOpts = append(opts.grpcDialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor("s2")))
-
-		conn, err := grpc.Dial(opts.upstreamAddr, opts.grpcDialOpts...)
+		conn, err := dialUpstream(opts)
 		if err != nil {
 			return nil, err
 		}
@@ -152,3 +138,37 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 
 	return cachingRedispatch, nil
 }
+
+// DialUpstream connects to the cluster dispatching upstream of the options, for the services
+// served by the dispatch servers alongside dispatching. It returns nil if no upstream is
+// specified.
+func DialUpstream(options ...Option) (*grpc.ClientConn, error) {
+	var opts optionState
+	for _, fn := range options {
+		fn(&opts)
+	}
+
+	if opts.upstreamAddr == "" {
+		return nil, nil
+	}
+	return dialUpstream(opts)
+}
+
+func dialUpstream(opts optionState) (*grpc.ClientConn, error) {
+	dialOpts := append([]grpc.DialOption{}, opts.grpcDialOpts...)
+	if opts.upstreamCAPath != "" {
+		// Ensure that the CA path exists.
+		if _, err := os.Stat(opts.upstreamCAPath); err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpcutil.WithCustomCerts(opts.upstreamCAPath, grpcutil.VerifyCA))
+		dialOpts = append(dialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
+	} else {
+		dialOpts = append(dialOpts, grpcutil.WithInsecureBearerToken(opts.grpcPresharedKey))
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor("s2")))
+
+	return grpc.Dial(opts.upstreamAddr, dialOpts...)
+}
@@ -267,33 +267,35 @@ func (ld *localDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchE
 }
 
 // DispatchLookup implements dispatch.Lookup interface
-func (ld *localDispatcher) DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
+func (ld *localDispatcher) DispatchLookup(
+	req *v1.DispatchLookupRequest,
+	stream dispatch.LookupStream,
+) error {
 	// TODO(jschorr): Since lookup is now calling reachable resources exclusively, we should
 	// probably move it out of the dispatcher and into computed
-	ctx, span := tracer.Start(ctx, "DispatchLookup", trace.WithAttributes(
+	ctx, span := tracer.Start(stream.Context(), "DispatchLookup", trace.WithAttributes(
 		attribute.String("start", tuple.StringRR(req.ObjectRelation)),
 		attribute.String("subject", tuple.StringONR(req.Subject)),
-		attribute.Int64("limit", int64(req.Limit)),
+		attribute.Int64("limit", int64(req.OptionalLimit)),
 	))
 	defer span.End()
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
+		return err
 	}
 
 	revision, err := ld.parseRevision(ctx, req.Metadata.AtRevision)
 	if err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
-	}
-
-	if req.Limit <= 0 {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata, ResolvedResources: []*v1.ResolvedResource{}}, nil
+		return err
 	}
 
-	return ld.lookupHandler.LookupViaReachability(ctx, graph.ValidatedLookupRequest{
-		DispatchLookupRequest: req,
-		Revision:              revision,
-	})
+	return ld.lookupHandler.LookupViaReachability(
+		graph.ValidatedLookupRequest{
+			DispatchLookupRequest: req,
+			Revision:              revision,
+		},
+		dispatch.StreamWithContext(ctx, stream),
+	)
 }
 
 // DispatchReachableResources implements dispatch.ReachableResources interface
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/goleak"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -111,52 +112,161 @@ func TestSimpleLookup(t *testing.T) {
 			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
 			require := require.New(t)
-			ctx, dispatch, revision := newLocalDispatcher(t)
-			defer dispatch.Close()
+			ctx, dispatcher, revision := newLocalDispatcher(t)
+			defer dispatcher.Close()
 
-			lookupResult, err := dispatch.DispatchLookup(ctx, &v1.DispatchLookupRequest{
+			stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+			err := dispatcher.DispatchLookup(&v1.DispatchLookupRequest{
 				ObjectRelation: tc.start,
 				Subject:        tc.target,
 				Metadata: &v1.ResolverMeta{
 					AtRevision:     revision.String(),
 					DepthRemaining: 50,
 				},
-				Limit: 10,
-			})
+			}, stream)
 
 			require.NoError(err)
-			require.ElementsMatch(tc.expectedResources, lookupResult.ResolvedResources, "Found: %v, Expected: %v", lookupResult.ResolvedResources, tc.expectedResources)
-			require.GreaterOrEqual(lookupResult.Metadata.DepthRequired, uint32(1))
-			require.LessOrEqual(int(lookupResult.Metadata.DispatchCount), tc.expectedDispatchCount, "Found dispatch count greater than expected")
-			require.Equal(0, int(lookupResult.Metadata.CachedDispatchCount))
-			require.Equal(tc.expectedDepthRequired, int(lookupResult.Metadata.DepthRequired), "Depth required mismatch")
+
+			foundResources, lookupMetadata := processResults(stream)
+			require.ElementsMatch(tc.expectedResources, foundResources, "Found: %v, Expected: %v", foundResources, tc.expectedResources)
+			require.GreaterOrEqual(lookupMetadata.DepthRequired, uint32(1))
+			require.LessOrEqual(int(lookupMetadata.DispatchCount), tc.expectedDispatchCount, "Found dispatch count greater than expected")
+			require.Equal(0, int(lookupMetadata.CachedDispatchCount))
+			require.Equal(tc.expectedDepthRequired, int(lookupMetadata.DepthRequired), "Depth required mismatch")
 
 			// We have to sleep a while to let the cache converge:
 			// https://github.com/outcaste-io/ristretto/blob/01b9f37dd0fd453225e042d6f3a27cd14f252cd0/cache_test.go#L17
 			time.Sleep(10 * time.Millisecond)
 
 			// Run again with the cache available.
-			lookupResult, err = dispatch.DispatchLookup(context.Background(), &v1.DispatchLookupRequest{
+			stream = dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+			err = dispatcher.DispatchLookup(&v1.DispatchLookupRequest{
 				ObjectRelation: tc.start,
 				Subject:        tc.target,
 				Metadata: &v1.ResolverMeta{
 					AtRevision:     revision.String(),
 					DepthRemaining: 50,
 				},
-				Limit: 10,
-			})
-			dispatch.Close()
+			}, stream)
+			dispatcher.Close()
 
 			require.NoError(err)
-			require.ElementsMatch(tc.expectedResources, lookupResult.ResolvedResources, "Found: %v, Expected: %v", lookupResult.ResolvedResources, tc.expectedResources)
-			require.GreaterOrEqual(lookupResult.Metadata.DepthRequired, uint32(1))
-			require.Equal(0, int(lookupResult.Metadata.DispatchCount))
-			require.LessOrEqual(int(lookupResult.Metadata.CachedDispatchCount), tc.expectedDispatchCount)
-			require.Equal(tc.expectedDepthRequired, int(lookupResult.Metadata.DepthRequired))
+
+			foundResources, lookupMetadata = processResults(stream)
+			require.ElementsMatch(tc.expectedResources, foundResources, "Found: %v, Expected: %v", foundResources, tc.expectedResources)
+			require.GreaterOrEqual(lookupMetadata.DepthRequired, uint32(1))
+			require.Equal(0, int(lookupMetadata.DispatchCount))
+			require.LessOrEqual(int(lookupMetadata.CachedDispatchCount), tc.expectedDispatchCount)
+			require.Equal(tc.expectedDepthRequired, int(lookupMetadata.DepthRequired))
 		})
 	}
 }
 
+func TestLookupWithLimitAndCursor(t *testing.T) {
+	defer goleak.VerifyNone(t, goleakIgnores...)
+
+	testCases := []struct {
+		start             *core.RelationReference
+		target            *core.ObjectAndRelation
+		expectedResources []string
+	}{
+		{
+			RR("document", "view"),
+			ONR("user", "unknown", "..."),
+			[]string{},
+		},
+		{
+			RR("document", "view"),
+			ONR("user", "legal", "..."),
+			[]string{"companyplan", "masterplan"},
+		},
+		{
+			RR("document", "view_and_edit"),
+			ONR("user", "multiroleguy", "..."),
+			[]string{"specialplan"},
+		},
+		{
+			RR("folder", "view"),
+			ONR("user", "owner", "..."),
+			[]string{"company", "strategy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		for _, limit := range []uint32{1, 2, 5} {
+			limit := limit
+			name := fmt.Sprintf(
+				"%s#%s->%s/limit-%d",
+				tc.start.Namespace,
+				tc.start.Relation,
+				tuple.StringONR(tc.target),
+				limit,
+			)
+
+			t.Run(name, func(t *testing.T) {
+				require := require.New(t)
+				ctx, dispatcher, revision := newLocalDispatcher(t)
+				defer dispatcher.Close()
+
+				found := map[string]struct{}{}
+				var cursor *v1.Cursor
+				for pageCount := 0; ; pageCount++ {
+					require.LessOrEqual(pageCount, len(tc.expectedResources)*2+1, "found too many pages")
+
+					stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+					err := dispatcher.DispatchLookup(&v1.DispatchLookupRequest{
+						ObjectRelation: tc.start,
+						Subject:        tc.target,
+						Metadata: &v1.ResolverMeta{
+							AtRevision:     revision.String(),
+							DepthRemaining: 50,
+						},
+						OptionalLimit:  limit,
+						OptionalCursor: cursor,
+					}, stream)
+					require.NoError(err)
+
+					resultCount := 0
+					for _, result := range stream.Results() {
+						if result.ResolvedResource == nil {
+							continue
+						}
+
+						require.NotNil(result.AfterResponseCursor)
+						found[result.ResolvedResource.ResourceId] = struct{}{}
+						cursor = result.AfterResponseCursor
+						resultCount++
+					}
+
+					require.LessOrEqual(resultCount, int(limit))
+					if resultCount < int(limit) {
+						break
+					}
+				}
+
+				foundIDs := make([]string, 0, len(found))
+				for resourceID := range found {
+					foundIDs = append(foundIDs, resourceID)
+				}
+				require.ElementsMatch(tc.expectedResources, foundIDs)
+			})
+		}
+	}
+}
+
+func processResults(stream *dispatch.CollectingDispatchStream[*v1.DispatchLookupResourcesResponse]) ([]*v1.ResolvedResource, *v1.ResponseMeta) {
+	foundResources := []*v1.ResolvedResource{}
+	responseMetadata := &v1.ResponseMeta{}
+	for _, result := range stream.Results() {
+		dispatch.AddResponseMetadata(responseMetadata, result.Metadata)
+		if result.ResolvedResource != nil {
+			foundResources = append(foundResources, result.ResolvedResource)
+		}
+	}
+	return foundResources, responseMetadata
+}
+
 func TestMaxDepthLookup(t *testing.T) {
 	require := require.New(t)
 
@@ -165,19 +275,19 @@ func TestMaxDepthLookup(t *testing.T) {
 
 	ds, revision := testfixtures.StandardDatastoreWithData(rawDS, require)
 
-	dispatch := NewLocalOnlyDispatcher(10)
+	dispatcher := NewLocalOnlyDispatcher(10)
 	ctx := datastoremw.ContextWithHandle(context.Background())
 	require.NoError(datastoremw.SetInContext(ctx, ds))
 
-	_, err = dispatch.DispatchLookup(ctx, &v1.DispatchLookupRequest{
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+	err = dispatcher.DispatchLookup(&v1.DispatchLookupRequest{
 		ObjectRelation: RR("document", "view"),
 		Subject:        ONR("user", "legal", "..."),
 		Metadata: &v1.ResolverMeta{
 			AtRevision:     revision.String(),
 			DepthRemaining: 0,
 		},
-		Limit: 10,
-	})
+	}, stream)
 
 	require.Error(err)
 }
@@ -18,6 +18,7 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
 )
 
 type reachableResource struct {
@@ -207,6 +208,98 @@ func TestMaxDepthreachableResources(t *testing.T) {
 	require.Error(err)
 }
 
+func TestReachableResourcesCursors(t *testing.T) {
+	testCases := []struct {
+		start  *core.RelationReference
+		target *core.ObjectAndRelation
+	}{
+		{
+			RR("document", "view"),
+			ONR("user", "legal", "..."),
+		},
+		{
+			RR("document", "view"),
+			ONR("user", "multiroleguy", "..."),
+		},
+		{
+			RR("document", "view_and_edit"),
+			ONR("user", "multiroleguy", "..."),
+		},
+		{
+			RR("folder", "view"),
+			ONR("user", "owner", "..."),
+		},
+		{
+			RR("document", "view"),
+			ONR("document", "masterplan", "view"),
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf(
+			"%s#%s->%s",
+			tc.start.Namespace,
+			tc.start.Relation,
+			tuple.StringONR(tc.target),
+		)
+
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			ctx, dispatcher, revision := newLocalDispatcher(t)
+			defer dispatcher.Close()
+
+			dispatchWithCursor := func(cursor *v1.Cursor) []*v1.DispatchReachableResourcesResponse {
+				stream := dispatch.NewCollectingDispatchStream[*v1.DispatchReachableResourcesResponse](ctx)
+				err := dispatcher.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+					ResourceRelation: tc.start,
+					SubjectRelation: &core.RelationReference{
+						Namespace: tc.target.Namespace,
+						Relation:  tc.target.Relation,
+					},
+					SubjectIds: []string{tc.target.ObjectId},
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     revision.String(),
+						DepthRemaining: 50,
+					},
+					OptionalCursor: cursor,
+				}, stream)
+				require.NoError(err)
+				return stream.Results()
+			}
+
+			// Collect the unordered results for comparison.
+			unordered := util.NewSet[string]()
+			for _, result := range dispatchWithCursor(nil) {
+				for _, found := range result.Resources {
+					unordered.Add(found.ResourceId)
+				}
+			}
+
+			// Ensure the ordered results contain the same resources, one per response.
+			ordered := dispatchWithCursor(&v1.Cursor{})
+			orderedIDs := util.NewSet[string]()
+			for _, result := range ordered {
+				require.Len(result.Resources, 1)
+				require.NotNil(result.AfterResponseCursor)
+				orderedIDs.Add(result.Resources[0].ResourceId)
+			}
+			require.ElementsMatch(unordered.AsSlice(), orderedIDs.AsSlice())
+
+			// Ensure that resuming after each result returns exactly the results which followed it.
+			for index, result := range ordered {
+				resumed := dispatchWithCursor(result.AfterResponseCursor)
+				require.Equal(len(ordered)-index-1, len(resumed))
+				for resumedIndex, resumedResult := range resumed {
+					require.Equal(ordered[index+resumedIndex+1].Resources[0].ResourceId, resumedResult.Resources[0].ResourceId)
+					require.Equal(ordered[index+resumedIndex+1].AfterResponseCursor.Sections, resumedResult.AfterResponseCursor.Sections)
+				}
+			}
+		})
+	}
+}
+
 type byONRAndPermission []reachableResource
 
 func (a byONRAndPermission) Len() int { return len(a) }
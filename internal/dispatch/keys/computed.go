@@ -64,6 +64,8 @@ func lookupRequestToKey(req *v1.DispatchLookupRequest, option dispatchCacheKeyHa
 		hashableRelationReference{req.ObjectRelation},
 		hashableOnr{req.Subject},
 		hashableContext{req.Context}, // NOTE: context is included here because lookup does a single dispatch
+		hashableLimit(req.OptionalLimit),
+		hashableCursor{req.OptionalCursor},
 	)
 }
 
@@ -80,6 +82,7 @@ func reachableResourcesRequestToKey(req *v1.DispatchReachableResourcesRequest, o
 		hashableRelationReference{req.ResourceRelation},
 		hashableRelationReference{req.SubjectRelation},
 		hashableIds(req.SubjectIds),
+		hashableCursor{req.OptionalCursor},
 	)
 }
 
@@ -112,13 +112,13 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with nil context",
@@ -126,14 +126,14 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
 					Context: nil,
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with empty context",
@@ -141,7 +141,7 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -151,7 +151,7 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with context",
@@ -159,7 +159,7 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -172,7 +172,7 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"8a9bd5bba3ba9cde9301",
+			"b7b9abd5edfee4ff03",
 		},
 		{
 			"lookup resources with different context",
@@ -180,7 +180,7 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -193,7 +193,7 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"f6db868dc194c19ade01",
+			"83e597a2cca8bde95c",
 		},
 		{
 			"lookup resources with escaped string",
@@ -201,7 +201,7 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -213,7 +213,7 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"f98bb6f7fce8eb9ecc01",
+			"c1bfeb8ac6aadcac5f",
 		},
 		{
 			"lookup resources with nested context",
@@ -221,7 +221,7 @@ func TestStableCacheKeys(t *testing.T) {
 				return lookupRequestToKey(&v1.DispatchLookupRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -237,7 +237,37 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"e0d8d0e099d68b96fa01",
+			"e3909c82bdbabfd06d",
+		},
+		{
+			"lookup resources without limit",
+			func() DispatchCacheKey {
+				return lookupRequestToKey(&v1.DispatchLookupRequest{
+					ObjectRelation: RR("document", "view"),
+					Subject:        ONR("user", "mariah", "..."),
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+				}, computeBothHashes)
+			},
+			"cdb3fddffcd8feace601",
+		},
+		{
+			"lookup resources with cursor",
+			func() DispatchCacheKey {
+				return lookupRequestToKey(&v1.DispatchLookupRequest{
+					ObjectRelation: RR("document", "view"),
+					Subject:        ONR("user", "mariah", "..."),
+					OptionalLimit:  10,
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+					OptionalCursor: &v1.Cursor{
+						Sections: []string{"1", "", "foo"},
+					},
+				}, computeBothHashes)
+			},
+			"f09ee3c09f84d7a604",
 		},
 		{
 			"reachable resources",
@@ -251,7 +281,39 @@ func TestStableCacheKeys(t *testing.T) {
 					},
 				}, computeBothHashes)
 			},
-			"e8848b9dd68f93a6c801",
+			"caf99d9fe4d68ab63f",
+		},
+		{
+			"reachable resources with empty cursor",
+			func() DispatchCacheKey {
+				return reachableResourcesRequestToKey(&v1.DispatchReachableResourcesRequest{
+					ResourceRelation: RR("document", "view"),
+					SubjectRelation:  RR("user", "..."),
+					SubjectIds:       []string{"mariah", "tom"},
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+					OptionalCursor: &v1.Cursor{},
+				}, computeBothHashes)
+			},
+			"afbfcdabb799f1e2c801",
+		},
+		{
+			"reachable resources with cursor",
+			func() DispatchCacheKey {
+				return reachableResourcesRequestToKey(&v1.DispatchReachableResourcesRequest{
+					ResourceRelation: RR("document", "view"),
+					SubjectRelation:  RR("user", "..."),
+					SubjectIds:       []string{"mariah", "tom"},
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+					OptionalCursor: &v1.Cursor{
+						Sections: []string{"1", "", "foo"},
+					},
+				}, computeBothHashes)
+			},
+			"80d4ddf5c4f8cd81ac01",
 		},
 		{
 			"lookup subjects",
@@ -511,7 +573,7 @@ func TestComputeContextHash(t *testing.T) {
 	result := lookupRequestToKey(&v1.DispatchLookupRequest{
 		ObjectRelation: RR("document", "view"),
 		Subject:        ONR("user", "mariah", "..."),
-		Limit:          10,
+		OptionalLimit:  10,
 		Metadata: &v1.ResolverMeta{
 			AtRevision: "1234",
 		},
@@ -531,5 +593,5 @@ func TestComputeContextHash(t *testing.T) {
 		}(),
 	}, computeBothHashes)
 
-	require.Equal(t, "82b4a3a3c5e3ecf1df01", hex.EncodeToString(result.StableSumAsBytes()))
+	require.Equal(t, "fffecbcab0f1fc9022", hex.EncodeToString(result.StableSumAsBytes()))
 }
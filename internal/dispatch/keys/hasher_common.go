@@ -64,6 +64,28 @@ func (hnr hashableOnr) AppendToHash(hasher hasherInterface) {
 	hasher.WriteString(hnr.Relation)
 }
 
+type hashableLimit uint32
+
+func (hl hashableLimit) AppendToHash(hasher hasherInterface) {
+	hasher.WriteString(strconv.FormatUint(uint64(hl), 10))
+}
+
+type hashableCursor struct{ *v1.Cursor }
+
+func (hc hashableCursor) AppendToHash(hasher hasherInterface) {
+	// NOTE: a nil cursor and an empty cursor produce differently ordered results, so the
+	// presence of the cursor is itself part of the hash.
+	if hc.Cursor == nil {
+		return
+	}
+
+	hasher.WriteString("cursor:")
+	for _, section := range hc.Sections {
+		hasher.WriteString(section)
+		hasher.WriteString(",")
+	}
+}
+
 type hashableString string
 
 func (hs hashableString) AppendToHash(hasher hasherInterface) {
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
@@ -23,6 +25,7 @@ type clusterClient interface {
 	DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest, opts ...grpc.CallOption) (*v1.DispatchLookupResponse, error)
 	DispatchReachableResources(ctx context.Context, in *v1.DispatchReachableResourcesRequest, opts ...grpc.CallOption) (v1.DispatchService_DispatchReachableResourcesClient, error)
 	DispatchLookupSubjects(ctx context.Context, in *v1.DispatchLookupSubjectsRequest, opts ...grpc.CallOption) (v1.DispatchService_DispatchLookupSubjectsClient, error)
+	DispatchLookupResources(ctx context.Context, in *v1.DispatchLookupRequest, opts ...grpc.CallOption) (v1.DispatchService_DispatchLookupResourcesClient, error)
 }
 
 type ClusterDispatcherConfig struct {
@@ -108,27 +111,78 @@ func (cr *clusterDispatcher) DispatchExpand(ctx context.Context, req *v1.Dispatc
 	return resp, nil
 }
 
-func (cr *clusterDispatcher) DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
+func (cr *clusterDispatcher) DispatchLookup(
+	req *v1.DispatchLookupRequest,
+	stream dispatch.LookupStream,
+) error {
+	requestKey, err := cr.keyHandler.LookupResourcesDispatchKey(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.WithValue(stream.Context(), balancer.CtxKey, requestKey)
+	stream = dispatch.StreamWithContext(ctx, stream)
+
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
+		return err
 	}
 
-	requestKey, err := cr.keyHandler.LookupResourcesDispatchKey(ctx, req)
+	withTimeout, cancelFn := context.WithTimeout(ctx, cr.dispatchOverallTimeout)
+	defer cancelFn()
+
+	client, err := cr.clusterClient.DispatchLookupResources(withTimeout, req)
 	if err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
+		return err
 	}
 
-	ctx = context.WithValue(ctx, balancer.CtxKey, requestKey)
+	published := false
+	for {
+		select {
+		case <-withTimeout.Done():
+			return withTimeout.Err()
 
-	withTimeout, cancelFn := context.WithTimeout(ctx, cr.dispatchOverallTimeout)
-	defer cancelFn()
+		default:
+			result, err := client.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if status.Code(err) == codes.Unimplemented && !published && req.OptionalCursor == nil {
+				return cr.dispatchUnaryLookup(withTimeout, req, stream)
+			} else if err != nil {
+				return err
+			}
 
-	resp, err := cr.clusterClient.DispatchLookup(withTimeout, req)
+			serr := stream.Publish(result)
+			if serr != nil {
+				return serr
+			}
+			published = true
+		}
+	}
+}
+
+// dispatchUnaryLookup dispatches the lookup to a node running a version without
+// DispatchLookupResources, publishing the resources of its single response to the stream.
+// Such a node cannot resume from a cursor, so no cursors are published.
+func (cr *clusterDispatcher) dispatchUnaryLookup(ctx context.Context, req *v1.DispatchLookupRequest, stream dispatch.LookupStream) error {
+	resp, err := cr.clusterClient.DispatchLookup(ctx, req)
 	if err != nil {
-		return &v1.DispatchLookupResponse{Metadata: requestFailureMetadata}, err
+		return err
 	}
 
-	return resp, nil
+	for index, resource := range resp.ResolvedResources {
+		metadata := &v1.ResponseMeta{DepthRequired: resp.Metadata.DepthRequired}
+		if index == 0 {
+			metadata = resp.Metadata
+		}
+
+		if err := stream.Publish(&v1.DispatchLookupResourcesResponse{
+			Metadata:         metadata,
+			ResolvedResource: resource,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (cr *clusterDispatcher) DispatchReachableResources(
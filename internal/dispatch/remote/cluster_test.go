@@ -123,3 +123,67 @@ func TestDispatchTimeout(t *testing.T) {
 		})
 	}
 }
+
+// legacyDispatchSvc is a dispatch service of a node running a version without
+// DispatchLookupResources.
+type legacyDispatchSvc struct {
+	v1.UnimplementedDispatchServiceServer
+}
+
+func (lds *legacyDispatchSvc) DispatchLookup(context.Context, *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
+	return &v1.DispatchLookupResponse{
+		Metadata: &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 2},
+		ResolvedResources: []*v1.ResolvedResource{
+			{ResourceId: "foo", Permissionship: v1.ResolvedResource_HAS_PERMISSION},
+			{ResourceId: "bar", Permissionship: v1.ResolvedResource_HAS_PERMISSION},
+		},
+	}, nil
+}
+
+func TestDispatchLookupToLegacyNode(t *testing.T) {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+	v1.RegisterDispatchServiceServer(s, &legacyDispatchSvc{})
+
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	dispatcher := NewClusterDispatcher(v1.NewDispatchServiceClient(conn), conn, ClusterDispatcherConfig{
+		KeyHandler: &keys.DirectKeyHandler{},
+	})
+
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+	err = dispatcher.DispatchLookup(&v1.DispatchLookupRequest{
+		ObjectRelation: &core.RelationReference{Namespace: "sometype", Relation: "somerel"},
+		Subject:        &core.ObjectAndRelation{Namespace: "foo", ObjectId: "bar", Relation: "..."},
+		Metadata:       &v1.ResolverMeta{DepthRemaining: 50},
+	}, stream)
+	require.NoError(t, err)
+
+	results := stream.Results()
+	require.Len(t, results, 2)
+	require.Equal(t, "foo", results[0].ResolvedResource.ResourceId)
+	require.Equal(t, "bar", results[1].ResolvedResource.ResourceId)
+	require.Equal(t, uint32(1), results[0].Metadata.DispatchCount)
+	require.Equal(t, uint32(0), results[1].Metadata.DispatchCount)
+	require.Equal(t, uint32(2), results[1].Metadata.DepthRequired)
+}
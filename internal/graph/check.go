@@ -450,7 +450,16 @@ func (cc *ConcurrentChecker) runSetOperation(ctx context.Context, crc currentReq
 	case *core.SetOperation_Child_UsersetRewrite:
 		return cc.checkUsersetRewrite(ctx, crc, child.UsersetRewrite)
 	case *core.SetOperation_Child_TupleToUserset:
-		return cc.checkTupleToUserset(ctx, crc, child.TupleToUserset)
+		return cc.checkTupleToUserset(ctx, crc, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset)
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		switch child.FunctionedTupleToUserset.Function {
+		case core.FunctionedTupleToUserset_FUNCTION_ANY:
+			return cc.checkTupleToUserset(ctx, crc, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset)
+		case core.FunctionedTupleToUserset_FUNCTION_ALL:
+			return cc.checkIntersectionTupleToUserset(ctx, crc, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset)
+		default:
+			return checkResultError(spiceerrors.MustBugf("unknown function %v for arrow in check", child.FunctionedTupleToUserset.Function), emptyMetadata)
+		}
 	case *core.SetOperation_Child_XNil:
 		return noMembers()
 	default:
@@ -539,32 +548,130 @@ func removeIndexFromSlice[T any](s []T, index int) []T {
 	return append(cpy, s[index+1:]...)
 }
 
-func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc currentRequestContext, ttu *core.TupleToUserset) CheckResult {
+func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc currentRequestContext, tuplesetRelation string, computedUserset *core.ComputedUserset) CheckResult {
 	log.Ctx(ctx).Trace().Object("ttu", crc.parentReq).Send()
+	subjectsToDispatch, relationshipsBySubjectONR, _, err := queryTuplesetRelationships(ctx, crc, tuplesetRelation)
+	if err != nil {
+		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+	}
+
+	return union(
+		ctx,
+		crc,
+		toDispatchChunks(crc, subjectsToDispatch),
+		func(ctx context.Context, crc currentRequestContext, dd directDispatch) CheckResult {
+			childResult := cc.checkComputedUserset(ctx, crc, computedUserset, dd.resourceType, dd.resourceIds)
+			if childResult.Err != nil {
+				return childResult
+			}
+
+			return mapFoundResources(childResult, dd.resourceType, relationshipsBySubjectONR)
+		},
+		cc.concurrencyLimit,
+	)
+}
+
+// checkIntersectionTupleToUserset checks an arrow with the `all` function: a resource is only a
+// member if the subject is found via *every* object related to it by the tupleset relation.
+func (cc *ConcurrentChecker) checkIntersectionTupleToUserset(ctx context.Context, crc currentRequestContext, tuplesetRelation string, computedUserset *core.ComputedUserset) CheckResult {
+	log.Ctx(ctx).Trace().Object("intersectionttu", crc.parentReq).Send()
+	subjectsToDispatch, _, relationshipsByResourceID, err := queryTuplesetRelationships(ctx, crc, tuplesetRelation)
+	if err != nil {
+		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+	}
+
+	// Every related object must be checked, so all results are required from the dispatches.
+	allResultsCrc := currentRequestContext{
+		parentReq:           crc.parentReq,
+		filteredResourceIDs: crc.filteredResourceIDs,
+		resultsSetting:      v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+		maxDispatchCount:    crc.maxDispatchCount,
+	}
+
+	// Collect the membership found for each of the related objects, by object type.
+	responseMetadata := emptyMetadata
+	membershipByType := map[string]*MembershipSet{}
+	for _, dd := range toDispatchChunks(crc, subjectsToDispatch) {
+		childResult := cc.checkComputedUserset(ctx, allResultsCrc, computedUserset, dd.resourceType, dd.resourceIds)
+		if childResult.Err != nil {
+			return checkResultError(childResult.Err, responseMetadata)
+		}
+		responseMetadata = combineResponseMetadata(responseMetadata, childResult.Resp.Metadata)
+
+		if _, ok := membershipByType[dd.resourceType.Namespace]; !ok {
+			membershipByType[dd.resourceType.Namespace] = NewMembershipSet()
+		}
+		membershipByType[dd.resourceType.Namespace].UnionWith(childResult.Resp.ResultsByResourceId)
+	}
+
+	// A resource is a member if all of its related objects are members, with the caveats of
+	// those objects and of the relationships to them combined.
+	membershipSet := NewMembershipSet()
+	for _, resourceID := range crc.filteredResourceIDs {
+		relationships, ok := relationshipsByResourceID.Get(resourceID)
+		if !ok {
+			continue
+		}
+
+		var caveatExpr *core.CaveatExpression
+		isMember := true
+		for _, relationship := range relationships {
+			typeMembership, ok := membershipByType[relationship.Subject.Namespace]
+			if !ok {
+				isMember = false
+				break
+			}
+
+			found, ok := typeMembership.membersByID[relationship.Subject.ObjectId]
+			if !ok {
+				isMember = false
+				break
+			}
+
+			caveatExpr = caveatAnd(caveatExpr, caveatAnd(wrapCaveat(relationship.Caveat), found))
+		}
+
+		if isMember {
+			membershipSet.addMember(resourceID, caveatExpr)
+		}
+	}
+
+	return checkResultsForMembership(membershipSet, responseMetadata)
+}
+
+// queryTuplesetRelationships returns the subjects of the relationships for the tupleset relation
+// on the resources being checked, along with those relationships by subject and by resource ID.
+func queryTuplesetRelationships(ctx context.Context, crc currentRequestContext, tuplesetRelation string) (*tuple.ONRByTypeSet, *util.MultiMap[string, *core.RelationTuple], *util.MultiMap[string, *core.RelationTuple], error) {
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(crc.parentReq.Revision)
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 		ResourceType:             crc.parentReq.ResourceRelation.Namespace,
 		OptionalResourceIds:      crc.filteredResourceIDs,
-		OptionalResourceRelation: ttu.Tupleset.Relation,
+		OptionalResourceRelation: tuplesetRelation,
 	})
 	if err != nil {
-		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+		return nil, nil, nil, err
 	}
 	defer it.Close()
 
 	subjectsToDispatch := tuple.NewONRByTypeSet()
 	relationshipsBySubjectONR := util.NewMultiMap[string, *core.RelationTuple]()
+	relationshipsByResourceID := util.NewMultiMap[string, *core.RelationTuple]()
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 		if it.Err() != nil {
-			return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
+			return nil, nil, nil, it.Err()
 		}
 
 		subjectsToDispatch.Add(tpl.Subject)
 		relationshipsBySubjectONR.Add(tuple.StringONR(tpl.Subject), tpl)
+		relationshipsByResourceID.Add(tpl.ResourceAndRelation.ObjectId, tpl)
 	}
 	it.Close()
 
-	// Convert the subjects into batched requests.
+	return subjectsToDispatch, relationshipsBySubjectONR, relationshipsByResourceID, nil
+}
+
+// toDispatchChunks converts the subjects into batched requests.
+func toDispatchChunks(crc currentRequestContext, subjectsToDispatch *tuple.ONRByTypeSet) []directDispatch {
 	toDispatch := make([]directDispatch, 0, subjectsToDispatch.Len())
 	subjectsToDispatch.ForEachType(func(rr *core.RelationReference, resourceIds []string) {
 		chunkCount := 0.0
@@ -577,21 +684,7 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 		})
 		dispatchChunkCountHistogram.Observe(chunkCount)
 	})
-
-	return union(
-		ctx,
-		crc,
-		toDispatch,
-		func(ctx context.Context, crc currentRequestContext, dd directDispatch) CheckResult {
-			childResult := cc.checkComputedUserset(ctx, crc, ttu.ComputedUserset, dd.resourceType, dd.resourceIds)
-			if childResult.Err != nil {
-				return childResult
-			}
-
-			return mapFoundResources(childResult, dd.resourceType, relationshipsBySubjectONR)
-		},
-		cc.concurrencyLimit,
-	)
+	return toDispatch
 }
 
 // union returns whether any one of the lazy checks pass, and is used for union.
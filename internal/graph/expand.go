@@ -191,7 +191,16 @@ func (ce *ConcurrentExpander) expandSetOperation(ctx context.Context, req Valida
 		case *core.SetOperation_Child_UsersetRewrite:
 			requests = append(requests, ce.expandUsersetRewrite(ctx, req, child.UsersetRewrite))
 		case *core.SetOperation_Child_TupleToUserset:
-			requests = append(requests, ce.expandTupleToUserset(ctx, req, child.TupleToUserset))
+			requests = append(requests, ce.expandTupleToUserset(ctx, req, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset, expandAny))
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			switch child.FunctionedTupleToUserset.Function {
+			case core.FunctionedTupleToUserset_FUNCTION_ANY:
+				requests = append(requests, ce.expandTupleToUserset(ctx, req, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset, expandAny))
+			case core.FunctionedTupleToUserset_FUNCTION_ALL:
+				requests = append(requests, ce.expandTupleToUserset(ctx, req, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset, expandAll))
+			default:
+				return expandError(spiceerrors.MustBugf("unknown function %v for arrow in expand", child.FunctionedTupleToUserset.Function))
+			}
 		case *core.SetOperation_Child_XNil:
 			requests = append(requests, emptyExpansion(req.ResourceAndRelation))
 		default:
@@ -253,13 +262,15 @@ func (ce *ConcurrentExpander) expandComputedUserset(ctx context.Context, req Val
 	})
 }
 
-func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req ValidatedExpandRequest, ttu *core.TupleToUserset) ReduceableExpandFunc {
+// expandTupleToUserset expands the computed userset on each of the objects found via the tupleset
+// relation, combining the expansions with the reducer.
+func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req ValidatedExpandRequest, tuplesetRelation string, computedUserset *core.ComputedUserset, reducer ExpandReducer) ReduceableExpandFunc {
 	return func(ctx context.Context, resultChan chan<- ExpandResult) {
 		ds := datastoremw.MustFromContext(ctx).SnapshotReader(req.Revision)
 		it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 			ResourceType:             req.ResourceAndRelation.Namespace,
 			OptionalResourceIds:      []string{req.ResourceAndRelation.ObjectId},
-			OptionalResourceRelation: ttu.Tupleset.Relation,
+			OptionalResourceRelation: tuplesetRelation,
 		})
 		if err != nil {
 			resultChan <- expandResultError(NewExpansionFailureErr(err), emptyMetadata)
@@ -274,12 +285,18 @@ func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req Valida
 				return
 			}
 
-			toDispatch := ce.expandComputedUserset(ctx, req, ttu.ComputedUserset, tpl)
+			toDispatch := ce.expandComputedUserset(ctx, req, computedUserset, tpl)
 			requestsToDispatch = append(requestsToDispatch, decorateWithCaveatIfNecessary(toDispatch, caveats.CaveatAsExpr(tpl.Caveat)))
 		}
 		it.Close()
 
-		resultChan <- expandAny(ctx, req.ResourceAndRelation, requestsToDispatch)
+		// With no objects found, the expansion is empty, rather than an intersection without children.
+		if len(requestsToDispatch) == 0 {
+			resultChan <- expandAny(ctx, req.ResourceAndRelation, requestsToDispatch)
+			return
+		}
+
+		resultChan <- reducer(ctx, req.ResourceAndRelation, requestsToDispatch)
 	}
 }
 
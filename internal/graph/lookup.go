@@ -9,6 +9,7 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -34,16 +35,68 @@ type ValidatedLookupRequest struct {
 	Revision datastore.Revision
 }
 
-type collectingStream struct {
-	checker *parallelChecker
-	req     ValidatedLookupRequest
-	context context.Context
+// lookupMetadata accumulates the metadata of the subproblems of a streaming lookup. As the
+// consumer of the stream sums the metadata found in all responses, each published response
+// only carries the metadata accrued since the previously published response.
+type lookupMetadata struct {
+	pending   *v1.ResponseMeta
+	published bool
+	mu        sync.Mutex
+}
+
+func newLookupMetadata() *lookupMetadata {
+	return &lookupMetadata{
+		pending: &v1.ResponseMeta{
+			DispatchCount: 1, // +1 for the lookup
+		},
+	}
+}
+
+// add adds the metadata of a subproblem.
+func (lm *lookupMetadata) add(metadata *v1.ResponseMeta) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	dispatch.AddResponseMetadata(lm.pending, metadata)
+}
+
+// take returns the metadata accrued since the last call to take.
+func (lm *lookupMetadata) take() *v1.ResponseMeta {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	taken := &v1.ResponseMeta{
+		DispatchCount:       lm.pending.DispatchCount,
+		CachedDispatchCount: lm.pending.CachedDispatchCount,
+		DepthRequired:       lm.pending.DepthRequired + 1, // +1 for the lookup
+	}
+
+	lm.pending.DispatchCount = 0
+	lm.pending.CachedDispatchCount = 0
+	lm.published = true
+	return taken
+}
+
+// publishRemaining publishes a response without a resolved resource if there is any metadata
+// that has not yet been reported to the stream.
+func (lm *lookupMetadata) publishRemaining(stream dispatch.LookupStream) error {
+	hasRemaining := func() bool {
+		lm.mu.Lock()
+		defer lm.mu.Unlock()
+		return !lm.published || lm.pending.DispatchCount > 0 || lm.pending.CachedDispatchCount > 0
+	}()
+	if !hasRemaining {
+		return nil
+	}
 
-	dispatchCount       uint32
-	cachedDispatchCount uint32
-	depthRequired       uint32
+	return stream.Publish(&v1.DispatchLookupResourcesResponse{
+		Metadata: lm.take(),
+	})
+}
 
-	mu sync.Mutex
+type collectingStream struct {
+	checker  *parallelChecker
+	metadata *lookupMetadata
+	context  context.Context
 }
 
 func (ls *collectingStream) Context() context.Context {
@@ -55,21 +108,17 @@ func (ls *collectingStream) Publish(result *v1.DispatchReachableResourcesRespons
 		return spiceerrors.MustBugf("got nil result for Lookup publish")
 	}
 
-	func() {
-		ls.mu.Lock()
-		defer ls.mu.Unlock()
-
-		ls.dispatchCount += result.Metadata.DispatchCount
-		ls.cachedDispatchCount += result.Metadata.CachedDispatchCount
-		ls.depthRequired = max(result.Metadata.DepthRequired, ls.depthRequired)
-	}()
+	ls.metadata.add(result.Metadata)
 
 	for _, found := range result.Resources {
 		if found.ResultStatus == v1.ReachableResource_HAS_PERMISSION {
-			ls.checker.AddResolvedResource(&v1.ResolvedResource{
+			err := ls.checker.AddResolvedResource(&v1.ResolvedResource{
 				ResourceId:     found.ResourceId,
 				Permissionship: v1.ResolvedResource_HAS_PERMISSION,
 			})
+			if err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -78,17 +127,31 @@ func (ls *collectingStream) Publish(result *v1.DispatchReachableResourcesRespons
 	return nil
 }
 
-func (cl *ConcurrentLookup) LookupViaReachability(ctx context.Context, req ValidatedLookupRequest) (*v1.DispatchLookupResponse, error) {
+// LookupViaReachability streams the resources for which the subject has permission, by
+// dispatching to the reachability API and checking any reachable resources that require it.
+//
+// If the request has a limit or a cursor, the results are found in a stable order and each
+// is published with the cursor at which to resume after it. Otherwise, results are published
+// as soon as they are found.
+func (cl *ConcurrentLookup) LookupViaReachability(req ValidatedLookupRequest, stream dispatch.LookupStream) error {
 	if req.Subject.ObjectId == tuple.PublicWildcard {
-		resp := lookupResultError(NewErrInvalidArgument(errors.New("cannot perform lookup on wildcard")), emptyMetadata)
-		return resp.Resp, resp.Err
+		return NewErrInvalidArgument(errors.New("cannot perform lookup on wildcard"))
+	}
+
+	if req.OptionalCursor != nil || req.OptionalLimit > 0 {
+		return cl.orderedLookupViaReachability(req, stream)
 	}
 
-	cancelCtx, cancel := context.WithCancel(ctx)
+	return cl.unorderedLookupViaReachability(req, stream)
+}
+
+func (cl *ConcurrentLookup) unorderedLookupViaReachability(req ValidatedLookupRequest, parentStream dispatch.LookupStream) error {
+	cancelCtx, cancel := context.WithCancel(parentStream.Context())
 	defer cancel()
 
-	checker := newParallelChecker(cancelCtx, cancel, cl.c, req, cl.concurrencyLimit)
-	stream := &collectingStream{checker, req, cancelCtx, 0, 0, 0, sync.Mutex{}}
+	metadata := newLookupMetadata()
+	checker := newParallelChecker(cancelCtx, cl.c, req, parentStream, metadata, cl.concurrencyLimit)
+	stream := &collectingStream{checker, metadata, cancelCtx}
 
 	// Start the checker.
 	checker.Start()
@@ -107,50 +170,201 @@ func (cl *ConcurrentLookup) LookupViaReachability(ctx context.Context, req Valid
 		Metadata:   req.Metadata,
 	}, stream)
 	if err != nil {
-		resp := lookupResultError(err, emptyMetadata)
-		return resp.Resp, resp.Err
+		cancel()
+		_ = checker.Wait()
+		return err
 	}
 
 	// Wait for the checker to finish.
-	allowed, err := checker.Wait()
-	if err != nil {
-		resp := lookupResultError(err, emptyMetadata)
-		return resp.Resp, resp.Err
+	if err := checker.Wait(); err != nil {
+		return err
 	}
 
-	res := lookupResult(allowed, req, &v1.ResponseMeta{
-		DispatchCount:       stream.dispatchCount + checker.DispatchCount() + 1, // +1 for the lookup
-		CachedDispatchCount: stream.cachedDispatchCount + checker.CachedDispatchCount(),
-		DepthRequired:       max(stream.depthRequired, checker.DepthRequired()) + 1, // +1 for the lookup
-	})
-	return res.Resp, res.Err
+	return metadata.publishRemaining(parentStream)
 }
 
-func lookupResult(foundResources []*v1.ResolvedResource, req ValidatedLookupRequest, subProblemMetadata *v1.ResponseMeta) LookupResult {
-	limitedResources := limitedSlice(foundResources, req.Limit)
+// errLimitReached is returned from the reachable resources stream to halt the dispatch once the
+// limit of the lookup has been reached.
+var errLimitReached = errors.New("lookup limit reached")
 
-	return LookupResult{
-		&v1.DispatchLookupResponse{
-			Metadata:          ensureMetadata(subProblemMetadata),
-			ResolvedResources: limitedResources,
-		},
-		nil,
+// orderedLookupItem is a reachable resource, along with the cursor at which it was found.
+type orderedLookupItem struct {
+	resource *v1.ReachableResource
+	cursor   *v1.Cursor
+}
+
+// orderedLookupPublisher checks the reachable resources of an ordered lookup in batches,
+// publishing those found in the order in which they were reached.
+//
+// NOTE: a resource can be reachable via more than a single path, and is only deduplicated within
+// the stream. Resuming with a cursor may therefore return a resource already returned before the
+// cursor.
+type orderedLookupPublisher struct {
+	c        dispatch.Check
+	req      ValidatedLookupRequest
+	stream   dispatch.LookupStream
+	metadata *lookupMetadata
+
+	batchSize      int
+	batch          []orderedLookupItem
+	published      map[string]v1.ResolvedResource_Permissionship
+	publishedCount uint32
+	limitReached   bool
+}
+
+func (olp *orderedLookupPublisher) add(ctx context.Context, resource *v1.ReachableResource, cursor *v1.Cursor) error {
+	olp.batch = append(olp.batch, orderedLookupItem{resource, cursor})
+	if len(olp.batch) < olp.batchSize {
+		return nil
 	}
+
+	return olp.flush(ctx)
 }
 
-func limitedSlice[T any](slice []T, limit uint32) []T {
-	if len(slice) > int(limit) {
-		return slice[0:limit]
+func (olp *orderedLookupPublisher) flush(ctx context.Context) error {
+	if len(olp.batch) == 0 {
+		return nil
+	}
+
+	toCheck := make([]string, 0, len(olp.batch))
+	for _, item := range olp.batch {
+		if item.resource.ResultStatus == v1.ReachableResource_REQUIRES_CHECK {
+			toCheck = append(toCheck, item.resource.ResourceId)
+		}
 	}
 
-	return slice
+	var results map[string]*v1.ResourceCheckResult
+	if len(toCheck) > 0 {
+		checkResults, resultsMeta, err := computed.ComputeBulkCheck(ctx, olp.c,
+			computed.CheckParameters{
+				ResourceType:  olp.req.ObjectRelation,
+				Subject:       olp.req.Subject,
+				CaveatContext: olp.req.Context.AsMap(),
+				AtRevision:    olp.req.Revision,
+				MaximumDepth:  olp.req.Metadata.DepthRemaining,
+				DebugOption:   computed.NoDebugging,
+			},
+			toCheck,
+		)
+		if err != nil {
+			return err
+		}
+
+		olp.metadata.add(resultsMeta)
+		results = checkResults
+	}
+
+	batch := olp.batch
+	olp.batch = nil
+
+	for _, item := range batch {
+		resolved := &v1.ResolvedResource{
+			ResourceId:     item.resource.ResourceId,
+			Permissionship: v1.ResolvedResource_HAS_PERMISSION,
+		}
+
+		if item.resource.ResultStatus == v1.ReachableResource_REQUIRES_CHECK {
+			result, ok := results[item.resource.ResourceId]
+			if !ok {
+				continue
+			}
+
+			resolved = resolvedResourceForCheckResult(item.resource.ResourceId, result)
+			if resolved == nil {
+				continue
+			}
+		}
+
+		// Skip resources already published, unless a conditional result is now found to be
+		// a definite one.
+		existing, ok := olp.published[resolved.ResourceId]
+		if ok && (existing == v1.ResolvedResource_HAS_PERMISSION || resolved.Permissionship == existing) {
+			continue
+		}
+		olp.published[resolved.ResourceId] = resolved.Permissionship
+
+		err := olp.stream.Publish(&v1.DispatchLookupResourcesResponse{
+			Metadata:            olp.metadata.take(),
+			ResolvedResource:    resolved,
+			AfterResponseCursor: item.cursor,
+		})
+		if err != nil {
+			return err
+		}
+
+		olp.publishedCount++
+		if olp.req.OptionalLimit > 0 && olp.publishedCount >= olp.req.OptionalLimit {
+			olp.limitReached = true
+			return errLimitReached
+		}
+	}
+
+	return nil
 }
 
-func lookupResultError(err error, subProblemMetadata *v1.ResponseMeta) LookupResult {
-	return LookupResult{
-		&v1.DispatchLookupResponse{
-			Metadata: ensureMetadata(subProblemMetadata),
+func (cl *ConcurrentLookup) orderedLookupViaReachability(req ValidatedLookupRequest, parentStream dispatch.LookupStream) error {
+	ctx, cancel := context.WithCancel(parentStream.Context())
+	defer cancel()
+
+	batchSize := int(maxDispatchChunkSize)
+	if req.OptionalLimit > 0 && req.OptionalLimit < uint32(batchSize) {
+		batchSize = int(req.OptionalLimit)
+	}
+
+	metadata := newLookupMetadata()
+	publisher := &orderedLookupPublisher{
+		c:         cl.c,
+		req:       req,
+		stream:    parentStream,
+		metadata:  metadata,
+		batchSize: batchSize,
+		batch:     make([]orderedLookupItem, 0, batchSize),
+		published: map[string]v1.ResolvedResource_Permissionship{},
+	}
+
+	stream := dispatch.NewHandlingDispatchStream(ctx, func(result *v1.DispatchReachableResourcesResponse) error {
+		metadata.add(result.Metadata)
+
+		for _, found := range result.Resources {
+			if err := publisher.add(ctx, found, result.AfterResponseCursor); err != nil {
+				if errors.Is(err, errLimitReached) {
+					// Cancel any further work.
+					cancel()
+				}
+				return err
+			}
+		}
+		return nil
+	})
+
+	cursor := req.OptionalCursor
+	if cursor == nil {
+		cursor = &v1.Cursor{}
+	}
+
+	// NOTE: in ordered mode, the reachability API publishes its results sequentially and in
+	// a stable order, each with the cursor to use to resume after it.
+	err := cl.r.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+		ResourceRelation: req.ObjectRelation,
+		SubjectRelation: &core.RelationReference{
+			Namespace: req.Subject.Namespace,
+			Relation:  req.Subject.Relation,
 		},
-		err,
+		SubjectIds:     []string{req.Subject.ObjectId},
+		Metadata:       req.Metadata,
+		OptionalCursor: cursor,
+	}, stream)
+	if publisher.limitReached {
+		// Any error is the result of halting the dispatch once the limit was reached.
+		return metadata.publishRemaining(parentStream)
 	}
+	if err != nil {
+		return err
+	}
+
+	if err := publisher.flush(ctx); err != nil && !errors.Is(err, errLimitReached) {
+		return err
+	}
+
+	return metadata.publishRemaining(parentStream)
 }
@@ -191,12 +191,13 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 	ctx context.Context,
 	parentRequest ValidatedLookupSubjectsRequest,
 	parentStream dispatch.LookupSubjectsStream,
-	ttu *core.TupleToUserset,
+	tuplesetRelation string,
+	computedUserset *core.ComputedUserset,
 ) error {
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(parentRequest.Revision)
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 		ResourceType:             parentRequest.ResourceRelation.Namespace,
-		OptionalResourceRelation: ttu.Tupleset.Relation,
+		OptionalResourceRelation: tuplesetRelation,
 		OptionalResourceIds:      parentRequest.ResourceIds,
 	})
 	if err != nil {
@@ -222,14 +223,14 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 		relationshipsBySubjectONR.Add(tuple.StringONR(&core.ObjectAndRelation{
 			Namespace: tpl.Subject.Namespace,
 			ObjectId:  tpl.Subject.ObjectId,
-			Relation:  ttu.ComputedUserset.Relation,
+			Relation:  computedUserset.Relation,
 		}), tpl)
 	}
 	it.Close()
 
 	// Map the found subject types by the computed userset relation, so that we dispatch to it.
 	toDispatchByComputedRelationType, err := toDispatchByTuplesetType.Map(func(resourceType *core.RelationReference) (*core.RelationReference, error) {
-		if err := namespace.CheckNamespaceAndRelation(ctx, resourceType.Namespace, ttu.ComputedUserset.Relation, false, ds); err != nil {
+		if err := namespace.CheckNamespaceAndRelation(ctx, resourceType.Namespace, computedUserset.Relation, false, ds); err != nil {
 			if errors.As(err, &namespace.ErrRelationNotFound{}) {
 				return nil, nil
 			}
@@ -239,7 +240,7 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 
 		return &core.RelationReference{
 			Namespace: resourceType.Namespace,
-			Relation:  ttu.ComputedUserset.Relation,
+			Relation:  computedUserset.Relation,
 		}, nil
 	})
 	if err != nil {
@@ -249,6 +250,170 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 	return cl.dispatchTo(ctx, parentRequest, toDispatchByComputedRelationType, relationshipsBySubjectONR, parentStream)
 }
 
+// lookupViaIntersectionTupleToUserset finds the subjects of the computed userset found on *all* of the
+// objects reached via the tupleset relation of each resource, as used by the `.all()` arrow.
+func (cl *ConcurrentLookupSubjects) lookupViaIntersectionTupleToUserset(
+	ctx context.Context,
+	parentRequest ValidatedLookupSubjectsRequest,
+	parentStream dispatch.LookupSubjectsStream,
+	tuplesetRelation string,
+	computedUserset *core.ComputedUserset,
+) error {
+	ds := datastoremw.MustFromContext(ctx).SnapshotReader(parentRequest.Revision)
+	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             parentRequest.ResourceRelation.Namespace,
+		OptionalResourceRelation: tuplesetRelation,
+		OptionalResourceIds:      parentRequest.ResourceIds,
+	})
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	toDispatchByTuplesetType := datasets.NewSubjectByTypeSet()
+	relationshipsByResourceID := util.NewMultiMap[string, *core.RelationTuple]()
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		if err := toDispatchByTuplesetType.AddSubjectOf(tpl); err != nil {
+			return err
+		}
+
+		relationshipsByResourceID.Add(tpl.ResourceAndRelation.ObjectId, tpl)
+	}
+	it.Close()
+
+	if relationshipsByResourceID.IsEmpty() {
+		return nil
+	}
+
+	// Collect the subjects found for each of the objects, grouped by object type. Unlike the union
+	// arrow, the results cannot be mapped to the resources as they arrive, since a subject must be
+	// found on every object related to a resource.
+	cancelCtx, checkCancel := context.WithCancel(ctx)
+	defer checkCancel()
+
+	g, subCtx := errgroup.WithContext(cancelCtx)
+	g.SetLimit(int(cl.concurrencyLimit))
+
+	collectorsByType := map[string]*dispatch.CollectingDispatchStream[*v1.DispatchLookupSubjectsResponse]{}
+	var typeErr error
+	toDispatchByTuplesetType.ForEachType(func(tuplesetType *core.RelationReference, foundSubjects datasets.SubjectSet) {
+		if typeErr != nil {
+			return
+		}
+
+		if err := namespace.CheckNamespaceAndRelation(subCtx, tuplesetType.Namespace, computedUserset.Relation, false, ds); err != nil {
+			// Objects without the computed relation have no subjects, which empties the intersection
+			// of any resource related to them.
+			if !errors.As(err, &namespace.ErrRelationNotFound{}) {
+				typeErr = err
+			}
+			return
+		}
+
+		collector := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](subCtx)
+		collectorsByType[tuplesetType.Namespace] = collector
+
+		slice := foundSubjects.AsSlice()
+		resourceIds := make([]string, 0, len(slice))
+		for _, foundSubject := range slice {
+			resourceIds = append(resourceIds, foundSubject.SubjectId)
+		}
+
+		util.ForEachChunk(resourceIds, maxDispatchChunkSize, func(resourceIdChunk []string) {
+			g.Go(func() error {
+				return cl.d.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{
+					ResourceRelation: &core.RelationReference{
+						Namespace: tuplesetType.Namespace,
+						Relation:  computedUserset.Relation,
+					},
+					ResourceIds:     resourceIdChunk,
+					SubjectRelation: parentRequest.SubjectRelation,
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     parentRequest.Revision.String(),
+						DepthRemaining: parentRequest.Metadata.DepthRemaining - 1,
+					},
+				}, collector)
+			})
+		})
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if typeErr != nil {
+		return typeErr
+	}
+
+	metadata := emptyMetadata
+	foundSubjectsByType := make(map[string]map[string]*v1.FoundSubjects, len(collectorsByType))
+	for namespaceName, collector := range collectorsByType {
+		results := datasets.NewSubjectSetByResourceID()
+		for _, result := range collector.Results() {
+			metadata = combineResponseMetadata(metadata, result.Metadata)
+			if err := results.UnionWith(result.FoundSubjectsByResourceId); err != nil {
+				return fmt.Errorf("failed to UnionWith under lookupViaIntersectionTupleToUserset: %w", err)
+			}
+		}
+		foundSubjectsByType[namespaceName] = results.AsMap()
+	}
+
+	// For each resource, intersect the subjects found on each of its related objects, applying the
+	// caveat of the relationship to that object's subjects.
+	mappedFoundSubjects := make(map[string]*v1.FoundSubjects)
+	for _, resourceID := range relationshipsByResourceID.Keys() {
+		relationships, _ := relationshipsByResourceID.Get(resourceID)
+
+		var intersected datasets.SubjectSet
+		for index, relationship := range relationships {
+			found, ok := foundSubjectsByType[relationship.Subject.Namespace][relationship.Subject.ObjectId]
+			if !ok {
+				intersected = datasets.NewSubjectSet()
+				break
+			}
+
+			subjects := datasets.NewSubjectSet()
+			if err := subjects.UnionWith(found.FoundSubjects); err != nil {
+				return fmt.Errorf("could not combine subject sets: %w", err)
+			}
+
+			if relationship.GetCaveat() != nil {
+				subjects = subjects.WithParentCaveatExpression(wrapCaveat(relationship.Caveat))
+			}
+
+			if index == 0 {
+				intersected = subjects
+				continue
+			}
+
+			if err := intersected.IntersectionDifference(subjects); err != nil {
+				return err
+			}
+
+			if intersected.IsEmpty() {
+				break
+			}
+		}
+
+		if !intersected.IsEmpty() {
+			mappedFoundSubjects[resourceID] = intersected.AsFoundSubjects()
+		}
+	}
+
+	if len(mappedFoundSubjects) == 0 {
+		return nil
+	}
+
+	return parentStream.Publish(&v1.DispatchLookupSubjectsResponse{
+		FoundSubjectsByResourceId: mappedFoundSubjects,
+		Metadata:                  addCallToResponseMetadata(metadata),
+	})
+}
+
 func (cl *ConcurrentLookupSubjects) lookupViaRewrite(
 	ctx context.Context,
 	req ValidatedLookupSubjectsRequest,
@@ -301,9 +466,26 @@ func (cl *ConcurrentLookupSubjects) lookupSetOperation(
 
 		case *core.SetOperation_Child_TupleToUserset:
 			g.Go(func() error {
-				return cl.lookupViaTupleToUserset(subCtx, req, stream, child.TupleToUserset)
+				return cl.lookupViaTupleToUserset(subCtx, req, stream, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset)
 			})
 
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			ttu := child.FunctionedTupleToUserset
+			switch ttu.Function {
+			case core.FunctionedTupleToUserset_FUNCTION_ANY:
+				g.Go(func() error {
+					return cl.lookupViaTupleToUserset(subCtx, req, stream, ttu.Tupleset.Relation, ttu.ComputedUserset)
+				})
+
+			case core.FunctionedTupleToUserset_FUNCTION_ALL:
+				g.Go(func() error {
+					return cl.lookupViaIntersectionTupleToUserset(subCtx, req, stream, ttu.Tupleset.Relation, ttu.ComputedUserset)
+				})
+
+			default:
+				return fmt.Errorf("unknown function `%v` for arrow in lookup subjects", ttu.Function)
+			}
+
 		case *core.SetOperation_Child_XNil:
 			// Purposely do nothing.
 			continue
@@ -0,0 +1,373 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// reachableResourcesCursor is the parsed form of the cursor for an ordered reachable resources
+// dispatch.
+//
+// An ordered dispatch is processed as a sequence of steps: the first step yields the subjects
+// themselves (if they are of the resource type), and each following step processes a single
+// entrypoint. The sections of the cursor are the index of the step, the relationship after which
+// the current chunk of the step begins (if any), and then the position within the chunk: either
+// the last reported resource ID or the cursor of the redispatch for the chunk.
+type reachableResourcesCursor struct {
+	step       int
+	chunkAfter string
+	inChunk    []string
+}
+
+func parseReachableResourcesCursor(cursor *v1.Cursor) (reachableResourcesCursor, error) {
+	if len(cursor.Sections) == 0 {
+		return reachableResourcesCursor{}, nil
+	}
+
+	if len(cursor.Sections) < 2 {
+		return reachableResourcesCursor{}, NewErrInvalidArgument(errors.New("invalid reachable resources cursor"))
+	}
+
+	step, err := strconv.Atoi(cursor.Sections[0])
+	if err != nil || step < 0 {
+		return reachableResourcesCursor{}, NewErrInvalidArgument(fmt.Errorf("invalid step in reachable resources cursor: %q", cursor.Sections[0]))
+	}
+
+	return reachableResourcesCursor{
+		step:       step,
+		chunkAfter: cursor.Sections[1],
+		inChunk:    cursor.Sections[2:],
+	}, nil
+}
+
+// forStep returns the position to resume within the given step.
+func (rrc reachableResourcesCursor) forStep(step int) (string, []string) {
+	if step != rrc.step {
+		return "", nil
+	}
+	return rrc.chunkAfter, rrc.inChunk
+}
+
+// chunkCursor returns the cursor for a position within the chunk for the step.
+func chunkCursor(step int, chunkAfter string, inChunk ...string) *v1.Cursor {
+	sections := make([]string, 0, len(inChunk)+2)
+	sections = append(sections, strconv.Itoa(step), chunkAfter)
+	sections = append(sections, inChunk...)
+	return &v1.Cursor{Sections: sections}
+}
+
+// orderedReachableResources performs the reachable resources dispatch sequentially, publishing
+// each reachable resource in its own response, along with the cursor at which to resume after it.
+// As no deduplication is performed across the steps, a resource may be published more than once.
+func (crr *ConcurrentReachableResources) orderedReachableResources(
+	req ValidatedReachableResourcesRequest,
+	stream dispatch.ReachableResourcesStream,
+) error {
+	ctx := stream.Context()
+
+	cursor, err := parseReachableResourcesCursor(req.OptionalCursor)
+	if err != nil {
+		return err
+	}
+
+	// Load the type system and reachability graph to find the entrypoints for the reachability.
+	ds := datastoremw.MustFromContext(ctx)
+	reader := ds.SnapshotReader(req.Revision)
+	_, typeSystem, err := namespace.ReadNamespaceAndTypes(ctx, req.ResourceRelation.Namespace, reader)
+	if err != nil {
+		return err
+	}
+
+	rg := namespace.ReachabilityGraphFor(typeSystem.AsValidated())
+	entrypoints, err := rg.OptimizedEntrypointsForSubjectToResource(ctx, &core.RelationReference{
+		Namespace: req.SubjectRelation.Namespace,
+		Relation:  req.SubjectRelation.Relation,
+	}, req.ResourceRelation)
+	if err != nil {
+		return err
+	}
+
+	// The entrypoints are not guaranteed to be returned in a stable order, so sort them.
+	sort.Slice(entrypoints, func(i, j int) bool {
+		return entrypoints[i].SortKey() < entrypoints[j].SortKey()
+	})
+
+	for step := cursor.step; step <= len(entrypoints); step++ {
+		chunkAfter, inChunk := cursor.forStep(step)
+
+		// The first step yields the subjects directly if the resource type matches the subject type.
+		if step == 0 {
+			if req.SubjectRelation.Namespace == req.ResourceRelation.Namespace &&
+				req.SubjectRelation.Relation == req.ResourceRelation.Relation {
+				rsm := subjectIDsToResourcesMap(req.ResourceRelation, req.SubjectIds)
+				resources := rsm.asReadOnly().asReachableResources(true)
+				if err := publishOrderedResources(stream, resources, step, "", inChunk, emptyMetadata); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		entrypoint := entrypoints[step-1]
+		switch entrypoint.EntrypointKind() {
+		case core.ReachabilityEntrypoint_RELATION_ENTRYPOINT:
+			relationReference, subjectsFilter, err := relationEntrypointSubjectsFilter(ctx, entrypoint, reader, req)
+			if err != nil {
+				return err
+			}
+
+			err = crr.orderedChunkedRedispatch(ctx, reader, subjectsFilter, relationReference, chunkAfter, inChunk,
+				func(ctx context.Context, drsm dispatchableResourcesSubjectMap, chunkAfter string, inChunk []string) error {
+					return crr.orderedRedispatchOrReport(ctx, relationReference, drsm, rg, entrypoint, stream, req, step, chunkAfter, inChunk)
+				})
+			if err != nil {
+				return err
+			}
+
+		case core.ReachabilityEntrypoint_COMPUTED_USERSET_ENTRYPOINT:
+			containingRelation := entrypoint.ContainingRelationOrPermission()
+			rewrittenSubjectRelation := &core.RelationReference{
+				Namespace: containingRelation.Namespace,
+				Relation:  containingRelation.Relation,
+			}
+
+			rsm := subjectIDsToResourcesMap(rewrittenSubjectRelation, req.SubjectIds)
+			err := crr.orderedRedispatchOrReport(ctx, rewrittenSubjectRelation, rsm.asReadOnly(), rg, entrypoint, stream, req, step, "", inChunk)
+			if err != nil {
+				return err
+			}
+
+		case core.ReachabilityEntrypoint_TUPLESET_TO_USERSET_ENTRYPOINT:
+			containingRelation := entrypoint.ContainingRelationOrPermission()
+			tuplesetRelationReference, subjectsFilter, err := ttuEntrypointSubjectsFilter(ctx, entrypoint, reader, req)
+			if err != nil {
+				return err
+			}
+
+			if subjectsFilter == nil {
+				continue
+			}
+
+			err = crr.orderedChunkedRedispatch(ctx, reader, *subjectsFilter, tuplesetRelationReference, chunkAfter, inChunk,
+				func(ctx context.Context, drsm dispatchableResourcesSubjectMap, chunkAfter string, inChunk []string) error {
+					return crr.orderedRedispatchOrReport(ctx, containingRelation, drsm, rg, entrypoint, stream, req, step, chunkAfter, inChunk)
+				})
+			if err != nil {
+				return err
+			}
+
+		default:
+			return spiceerrors.MustBugf("Unknown kind of entrypoint: %v", entrypoint.EntrypointKind())
+		}
+	}
+
+	return nil
+}
+
+// orderedChunkedRedispatch reads the relationships matching the subjects filter in chunks, sorted
+// by resource, and invokes the handler for each chunk in turn. Reading begins with the chunk after
+// the given relationship, if any, and the in-chunk cursor is handed to the handler of that chunk.
+func (crr *ConcurrentReachableResources) orderedChunkedRedispatch(
+	ctx context.Context,
+	reader datastore.Reader,
+	subjectsFilter datastore.SubjectsFilter,
+	resourceType *core.RelationReference,
+	chunkAfter string,
+	inChunk []string,
+	handler func(ctx context.Context, resources dispatchableResourcesSubjectMap, chunkAfter string, inChunk []string) error,
+) error {
+	var after options.Cursor
+	if chunkAfter != "" {
+		parsed := tuple.Parse(chunkAfter)
+		if parsed == nil {
+			return NewErrInvalidArgument(fmt.Errorf("invalid relationship in reachable resources cursor: %q", chunkAfter))
+		}
+		after = parsed
+	}
+
+	limit := uint64(maxDispatchChunkSize)
+	for {
+		it, err := reader.ReverseQueryRelationships(
+			ctx,
+			subjectsFilter,
+			options.WithResRelation(&options.ResourceRelation{
+				Namespace: resourceType.Namespace,
+				Relation:  resourceType.Relation,
+			}),
+			options.WithSortForReverse(options.ByResource),
+			options.WithAfterForReverse(after),
+			options.WithReverseLimit(&limit),
+		)
+		if err != nil {
+			return err
+		}
+
+		rsm := newResourcesSubjectMap(resourceType)
+		var last *core.RelationTuple
+		count := uint64(0)
+		for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+			if it.Err() != nil {
+				it.Close()
+				return it.Err()
+			}
+
+			if err := rsm.addRelationship(tpl); err != nil {
+				it.Close()
+				return err
+			}
+
+			last = tpl
+			count++
+		}
+		if it.Err() != nil {
+			it.Close()
+			return it.Err()
+		}
+		it.Close()
+
+		if count == 0 {
+			return nil
+		}
+
+		if err := handler(ctx, rsm.asReadOnly(), chunkAfter, inChunk); err != nil {
+			return err
+		}
+
+		if count < limit {
+			return nil
+		}
+
+		after = last
+		chunkAfter = tuple.StringWithoutCaveat(last)
+		inChunk = nil
+	}
+}
+
+// orderedRedispatchOrReport is the ordered form of redispatchOrReport: resources are reported
+// in order of their IDs, and redispatches are performed sequentially, with the cursor of each
+// redispatched result prefixed with the position of the chunk.
+func (crr *ConcurrentReachableResources) orderedRedispatchOrReport(
+	ctx context.Context,
+	foundResourceType *core.RelationReference,
+	foundResources dispatchableResourcesSubjectMap,
+	rg *namespace.ReachabilityGraph,
+	entrypoint namespace.ReachabilityEntrypoint,
+	parentStream dispatch.ReachableResourcesStream,
+	parentRequest ValidatedReachableResourcesRequest,
+	step int,
+	chunkAfter string,
+	inChunk []string,
+) error {
+	if foundResources.isEmpty() {
+		// Nothing more to do.
+		return nil
+	}
+
+	// Check for entrypoints for the new found resource type.
+	hasResourceEntrypoints, err := rg.HasOptimizedEntrypointsForSubjectToResource(ctx, foundResourceType, parentRequest.ResourceRelation)
+	if err != nil {
+		return err
+	}
+
+	// If there are no entrypoints, then no further dispatch is necessary.
+	if !hasResourceEntrypoints {
+		// If the found resource matches the target resource type and relation, yield the resource.
+		if foundResourceType.Namespace == parentRequest.ResourceRelation.Namespace &&
+			foundResourceType.Relation == parentRequest.ResourceRelation.Relation {
+			resources := foundResources.asReachableResources(entrypoint.IsDirectResult())
+			return publishOrderedResources(parentStream, resources, step, chunkAfter, inChunk, emptyMetadata)
+		}
+
+		// Otherwise, we're done.
+		return nil
+	}
+
+	// Otherwise, redispatch, resuming the redispatch at its cursor, if any.
+	stream := &dispatch.WrappedDispatchStream[*v1.DispatchReachableResourcesResponse]{
+		Stream: parentStream,
+		Ctx:    ctx,
+		Processor: func(result *v1.DispatchReachableResourcesResponse) (*v1.DispatchReachableResourcesResponse, bool, error) {
+			// Map the found resources via the subject+resources used for dispatching, to determine
+			// if any need to be made conditional due to caveats.
+			mapped, err := foundResources.mapFoundResources(result.Resources, entrypoint.IsDirectResult())
+			if err != nil {
+				return nil, false, err
+			}
+
+			var childSections []string
+			if result.AfterResponseCursor != nil {
+				childSections = result.AfterResponseCursor.Sections
+			}
+
+			return &v1.DispatchReachableResourcesResponse{
+				Resources:           mapped,
+				Metadata:            addCallToResponseMetadata(result.Metadata),
+				AfterResponseCursor: chunkCursor(step, chunkAfter, childSections...),
+			}, true, nil
+		},
+	}
+
+	subjectIDs := foundResources.resourceIDs()
+	sort.Strings(subjectIDs)
+
+	return crr.d.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+		ResourceRelation: parentRequest.ResourceRelation,
+		SubjectRelation:  foundResourceType,
+		SubjectIds:       subjectIDs,
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     parentRequest.Revision.String(),
+			DepthRemaining: parentRequest.Metadata.DepthRemaining - 1,
+		},
+		OptionalCursor: &v1.Cursor{Sections: inChunk},
+	}, stream)
+}
+
+// publishOrderedResources publishes the resources in order of their IDs, each in its own response,
+// skipping those at or before the resource ID found in the in-chunk cursor, if any.
+func publishOrderedResources(
+	stream dispatch.ReachableResourcesStream,
+	resources []*v1.ReachableResource,
+	step int,
+	chunkAfter string,
+	inChunk []string,
+	metadata *v1.ResponseMeta,
+) error {
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ResourceId < resources[j].ResourceId
+	})
+
+	afterResourceID := ""
+	if len(inChunk) > 0 {
+		afterResourceID = inChunk[0]
+	}
+
+	for _, resource := range resources {
+		if afterResourceID != "" && resource.ResourceId <= afterResourceID {
+			continue
+		}
+
+		err := stream.Publish(&v1.DispatchReachableResourcesResponse{
+			Resources:           []*v1.ReachableResource{resource},
+			Metadata:            metadata,
+			AfterResponseCursor: chunkCursor(step, chunkAfter, resource.ResourceId),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"sync"
 
-	"golang.org/x/exp/maps"
 	"golang.org/x/sync/semaphore"
 
 	"github.com/authzed/spicedb/internal/dispatch"
@@ -14,11 +13,11 @@ import (
 )
 
 // parallelChecker is a helper for initiating checks over a large set of resources of a specific
-// type, for a specific subject, and putting the results concurrently into a set.
+// type, for a specific subject, and publishing the results concurrently to a lookup stream as
+// they are found.
 type parallelChecker struct {
-	c      dispatch.Check
-	t      *TaskRunner
-	cancel func()
+	c dispatch.Check
+	t *TaskRunner
 
 	toCheck         chan string
 	enqueuedToCheck *util.Set[string]
@@ -26,22 +25,19 @@ type parallelChecker struct {
 	lookupRequest ValidatedLookupRequest
 	maxConcurrent uint16
 
-	foundResourceIDs map[string]*v1.ResolvedResource
+	stream   dispatch.LookupStream
+	metadata *lookupMetadata
 
-	dispatchCount       uint32
-	cachedDispatchCount uint32
-	depthRequired       uint32
+	foundResourceIDs map[string]*v1.ResolvedResource
 
 	mu sync.Mutex
 }
 
 // newParallelChecker creates a new parallel checker, for a given subject.
-func newParallelChecker(ctx context.Context, cancel func(), c dispatch.Check, req ValidatedLookupRequest, maxConcurrent uint16) *parallelChecker {
+func newParallelChecker(ctx context.Context, c dispatch.Check, req ValidatedLookupRequest, stream dispatch.LookupStream, metadata *lookupMetadata, maxConcurrent uint16) *parallelChecker {
 	t := NewTaskRunner(ctx, maxConcurrent+1) // +1 for the work scheduling goroutine
 	toCheck := make(chan string, maxConcurrent)
 	return &parallelChecker{
-		cancel: cancel,
-
 		c: c,
 		t: t,
 
@@ -51,58 +47,39 @@ func newParallelChecker(ctx context.Context, cancel func(), c dispatch.Check, re
 		lookupRequest: req,
 		maxConcurrent: maxConcurrent,
 
-		foundResourceIDs:    map[string]*v1.ResolvedResource{},
-		dispatchCount:       0,
-		cachedDispatchCount: 0,
-		depthRequired:       0,
+		stream:   stream,
+		metadata: metadata,
+
+		foundResourceIDs: map[string]*v1.ResolvedResource{},
 
 		mu: sync.Mutex{},
 	}
 }
 
-// AddResolvedResource adds a resource that has been already checked to the set.
-func (pc *parallelChecker) AddResolvedResource(resolvedResource *v1.ResolvedResource) {
+// AddResolvedResource adds a resource that has been already checked, publishing it if it was not
+// previously found.
+func (pc *parallelChecker) AddResolvedResource(resolvedResource *v1.ResolvedResource) error {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
-	pc.addResultsUnsafe(resolvedResource)
+	return pc.addResultsUnsafe(resolvedResource)
 }
 
-// DispatchCount returns the number of dispatches used for checks.
-func (pc *parallelChecker) DispatchCount() uint32 {
-	return pc.dispatchCount
-}
-
-// CachedDispatchCount returns the number of cached dispatches used for checks.
-func (pc *parallelChecker) CachedDispatchCount() uint32 {
-	return pc.cachedDispatchCount
-}
-
-// DepthRequired returns the maximum depth required for the checks.
-func (pc *parallelChecker) DepthRequired() uint32 {
-	return pc.depthRequired
-}
-
-func (pc *parallelChecker) addResultsUnsafe(resolvedResource *v1.ResolvedResource) {
-	// If the result being added is conditional and we've already found a valid permission, skip.
-	if resolvedResource.Permissionship == v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
-		existing, ok := pc.foundResourceIDs[resolvedResource.ResourceId]
-		if ok && existing.Permissionship == v1.ResolvedResource_HAS_PERMISSION {
-			return
+func (pc *parallelChecker) addResultsUnsafe(resolvedResource *v1.ResolvedResource) error {
+	existing, ok := pc.foundResourceIDs[resolvedResource.ResourceId]
+	if ok {
+		// If we've already found a valid permission, skip. Otherwise, only a valid permission
+		// overloads the already published conditional result.
+		if existing.Permissionship == v1.ResolvedResource_HAS_PERMISSION ||
+			resolvedResource.Permissionship == v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
+			return nil
 		}
 	}
 
 	pc.foundResourceIDs[resolvedResource.ResourceId] = resolvedResource
-	if len(pc.foundResourceIDs) >= int(pc.lookupRequest.Limit) {
-		// Cancel any further work
-		pc.cancel()
-		return
-	}
-}
-
-func (pc *parallelChecker) updateStatsUnsafe(metadata *v1.ResponseMeta) {
-	pc.dispatchCount += metadata.DispatchCount
-	pc.cachedDispatchCount += metadata.CachedDispatchCount
-	pc.depthRequired = max(pc.depthRequired, metadata.DepthRequired)
+	return pc.stream.Publish(&v1.DispatchLookupResourcesResponse{
+		Metadata:         pc.metadata.take(),
+		ResolvedResource: resolvedResource,
+	})
 }
 
 // QueueToCheck queues a resource ID to be checked.
@@ -110,11 +87,6 @@ func (pc *parallelChecker) QueueToCheck(resourceID string) bool {
 	queue := func() bool {
 		pc.mu.Lock()
 		defer pc.mu.Unlock()
-		if len(pc.foundResourceIDs) >= int(pc.lookupRequest.Limit) {
-			close(pc.toCheck)
-			return false
-		}
-
 		return pc.enqueuedToCheck.Add(resourceID)
 	}()
 	if !queue {
@@ -184,24 +156,21 @@ func (pc *parallelChecker) Start() {
 					return err
 				}
 
+				pc.metadata.add(resultsMeta)
+
 				pc.mu.Lock()
-				pc.updateStatsUnsafe(resultsMeta)
+				defer pc.mu.Unlock()
 
 				for resourceID, result := range results {
-					if result.Membership == v1.ResourceCheckResult_MEMBER {
-						pc.addResultsUnsafe(&v1.ResolvedResource{
-							ResourceId:     resourceID,
-							Permissionship: v1.ResolvedResource_HAS_PERMISSION,
-						})
-					} else if result.Membership == v1.ResourceCheckResult_CAVEATED_MEMBER {
-						pc.addResultsUnsafe(&v1.ResolvedResource{
-							ResourceId:             resourceID,
-							Permissionship:         v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION,
-							MissingRequiredContext: result.MissingExprFields,
-						})
+					resolved := resolvedResourceForCheckResult(resourceID, result)
+					if resolved == nil {
+						continue
+					}
+
+					if err := pc.addResultsUnsafe(resolved); err != nil {
+						return err
 					}
 				}
-				pc.mu.Unlock()
 				return nil
 			})
 		}
@@ -211,13 +180,31 @@ func (pc *parallelChecker) Start() {
 }
 
 // Wait waits for the parallel checker to finish performing all of its
-// checks and returns the set of resources that checked, along with whether an
-// error occurred. Once called, no new items can be added via QueueToCheck.
-func (pc *parallelChecker) Wait() ([]*v1.ResolvedResource, error) {
+// checks, returning whether an error occurred. Once called, no new items can
+// be added via QueueToCheck.
+func (pc *parallelChecker) Wait() error {
 	close(pc.toCheck)
-	if err := pc.t.Wait(); err != nil {
-		return nil, err
-	}
+	return pc.t.Wait()
+}
+
+// resolvedResourceForCheckResult returns the resolved resource for the result of checking the
+// resource, or nil if the subject does not have permission.
+func resolvedResourceForCheckResult(resourceID string, result *v1.ResourceCheckResult) *v1.ResolvedResource {
+	switch result.Membership {
+	case v1.ResourceCheckResult_MEMBER:
+		return &v1.ResolvedResource{
+			ResourceId:     resourceID,
+			Permissionship: v1.ResolvedResource_HAS_PERMISSION,
+		}
 
-	return maps.Values(pc.foundResourceIDs), nil
+	case v1.ResourceCheckResult_CAVEATED_MEMBER:
+		return &v1.ResolvedResource{
+			ResourceId:             resourceID,
+			Permissionship:         v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION,
+			MissingRequiredContext: result.MissingExprFields,
+		}
+
+	default:
+		return nil
+	}
 }
@@ -6,53 +6,63 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/authzed/spicedb/internal/dispatch"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
 func TestParallelCheckerDirectOverload(t *testing.T) {
-	pc := newParallelChecker(context.Background(), func() {}, nil, ValidatedLookupRequest{
-		DispatchLookupRequest: &v1.DispatchLookupRequest{
-			Limit: 50,
-		},
-	}, 10)
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+	pc := newParallelChecker(context.Background(), nil, ValidatedLookupRequest{
+		DispatchLookupRequest: &v1.DispatchLookupRequest{},
+	}, stream, newLookupMetadata(), 10)
 
 	// Add a conditional item and ensure it is added.
-	pc.addResultsUnsafe(&v1.ResolvedResource{
+	require.NoError(t, pc.addResultsUnsafe(&v1.ResolvedResource{
 		ResourceId:     "foo",
 		Permissionship: v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION,
-	})
+	}))
 
 	require.Equal(t, v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION, pc.foundResourceIDs["foo"].Permissionship)
+	require.Len(t, stream.Results(), 1)
 
 	// Add a concrete item and ensure it overloads.
-	pc.addResultsUnsafe(&v1.ResolvedResource{
+	require.NoError(t, pc.addResultsUnsafe(&v1.ResolvedResource{
 		ResourceId:     "foo",
 		Permissionship: v1.ResolvedResource_HAS_PERMISSION,
-	})
+	}))
 
 	require.Equal(t, v1.ResolvedResource_HAS_PERMISSION, pc.foundResourceIDs["foo"].Permissionship)
+	require.Len(t, stream.Results(), 2)
 
 	// Add a conditional item and ensure it is ignored.
-	pc.addResultsUnsafe(&v1.ResolvedResource{
+	require.NoError(t, pc.addResultsUnsafe(&v1.ResolvedResource{
 		ResourceId:     "foo",
 		Permissionship: v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION,
-	})
+	}))
 
 	require.Equal(t, v1.ResolvedResource_HAS_PERMISSION, pc.foundResourceIDs["foo"].Permissionship)
+	require.Len(t, stream.Results(), 2)
 }
 
-func TestQueueToCheckLimit(t *testing.T) {
-	pc := newParallelChecker(context.Background(), func() {}, nil, ValidatedLookupRequest{
-		DispatchLookupRequest: &v1.DispatchLookupRequest{
-			Limit: 1,
-		},
-	}, 10)
+func TestLookupMetadataDeltas(t *testing.T) {
+	metadata := newLookupMetadata()
+	metadata.add(&v1.ResponseMeta{DispatchCount: 2, CachedDispatchCount: 1, DepthRequired: 3})
 
-	pc.addResultsUnsafe(&v1.ResolvedResource{
-		ResourceId:     "foo",
-		Permissionship: v1.ResolvedResource_HAS_PERMISSION,
-	})
+	first := metadata.take()
+	require.Equal(t, uint32(3), first.DispatchCount)
+	require.Equal(t, uint32(1), first.CachedDispatchCount)
+	require.Equal(t, uint32(4), first.DepthRequired)
+
+	// Only the metadata accrued since the previous take is returned, while the depth is retained.
+	metadata.add(&v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1})
+
+	second := metadata.take()
+	require.Equal(t, uint32(1), second.DispatchCount)
+	require.Equal(t, uint32(0), second.CachedDispatchCount)
+	require.Equal(t, uint32(4), second.DepthRequired)
 
-	// Queue a second and ensure it is ignored.
-	require.False(t, pc.QueueToCheck("bar"))
+	// Nothing remains to be published.
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+	require.NoError(t, metadata.publishRemaining(stream))
+	require.Empty(t, stream.Results())
 }
@@ -45,6 +45,12 @@ func (crr *ConcurrentReachableResources) ReachableResources(
 		return fmt.Errorf("no subjects ids given to reachable resources dispatch")
 	}
 
+	// If a cursor was given, the results must be produced in a stable order so that they can
+	// be resumed.
+	if req.OptionalCursor != nil {
+		return crr.orderedReachableResources(req, stream)
+	}
+
 	// If the resource type matches the subject type, yield directly as a one-to-one result
 	// for each subjectID.
 	if req.SubjectRelation.Namespace == req.ResourceRelation.Namespace &&
@@ -144,14 +150,34 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 	stream dispatch.ReachableResourcesStream,
 	dispatched *syncONRSet,
 ) error {
-	relationReference, err := entrypoint.DirectRelation()
+	relationReference, subjectsFilter, err := relationEntrypointSubjectsFilter(ctx, entrypoint, reader, req)
 	if err != nil {
 		return err
 	}
 
+	crr.scheduleChunkedRedispatch(t, reader, subjectsFilter, relationReference,
+		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
+			return crr.redispatchOrReport(ctx, t, relationReference, drsm, rg, entrypoint, stream, req, dispatched)
+		})
+	return nil
+}
+
+// relationEntrypointSubjectsFilter returns the relation for the relation entrypoint, along with
+// the filter for finding the relationships of the requested subjects for that relation.
+func relationEntrypointSubjectsFilter(
+	ctx context.Context,
+	entrypoint namespace.ReachabilityEntrypoint,
+	reader datastore.Reader,
+	req ValidatedReachableResourcesRequest,
+) (*core.RelationReference, datastore.SubjectsFilter, error) {
+	relationReference, err := entrypoint.DirectRelation()
+	if err != nil {
+		return nil, datastore.SubjectsFilter{}, err
+	}
+
 	_, relTypeSystem, err := namespace.ReadNamespaceAndTypes(ctx, relationReference.Namespace, reader)
 	if err != nil {
-		return err
+		return nil, datastore.SubjectsFilter{}, err
 	}
 
 	// Build the list of subjects to lookup based on the type information available.
@@ -161,7 +187,7 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 		req.SubjectRelation.Relation,
 	)
 	if err != nil {
-		return err
+		return nil, datastore.SubjectsFilter{}, err
 	}
 
 	subjectIds := make([]string, 0, len(req.SubjectIds)+1)
@@ -172,7 +198,7 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 	if req.SubjectRelation.Relation == tuple.Ellipsis {
 		isWildcardAllowed, err := relTypeSystem.IsAllowedPublicNamespace(relationReference.Relation, req.SubjectRelation.Namespace)
 		if err != nil {
-			return err
+			return nil, datastore.SubjectsFilter{}, err
 		}
 
 		if isWildcardAllowed == namespace.PublicSubjectAllowed {
@@ -180,7 +206,6 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 		}
 	}
 
-	// Lookup the subjects and then redispatch/report results.
 	subjectsFilter := datastore.SubjectsFilter{
 		SubjectType:        req.SubjectRelation.Namespace,
 		OptionalSubjectIds: subjectIds,
@@ -189,11 +214,7 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 		},
 	}
 
-	crr.scheduleChunkedRedispatch(t, reader, subjectsFilter, relationReference,
-		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
-			return crr.redispatchOrReport(ctx, t, relationReference, drsm, rg, entrypoint, stream, req, dispatched)
-		})
-	return nil
+	return relationReference, subjectsFilter, nil
 }
 
 func min(a, b int) int {
@@ -274,15 +295,41 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 	dispatched *syncONRSet,
 ) error {
 	containingRelation := entrypoint.ContainingRelationOrPermission()
+	tuplesetRelationReference, subjectsFilter, err := ttuEntrypointSubjectsFilter(ctx, entrypoint, reader, req)
+	if err != nil {
+		return err
+	}
+
+	if subjectsFilter == nil {
+		return nil
+	}
+
+	crr.scheduleChunkedRedispatch(t, reader, *subjectsFilter, tuplesetRelationReference,
+		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
+			return crr.redispatchOrReport(ctx, t, containingRelation, drsm, rg, entrypoint, stream, req, dispatched)
+		})
+	return nil
+}
+
+// ttuEntrypointSubjectsFilter returns the tupleset relation for the TTU entrypoint, along with the
+// filter for finding the relationships of the requested subjects in the tupleset. If no such
+// relationships can exist, the returned filter is nil.
+func ttuEntrypointSubjectsFilter(
+	ctx context.Context,
+	entrypoint namespace.ReachabilityEntrypoint,
+	reader datastore.Reader,
+	req ValidatedReachableResourcesRequest,
+) (*core.RelationReference, *datastore.SubjectsFilter, error) {
+	containingRelation := entrypoint.ContainingRelationOrPermission()
 
 	_, ttuTypeSystem, err := namespace.ReadNamespaceAndTypes(ctx, containingRelation.Namespace, reader)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	tuplesetRelation, err := entrypoint.TuplesetRelation()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Determine the subject relation(s) for which to search. Note that we need to do so
@@ -292,7 +339,7 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 
 	isEllipsisAllowed, err := ttuTypeSystem.IsAllowedDirectRelation(tuplesetRelation, req.SubjectRelation.Namespace, tuple.Ellipsis)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if isEllipsisAllowed == namespace.DirectRelationValid {
 		relationFilter = relationFilter.WithEllipsisRelation()
@@ -300,18 +347,18 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 
 	isDirectAllowed, err := ttuTypeSystem.IsAllowedDirectRelation(tuplesetRelation, req.SubjectRelation.Namespace, req.SubjectRelation.Relation)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if isDirectAllowed == namespace.DirectRelationValid {
 		relationFilter = relationFilter.WithNonEllipsisRelation(req.SubjectRelation.Relation)
 	}
 
 	if relationFilter.IsEmpty() {
-		return nil
+		return nil, nil, nil
 	}
 
 	// Search for the resolved subjects in the tupleset of the TTU.
-	subjectsFilter := datastore.SubjectsFilter{
+	subjectsFilter := &datastore.SubjectsFilter{
 		SubjectType:        req.SubjectRelation.Namespace,
 		OptionalSubjectIds: req.SubjectIds,
 		RelationFilter:     relationFilter,
@@ -322,11 +369,7 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 		Relation:  tuplesetRelation,
 	}
 
-	crr.scheduleChunkedRedispatch(t, reader, subjectsFilter, tuplesetRelationReference,
-		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
-			return crr.redispatchOrReport(ctx, t, containingRelation, drsm, rg, entrypoint, stream, req, dispatched)
-		})
-	return nil
+	return tuplesetRelationReference, subjectsFilter, nil
 }
 
 // redispatchOrReport checks if further redispatching is necessary for the found resource
@@ -0,0 +1,107 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sourcegraph/go-lsp"
+
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+const diagnosticSource = "spicedb"
+
+// compileDocument compiles the document and validates the definitions it declares, returning
+// the compiled schema, if it compiles, and the issues found.
+func compileDocument(ctx context.Context, doc *document, resolver compiler.ImportResolver) (*compiler.CompiledSchema, []lsp.Diagnostic) {
+	empty := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       doc.source(),
+		SchemaString: doc.text,
+	}, &empty, compiler.WithImportResolver(resolver))
+	if err != nil {
+		return nil, []lsp.Diagnostic{compilerDiagnostic(doc, err)}
+	}
+
+	// Imported definitions are validated when their own files are opened.
+	var diagnostics []lsp.Diagnostic
+	for _, caveatDef := range compiled.CaveatDefinitions {
+		if def := doc.index.definition(caveatDef.Name); def != nil {
+			if err := namespace.ValidateCaveatDefinition(caveatDef); err != nil {
+				diagnostics = append(diagnostics, validationDiagnostic(doc, def, err))
+			}
+		}
+	}
+
+	nsResolver := namespace.ResolverForPredefinedDefinitions(namespace.PredefinedElements{
+		Namespaces: compiled.ObjectDefinitions,
+		Caveats:    compiled.CaveatDefinitions,
+	})
+	for _, nsDef := range compiled.ObjectDefinitions {
+		def := doc.index.definition(nsDef.Name)
+		if def == nil {
+			continue
+		}
+
+		ts, err := namespace.NewNamespaceTypeSystem(nsDef, nsResolver)
+		if err == nil {
+			_, err = ts.Validate(ctx)
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, validationDiagnostic(doc, def, err))
+		}
+	}
+
+	return compiled, diagnostics
+}
+
+// compilerDiagnostic returns the diagnostic for the error returned by the compiler. Errors
+// found in imported files are reported at the start of the document.
+func compilerDiagnostic(doc *document, err error) lsp.Diagnostic {
+	diagnostic := lsp.Diagnostic{
+		Severity: lsp.Error,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}
+
+	var contextError compiler.ErrorWithContext
+	if !errors.As(err, &contextError) || contextError.Source != doc.source() {
+		return diagnostic
+	}
+
+	startLine, startColumn, serr := contextError.SourceRange.Start().LineAndColumn()
+	if serr != nil {
+		return diagnostic
+	}
+
+	start := offsetForLineAndColumn(doc.text, startLine, startColumn)
+	end := start + len(contextError.ErrorSourceCode)
+	if endLine, endColumn, eerr := contextError.SourceRange.End().LineAndColumn(); eerr == nil {
+		if rangeEnd := offsetForLineAndColumn(doc.text, endLine, endColumn); rangeEnd > end {
+			end = rangeEnd
+		}
+	}
+
+	diagnostic.Message = contextError.BaseMessage
+	diagnostic.Range = rangeForSpan(doc.text, span{start, end})
+	return diagnostic
+}
+
+// validationDiagnostic returns the diagnostic for the error found when validating the
+// definition. Errors without a position are reported on the name of the definition.
+func validationDiagnostic(doc *document, def *indexedDefinition, err error) lsp.Diagnostic {
+	diagnosticSpan := def.nameSpan
+	if errWithSource, ok := spiceerrors.AsErrorWithSource(err); ok && errWithSource.LineNumber > 0 {
+		start := offsetForLineAndColumn(doc.text, int(errWithSource.LineNumber)-1, int(errWithSource.ColumnPosition)-1)
+		diagnosticSpan = span{start, start + len(errWithSource.SourceCodeString)}
+	}
+
+	return lsp.Diagnostic{
+		Range:    rangeForSpan(doc.text, diagnosticSpan),
+		Severity: lsp.Error,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}
+}
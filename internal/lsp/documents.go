@@ -0,0 +1,182 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sourcegraph/go-lsp"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// document is a schema file opened in the editor.
+type document struct {
+	uri   lsp.DocumentURI
+	text  string
+	index *schemaIndex
+
+	// compiled is the result of the last successful compilation of the document, if any.
+	compiled *compiler.CompiledSchema
+}
+
+// source returns the source of the document given to the compiler, which is its path for
+// files, so that its imports are resolved against its directory.
+func (doc *document) source() input.Source {
+	return sourceForURI(doc.uri)
+}
+
+func sourceForURI(uri lsp.DocumentURI) input.Source {
+	parsed, err := url.Parse(string(uri))
+	if err != nil || parsed.Scheme != "file" {
+		return input.Source(uri)
+	}
+	return input.Source(filepath.FromSlash(parsed.Path))
+}
+
+func uriForSource(source input.Source) lsp.DocumentURI {
+	if !filepath.IsAbs(string(source)) {
+		return lsp.DocumentURI(source)
+	}
+	return lsp.DocumentURI((&url.URL{Scheme: "file", Path: filepath.ToSlash(string(source))}).String())
+}
+
+// schemaFile is a schema file of the workspace of a document: the document itself, or a file
+// it imports directly or indirectly.
+type schemaFile struct {
+	uri   lsp.DocumentURI
+	text  string
+	index *schemaIndex
+}
+
+// workspace returns the document followed by the files it imports, as read by the resolver.
+// Files which cannot be read are skipped.
+func workspace(doc *document, resolver compiler.ImportResolver) []schemaFile {
+	files := []schemaFile{{doc.uri, doc.text, doc.index}}
+	seen := map[input.Source]struct{}{doc.source(): {}}
+
+	sources := []input.Source{doc.source()}
+	for fileIndex := 0; fileIndex < len(files); fileIndex++ {
+		for _, importPath := range files[fileIndex].index.imports {
+			imported, err := resolver.ResolveImport(sources[fileIndex], importPath)
+			if err != nil {
+				continue
+			}
+
+			if _, ok := seen[imported.Source]; ok {
+				continue
+			}
+			seen[imported.Source] = struct{}{}
+
+			sources = append(sources, imported.Source)
+			files = append(files, schemaFile{
+				uri:   uriForSource(imported.Source),
+				text:  imported.SchemaString,
+				index: indexSchema(imported.SchemaString),
+			})
+		}
+	}
+	return files
+}
+
+// documentImportResolver resolves imports to the documents open in the editor, whose contents
+// may not have been saved, and otherwise to the filesystem.
+type documentImportResolver struct {
+	documents map[lsp.DocumentURI]*document
+}
+
+// ResolveImport implements compiler.ImportResolver.
+func (dir documentImportResolver) ResolveImport(importingSource input.Source, importPath string) (compiler.InputSchema, error) {
+	resolved := filepath.FromSlash(importPath)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(string(importingSource)), resolved)
+	}
+
+	if doc, ok := dir.documents[uriForSource(input.Source(resolved))]; ok {
+		return compiler.InputSchema{
+			Source:       input.Source(filepath.Clean(resolved)),
+			SchemaString: doc.text,
+		}, nil
+	}
+
+	return compiler.FilesystemImportResolver{}.ResolveImport(importingSource, importPath)
+}
+
+// offsetForPosition returns the byte offset in the text of the position, whose character is
+// counted in UTF-16 code units as in the protocol.
+func offsetForPosition(text string, position lsp.Position) int {
+	offset := 0
+	for line := 0; line < position.Line; line++ {
+		newline := strings.IndexByte(text[offset:], '\n')
+		if newline < 0 {
+			return len(text)
+		}
+		offset += newline + 1
+	}
+
+	for units := 0; units < position.Character && offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' {
+			break
+		}
+		offset += size
+		units += utf16Length(r)
+	}
+	return offset
+}
+
+// positionForOffset returns the position of the byte offset in the text.
+func positionForOffset(text string, offset int) lsp.Position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	character := 0
+	for _, r := range text[lineStart:offset] {
+		character += utf16Length(r)
+	}
+
+	return lsp.Position{
+		Line:      strings.Count(text[:offset], "\n"),
+		Character: character,
+	}
+}
+
+// offsetForLineAndColumn returns the byte offset in the text of the zero-indexed line and
+// byte column, as found in compiler errors and source positions.
+func offsetForLineAndColumn(text string, line int, column int) int {
+	offset := 0
+	for current := 0; current < line; current++ {
+		newline := strings.IndexByte(text[offset:], '\n')
+		if newline < 0 {
+			return len(text)
+		}
+		offset += newline + 1
+	}
+
+	lineEnd := strings.IndexByte(text[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(text) - offset
+	}
+	if column > lineEnd {
+		column = lineEnd
+	}
+	return offset + column
+}
+
+func rangeForSpan(text string, s span) lsp.Range {
+	return lsp.Range{
+		Start: positionForOffset(text, s.start),
+		End:   positionForOffset(text, s.end),
+	}
+}
+
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
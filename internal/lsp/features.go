@@ -0,0 +1,319 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/go-lsp"
+
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// target is a declaration found in the workspace of a document: a definition or caveat, or a
+// relation or permission of a definition when relation is set.
+type target struct {
+	file       schemaFile
+	definition *indexedDefinition
+	relation   *indexedRelation
+}
+
+func (t target) nameSpan() span {
+	if t.relation != nil {
+		return t.relation.nameSpan
+	}
+	return t.definition.nameSpan
+}
+
+func (t target) declaration() span {
+	if t.relation != nil {
+		return t.relation.declaration
+	}
+	return t.definition.declaration
+}
+
+func lookupDefinition(files []schemaFile, name string, isCaveat bool) (target, bool) {
+	for _, file := range files {
+		if def := file.index.definition(name); def != nil && def.isCaveat == isCaveat {
+			return target{file: file, definition: def}, true
+		}
+	}
+	return target{}, false
+}
+
+func lookupRelation(files []schemaFile, definitionName string, relationName string) (target, bool) {
+	found, ok := lookupDefinition(files, definitionName, false)
+	if !ok {
+		return target{}, false
+	}
+
+	found.relation = found.definition.relation(relationName)
+	return found, found.relation != nil
+}
+
+// resolveReference returns the declarations the reference refers to. Arrows refer to the
+// relation on each subject type of their tupleset relation which has it.
+func resolveReference(files []schemaFile, ref reference) []target {
+	var found target
+	var ok bool
+
+	switch ref.kind {
+	case referenceType:
+		found, ok = lookupDefinition(files, ref.name, false)
+
+	case referenceCaveat:
+		found, ok = lookupDefinition(files, ref.name, true)
+
+	case referenceRelation:
+		found, ok = lookupRelation(files, ref.definitionName, ref.name)
+
+	case referenceArrow:
+		tupleset, ok := lookupRelation(files, ref.definitionName, ref.tuplesetRelation)
+		if !ok {
+			return nil
+		}
+
+		var targets []target
+		for _, allowedType := range tupleset.relation.allowedTypes {
+			if found, ok := lookupRelation(files, allowedType, ref.name); ok {
+				targets = append(targets, found)
+			}
+		}
+		return targets
+	}
+
+	if !ok {
+		return nil
+	}
+	return []target{found}
+}
+
+// declarationAt returns the declaration whose name is at the offset in the document, if any.
+func declarationAt(files []schemaFile, offset int) (target, bool) {
+	for _, def := range files[0].index.definitions {
+		if def.nameSpan.contains(offset) {
+			return target{file: files[0], definition: def}, true
+		}
+
+		for _, rel := range def.relations {
+			if rel.nameSpan.contains(offset) {
+				return target{file: files[0], definition: def, relation: rel}, true
+			}
+		}
+	}
+	return target{}, false
+}
+
+// definition returns the locations of the declarations referred to at the offset in the
+// document.
+func definition(doc *document, offset int, files []schemaFile) []lsp.Location {
+	locations := []lsp.Location{}
+
+	ref, ok := doc.index.referenceAt(offset)
+	if !ok {
+		return locations
+	}
+
+	for _, found := range resolveReference(files, ref) {
+		locations = append(locations, lsp.Location{
+			URI:   found.file.uri,
+			Range: rangeForSpan(found.file.text, found.nameSpan()),
+		})
+	}
+	return locations
+}
+
+// hover returns the declarations referred to or declared at the offset in the document, along
+// with their comments.
+func hover(doc *document, offset int, files []schemaFile) *lsp.Hover {
+	var targets []target
+	var hoverSpan span
+	if ref, ok := doc.index.referenceAt(offset); ok {
+		targets = resolveReference(files, ref)
+		hoverSpan = ref.span
+	} else if found, ok := declarationAt(files, offset); ok {
+		targets = []target{found}
+		hoverSpan = found.nameSpan()
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	contents := make([]lsp.MarkedString, 0, len(targets))
+	for _, found := range targets {
+		declaration := found.declaration()
+		lines := append(comments(doc.compiled, found), found.file.text[declaration.start:declaration.end])
+		contents = append(contents, lsp.MarkedString{
+			Language: "zed",
+			Value:    strings.Join(lines, "\n"),
+		})
+	}
+
+	hoverRange := rangeForSpan(doc.text, hoverSpan)
+	return &lsp.Hover{
+		Contents: contents,
+		Range:    &hoverRange,
+	}
+}
+
+// comments returns the doc comments of the declaration, as found in the metadata of the last
+// successful compilation of the document.
+func comments(compiled *compiler.CompiledSchema, found target) []string {
+	if compiled == nil {
+		return nil
+	}
+
+	for _, def := range compiled.OrderedDefinitions {
+		if def.GetName() != found.definition.name {
+			continue
+		}
+
+		switch def := def.(type) {
+		case *core.CaveatDefinition:
+			return namespace.GetComments(def.Metadata)
+
+		case *core.NamespaceDefinition:
+			if found.relation == nil {
+				return namespace.GetComments(def.Metadata)
+			}
+
+			for _, rel := range def.Relation {
+				if rel.Name == found.relation.name {
+					return namespace.GetComments(rel.Metadata)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// completion returns the names which can be written at the offset in the document: those of
+// definitions where a type is expected, caveats after `with`, and relations and permissions of
+// the definition referred to otherwise.
+func completion(doc *document, offset int, files []schemaFile) lsp.CompletionList {
+	list := lsp.CompletionList{Items: []lsp.CompletionItem{}}
+
+	// The name being written, if any, is completed as a whole.
+	start := offset
+	for start > 0 && isIdentifierByte(doc.text[start-1]) {
+		start--
+	}
+
+	expected, ok := expectedReferenceAt(doc.text, start)
+	if !ok {
+		return list
+	}
+
+	seen := map[string]struct{}{}
+	add := func(item lsp.CompletionItem) {
+		if _, ok := seen[item.Label]; ok {
+			return
+		}
+		seen[item.Label] = struct{}{}
+		list.Items = append(list.Items, item)
+	}
+
+	addRelations := func(def *indexedDefinition) {
+		for _, rel := range def.relations {
+			item := lsp.CompletionItem{Label: rel.name, Kind: lsp.CIKField, Detail: "relation"}
+			if rel.isPermission {
+				item.Kind = lsp.CIKProperty
+				item.Detail = "permission"
+			}
+			add(item)
+		}
+	}
+
+	switch expected.kind {
+	case referenceType, referenceCaveat:
+		isCaveat := expected.kind == referenceCaveat
+		for _, file := range files {
+			for _, def := range file.index.definitions {
+				if def.isCaveat != isCaveat {
+					continue
+				}
+
+				item := lsp.CompletionItem{Label: def.name, Kind: lsp.CIKClass, Detail: "definition"}
+				if isCaveat {
+					item.Kind = lsp.CIKFunction
+					item.Detail = "caveat"
+				}
+				add(item)
+			}
+		}
+
+	case referenceRelation:
+		if found, ok := lookupDefinition(files, expected.definitionName, false); ok {
+			addRelations(found.definition)
+		}
+
+	case referenceArrow:
+		if tupleset, ok := lookupRelation(files, expected.definitionName, expected.tuplesetRelation); ok {
+			for _, allowedType := range tupleset.relation.allowedTypes {
+				if found, ok := lookupDefinition(files, allowedType, false); ok {
+					addRelations(found.definition)
+				}
+			}
+		}
+	}
+
+	return list
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// formatting returns the edit replacing the document with its formatted schema. Documents which
+// do not compile are left as is.
+func formatting(doc *document) ([]lsp.TextEdit, error) {
+	edits := []lsp.TextEdit{}
+
+	// Imports are not resolved, as only the definitions of the document are formatted.
+	empty := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       doc.source(),
+		SchemaString: doc.text,
+	}, &empty, compiler.WithImportResolver(emptyImportResolver{}))
+	if err != nil {
+		return edits, nil
+	}
+
+	formatted, _, err := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if err != nil {
+		return nil, err
+	}
+
+	var imports strings.Builder
+	for _, importPath := range doc.index.imports {
+		fmt.Fprintf(&imports, "import %q\n", importPath)
+	}
+	if imports.Len() > 0 {
+		imports.WriteString("\n")
+	}
+
+	formatted = imports.String() + formatted + "\n"
+	if formatted == doc.text {
+		return edits, nil
+	}
+
+	return append(edits, lsp.TextEdit{
+		Range: lsp.Range{
+			Start: lsp.Position{},
+			End:   positionForOffset(doc.text, len(doc.text)),
+		},
+		NewText: formatted,
+	}), nil
+}
+
+// emptyImportResolver resolves every import to an empty schema.
+type emptyImportResolver struct{}
+
+// ResolveImport implements compiler.ImportResolver.
+func (emptyImportResolver) ResolveImport(_ input.Source, importPath string) (compiler.InputSchema, error) {
+	return compiler.InputSchema{Source: input.Source(importPath)}, nil
+}
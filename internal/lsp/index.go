@@ -0,0 +1,398 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemadsl/lexer"
+)
+
+// span is a range of byte offsets into the text of a schema file.
+type span struct {
+	start int
+	end   int
+}
+
+// contains returns whether the offset is within the span, including its end, so that a
+// cursor placed just after a name is found on it.
+func (s span) contains(offset int) bool {
+	return offset >= s.start && offset <= s.end
+}
+
+type referenceKind int
+
+const (
+	// referenceType is a reference to an object definition, as an allowed type of a relation.
+	referenceType referenceKind = iota
+
+	// referenceCaveat is a reference to a caveat, as an allowed type of a relation.
+	referenceCaveat
+
+	// referenceRelation is a reference to a relation or permission of a definition.
+	referenceRelation
+
+	// referenceArrow is a reference to a relation or permission of the subject types of the
+	// tupleset relation of an arrow.
+	referenceArrow
+)
+
+// reference is a name found in a schema which refers to a declaration.
+type reference struct {
+	kind referenceKind
+	name string
+	span span
+
+	// definitionName is the definition holding the relation, for relation references, and
+	// the definition holding the tupleset relation, for arrow references.
+	definitionName string
+
+	// tuplesetRelation is the relation on the left of the arrow, for arrow references.
+	tuplesetRelation string
+}
+
+// indexedRelation is a relation or permission declared in a schema.
+type indexedRelation struct {
+	name         string
+	isPermission bool
+	nameSpan     span
+	declaration  span
+
+	// allowedTypes holds the names of the definitions allowed on a relation.
+	allowedTypes []string
+}
+
+// indexedDefinition is an object definition or a caveat declared in a schema.
+type indexedDefinition struct {
+	name        string
+	isCaveat    bool
+	nameSpan    span
+	declaration span
+	relations   []*indexedRelation
+}
+
+func (def *indexedDefinition) relation(name string) *indexedRelation {
+	for _, rel := range def.relations {
+		if rel.name == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+// schemaIndex holds the declarations, references and imports found in a schema. It is built
+// from the tokens of the schema alone, so that it is available while the schema is being
+// edited and does not compile.
+type schemaIndex struct {
+	definitions []*indexedDefinition
+	references  []reference
+	imports     []string
+}
+
+func (idx *schemaIndex) definition(name string) *indexedDefinition {
+	for _, def := range idx.definitions {
+		if def.name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// referenceAt returns the reference found at the offset, if any.
+func (idx *schemaIndex) referenceAt(offset int) (reference, bool) {
+	for _, ref := range idx.references {
+		if ref.span.contains(offset) {
+			return ref, true
+		}
+	}
+	return reference{}, false
+}
+
+// indexSchema returns the index of the schema.
+func indexSchema(schema string) *schemaIndex {
+	idx := &schemaIndex{}
+	sc := &scanner{index: idx}
+	for _, token := range significantTokens(schema) {
+		sc.consume(token)
+	}
+	return idx
+}
+
+// significantTokens returns the tokens of the schema, without whitespace and comments. Lexing
+// stops at the first error.
+func significantTokens(schema string) []lexer.Lexeme {
+	lx := lexer.NewPeekableLexer(lexer.Lex(input.Source("schema"), schema))
+	defer lx.Close()
+
+	var tokens []lexer.Lexeme
+	for {
+		token := lx.NextToken()
+		switch token.Kind {
+		case lexer.TokenTypeEOF, lexer.TokenTypeError:
+			return tokens
+
+		case lexer.TokenTypeWhitespace, lexer.TokenTypeNewline, lexer.TokenTypeSyntheticSemicolon,
+			lexer.TokenTypeSinglelineComment, lexer.TokenTypeMultilineComment:
+			continue
+
+		default:
+			tokens = append(tokens, token)
+		}
+	}
+}
+
+type pendingName int
+
+const (
+	pendingNone pendingName = iota
+	pendingImport
+	pendingDefinition
+	pendingCaveat
+	pendingRelation
+	pendingPermission
+)
+
+// scanner follows the structure of a schema, token by token, adding what it finds to the
+// index.
+type scanner struct {
+	index *schemaIndex
+	depth int
+
+	// pending is the kind of name expected next, after a keyword.
+	pending      pendingName
+	keywordStart int
+
+	// definition is the definition whose name or body is being scanned.
+	definition *indexedDefinition
+
+	// relation is the relation or permission whose statement is being scanned, and
+	// inExpression whether its permission expression has started.
+	relation     *indexedRelation
+	inExpression bool
+
+	// statement holds the tokens of the statement being scanned, after its name.
+	statement []lexer.Lexeme
+}
+
+func (sc *scanner) consume(token lexer.Lexeme) {
+	end := int(token.Position) + len(token.Value)
+
+	switch {
+	case token.Kind == lexer.TokenTypeLeftBrace:
+		sc.depth++
+		sc.pending = pendingNone
+		sc.relation = nil
+		return
+
+	case token.Kind == lexer.TokenTypeRightBrace:
+		sc.depth--
+		sc.relation = nil
+		if sc.depth <= 0 {
+			sc.depth = 0
+			sc.definition = nil
+		}
+		return
+
+	case sc.depth == 0 && token.Kind == lexer.TokenTypeKeyword && (token.Value == "definition" || token.Value == "caveat"):
+		sc.pending = pendingDefinition
+		if token.Value == "caveat" {
+			sc.pending = pendingCaveat
+		}
+		sc.keywordStart = int(token.Position)
+		sc.definition = nil
+		return
+
+	case sc.depth == 0 && token.Kind == lexer.TokenTypeIdentifier && token.Value == "import" && sc.definition == nil:
+		sc.pending = pendingImport
+		return
+
+	case sc.depth == 1 && sc.definition != nil && !sc.definition.isCaveat && token.Kind == lexer.TokenTypeKeyword &&
+		(token.Value == "relation" || token.Value == "permission"):
+		sc.pending = pendingRelation
+		if token.Value == "permission" {
+			sc.pending = pendingPermission
+		}
+		sc.keywordStart = int(token.Position)
+		sc.relation = nil
+		return
+	}
+
+	switch sc.pending {
+	case pendingImport:
+		sc.pending = pendingNone
+		if token.Kind == lexer.TokenTypeString {
+			sc.index.imports = append(sc.index.imports, strings.Trim(token.Value, `"'`))
+		}
+		return
+
+	case pendingDefinition, pendingCaveat:
+		isCaveat := sc.pending == pendingCaveat
+		sc.pending = pendingNone
+		if token.Kind == lexer.TokenTypeIdentifier {
+			sc.definition = &indexedDefinition{
+				name:        token.Value,
+				isCaveat:    isCaveat,
+				nameSpan:    span{int(token.Position), end},
+				declaration: span{sc.keywordStart, end},
+			}
+			sc.index.definitions = append(sc.index.definitions, sc.definition)
+			sc.statement = nil
+		}
+		return
+
+	case pendingRelation, pendingPermission:
+		isPermission := sc.pending == pendingPermission
+		sc.pending = pendingNone
+		if token.Kind == lexer.TokenTypeIdentifier {
+			sc.relation = &indexedRelation{
+				name:         token.Value,
+				isPermission: isPermission,
+				nameSpan:     span{int(token.Position), end},
+				declaration:  span{sc.keywordStart, end},
+			}
+			sc.definition.relations = append(sc.definition.relations, sc.relation)
+			sc.inExpression = false
+			sc.statement = nil
+		}
+		return
+	}
+
+	// A prefixed definition name, such as `tenant/user`, is lexed as two identifiers around a
+	// slash.
+	if sc.depth == 0 && sc.definition != nil {
+		if sc.extendName(token, &sc.definition.name, &sc.definition.nameSpan) {
+			sc.definition.declaration.end = sc.definition.nameSpan.end
+		}
+		sc.statement = append(sc.statement, token)
+		return
+	}
+
+	if sc.relation == nil {
+		return
+	}
+
+	sc.relation.declaration.end = end
+	if token.Kind == lexer.TokenTypeEquals {
+		sc.inExpression = true
+	}
+
+	if token.Kind == lexer.TokenTypeIdentifier {
+		sc.addReference(token)
+	}
+	sc.statement = append(sc.statement, token)
+}
+
+// extendName appends the identifier to the name, if it follows a slash directly after it.
+func (sc *scanner) extendName(token lexer.Lexeme, name *string, nameSpan *span) bool {
+	if token.Kind != lexer.TokenTypeIdentifier || len(sc.statement) == 0 {
+		return false
+	}
+
+	slash := sc.statement[len(sc.statement)-1]
+	if slash.Kind != lexer.TokenTypeDiv || int(slash.Position) != nameSpan.end || int(token.Position) != nameSpan.end+1 {
+		return false
+	}
+
+	*name += "/" + token.Value
+	nameSpan.end = int(token.Position) + len(token.Value)
+	return true
+}
+
+// addReference adds the reference made by the identifier, if any, to the index.
+func (sc *scanner) addReference(token lexer.Lexeme) {
+	tokenSpan := span{int(token.Position), int(token.Position) + len(token.Value)}
+
+	// Prefixed type names continue the previous type reference.
+	refs := sc.index.references
+	if len(refs) > 0 && refs[len(refs)-1].kind == referenceType {
+		last := &refs[len(refs)-1]
+		if sc.extendName(token, &last.name, &last.span) {
+			sc.relation.allowedTypes[len(sc.relation.allowedTypes)-1] = last.name
+			return
+		}
+	}
+
+	ref, ok := sc.expectedReference()
+	if !ok {
+		return
+	}
+
+	ref.name = token.Value
+	ref.span = tokenSpan
+	if ref.kind == referenceType {
+		sc.relation.allowedTypes = append(sc.relation.allowedTypes, ref.name)
+	}
+	sc.index.references = append(sc.index.references, ref)
+}
+
+// expectedReference returns the kind of reference an identifier following the tokens scanned
+// so far would make, if any.
+func (sc *scanner) expectedReference() (reference, bool) {
+	if sc.relation == nil || sc.definition == nil {
+		return reference{}, false
+	}
+
+	previous := func(index int) lexer.Lexeme {
+		if index > len(sc.statement) {
+			return lexer.Lexeme{}
+		}
+		return sc.statement[len(sc.statement)-index]
+	}
+
+	if !sc.inExpression {
+		switch {
+		case previous(1).Kind == lexer.TokenTypeColon, previous(1).Kind == lexer.TokenTypePipe:
+			return reference{kind: referenceType}, true
+
+		case previous(1).Kind == lexer.TokenTypeKeyword && previous(1).Value == "with":
+			return reference{kind: referenceCaveat}, true
+
+		case previous(1).Kind == lexer.TokenTypeHash:
+			typeRefs := sc.relation.allowedTypes
+			if len(typeRefs) == 0 {
+				return reference{}, false
+			}
+			return reference{kind: referenceRelation, definitionName: typeRefs[len(typeRefs)-1]}, true
+
+		default:
+			return reference{}, false
+		}
+	}
+
+	switch {
+	case previous(1).Kind == lexer.TokenTypeRightArrow && previous(2).Kind == lexer.TokenTypeIdentifier:
+		return reference{
+			kind:             referenceArrow,
+			definitionName:   sc.definition.name,
+			tuplesetRelation: previous(2).Value,
+		}, true
+
+	case previous(1).Kind == lexer.TokenTypeLeftParen && previous(2).Kind == lexer.TokenTypeIdentifier &&
+		(previous(2).Value == "any" || previous(2).Value == "all") &&
+		previous(3).Kind == lexer.TokenTypePeriod && previous(4).Kind == lexer.TokenTypeIdentifier:
+		return reference{
+			kind:             referenceArrow,
+			definitionName:   sc.definition.name,
+			tuplesetRelation: previous(4).Value,
+		}, true
+
+	case previous(1).Kind == lexer.TokenTypePeriod:
+		// The function of an arrow, such as `any` or `all`.
+		return reference{}, false
+
+	default:
+		return reference{kind: referenceRelation, definitionName: sc.definition.name}, true
+	}
+}
+
+// expectedReferenceAt returns the kind of reference an identifier starting at the offset
+// would make, if any.
+func expectedReferenceAt(schema string, offset int) (reference, bool) {
+	sc := &scanner{index: &schemaIndex{}}
+	for _, token := range significantTokens(schema) {
+		if int(token.Position) >= offset {
+			break
+		}
+		sc.consume(token)
+	}
+	return sc.expectedReference()
+}
@@ -0,0 +1,254 @@
+// Package lsp implements a language server for schemas, speaking the Language Server Protocol
+// over a stream such as stdio.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Server is a language server for schema files. Each document is compiled along with the files
+// it imports, which are read from the documents open in the editor or from the filesystem.
+type Server struct {
+	mu        sync.Mutex
+	documents map[lsp.DocumentURI]*document
+}
+
+// NewServer creates a new language server.
+func NewServer() *Server {
+	return &Server{
+		documents: map[lsp.DocumentURI]*document{},
+	}
+}
+
+// Run serves the protocol over the reader and writer, until the client exits, the stream is
+// closed or the context is canceled.
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	stream := jsonrpc2.NewBufferedStream(readWriteCloser{r, w}, jsonrpc2.VSCodeObjectCodec{})
+	conn := jsonrpc2.NewConn(ctx, stream, jsonrpc2.HandlerWithError(s.handle))
+
+	select {
+	case <-ctx.Done():
+		if err := conn.Close(); err != nil && err != jsonrpc2.ErrClosed {
+			return err
+		}
+		return ctx.Err()
+
+	case <-conn.DisconnectNotify():
+		return nil
+	}
+}
+
+type readWriteCloser struct {
+	io.Reader
+	io.Writer
+}
+
+// Close implements io.Closer. The reader and writer are owned by the caller of Run.
+func (readWriteCloser) Close() error {
+	return nil
+}
+
+func (s *Server) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Method {
+	case "initialize":
+		return lsp.InitializeResult{
+			Capabilities: lsp.ServerCapabilities{
+				TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
+					Options: &lsp.TextDocumentSyncOptions{
+						OpenClose: true,
+						Change:    lsp.TDSKFull,
+					},
+				},
+				HoverProvider: true,
+				CompletionProvider: &lsp.CompletionOptions{
+					TriggerCharacters: []string{"#", ">", "(", ":", "|"},
+				},
+				DefinitionProvider:         true,
+				DocumentFormattingProvider: true,
+			},
+		}, nil
+
+	case "initialized", "shutdown":
+		return nil, nil
+
+	case "exit":
+		return nil, conn.Close()
+
+	case "textDocument/didOpen":
+		var params lsp.DidOpenTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.updateDocument(ctx, conn, params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params lsp.DidChangeTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+
+		// Documents are synchronized in full, so the last change holds the whole text.
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.updateDocument(ctx, conn, params.TextDocument.URI, text)
+
+	case "textDocument/didClose":
+		var params lsp.DidCloseTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		delete(s.documents, params.TextDocument.URI)
+		if err := publishDiagnostics(ctx, conn, params.TextDocument.URI, nil); err != nil {
+			return nil, err
+		}
+		return nil, s.refreshDiagnostics(ctx, conn)
+
+	case "textDocument/definition":
+		var params lsp.TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return definition(doc, offsetForPosition(doc.text, params.Position), s.workspace(doc)), nil
+
+	case "textDocument/hover":
+		var params lsp.TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return hover(doc, offsetForPosition(doc.text, params.Position), s.workspace(doc)), nil
+
+	case "textDocument/completion":
+		var params lsp.CompletionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return completion(doc, offsetForPosition(doc.text, params.Position), s.workspace(doc)), nil
+
+	case "textDocument/formatting":
+		var params lsp.DocumentFormattingParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return formatting(doc)
+	}
+
+	if req.Notif {
+		// Notifications, such as `$/cancelRequest`, need no reply and are ignored.
+		return nil, nil
+	}
+
+	return nil, &jsonrpc2.Error{
+		Code:    jsonrpc2.CodeMethodNotFound,
+		Message: fmt.Sprintf("method not supported: %s", req.Method),
+	}
+}
+
+func unmarshalParams(req *jsonrpc2.Request, params interface{}) error {
+	if req.Params == nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "missing params"}
+	}
+
+	if err := json.Unmarshal(*req.Params, params); err != nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) document(uri lsp.DocumentURI) (*document, error) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("document not open: %s", uri),
+		}
+	}
+	return doc, nil
+}
+
+func (s *Server) resolver() documentImportResolver {
+	return documentImportResolver{documents: s.documents}
+}
+
+func (s *Server) workspace(doc *document) []schemaFile {
+	return workspace(doc, s.resolver())
+}
+
+// updateDocument sets the text of the document, then publishes the diagnostics of the open
+// documents, as those importing the document may be affected by the change.
+func (s *Server) updateDocument(ctx context.Context, conn *jsonrpc2.Conn, uri lsp.DocumentURI, text string) error {
+	previous := s.documents[uri]
+	doc := &document{
+		uri:   uri,
+		text:  text,
+		index: indexSchema(text),
+	}
+	if previous != nil {
+		doc.compiled = previous.compiled
+	}
+
+	s.documents[uri] = doc
+	return s.refreshDiagnostics(ctx, conn)
+}
+
+func (s *Server) refreshDiagnostics(ctx context.Context, conn *jsonrpc2.Conn) error {
+	resolver := s.resolver()
+	for uri, doc := range s.documents {
+		compiled, diagnostics := compileDocument(ctx, doc, resolver)
+		if compiled != nil {
+			doc.compiled = compiled
+		}
+
+		if err := publishDiagnostics(ctx, conn, uri, diagnostics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishDiagnostics(ctx context.Context, conn *jsonrpc2.Conn, uri lsp.DocumentURI, diagnostics []lsp.Diagnostic) error {
+	if diagnostics == nil {
+		// Clients expect a list, even when there is nothing to report.
+		diagnostics = []lsp.Diagnostic{}
+	}
+
+	log.Ctx(ctx).Trace().Str("uri", string(uri)).Int("diagnostics", len(diagnostics)).Msg("publishing diagnostics")
+	return conn.Notify(ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
@@ -0,0 +1,438 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `caveat only_on_tuesday(day_of_week string) {
+	day_of_week == 'tuesday'
+}
+
+/** user is a user of the system */
+definition user {}
+
+definition org/team {
+	// member is a member of the team
+	relation member: user
+	relation admin: user
+}
+
+definition document {
+	// viewer can view the document
+	relation viewer: user | org/team#member | user with only_on_tuesday
+	relation team: org/team
+	permission view = viewer + team->member + team.all(admin)
+}
+`
+
+type testClient struct {
+	t           *testing.T
+	conn        *jsonrpc2.Conn
+	diagnostics chan lsp.PublishDiagnosticsParams
+}
+
+// newTestClient runs a server over an in-memory connection and initializes it.
+func newTestClient(t *testing.T) *testClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	serverConn, clientConn := net.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- NewServer().Run(ctx, serverConn, serverConn)
+	}()
+
+	client := &testClient{
+		t:           t,
+		diagnostics: make(chan lsp.PublishDiagnosticsParams, 100),
+	}
+	client.conn = jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(clientConn, jsonrpc2.VSCodeObjectCodec{}),
+		jsonrpc2.HandlerWithError(func(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			if req.Method == "textDocument/publishDiagnostics" {
+				var params lsp.PublishDiagnosticsParams
+				if err := json.Unmarshal(*req.Params, &params); err != nil {
+					return nil, err
+				}
+				client.diagnostics <- params
+			}
+			return nil, nil
+		}))
+
+	t.Cleanup(func() {
+		require.NoError(t, client.conn.Notify(ctx, "exit", nil))
+		require.NoError(t, <-served)
+		cancel()
+		require.NoError(t, client.conn.Close())
+		require.NoError(t, clientConn.Close())
+	})
+
+	var result lsp.InitializeResult
+	client.call("initialize", lsp.InitializeParams{}, &result)
+	require.True(t, result.Capabilities.DefinitionProvider)
+	require.True(t, result.Capabilities.HoverProvider)
+	require.True(t, result.Capabilities.DocumentFormattingProvider)
+	require.NotNil(t, result.Capabilities.CompletionProvider)
+
+	return client
+}
+
+func (c *testClient) call(method string, params interface{}, result interface{}) {
+	require.NoError(c.t, c.conn.Call(context.Background(), method, params, result))
+}
+
+func (c *testClient) open(uri lsp.DocumentURI, text string) {
+	require.NoError(c.t, c.conn.Notify(context.Background(), "textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "zed", Text: text},
+	}))
+}
+
+func (c *testClient) change(uri lsp.DocumentURI, text string) {
+	require.NoError(c.t, c.conn.Notify(context.Background(), "textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: uri}},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: text}},
+	}))
+}
+
+// waitForDiagnostics returns the next diagnostics published for the document.
+func (c *testClient) waitForDiagnostics(uri lsp.DocumentURI) []lsp.Diagnostic {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case params := <-c.diagnostics:
+			if params.URI == uri {
+				return params.Diagnostics
+			}
+
+		case <-timeout:
+			require.FailNow(c.t, "timed out waiting for diagnostics", uri)
+		}
+	}
+}
+
+func positionParams(uri lsp.DocumentURI, position lsp.Position) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     position,
+	}
+}
+
+// after returns the position just after the first occurrence of the text in the schema.
+func after(t *testing.T, schema string, text string) lsp.Position {
+	index := strings.Index(schema, text)
+	require.GreaterOrEqual(t, index, 0, "missing %q", text)
+	return positionForOffset(schema, index+len(text))
+}
+
+// nameRange returns the range of the name within the first occurrence of the declaration in
+// the schema.
+func nameRange(t *testing.T, schema string, declaration string, name string) lsp.Range {
+	index := strings.Index(schema, declaration)
+	require.GreaterOrEqual(t, index, 0, "missing %q", declaration)
+	start := index + strings.Index(declaration, name)
+	return rangeForSpan(schema, span{start, start + len(name)})
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name            string
+		schema          string
+		expectedMessage string
+		expectedRange   lsp.Range
+	}{
+		{
+			"valid schema",
+			testSchema,
+			"",
+			lsp.Range{},
+		},
+		{
+			"parse error",
+			"definition user {}\n\ndefinition document {\n\trelation viewer: user +\n}",
+			"Expected end of statement or definition, found: TokenTypePlus",
+			lsp.Range{Start: lsp.Position{Line: 3, Character: 23}, End: lsp.Position{Line: 3, Character: 24}},
+		},
+		{
+			"unknown type",
+			"definition document {\n\trelation viewer: user\n}",
+			"could not lookup definition `user` for relation `viewer`: object definition `user` not found",
+			lsp.Range{Start: lsp.Position{Line: 1, Character: 18}, End: lsp.Position{Line: 1, Character: 22}},
+		},
+		{
+			"unknown relation",
+			"definition user {}\n\ndefinition document {\n\tpermission view = viewer\n}",
+			"relation/permission `viewer` not found under definition `document`",
+			lsp.Range{Start: lsp.Position{Line: 3, Character: 19}, End: lsp.Position{Line: 3, Character: 25}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t)
+			uri := lsp.DocumentURI("file:///schema.zed")
+
+			client.open(uri, tt.schema)
+			diagnostics := client.waitForDiagnostics(uri)
+			if tt.expectedMessage == "" {
+				require.Empty(t, diagnostics)
+				return
+			}
+
+			require.Len(t, diagnostics, 1)
+			require.Equal(t, tt.expectedMessage, diagnostics[0].Message)
+			require.Equal(t, tt.expectedRange, diagnostics[0].Range)
+			require.Equal(t, lsp.Error, diagnostics[0].Severity)
+
+			// Fixing the schema clears the diagnostics.
+			client.change(uri, testSchema)
+			require.Empty(t, client.waitForDiagnostics(uri))
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		expected []lsp.Range
+	}{
+		{"type", "relation admin: us", []lsp.Range{nameRange(t, testSchema, "definition user", "user")}},
+		{"prefixed type", "relation team: org/te", []lsp.Range{nameRange(t, testSchema, "definition org/team", "org/team")}},
+		{"prefix of prefixed type", "relation team: o", []lsp.Range{nameRange(t, testSchema, "definition org/team", "org/team")}},
+		{"subject relation", "org/team#mem", []lsp.Range{nameRange(t, testSchema, "relation member", "member")}},
+		{"caveat", "with only_on", []lsp.Range{nameRange(t, testSchema, "caveat only_on_tuesday", "only_on_tuesday")}},
+		{"relation", "view = view", []lsp.Range{nameRange(t, testSchema, "relation viewer", "viewer")}},
+		{"tupleset relation", "+ tea", []lsp.Range{nameRange(t, testSchema, "relation team", "team")}},
+		{"arrow", "team->mem", []lsp.Range{nameRange(t, testSchema, "relation member", "member")}},
+		{"all arrow", "team.all(adm", []lsp.Range{nameRange(t, testSchema, "relation admin", "admin")}},
+		{"arrow function", "team.al", nil},
+		{"declaration", "relation vie", nil},
+	}
+
+	client := newTestClient(t)
+	uri := lsp.DocumentURI("file:///schema.zed")
+	client.open(uri, testSchema)
+	require.Empty(t, client.waitForDiagnostics(uri))
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var locations []lsp.Location
+			client.call("textDocument/definition", positionParams(uri, after(t, testSchema, tt.position)), &locations)
+
+			ranges := make([]lsp.Range, 0, len(locations))
+			for _, location := range locations {
+				require.Equal(t, uri, location.URI)
+				ranges = append(ranges, location.Range)
+			}
+			require.ElementsMatch(t, tt.expected, ranges)
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		expected string
+	}{
+		{"definition with comment", "relation admin: us", "/** user is a user of the system */\ndefinition user"},
+		{"relation with comment", "team->mem", "// member is a member of the team\nrelation member: user"},
+		{"relation without comment", "team.all(adm", "relation admin: user"},
+		{"permission declaration", "permission vi", "permission view = viewer + team->member + team.all(admin)"},
+		{"caveat", "with only_on", "caveat only_on_tuesday"},
+		{"nothing", "definition user {", ""},
+	}
+
+	client := newTestClient(t)
+	uri := lsp.DocumentURI("file:///schema.zed")
+	client.open(uri, testSchema)
+	require.Empty(t, client.waitForDiagnostics(uri))
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var result *lsp.Hover
+			client.call("textDocument/hover", positionParams(uri, after(t, testSchema, tt.position)), &result)
+			if tt.expected == "" {
+				require.Nil(t, result)
+				return
+			}
+
+			require.NotNil(t, result)
+			require.Len(t, result.Contents, 1)
+			require.Equal(t, "zed", result.Contents[0].Language)
+			require.Equal(t, tt.expected, result.Contents[0].Value)
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected []string
+	}{
+		{
+			"types",
+			"definition user {}\ndefinition document {\n\trelation viewer: ‸\n}",
+			[]string{"user", "document"},
+		},
+		{
+			"partial type",
+			"definition user {}\ndefinition document {\n\trelation viewer: us‸\n}",
+			[]string{"user", "document"},
+		},
+		{
+			"caveats",
+			"caveat some_caveat(a int) { a == 1 }\ndefinition user {}\ndefinition document {\n\trelation viewer: user with ‸\n}",
+			[]string{"some_caveat"},
+		},
+		{
+			"subject relations",
+			"definition group {\n\trelation member: user\n\tpermission membership = member\n}\ndefinition document {\n\trelation viewer: group#‸\n}",
+			[]string{"member", "membership"},
+		},
+		{
+			"permission expression",
+			"definition document {\n\trelation viewer: user\n\trelation editor: user\n\tpermission view = viewer + ‸\n}",
+			[]string{"viewer", "editor", "view"},
+		},
+		{
+			"arrow",
+			"definition folder {\n\trelation reader: user\n}\ndefinition org {\n\trelation admin: user\n}\ndefinition document {\n\trelation parent: folder | org\n\tpermission view = parent->‸\n}",
+			[]string{"reader", "admin"},
+		},
+		{
+			"any arrow",
+			"definition folder {\n\trelation reader: user\n}\ndefinition document {\n\trelation parent: folder\n\tpermission view = parent.any(‸\n}",
+			[]string{"reader"},
+		},
+		{
+			"definition name",
+			"definition ‸",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t)
+			uri := lsp.DocumentURI("file:///schema.zed")
+
+			schema := strings.Replace(tt.schema, "‸", "", 1)
+			client.open(uri, schema)
+			client.waitForDiagnostics(uri)
+
+			var result lsp.CompletionList
+			client.call("textDocument/completion", lsp.CompletionParams{
+				TextDocumentPositionParams: positionParams(uri, positionForOffset(schema, strings.Index(tt.schema, "‸"))),
+			}, &result)
+
+			labels := make([]string, 0, len(result.Items))
+			for _, item := range result.Items {
+				labels = append(labels, item.Label)
+			}
+			require.ElementsMatch(t, tt.expected, labels)
+		})
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{
+			"unformatted",
+			"definition user {}\ndefinition document {\n  relation viewer: user\n    permission view = viewer\n}",
+			"definition user {}\n\ndefinition document {\n\trelation viewer: user\n\tpermission view = viewer\n}\n",
+		},
+		{
+			"already formatted",
+			"definition user {}\n",
+			"",
+		},
+		{
+			"imports",
+			"import \"common.zed\"\ndefinition document {\n  relation viewer: user\n}",
+			"import \"common.zed\"\n\ndefinition document {\n\trelation viewer: user\n}\n",
+		},
+		{
+			"invalid",
+			"definition document {",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t)
+			uri := lsp.DocumentURI("file:///schema.zed")
+			client.open(uri, tt.schema)
+			client.waitForDiagnostics(uri)
+
+			var edits []lsp.TextEdit
+			client.call("textDocument/formatting", lsp.DocumentFormattingParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			}, &edits)
+			if tt.expected == "" {
+				require.Empty(t, edits)
+				return
+			}
+
+			require.Len(t, edits, 1)
+			require.Equal(t, lsp.Position{}, edits[0].Range.Start)
+			require.Equal(t, positionForOffset(tt.schema, len(tt.schema)), edits[0].Range.End)
+			require.Equal(t, tt.expected, edits[0].NewText)
+		})
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	commonPath := filepath.Join(dir, "common.zed")
+	common := "// user is a user\ndefinition user {}\n"
+	require.NoError(t, os.WriteFile(commonPath, []byte(common), 0o600))
+
+	schema := "import \"common.zed\"\n\ndefinition document {\n\trelation viewer: user\n}\n"
+	uri := uriForSource(sourceForURI(lsp.DocumentURI("file://" + filepath.ToSlash(filepath.Join(dir, "schema.zed")))))
+
+	client := newTestClient(t)
+	client.open(uri, schema)
+	require.Empty(t, client.waitForDiagnostics(uri))
+
+	// Definitions in imported files are found on disk.
+	var locations []lsp.Location
+	client.call("textDocument/definition", positionParams(uri, after(t, schema, "viewer: us")), &locations)
+	require.Equal(t, []lsp.Location{{
+		URI:   uriForSource(sourceForURI(lsp.DocumentURI("file://" + filepath.ToSlash(commonPath)))),
+		Range: nameRange(t, common, "definition user", "user"),
+	}}, locations)
+
+	var result *lsp.Hover
+	client.call("textDocument/hover", positionParams(uri, after(t, schema, "viewer: us")), &result)
+	require.NotNil(t, result)
+	require.Equal(t, "// user is a user\ndefinition user", result.Contents[0].Value)
+
+	// Imported files open in the editor are used instead of those on disk, and changing them
+	// updates the diagnostics of the files importing them.
+	commonURI := locations[0].URI
+	client.open(commonURI, "definition member {}\n")
+	diagnostics := client.waitForDiagnostics(uri)
+	require.Len(t, diagnostics, 1)
+	require.Contains(t, diagnostics[0].Message, "object definition `user` not found")
+}
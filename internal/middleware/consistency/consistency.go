@@ -13,6 +13,7 @@ import (
 
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/session"
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/zedtoken"
@@ -76,12 +77,13 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 	switch {
 	case consistency == nil || consistency.GetMinimizeLatency():
-		// Minimize Latency: Use the datastore's current revision, whatever it may be.
+		// Minimize Latency: Use the datastore's current revision, whatever it may be, unless the
+		// session of the request wrote at a later revision.
 		databaseRev, err := ds.OptimizedRevision(ctx)
 		if err != nil {
 			return rewriteDatastoreError(ctx, err)
 		}
-		revision = databaseRev
+		revision = atLeastLatestSessionWrite(ctx, databaseRev)
 
 	case consistency.GetFullyConsistent():
 		// Fully Consistent: Use the datastore's synchronized revision.
@@ -98,7 +100,7 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 		if err != nil {
 			return rewriteDatastoreError(ctx, err)
 		}
-		revision = picked
+		revision = atLeastLatestSessionWrite(ctx, picked)
 
 	case consistency.GetAtExactSnapshot() != nil:
 		// Exact snapshot: Use the revision as encoded in the zed token.
@@ -125,6 +127,7 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 var bypassServiceWhitelist = map[string]struct{}{
 	"/grpc.reflection.v1alpha.ServerReflection/": {},
 	"/grpc.health.v1.Health/":                    {},
+	"/testserver.v1.TokenDatastoreService/":      {},
 }
 
 // UnaryServerInterceptor returns a new unary server interceptor that performs per-request exchange of
@@ -200,6 +203,22 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	return databaseRev, nil
 }
 
+// atLeastLatestSessionWrite returns the given revision if it is later than the latest write of
+// the session of the request, and the revision of that write otherwise. As revisions are only
+// partially ordered, the revision of the write is used unless the given revision is known to be
+// later, so that the write is always visible.
+func atLeastLatestSessionWrite(ctx context.Context, revision datastore.Revision) datastore.Revision {
+	written := session.LatestWriteFromContext(ctx)
+	if written == nil {
+		return revision
+	}
+
+	if revision.GreaterThan(written) {
+		return revision
+	}
+	return written
+}
+
 func rewriteDatastoreError(ctx context.Context, err error) error {
 	// Check if the error can be directly used.
 	if _, ok := status.FromError(err); ok {
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/internal/middleware/session"
+	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
@@ -159,3 +161,64 @@ func TestAddRevisionToContextNoConsistencyAPI(t *testing.T) {
 	_, _, err := RevisionFromContext(updated)
 	require.Error(err)
 }
+
+func TestAddRevisionToContextSessionWrite(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+
+	updated := ContextWithHandle(session.ContextWithLatestWrite(context.Background(), exact))
+	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{}, ds)
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextSessionWriteOlderThanOptimized(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(exact, nil).Once()
+
+	updated := ContextWithHandle(session.ContextWithLatestWrite(context.Background(), optimized))
+	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{}, ds)
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
+// concurrentRevision is a revision which is ordered with respect to no other revision, as with
+// the revisions of concurrent transactions in some datastores.
+type concurrentRevision struct {
+	revision.Decimal
+}
+
+func (concurrentRevision) GreaterThan(_ datastore.Revision) bool { return false }
+
+func (concurrentRevision) LessThan(_ datastore.Revision) bool { return false }
+
+func TestAddRevisionToContextSessionWriteConcurrentWithOptimized(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(concurrentRevision{optimized}, nil).Once()
+
+	updated := ContextWithHandle(session.ContextWithLatestWrite(context.Background(), concurrentRevision{exact}))
+	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{}, ds)
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev.(concurrentRevision).Decimal))
+	ds.AssertExpectations(t)
+}
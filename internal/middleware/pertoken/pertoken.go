@@ -1,19 +1,26 @@
 package pertoken
 
 import (
+	"container/list"
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/authzed/authzed-go/pkg/requestmeta"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
+	testserverv1 "github.com/authzed/spicedb/pkg/proto/testserver/v1"
 	"github.com/authzed/spicedb/pkg/validationfile"
 )
 
@@ -22,20 +29,94 @@ const (
 	revisionQuantization = 10 * time.Millisecond
 )
 
+// FixtureMetadataKey is the key in the request metadata naming the fixture from which the
+// datastore of the token is seeded, when the request is the first made with the token.
+const FixtureMetadataKey requestmeta.RequestMetadataHeaderKey = "io.spicedb.testing.fixture"
+
+// adminServicePrefix is the method prefix of the service administering the datastores, which
+// does not use a datastore of its own.
+var adminServicePrefix = "/" + testserverv1.TokenDatastoreService_ServiceDesc.ServiceName + "/"
+
 // MiddlewareForTesting is used to create a unique datastore for each token. It is intended for use in the
 // testserver only.
 type MiddlewareForTesting struct {
-	datastoreByToken *sync.Map
-	configFilePaths  []string
+	sync.Mutex
+
+	// recentlyUsed holds a *tokenDatastore for each token, the most recently used first.
+	recentlyUsed     *list.List
+	datastoreByToken map[string]*list.Element
+
+	configFilePaths []string
+	fixtures        map[string]string
+	idleTTL         time.Duration
+	maxDatastores   int
+	now             func() time.Time
+}
+
+type tokenDatastore struct {
+	token    string
+	fixture  string
+	ds       datastore.Datastore
+	lastUsed time.Time
+}
+
+// TokenDatastore describes the datastore of a token.
+type TokenDatastore struct {
+	// Token is the bearer token the datastore is used for.
+	Token string
+
+	// Fixture is the name of the fixture the datastore was seeded from, or empty if it was
+	// seeded from the config files.
+	Fixture string
+
+	// LastUsed is the time of the last request made with the token.
+	LastUsed time.Time
+}
+
+func (td *tokenDatastore) describe() TokenDatastore {
+	return TokenDatastore{Token: td.token, Fixture: td.fixture, LastUsed: td.lastUsed}
+}
+
+// Option is an option for the per-token datastore middleware.
+type Option func(*MiddlewareForTesting)
+
+// WithIdleTTL evicts the datastore of a token once no request has been made with the token for
+// the duration. A duration of 0 never evicts idle datastores.
+func WithIdleTTL(idleTTL time.Duration) Option {
+	return func(m *MiddlewareForTesting) {
+		m.idleTTL = idleTTL
+	}
+}
+
+// WithMaxDatastores evicts the least recently used datastores once there are more than the
+// maximum. A maximum of 0 allows any number of datastores.
+func WithMaxDatastores(maxDatastores int) Option {
+	return func(m *MiddlewareForTesting) {
+		m.maxDatastores = maxDatastores
+	}
+}
+
+// WithFixtures sets the config files, by fixture name, from which datastores can be seeded
+// instead of the config files of the middleware.
+func WithFixtures(fixtures map[string]string) Option {
+	return func(m *MiddlewareForTesting) {
+		m.fixtures = fixtures
+	}
 }
 
 // NewMiddleware returns a new per-token datastore middleware that initializes each datastore with the data in the
 // config files.
-func NewMiddleware(configFilePaths []string) *MiddlewareForTesting {
-	return &MiddlewareForTesting{
-		datastoreByToken: &sync.Map{},
+func NewMiddleware(configFilePaths []string, opts ...Option) *MiddlewareForTesting {
+	m := &MiddlewareForTesting{
+		recentlyUsed:     list.New(),
+		datastoreByToken: map[string]*list.Element{},
 		configFilePaths:  configFilePaths,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 type squashable interface {
@@ -44,32 +125,169 @@ type squashable interface {
 
 func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datastore.Datastore, error) {
 	tokenStr, _ := grpcauth.AuthFromMD(ctx, "bearer")
-	tokenDatastore, ok := m.datastoreByToken.Load(tokenStr)
-	if ok {
-		return tokenDatastore.(datastore.Datastore), nil
+
+	m.Lock()
+	now := m.now()
+	m.evictLocked(now)
+	if elem, ok := m.datastoreByToken[tokenStr]; ok {
+		entry := m.touchLocked(elem, now)
+		m.Unlock()
+		return entry.ds, nil
+	}
+	m.Unlock()
+
+	var fixture string
+	if values := metadata.ValueFromIncomingContext(ctx, string(FixtureMetadataKey)); len(values) > 0 {
+		fixture = values[0]
+	}
+
+	log.Ctx(ctx).Debug().Str("token", tokenStr).Str("fixture", fixture).Msg("initializing new upstream for token")
+	ds, err := m.newDatastore(ctx, fixture)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	// Another request made with the token may have created its datastore in the meantime.
+	if elem, ok := m.datastoreByToken[tokenStr]; ok {
+		return m.touchLocked(elem, now).ds, nil
+	}
+
+	m.storeLocked(&tokenDatastore{token: tokenStr, fixture: fixture, ds: ds, lastUsed: now})
+	return ds, nil
+}
+
+// newDatastore creates a datastore seeded from the fixture, or from the config files if the
+// fixture is empty.
+func (m *MiddlewareForTesting) newDatastore(ctx context.Context, fixture string) (datastore.Datastore, error) {
+	configFilePaths := m.configFilePaths
+	if fixture != "" {
+		path, ok := m.fixtures[fixture]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown fixture `%s`", fixture)
+		}
+		configFilePaths = []string{path}
 	}
 
-	log.Ctx(ctx).Debug().Str("token", tokenStr).Msg("initializing new upstream for token")
 	ds, err := memdb.NewMemdbDatastore(0, revisionQuantization, gcWindow)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init datastore: %w", err)
 	}
 
-	_, _, err = validationfile.PopulateFromFiles(ctx, ds, m.configFilePaths)
+	_, _, err = validationfile.PopulateFromFiles(ctx, ds, configFilePaths)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config files: %w", err)
 	}
 
 	// Squash the revisions so that the caller sees all the populated data.
 	ds.(squashable).SquashRevisionsForTesting()
-
-	m.datastoreByToken.Store(tokenStr, ds)
 	return ds, nil
 }
 
+func (m *MiddlewareForTesting) touchLocked(elem *list.Element, now time.Time) *tokenDatastore {
+	entry := elem.Value.(*tokenDatastore)
+	entry.lastUsed = now
+	m.recentlyUsed.MoveToFront(elem)
+	return entry
+}
+
+// storeLocked sets the datastore of the token, replacing any existing one, then evicts the least
+// recently used datastores beyond the maximum.
+func (m *MiddlewareForTesting) storeLocked(entry *tokenDatastore) {
+	if elem, ok := m.datastoreByToken[entry.token]; ok {
+		m.recentlyUsed.Remove(elem)
+	}
+	m.datastoreByToken[entry.token] = m.recentlyUsed.PushFront(entry)
+
+	for m.maxDatastores > 0 && m.recentlyUsed.Len() > m.maxDatastores {
+		m.removeLocked(m.recentlyUsed.Back(), "maximum datastore count reached")
+	}
+}
+
+// evictLocked evicts the datastores which have been idle for longer than the idle TTL.
+func (m *MiddlewareForTesting) evictLocked(now time.Time) {
+	if m.idleTTL <= 0 {
+		return
+	}
+
+	for elem := m.recentlyUsed.Back(); elem != nil; elem = m.recentlyUsed.Back() {
+		if now.Sub(elem.Value.(*tokenDatastore).lastUsed) <= m.idleTTL {
+			return
+		}
+		m.removeLocked(elem, "idle TTL expired")
+	}
+}
+
+// removeLocked forgets the datastore, without closing it: requests still running against it
+// complete normally, and its memory is released once they have.
+func (m *MiddlewareForTesting) removeLocked(elem *list.Element, reason string) {
+	entry := m.recentlyUsed.Remove(elem).(*tokenDatastore)
+	delete(m.datastoreByToken, entry.token)
+	log.Debug().Str("token", entry.token).Str("reason", reason).Msg("evicted datastore for token")
+}
+
+// List returns the datastores of the tokens, the most recently used first.
+func (m *MiddlewareForTesting) List() []TokenDatastore {
+	m.Lock()
+	defer m.Unlock()
+
+	m.evictLocked(m.now())
+
+	datastores := make([]TokenDatastore, 0, m.recentlyUsed.Len())
+	for elem := m.recentlyUsed.Front(); elem != nil; elem = elem.Next() {
+		datastores = append(datastores, elem.Value.(*tokenDatastore).describe())
+	}
+	return datastores
+}
+
+// Reset replaces the datastore of the token with one seeded from the fixture, creating it if
+// the token has none. If the fixture is empty, the datastore is seeded as it was before.
+func (m *MiddlewareForTesting) Reset(ctx context.Context, token string, fixture string) (TokenDatastore, error) {
+	if fixture == "" {
+		m.Lock()
+		if elem, ok := m.datastoreByToken[token]; ok {
+			fixture = elem.Value.(*tokenDatastore).fixture
+		}
+		m.Unlock()
+	}
+
+	ds, err := m.newDatastore(ctx, fixture)
+	if err != nil {
+		return TokenDatastore{}, err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	entry := &tokenDatastore{token: token, fixture: fixture, ds: ds, lastUsed: m.now()}
+	m.storeLocked(entry)
+	return entry.describe(), nil
+}
+
+// Delete removes the datastore of the token, returning whether the token had one.
+func (m *MiddlewareForTesting) Delete(token string) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	elem, ok := m.datastoreByToken[token]
+	if !ok {
+		return false
+	}
+
+	m.recentlyUsed.Remove(elem)
+	delete(m.datastoreByToken, token)
+	return true
+}
+
 // UnaryServerInterceptor returns a new unary server interceptor that sets a separate in-memory datastore per token
 func (m *MiddlewareForTesting) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(ctx, req)
+		}
+
 		tokenDatastore, err := m.getOrCreateDatastore(ctx)
 		if err != nil {
 			return nil, err
@@ -87,6 +305,10 @@ func (m *MiddlewareForTesting) UnaryServerInterceptor() grpc.UnaryServerIntercep
 // StreamServerInterceptor returns a new stream server interceptor that sets a separate in-memory datastore per token
 func (m *MiddlewareForTesting) StreamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(srv, stream)
+		}
+
 		tokenDatastore, err := m.getOrCreateDatastore(stream.Context())
 		if err != nil {
 			return err
@@ -0,0 +1,192 @@
+package pertoken
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	defaultConfig = `schema: |-
+  definition user {}
+`
+	documentsConfig = `schema: |-
+  definition user {}
+
+  definition document {
+    relation viewer: user
+  }
+`
+)
+
+func writeConfig(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func requestContext(token string, fixture string) context.Context {
+	md := metadata.Pairs("authorization", "bearer "+token)
+	if fixture != "" {
+		md.Append(string(FixtureMetadataKey), fixture)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.now
+}
+
+func newTestMiddleware(t *testing.T, opts ...Option) (*MiddlewareForTesting, *fakeClock) {
+	m := NewMiddleware([]string{writeConfig(t, "default.yaml", defaultConfig)}, append([]Option{
+		WithFixtures(map[string]string{"documents": writeConfig(t, "documents.yaml", documentsConfig)}),
+	}, opts...)...)
+
+	clock := &fakeClock{now: time.Now()}
+	m.now = clock.Now
+	return m, clock
+}
+
+// datastoreFor runs a request with the token through the interceptor, returning the datastore
+// the request was given.
+func datastoreFor(t *testing.T, m *MiddlewareForTesting, token string, fixture string) datastore.Datastore {
+	ds, err := interceptedDatastore(m, "/authzed.api.v1.PermissionsService/CheckPermission", token, fixture)
+	require.NoError(t, err)
+	return ds
+}
+
+func interceptedDatastore(m *MiddlewareForTesting, method string, token string, fixture string) (datastore.Datastore, error) {
+	var ds datastore.Datastore
+	_, err := m.UnaryServerInterceptor()(requestContext(token, fixture), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		ds = datastoremw.FromContext(ctx)
+		return nil, nil
+	})
+	return ds, err
+}
+
+func namespaceNames(t *testing.T, ds datastore.Datastore) []string {
+	ctx := context.Background()
+
+	head, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	namespaces, err := ds.SnapshotReader(head).ListAllNamespaces(ctx)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Definition.Name)
+	}
+	return names
+}
+
+func tokens(m *MiddlewareForTesting) []string {
+	var tokens []string
+	for _, td := range m.List() {
+		tokens = append(tokens, td.Token)
+	}
+	return tokens
+}
+
+func TestDatastorePerToken(t *testing.T) {
+	m, _ := newTestMiddleware(t)
+
+	first := datastoreFor(t, m, "first", "")
+	require.Same(t, first, datastoreFor(t, m, "first", ""))
+	require.NotSame(t, first, datastoreFor(t, m, "second", ""))
+	require.ElementsMatch(t, []string{"user"}, namespaceNames(t, first))
+
+	require.Equal(t, []string{"second", "first"}, tokens(m))
+}
+
+func TestFixture(t *testing.T) {
+	m, _ := newTestMiddleware(t)
+
+	ds := datastoreFor(t, m, "first", "documents")
+	require.ElementsMatch(t, []string{"user", "document"}, namespaceNames(t, ds))
+	require.Equal(t, "documents", m.List()[0].Fixture)
+
+	// The fixture only applies when the datastore is created.
+	require.Same(t, ds, datastoreFor(t, m, "first", ""))
+
+	_, err := interceptedDatastore(m, "/authzed.api.v1.PermissionsService/CheckPermission", "second", "unknown")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, []string{"first"}, tokens(m))
+}
+
+func TestIdleTTL(t *testing.T) {
+	m, clock := newTestMiddleware(t, WithIdleTTL(time.Minute))
+
+	first := datastoreFor(t, m, "first", "")
+	clock.now = clock.now.Add(45 * time.Second)
+	datastoreFor(t, m, "second", "")
+
+	clock.now = clock.now.Add(30 * time.Second)
+	require.Equal(t, []string{"second"}, tokens(m))
+
+	// A new datastore is created once the previous one was evicted.
+	require.NotSame(t, first, datastoreFor(t, m, "first", ""))
+}
+
+func TestMaxDatastores(t *testing.T) {
+	m, _ := newTestMiddleware(t, WithMaxDatastores(2))
+
+	first := datastoreFor(t, m, "first", "")
+	datastoreFor(t, m, "second", "")
+	require.Same(t, first, datastoreFor(t, m, "first", ""))
+
+	datastoreFor(t, m, "third", "")
+	require.Equal(t, []string{"third", "first"}, tokens(m))
+}
+
+func TestResetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMiddleware(t)
+
+	first := datastoreFor(t, m, "first", "documents")
+
+	// Resetting without a fixture keeps the fixture of the datastore.
+	reset, err := m.Reset(ctx, "first", "")
+	require.NoError(t, err)
+	require.Equal(t, "documents", reset.Fixture)
+
+	resetDatastore := datastoreFor(t, m, "first", "")
+	require.NotSame(t, first, resetDatastore)
+	require.ElementsMatch(t, []string{"user", "document"}, namespaceNames(t, resetDatastore))
+
+	// Resetting a token without a datastore creates it.
+	_, err = m.Reset(ctx, "second", "documents")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"user", "document"}, namespaceNames(t, datastoreFor(t, m, "second", "")))
+
+	_, err = m.Reset(ctx, "second", "unknown")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	require.True(t, m.Delete("first"))
+	require.False(t, m.Delete("first"))
+	require.Equal(t, []string{"second"}, tokens(m))
+}
+
+func TestAdminServiceBypass(t *testing.T) {
+	m, _ := newTestMiddleware(t)
+
+	ds, err := interceptedDatastore(m, "/testserver.v1.TokenDatastoreService/ListTokenDatastores", "first", "")
+	require.NoError(t, err)
+	require.Nil(t, ds)
+	require.Empty(t, m.List())
+}
@@ -0,0 +1,624 @@
+// Package schemavalidation defines middleware that validates the object types, relations,
+// permissions and caveat context referenced by a v1 API request against the schema, before the
+// request reaches its service.
+package schemavalidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/caveats"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/consistency"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/sharederrors"
+	caveatspkg "github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that validates the schema
+// references of the incoming request, if any and if the middleware is enabled.
+func UnaryServerInterceptor(isEnabled bool) grpc.UnaryServerInterceptor {
+	if !isEnabled {
+		return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
+		}
+	}
+
+	sv := newSchemaValidator()
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := sv.validateIncomingRequest(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that validates the schema
+// references of the incoming request messages, if any and if the middleware is enabled.
+func StreamServerInterceptor(isEnabled bool) grpc.StreamServerInterceptor {
+	if !isEnabled {
+		return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, stream)
+		}
+	}
+
+	sv := newSchemaValidator()
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &recvWrapper{stream, sv})
+	}
+}
+
+type recvWrapper struct {
+	grpc.ServerStream
+	sv *schemaValidator
+}
+
+func (s *recvWrapper) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.sv.validateIncomingRequest(s.Context(), m)
+}
+
+type validatable interface {
+	Validate() error
+}
+
+type handwrittenValidatable interface {
+	HandwrittenValidate() error
+}
+
+// schemaValidator validates requests, sharing the type systems it builds between them.
+type schemaValidator struct {
+	typeSystems *typeSystemCache
+}
+
+func newSchemaValidator() *schemaValidator {
+	return &schemaValidator{typeSystems: newTypeSystemCache()}
+}
+
+// validateIncomingRequest validates the request against the schema at the revision chosen by
+// the consistency middleware.
+//
+// Requests without consistency, such as writes, are validated against the schema at the
+// optimized revision, which avoids reading the head revision for each of them. As the schema may
+// have changed since that revision, a request found to be invalid is validated again at the head
+// revision before it is rejected.
+//
+// Validation is best-effort: requests which fail their proto validation and failures to read
+// the schema are left for the service to report.
+func (sv *schemaValidator) validateIncomingRequest(ctx context.Context, req interface{}) error {
+	if !isValidatedRequest(req) {
+		return nil
+	}
+
+	if v, ok := req.(validatable); ok && v.Validate() != nil {
+		return nil
+	}
+
+	if v, ok := req.(handwrittenValidatable); ok && v.HandwrittenValidate() != nil {
+		return nil
+	}
+
+	ds := datastoremw.MustFromContext(ctx)
+	if revision, _, err := consistency.RevisionFromContext(ctx); err == nil {
+		return sv.validateRequest(ctx, ds.SnapshotReader(revision), req)
+	}
+
+	revision, err := ds.OptimizedRevision(ctx)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("skipping schema validation of request")
+		return nil
+	}
+
+	if err := sv.validateRequest(ctx, ds.SnapshotReader(revision), req); err == nil {
+		return nil
+	}
+
+	revision, err = ds.HeadRevision(ctx)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("skipping schema validation of request")
+		return nil
+	}
+
+	return sv.validateRequest(ctx, ds.SnapshotReader(revision), req)
+}
+
+func isValidatedRequest(req interface{}) bool {
+	switch req.(type) {
+	case *v1.CheckPermissionRequest,
+		*v1.ExpandPermissionTreeRequest,
+		*v1.LookupResourcesRequest,
+		*v1.LookupSubjectsRequest,
+		*v1.ReadRelationshipsRequest,
+		*v1.WriteRelationshipsRequest,
+		*v1.DeleteRelationshipsRequest,
+		*v1.WatchRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateRequest returns an error describing every schema reference in the request which is
+// invalid, or nil if there are none.
+func (sv *schemaValidator) validateRequest(ctx context.Context, reader datastore.Reader, req interface{}) error {
+	v := &requestValidator{
+		ctx:         ctx,
+		reader:      reader,
+		cache:       sv.typeSystems,
+		typeSystems: map[string]typeSystemLookup{},
+	}
+
+	switch req := req.(type) {
+	case *v1.CheckPermissionRequest:
+		v.objectAndRelation("resource.object_type", "permission", req.Resource.ObjectType, req.Permission, false)
+		v.subjectReference("subject", req.Subject)
+		v.requestContext("context", req.Context)
+
+	case *v1.ExpandPermissionTreeRequest:
+		v.objectAndRelation("resource.object_type", "permission", req.Resource.ObjectType, req.Permission, false)
+
+	case *v1.LookupResourcesRequest:
+		v.objectAndRelation("resource_object_type", "permission", req.ResourceObjectType, req.Permission, false)
+		v.subjectReference("subject", req.Subject)
+		v.requestContext("context", req.Context)
+
+	case *v1.LookupSubjectsRequest:
+		v.objectAndRelation("resource.object_type", "permission", req.Resource.ObjectType, req.Permission, false)
+		v.objectAndRelation("subject_object_type", "optional_subject_relation", req.SubjectObjectType, defaultEllipsis(req.OptionalSubjectRelation), true)
+		v.requestContext("context", req.Context)
+
+	case *v1.ReadRelationshipsRequest:
+		v.relationshipFilter("relationship_filter", req.RelationshipFilter)
+
+	case *v1.WriteRelationshipsRequest:
+		// The service rejects duplicate updates before checking the relationships against the
+		// schema, so those requests are left for it to report.
+		if hasDuplicateUpdates(req.Updates) {
+			return nil
+		}
+
+		for i, precondition := range req.OptionalPreconditions {
+			v.relationshipFilter(fmt.Sprintf("optional_preconditions[%d].filter", i), precondition.Filter)
+		}
+
+		expiration, updateExpirations, err := requestExpirations(ctx, len(req.Updates))
+		if err != nil {
+			// Reported by the service.
+			return nil
+		}
+
+		for i, update := range req.Updates {
+			_, updateExpiration := updateExpirations[i]
+			v.relationshipUpdate(fmt.Sprintf("updates[%d].relationship", i), tuple.UpdateFromRelationshipUpdate(update), expiration || updateExpiration)
+		}
+
+	case *v1.DeleteRelationshipsRequest:
+		for i, precondition := range req.OptionalPreconditions {
+			v.relationshipFilter(fmt.Sprintf("optional_preconditions[%d].filter", i), precondition.Filter)
+		}
+		v.relationshipFilter("relationship_filter", req.RelationshipFilter)
+
+	case *v1.WatchRequest:
+		for i, objectType := range req.OptionalObjectTypes {
+			v.typeSystem(fmt.Sprintf("optional_object_types[%d]", i), objectType)
+		}
+	}
+
+	if v.failed != nil {
+		log.Ctx(ctx).Debug().Err(v.failed).Msg("skipping schema validation of request")
+		return nil
+	}
+
+	return v.asError()
+}
+
+// fieldViolation is an invalid schema reference found in a request field.
+type fieldViolation struct {
+	field string
+	err   error
+}
+
+type typeSystemLookup struct {
+	ts  *namespace.TypeSystem
+	err error
+}
+
+// maxCachedTypeSystems is the maximum number of object types whose type systems are cached.
+const maxCachedTypeSystems = 1024
+
+// typeSystemCache holds the type system built for the last read definition of each object type,
+// so that requests which read the same definition from the datastore's namespace cache share its
+// type system rather than building their own. Once it holds maxCachedTypeSystems object types,
+// caching another evicts an arbitrary one.
+type typeSystemCache struct {
+	sync.Mutex
+	byName map[string]cachedTypeSystem
+}
+
+type cachedTypeSystem struct {
+	definition *core.NamespaceDefinition
+	ts         *namespace.TypeSystem
+}
+
+func newTypeSystemCache() *typeSystemCache {
+	return &typeSystemCache{byName: map[string]cachedTypeSystem{}}
+}
+
+// typeSystem returns the type system for the definition of the object type read by the reader.
+func (c *typeSystemCache) typeSystem(ctx context.Context, reader datastore.Reader, objectType string) (*namespace.TypeSystem, error) {
+	definition, _, err := reader.ReadNamespaceByName(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	cached, ok := c.byName[objectType]
+	c.Unlock()
+	if ok && cached.definition == definition {
+		return cached.ts, nil
+	}
+
+	// The type system is only used to look up the relations of its own definition, so it is built
+	// without the reader, which the cache would otherwise retain.
+	ts, err := namespace.NewNamespaceTypeSystem(definition, namespace.ResolverForPredefinedDefinitions(namespace.PredefinedElements{}))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	if _, ok := c.byName[objectType]; !ok && len(c.byName) >= maxCachedTypeSystems {
+		for name := range c.byName {
+			delete(c.byName, name)
+			break
+		}
+	}
+	c.byName[objectType] = cachedTypeSystem{definition, ts}
+	c.Unlock()
+	return ts, nil
+}
+
+type requestValidator struct {
+	ctx    context.Context
+	reader datastore.Reader
+	cache  *typeSystemCache
+
+	typeSystems map[string]typeSystemLookup
+	caveats     map[string]*core.CaveatDefinition
+
+	violations []fieldViolation
+
+	// failed is set if the schema could not be read, in which case the violations are
+	// incomplete.
+	failed error
+}
+
+func (v *requestValidator) violation(field string, err error) {
+	v.violations = append(v.violations, fieldViolation{field, err})
+}
+
+// typeSystem returns the type system for the object type, recording a violation of the field
+// if the object type does not exist.
+func (v *requestValidator) typeSystem(field, objectType string) *namespace.TypeSystem {
+	if objectType == "" || v.failed != nil {
+		return nil
+	}
+
+	lookup, ok := v.typeSystems[objectType]
+	if !ok {
+		ts, err := v.cache.typeSystem(v.ctx, v.reader, objectType)
+		if err != nil && !errors.As(err, &datastore.ErrNamespaceNotFound{}) {
+			v.failed = err
+			return nil
+		}
+
+		lookup = typeSystemLookup{ts, err}
+		v.typeSystems[objectType] = lookup
+	}
+
+	if lookup.err != nil {
+		v.violation(field, lookup.err)
+		return nil
+	}
+
+	return lookup.ts
+}
+
+// relation records a violation of the field if the relation or permission does not exist in
+// the type system.
+func (v *requestValidator) relation(field string, ts *namespace.TypeSystem, relation string, allowEllipsis bool) bool {
+	if ts == nil {
+		return false
+	}
+
+	if allowEllipsis && relation == tuple.Ellipsis {
+		return true
+	}
+
+	if !ts.HasRelation(relation) {
+		v.violation(field, namespace.NewRelationNotFoundErr(ts.Namespace().Name, relation))
+		return false
+	}
+
+	return true
+}
+
+func (v *requestValidator) objectAndRelation(typeField, relationField, objectType, relation string, allowEllipsis bool) {
+	v.relation(relationField, v.typeSystem(typeField, objectType), relation, allowEllipsis)
+}
+
+func (v *requestValidator) subjectReference(field string, subject *v1.SubjectReference) {
+	v.objectAndRelation(
+		field+".object.object_type",
+		field+".optional_relation",
+		subject.Object.ObjectType,
+		defaultEllipsis(subject.OptionalRelation),
+		true,
+	)
+}
+
+func (v *requestValidator) relationshipFilter(field string, filter *v1.RelationshipFilter) {
+	if filter == nil {
+		return
+	}
+
+	v.objectAndRelation(
+		field+".resource_type",
+		field+".optional_relation",
+		filter.ResourceType,
+		defaultEllipsis(filter.OptionalRelation),
+		filter.OptionalRelation == "",
+	)
+
+	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
+		subjectRelation := ""
+		if subjectFilter.OptionalRelation != nil {
+			subjectRelation = subjectFilter.OptionalRelation.Relation
+		}
+
+		v.objectAndRelation(
+			field+".optional_subject_filter.subject_type",
+			field+".optional_subject_filter.optional_relation.relation",
+			subjectFilter.SubjectType,
+			defaultEllipsis(subjectRelation),
+			true,
+		)
+	}
+}
+
+// relationshipUpdate performs the checks of relationships.ValidateRelationshipUpdates on a
+// single update, recording a violation for each invalid part of the relationship.
+func (v *requestValidator) relationshipUpdate(field string, update *core.RelationTupleUpdate, expiration bool) {
+	if expiration && update.Operation != core.RelationTupleUpdate_DELETE {
+		// Only the presence of an expiration matters to type checking.
+		update.Tuple.OptionalExpirationTime = timestamppb.Now()
+	}
+
+	resource := update.Tuple.ResourceAndRelation
+	subject := update.Tuple.Subject
+
+	resourceTS := v.typeSystem(field+".resource.object_type", resource.Namespace)
+	validResource := v.relation(field+".relation", resourceTS, resource.Relation, false)
+
+	subjectTS := v.typeSystem(field+".subject.object.object_type", subject.Namespace)
+	validSubject := v.relation(field+".subject.optional_relation", subjectTS, subject.Relation, true)
+
+	if validResource && resourceTS.IsPermission(resource.Relation) {
+		v.violation(field+".relation", relationships.NewCannotWriteToPermissionError(update))
+		validResource = false
+	}
+
+	if validResource && validSubject {
+		if err := relationships.ValidateAllowedSubject(resourceTS, update); err != nil {
+			v.violation(field+".subject", err)
+		}
+	}
+
+	if update.Tuple.Caveat == nil || update.Tuple.Caveat.CaveatName == "" || len(update.Tuple.Caveat.Context.GetFields()) == 0 {
+		return
+	}
+
+	caveat, ok := v.caveatDefinitions()[update.Tuple.Caveat.CaveatName]
+	if !ok {
+		if v.failed == nil {
+			v.violation(field+".optional_caveat.caveat_name", relationships.NewCaveatNotFoundError(update))
+		}
+		return
+	}
+
+	expr := caveats.CaveatAsExpr(update.Tuple.Caveat)
+	for _, key := range sortedKeys(update.Tuple.Caveat.Context) {
+		_, err := caveatspkg.ConvertContextToParameters(
+			map[string]any{key: update.Tuple.Caveat.Context.Fields[key].AsInterface()},
+			caveat.ParameterTypes,
+			caveatspkg.ErrorForUnknownParameters,
+		)
+		if err != nil {
+			v.violation(field+".optional_caveat.context."+key, caveats.NewParameterTypeError(expr, err))
+		}
+	}
+}
+
+// requestContext checks that the value of every key of a request's caveat context which is a
+// parameter of a caveat in the schema can be converted to the type of that parameter. Keys which
+// are not parameters of any caveat are allowed, as callers may send the same context to requests
+// whose caveats use different parameters.
+func (v *requestValidator) requestContext(field string, caveatContext *structpb.Struct) {
+	if len(caveatContext.GetFields()) == 0 {
+		return
+	}
+
+	definitions := v.caveatDefinitions()
+	if v.failed != nil {
+		return
+	}
+
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, key := range sortedKeys(caveatContext) {
+		value := map[string]any{key: caveatContext.Fields[key].AsInterface()}
+
+		var conversionErr error
+		for _, name := range names {
+			definition := definitions[name]
+			if _, ok := definition.ParameterTypes[key]; !ok {
+				continue
+			}
+
+			_, err := caveatspkg.ConvertContextToParameters(value, definition.ParameterTypes, caveatspkg.SkipUnknownParameters)
+			if err == nil {
+				conversionErr = nil
+				break
+			}
+
+			if conversionErr == nil {
+				conversionErr = caveats.NewParameterTypeError(caveats.CaveatAsExpr(&core.ContextualizedCaveat{CaveatName: name}), err)
+			}
+		}
+
+		if conversionErr != nil {
+			v.violation(field+"."+key, conversionErr)
+		}
+	}
+}
+
+// caveatDefinitions returns all caveats in the schema, by name.
+func (v *requestValidator) caveatDefinitions() map[string]*core.CaveatDefinition {
+	if v.caveats != nil || v.failed != nil {
+		return v.caveats
+	}
+
+	found, err := v.reader.ListAllCaveats(v.ctx)
+	if err != nil {
+		v.failed = err
+		return nil
+	}
+
+	v.caveats = make(map[string]*core.CaveatDefinition, len(found))
+	for _, caveat := range found {
+		v.caveats[caveat.Definition.Name] = caveat.Definition
+	}
+	return v.caveats
+}
+
+// asError returns a status with the code, message and error details of the first violation,
+// along with a BadRequest listing every violation.
+func (v *requestValidator) asError() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	fieldViolations := make([]*errdetails.BadRequest_FieldViolation, 0, len(v.violations))
+	for _, violation := range v.violations {
+		fieldViolations = append(fieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.field,
+			Description: violation.err.Error(),
+		})
+	}
+
+	withDetails, err := statusForViolation(v.violations[0].err).WithDetails(&errdetails.BadRequest{
+		FieldViolations: fieldViolations,
+	})
+	if err != nil {
+		log.Ctx(v.ctx).Err(err).Msg("could not add field violations to error")
+		return statusForViolation(v.violations[0].err).Err()
+	}
+
+	return withDetails.Err()
+}
+
+// statusForViolation returns the status which the services return for the error.
+func statusForViolation(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	var nsNotFoundError sharederrors.UnknownNamespaceError
+	var relationNotFoundError sharederrors.UnknownRelationError
+
+	switch {
+	case errors.As(err, &nsNotFoundError):
+		return status.Convert(spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_DEFINITION))
+	case errors.As(err, &relationNotFoundError):
+		return status.Convert(spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION))
+	default:
+		return status.New(codes.InvalidArgument, err.Error())
+	}
+}
+
+// requestExpirations returns whether the WriteRelationships call sets an expiration on all the
+// relationships it writes, and the expirations it sets on single updates.
+func requestExpirations(ctx context.Context, updateCount int) (bool, map[int]*timestamppb.Timestamp, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false, nil, nil
+	}
+
+	updateExpirations, err := tuple.ParseUpdateExpirations(md.Get(string(tuple.WriteRelationshipsUpdateExpiration)), updateCount)
+	if err != nil {
+		return false, nil, err
+	}
+
+	values := md.Get(string(tuple.WriteRelationshipsExpiration))
+	if len(values) == 0 || values[0] == "" {
+		return false, updateExpirations, nil
+	}
+
+	_, err = tuple.ParseExpiration(values[0])
+	return err == nil, updateExpirations, err
+}
+
+// hasDuplicateUpdates returns whether more than one of the updates is for the same relationship,
+// ignoring caveats.
+func hasDuplicateUpdates(updates []*v1.RelationshipUpdate) bool {
+	seen := make(map[string]struct{}, len(updates))
+	for _, update := range updates {
+		key := tuple.StringRelationshipWithoutCaveat(update.Relationship)
+		if _, ok := seen[key]; ok {
+			return true
+		}
+		seen[key] = struct{}{}
+	}
+	return false
+}
+
+func defaultEllipsis(relation string) string {
+	if relation == "" {
+		return tuple.Ellipsis
+	}
+	return relation
+}
+
+func sortedKeys(s *structpb.Struct) []string {
+	keys := make([]string, 0, len(s.GetFields()))
+	for key := range s.GetFields() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,399 @@
+package schemavalidation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+const testSchema = `
+	definition user {}
+
+	caveat only_on(day string) {
+		day == 'tuesday'
+	}
+
+	definition group {
+		relation member: user | group#member
+	}
+
+	definition document {
+		relation viewer: user | group#member | user with only_on
+		permission view = viewer
+	}
+`
+
+func mustStruct(t *testing.T, values map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(values)
+	require.NoError(t, err)
+	return s
+}
+
+func obj(objectType, objectID string) *v1.ObjectReference {
+	return &v1.ObjectReference{ObjectType: objectType, ObjectId: objectID}
+}
+
+func sub(objectType, objectID, relation string) *v1.SubjectReference {
+	return &v1.SubjectReference{Object: obj(objectType, objectID), OptionalRelation: relation}
+}
+
+func TestValidateRequest(t *testing.T) {
+	testCases := []struct {
+		name               string
+		request            any
+		expectedCode       codes.Code
+		expectedReason     v1.ErrorReason
+		expectedViolations map[string]string
+	}{
+		{
+			name: "valid check",
+			request: &v1.CheckPermissionRequest{
+				Resource:   obj("document", "doc1"),
+				Permission: "view",
+				Subject:    sub("group", "eng", "member"),
+				Context:    mustStruct(t, map[string]any{"day": "monday"}),
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "check with unknown types",
+			request: &v1.CheckPermissionRequest{
+				Resource:   obj("notdocument", "doc1"),
+				Permission: "view",
+				Subject:    sub("user", "tom", "member"),
+			},
+			expectedCode:   codes.FailedPrecondition,
+			expectedReason: v1.ErrorReason_ERROR_REASON_UNKNOWN_DEFINITION,
+			expectedViolations: map[string]string{
+				"resource.object_type":      "object definition `notdocument` not found",
+				"subject.optional_relation": "relation/permission `member` not found under definition `user`",
+			},
+		},
+		{
+			name: "check with unknown permission",
+			request: &v1.CheckPermissionRequest{
+				Resource:   obj("document", "doc1"),
+				Permission: "edit",
+				Subject:    sub("user", "tom", ""),
+			},
+			expectedCode:   codes.FailedPrecondition,
+			expectedReason: v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION,
+			expectedViolations: map[string]string{
+				"permission": "relation/permission `edit` not found under definition `document`",
+			},
+		},
+		{
+			name: "check with invalid context",
+			request: &v1.CheckPermissionRequest{
+				Resource:   obj("document", "doc1"),
+				Permission: "view",
+				Subject:    sub("user", "tom", ""),
+				Context:    mustStruct(t, map[string]any{"day": []any{"monday"}, "hour": 12}),
+			},
+			expectedCode:   codes.InvalidArgument,
+			expectedReason: v1.ErrorReason_ERROR_REASON_CAVEAT_PARAMETER_TYPE_ERROR,
+			expectedViolations: map[string]string{
+				"context.day": "type error for parameters for caveat `only_on`",
+			},
+		},
+		{
+			name: "check with context for unknown parameter",
+			request: &v1.CheckPermissionRequest{
+				Resource:   obj("document", "doc1"),
+				Permission: "view",
+				Subject:    sub("user", "tom", ""),
+				Context:    mustStruct(t, map[string]any{"day": "monday", "hour": 12}),
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "lookup subjects with unknown subject relation",
+			request: &v1.LookupSubjectsRequest{
+				Resource:                obj("document", "doc1"),
+				Permission:              "view",
+				SubjectObjectType:       "group",
+				OptionalSubjectRelation: "admin",
+			},
+			expectedCode:   codes.FailedPrecondition,
+			expectedReason: v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION,
+			expectedViolations: map[string]string{
+				"optional_subject_relation": "relation/permission `admin` not found under definition `group`",
+			},
+		},
+		{
+			name: "read with unknown filter types",
+			request: &v1.ReadRelationshipsRequest{
+				RelationshipFilter: &v1.RelationshipFilter{
+					ResourceType:          "document",
+					OptionalRelation:      "owner",
+					OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: "team"},
+				},
+			},
+			expectedCode:   codes.FailedPrecondition,
+			expectedReason: v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION,
+			expectedViolations: map[string]string{
+				"relationship_filter.optional_relation":                    "relation/permission `owner` not found under definition `document`",
+				"relationship_filter.optional_subject_filter.subject_type": "object definition `team` not found",
+			},
+		},
+		{
+			name: "valid write",
+			request: &v1.WriteRelationshipsRequest{
+				Updates: []*v1.RelationshipUpdate{
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: obj("document", "doc1"),
+							Relation: "viewer",
+							Subject:  sub("user", "tom", ""),
+							OptionalCaveat: &v1.ContextualizedCaveat{
+								CaveatName: "only_on",
+								Context:    mustStruct(t, map[string]any{"day": "tuesday"}),
+							},
+						},
+					},
+				},
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "write with duplicate invalid updates",
+			request: &v1.WriteRelationshipsRequest{
+				Updates: []*v1.RelationshipUpdate{
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: obj("group", "eng"),
+							Relation: "member",
+							Subject:  sub("document", "doc1", ""),
+						},
+					},
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_DELETE,
+						Relationship: &v1.Relationship{
+							Resource: obj("group", "eng"),
+							Relation: "member",
+							Subject:  sub("document", "doc1", ""),
+						},
+					},
+				},
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "write with invalid updates",
+			request: &v1.WriteRelationshipsRequest{
+				Updates: []*v1.RelationshipUpdate{
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: obj("document", "doc1"),
+							Relation: "view",
+							Subject:  sub("user", "tom", ""),
+						},
+					},
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: obj("group", "eng"),
+							Relation: "member",
+							Subject:  sub("document", "doc1", ""),
+						},
+					},
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: obj("document", "doc1"),
+							Relation: "viewer",
+							Subject:  sub("user", "tom", ""),
+							OptionalCaveat: &v1.ContextualizedCaveat{
+								CaveatName: "only_on",
+								Context:    mustStruct(t, map[string]any{"hour": 12}),
+							},
+						},
+					},
+				},
+			},
+			expectedCode:   codes.InvalidArgument,
+			expectedReason: v1.ErrorReason_ERROR_REASON_CANNOT_UPDATE_PERMISSION,
+			expectedViolations: map[string]string{
+				"updates[0].relationship.relation":                     "cannot write a relationship to permission `view` under definition `document`",
+				"updates[1].relationship.subject":                      "are not allowed on relation `group#member`",
+				"updates[2].relationship.optional_caveat.context.hour": "unknown parameter `hour`",
+			},
+		},
+		{
+			name: "watch with unknown object type",
+			request: &v1.WatchRequest{
+				OptionalObjectTypes: []string{"document", "folder"},
+			},
+			expectedCode:   codes.FailedPrecondition,
+			expectedReason: v1.ErrorReason_ERROR_REASON_UNKNOWN_DEFINITION,
+			expectedViolations: map[string]string{
+				"optional_object_types[1]": "object definition `folder` not found",
+			},
+		},
+	}
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := tf.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+	reader := ds.SnapshotReader(revision)
+	sv := newSchemaValidator()
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := sv.validateRequest(context.Background(), reader, tc.request)
+			if tc.expectedCode == codes.OK {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Equal(t, tc.expectedCode, status.Code(err))
+			spiceerrors.RequireReason(t, tc.expectedReason, err)
+
+			var badRequest *errdetails.BadRequest
+			for _, detail := range status.Convert(err).Details() {
+				if found, ok := detail.(*errdetails.BadRequest); ok {
+					badRequest = found
+				}
+			}
+			require.NotNil(t, badRequest)
+
+			violations := make(map[string]string, len(badRequest.FieldViolations))
+			for _, violation := range badRequest.FieldViolations {
+				violations[violation.Field] = violation.Description
+			}
+
+			require.Len(t, violations, len(tc.expectedViolations))
+			for field, description := range tc.expectedViolations {
+				require.Contains(t, violations, field)
+				require.Contains(t, violations[field], description)
+			}
+		})
+	}
+}
+
+func TestDisabledInterceptorsSkipValidation(t *testing.T) {
+	// The context has no datastore, so validating the request would panic.
+	request := &v1.CheckPermissionRequest{
+		Resource:   obj("notdocument", "doc1"),
+		Permission: "view",
+		Subject:    sub("user", "tom", ""),
+	}
+
+	called := false
+	_, err := UnaryServerInterceptor(false)(context.Background(), request, nil, func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.True(t, called)
+
+	called = false
+	err = StreamServerInterceptor(false)(nil, nil, nil, func(srv any, stream grpc.ServerStream) error {
+		called = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+type fixedDefinitionReader struct {
+	datastore.Reader
+	definition *core.NamespaceDefinition
+}
+
+func (r fixedDefinitionReader) ReadNamespaceByName(_ context.Context, _ string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	return r.definition, datastore.NoRevision, nil
+}
+
+func TestTypeSystemCacheReusesTypeSystemForSameDefinition(t *testing.T) {
+	cache := newTypeSystemCache()
+	definition := &core.NamespaceDefinition{Name: "document"}
+
+	first, err := cache.typeSystem(context.Background(), fixedDefinitionReader{definition: definition}, "document")
+	require.NoError(t, err)
+
+	second, err := cache.typeSystem(context.Background(), fixedDefinitionReader{definition: definition}, "document")
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	changed, err := cache.typeSystem(context.Background(), fixedDefinitionReader{definition: definition.CloneVT()}, "document")
+	require.NoError(t, err)
+	require.NotSame(t, first, changed)
+}
+
+func TestTypeSystemCacheIsBounded(t *testing.T) {
+	cache := newTypeSystemCache()
+	for i := 0; i < maxCachedTypeSystems+10; i++ {
+		name := fmt.Sprintf("document%d", i)
+		_, err := cache.typeSystem(context.Background(), fixedDefinitionReader{definition: &core.NamespaceDefinition{Name: name}}, name)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, cache.byName, maxCachedTypeSystems)
+}
+
+type staleOptimizedRevisionDatastore struct {
+	datastore.Datastore
+	optimized datastore.Revision
+}
+
+func (ds staleOptimizedRevisionDatastore) OptimizedRevision(_ context.Context) (datastore.Revision, error) {
+	return ds.optimized, nil
+}
+
+func TestValidateIncomingRequestRechecksStaleOptimizedRevision(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := tf.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+	_, _ = tf.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema+`definition folder {
+		relation viewer: user
+	}`, nil, require.New(t))
+
+	ctx := datastoremw.ContextWithDatastore(context.Background(), staleOptimizedRevisionDatastore{ds, revision})
+	sv := newSchemaValidator()
+
+	err = sv.validateIncomingRequest(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: &v1.Relationship{
+				Resource: obj("folder", "folder1"),
+				Relation: "viewer",
+				Subject:  sub("user", "tom", ""),
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	err = sv.validateIncomingRequest(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: &v1.Relationship{
+				Resource: obj("folder", "folder1"),
+				Relation: "editor",
+				Subject:  sub("user", "tom", ""),
+			},
+		}},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,162 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/balancer"
+	"github.com/authzed/spicedb/pkg/datastore"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+const (
+	peerRequestTimeout = 1 * time.Second
+
+	// ownerAnswerTTL is how long the latest write of a session read from its owner is reused for
+	// before the owner is asked again, which bounds how stale the writes made through other nodes
+	// can be when read through this one.
+	ownerAnswerTTL = 1 * time.Second
+
+	// maxOwnerAnswers is the maximum number of answers of owners kept; beyond it, arbitrary
+	// answers are dropped.
+	maxOwnerAnswers = 10_000
+)
+
+type sessionClient interface {
+	RecordSessionWrite(ctx context.Context, req *dispatchv1.RecordSessionWriteRequest, opts ...grpc.CallOption) (*dispatchv1.RecordSessionWriteResponse, error)
+	GetSessionWrite(ctx context.Context, req *dispatchv1.GetSessionWriteRequest, opts ...grpc.CallOption) (*dispatchv1.GetSessionWriteResponse, error)
+}
+
+// NewPeerTracker returns a Tracker which records the writes of each session both in the local
+// tracker and on the node of the cluster owning the session, so that reads made in the session
+// through any node observe them. The owner is the node picked for the session identifier by the
+// consistent hashring of the dispatch connection of the client, whose SessionService must serve
+// the local tracker of each node.
+//
+// Writes are propagated to the owner as they are made, but reads do not query it each time: its
+// answer for a session is reused for ownerAnswerTTL. Writes made through this node are always
+// observed by reads through it, while those made through other nodes are observed within
+// ownerAnswerTTL.
+//
+// Peers are used on a best-effort basis: if the owner of a session cannot be reached, the
+// local tracker is used alone.
+func NewPeerTracker(local Tracker, client sessionClient) Tracker {
+	return &peerTracker{
+		local:        local,
+		client:       client,
+		ownerAnswers: map[string]ownerAnswer{},
+		now:          time.Now,
+	}
+}
+
+type peerTracker struct {
+	sync.Mutex
+
+	local  Tracker
+	client sessionClient
+
+	ownerAnswers map[string]ownerAnswer
+	now          func() time.Time
+}
+
+// ownerAnswer is the latest write of a session read from its owner, nil if it had none.
+type ownerAnswer struct {
+	latestWrite datastore.Revision
+	readAt      time.Time
+}
+
+func peerContext(ctx context.Context, sessionID string) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, balancer.CtxKey, []byte(sessionID))
+	return context.WithTimeout(ctx, peerRequestTimeout)
+}
+
+func (pt *peerTracker) RecordWrite(ctx context.Context, sessionID string, written datastore.Revision) error {
+	if err := pt.local.RecordWrite(ctx, sessionID, written); err != nil {
+		return err
+	}
+
+	peerCtx, cancel := peerContext(ctx, sessionID)
+	defer cancel()
+
+	_, err := pt.client.RecordSessionWrite(peerCtx, &dispatchv1.RecordSessionWriteRequest{
+		SessionId: sessionID,
+		WrittenAt: zedtoken.MustNewFromRevision(written).Token,
+	})
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("session", sessionID).Msg("session: could not record write on peer")
+	}
+	return nil
+}
+
+func (pt *peerTracker) LatestWrite(ctx context.Context, sessionID string) (datastore.Revision, error) {
+	latest, err := pt.local.LatestWrite(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerLatest, err := pt.ownerLatestWrite(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest == nil || (ownerLatest != nil && ownerLatest.GreaterThan(latest)) {
+		return ownerLatest, nil
+	}
+	return latest, nil
+}
+
+// ownerLatestWrite returns the latest write of the session known to its owner, reusing the
+// answer of the owner if it was read less than ownerAnswerTTL ago.
+func (pt *peerTracker) ownerLatestWrite(ctx context.Context, sessionID string) (datastore.Revision, error) {
+	ds := datastoremw.FromContext(ctx)
+	if ds == nil {
+		return nil, nil
+	}
+
+	pt.Lock()
+	answer, ok := pt.ownerAnswers[sessionID]
+	pt.Unlock()
+	if ok && pt.now().Sub(answer.readAt) < ownerAnswerTTL {
+		return answer.latestWrite, nil
+	}
+
+	peerCtx, cancel := peerContext(ctx, sessionID)
+	defer cancel()
+
+	resp, err := pt.client.GetSessionWrite(peerCtx, &dispatchv1.GetSessionWriteRequest{SessionId: sessionID})
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("session", sessionID).Msg("session: could not read latest write from peer")
+		return nil, nil
+	}
+
+	var ownerLatest datastore.Revision
+	if resp.WrittenAt != "" {
+		ownerLatest, err = zedtoken.DecodeRevision(&v1.ZedToken{Token: resp.WrittenAt}, ds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pt.Lock()
+	defer pt.Unlock()
+
+	// Beyond the maximum, answers are dropped until a live one is, so that expired answers are
+	// dropped along the way.
+	if _, ok := pt.ownerAnswers[sessionID]; !ok && len(pt.ownerAnswers) >= maxOwnerAnswers {
+		for id, answer := range pt.ownerAnswers {
+			delete(pt.ownerAnswers, id)
+			if pt.now().Sub(answer.readAt) < ownerAnswerTTL {
+				break
+			}
+		}
+	}
+	pt.ownerAnswers[sessionID] = ownerAnswer{ownerLatest, pt.now()}
+	return ownerLatest, nil
+}
@@ -0,0 +1,196 @@
+// Package session implements session consistency: reads made in a session are performed at
+// a revision at least as fresh as the latest write of the session, without the client passing
+// ZedTokens along or requesting full consistency. Sessions are opt-in: the client picks the
+// identifier of its session and sends it in the metadata of each request, and requests made
+// without one are not tracked.
+package session
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// MetadataKey is the key in the request metadata carrying the identifier of the session the
+// request is made in.
+const MetadataKey requestmeta.RequestMetadataHeaderKey = "io.spicedb.session"
+
+var validSessionID = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,128}$`)
+
+// Tracker tracks the revision of the latest write of each session.
+type Tracker interface {
+	// RecordWrite records a write made in the session at the revision, unless a later write was
+	// already recorded for the session.
+	RecordWrite(ctx context.Context, sessionID string, written datastore.Revision) error
+
+	// LatestWrite returns the revision of the latest write recorded for the session, or nil if
+	// none is tracked.
+	LatestWrite(ctx context.Context, sessionID string) (datastore.Revision, error)
+}
+
+type ctxKeyType struct{}
+
+var latestWriteKey ctxKeyType = struct{}{}
+
+// LatestWriteFromContext returns the revision of the latest write of the session the request is
+// made in, or nil if the request has no session or the session has no tracked write.
+func LatestWriteFromContext(ctx context.Context) datastore.Revision {
+	if written, ok := ctx.Value(latestWriteKey).(datastore.Revision); ok {
+		return written
+	}
+	return nil
+}
+
+// sessionFromContext returns the identifier of the session of the request, or an empty string if
+// the request has none.
+func sessionFromContext(ctx context.Context) (string, error) {
+	values := metadata.ValueFromIncomingContext(ctx, string(MetadataKey))
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	if !validSessionID.MatchString(values[0]) {
+		return "", status.Errorf(codes.InvalidArgument, "invalid session identifier in `%s` metadata", MetadataKey)
+	}
+	return values[0], nil
+}
+
+// ContextWithLatestWrite returns a context in which the latest write of the session of the
+// request is at the revision.
+func ContextWithLatestWrite(ctx context.Context, written datastore.Revision) context.Context {
+	return context.WithValue(ctx, latestWriteKey, written)
+}
+
+func contextWithTrackedWrite(ctx context.Context, tracker Tracker, sessionID string) (context.Context, error) {
+	if sessionID == "" {
+		return ctx, nil
+	}
+
+	written, err := tracker.LatestWrite(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if written == nil {
+		return ctx, nil
+	}
+	return ContextWithLatestWrite(ctx, written), nil
+}
+
+type hasWrittenAt interface {
+	GetWrittenAt() *v1.ZedToken
+}
+
+type hasDeletedAt interface {
+	GetDeletedAt() *v1.ZedToken
+}
+
+// writtenRevision returns the revision at which the request whose response is given wrote, or nil
+// if the request is not a write.
+func writtenRevision(ctx context.Context, resp interface{}) (datastore.Revision, error) {
+	ds := datastoremw.FromContext(ctx)
+	if ds == nil {
+		return nil, nil
+	}
+
+	var token *v1.ZedToken
+	switch resp := resp.(type) {
+	case hasWrittenAt:
+		token = resp.GetWrittenAt()
+	case hasDeletedAt:
+		token = resp.GetDeletedAt()
+	case *v1.WriteSchemaResponse:
+		// Schema writes do not return their revision, which the head revision is at least as
+		// fresh as.
+		return ds.HeadRevision(ctx)
+	}
+
+	if token == nil {
+		return nil, nil
+	}
+	return zedtoken.DecodeRevision(token, ds)
+}
+
+func recordWrite(ctx context.Context, tracker Tracker, sessionID string, resp interface{}) {
+	if sessionID == "" {
+		return
+	}
+
+	written, err := writtenRevision(ctx, resp)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("session: could not determine the revision of the write")
+		return
+	}
+	if written == nil {
+		return
+	}
+
+	if err := tracker.RecordWrite(ctx, sessionID, written); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("session", sessionID).Msg("session: could not record write")
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which records the writes of each
+// session in the tracker, and sets the latest write of the session of the request in its
+// context for the consistency middleware. If the tracker is nil, sessions are ignored.
+func UnaryServerInterceptor(tracker Tracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if tracker == nil {
+			return handler(ctx, req)
+		}
+
+		sessionID, err := sessionFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		newCtx, err := contextWithTrackedWrite(ctx, tracker, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(newCtx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		recordWrite(newCtx, tracker, sessionID, resp)
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which sets the latest write
+// of the session of the request in its context for the consistency middleware. If the tracker
+// is nil, sessions are ignored.
+func StreamServerInterceptor(tracker Tracker) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if tracker == nil {
+			return handler(srv, stream)
+		}
+
+		sessionID, err := sessionFromContext(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		ctx, err := contextWithTrackedWrite(stream.Context(), tracker, sessionID)
+		if err != nil {
+			return err
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,256 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func rev(n int64) datastore.Revision {
+	return revision.NewFromDecimal(decimal.NewFromInt(n))
+}
+
+func requireRevision(t *testing.T, expected int64, actual datastore.Revision) {
+	t.Helper()
+	require.NotNil(t, actual)
+	require.True(t, rev(expected).Equal(actual), "expected revision %d, found %s", expected, actual)
+}
+
+func TestMemoryTrackerKeepsLatestWrite(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewMemoryTracker(0, 0)
+
+	written, err := tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	require.Nil(t, written)
+
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(5)))
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(3)))
+	require.NoError(t, tracker.RecordWrite(ctx, "second", rev(1)))
+
+	written, err = tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	requireRevision(t, 5, written)
+
+	written, err = tracker.LatestWrite(ctx, "second")
+	require.NoError(t, err)
+	requireRevision(t, 1, written)
+}
+
+func TestMemoryTrackerEviction(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewMemoryTracker(2, time.Minute).(*memoryTracker)
+
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(1)))
+	require.NoError(t, tracker.RecordWrite(ctx, "second", rev(2)))
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(3)))
+	require.NoError(t, tracker.RecordWrite(ctx, "third", rev(4)))
+
+	// The least recently written session is evicted beyond the maximum.
+	written, err := tracker.LatestWrite(ctx, "second")
+	require.NoError(t, err)
+	require.Nil(t, written)
+
+	now = now.Add(45 * time.Second)
+	require.NoError(t, tracker.RecordWrite(ctx, "third", rev(5)))
+
+	// Sessions which have not written for the TTL are evicted.
+	now = now.Add(30 * time.Second)
+	written, err = tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	require.Nil(t, written)
+
+	written, err = tracker.LatestWrite(ctx, "third")
+	require.NoError(t, err)
+	requireRevision(t, 5, written)
+}
+
+type headerRecorder struct {
+	header metadata.MD
+}
+
+func (hr *headerRecorder) Method() string { return "" }
+
+func (hr *headerRecorder) SetHeader(md metadata.MD) error {
+	hr.header = metadata.Join(hr.header, md)
+	return nil
+}
+
+func (hr *headerRecorder) SendHeader(md metadata.MD) error { return hr.SetHeader(md) }
+
+func (hr *headerRecorder) SetTrailer(_ metadata.MD) error { return nil }
+
+func requestContext(t *testing.T, ds datastore.Datastore, sessionID string) (context.Context, *headerRecorder) {
+	ctx := datastoremw.ContextWithHandle(context.Background())
+	require.NoError(t, datastoremw.SetInContext(ctx, ds))
+
+	if sessionID != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(string(MetadataKey), sessionID))
+	}
+
+	recorder := &headerRecorder{}
+	return grpc.NewContextWithServerTransportStream(ctx, recorder), recorder
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	tracker := NewMemoryTracker(0, 0)
+	interceptor := UnaryServerInterceptor(tracker)
+	info := &grpc.UnaryServerInfo{}
+
+	// Writes made without a session are not tracked.
+	ctx, recorder := requestContext(t, ds, "")
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		require.Nil(t, LatestWriteFromContext(ctx))
+		return &v1.WriteRelationshipsResponse{WrittenAt: zedtoken.MustNewFromRevision(rev(5))}, nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, recorder.header)
+	require.Empty(t, tracker.(*memoryTracker).sessions)
+
+	// Writes made in a session are recorded in it.
+	sessionID := "some-session"
+	ctx, _ = requestContext(t, ds, sessionID)
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		require.Nil(t, LatestWriteFromContext(ctx))
+		return &v1.WriteRelationshipsResponse{WrittenAt: zedtoken.MustNewFromRevision(rev(7))}, nil
+	})
+	require.NoError(t, err)
+
+	// Requests made in the session observe its latest write.
+	ctx, _ = requestContext(t, ds, sessionID)
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		requireRevision(t, 7, LatestWriteFromContext(ctx))
+		return &v1.CheckPermissionResponse{}, nil
+	})
+	require.NoError(t, err)
+
+	// Later writes in the session are recorded in it.
+	ctx, _ = requestContext(t, ds, sessionID)
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &v1.DeleteRelationshipsResponse{DeletedAt: zedtoken.MustNewFromRevision(rev(9))}, nil
+	})
+	require.NoError(t, err)
+
+	written, err := tracker.LatestWrite(context.Background(), sessionID)
+	require.NoError(t, err)
+	requireRevision(t, 9, written)
+
+	// Failed writes are not recorded.
+	ctx, _ = requestContext(t, ds, sessionID)
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &v1.WriteRelationshipsResponse{WrittenAt: zedtoken.MustNewFromRevision(rev(11))}, errors.New("failed")
+	})
+	require.Error(t, err)
+
+	written, err = tracker.LatestWrite(context.Background(), sessionID)
+	require.NoError(t, err)
+	requireRevision(t, 9, written)
+}
+
+func TestInvalidSessionID(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx, _ := requestContext(t, ds, "not a session")
+	_, err = UnaryServerInterceptor(NewMemoryTracker(0, 0))(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type fakeSessionClient struct {
+	written map[string]string
+	err     error
+	reads   int
+}
+
+func (fsc *fakeSessionClient) RecordSessionWrite(_ context.Context, req *dispatchv1.RecordSessionWriteRequest, _ ...grpc.CallOption) (*dispatchv1.RecordSessionWriteResponse, error) {
+	if fsc.err != nil {
+		return nil, fsc.err
+	}
+	fsc.written[req.SessionId] = req.WrittenAt
+	return &dispatchv1.RecordSessionWriteResponse{}, nil
+}
+
+func (fsc *fakeSessionClient) GetSessionWrite(_ context.Context, req *dispatchv1.GetSessionWriteRequest, _ ...grpc.CallOption) (*dispatchv1.GetSessionWriteResponse, error) {
+	fsc.reads++
+	if fsc.err != nil {
+		return nil, fsc.err
+	}
+	return &dispatchv1.GetSessionWriteResponse{WrittenAt: fsc.written[req.SessionId]}, nil
+}
+
+func TestPeerTracker(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ctx, _ := requestContext(t, ds, "")
+
+	client := &fakeSessionClient{written: map[string]string{}}
+	local := NewMemoryTracker(0, 0)
+	tracker := NewPeerTracker(local, client).(*peerTracker)
+
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(3)))
+	require.Equal(t, zedtoken.MustNewFromRevision(rev(3)).Token, client.written["first"])
+
+	// Writes recorded on the owner by other nodes are observed.
+	client.written["first"] = zedtoken.MustNewFromRevision(rev(8)).Token
+	written, err := tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	requireRevision(t, 8, written)
+	require.Equal(t, 1, client.reads)
+
+	// The answer of the owner is reused for a while, without asking it again.
+	client.written["first"] = zedtoken.MustNewFromRevision(rev(10)).Token
+	written, err = tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	requireRevision(t, 8, written)
+	require.Equal(t, 1, client.reads)
+
+	// Writes made through this node are observed without asking the owner.
+	require.NoError(t, tracker.RecordWrite(ctx, "first", rev(12)))
+	written, err = tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	requireRevision(t, 12, written)
+	require.Equal(t, 1, client.reads)
+
+	// The owner is asked again once its answer has expired.
+	client.written["first"] = zedtoken.MustNewFromRevision(rev(14)).Token
+	now = now.Add(ownerAnswerTTL)
+	written, err = tracker.LatestWrite(ctx, "first")
+	require.NoError(t, err)
+	requireRevision(t, 14, written)
+	require.Equal(t, 2, client.reads)
+
+	// The local tracker is used alone when the owner cannot be reached.
+	client.err = errors.New("unavailable")
+	require.NoError(t, tracker.RecordWrite(ctx, "second", rev(4)))
+
+	written, err = tracker.LatestWrite(ctx, "second")
+	require.NoError(t, err)
+	requireRevision(t, 4, written)
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// NewMemoryTracker returns a Tracker holding the sessions in memory. A session is forgotten once
+// it has not written for the TTL, or once more than maxSessions sessions have written since it
+// last did. A TTL or maximum of 0 disables the corresponding limit.
+func NewMemoryTracker(maxSessions int, ttl time.Duration) Tracker {
+	return &memoryTracker{
+		recentlyWritten: list.New(),
+		sessions:        map[string]*list.Element{},
+		maxSessions:     maxSessions,
+		ttl:             ttl,
+		now:             time.Now,
+	}
+}
+
+type memoryTracker struct {
+	sync.Mutex
+
+	// recentlyWritten holds a *trackedSession for each session, the most recently written first.
+	recentlyWritten *list.List
+	sessions        map[string]*list.Element
+
+	maxSessions int
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+type trackedSession struct {
+	id          string
+	latestWrite datastore.Revision
+	writtenAt   time.Time
+}
+
+func (mt *memoryTracker) RecordWrite(_ context.Context, sessionID string, written datastore.Revision) error {
+	mt.Lock()
+	defer mt.Unlock()
+
+	now := mt.now()
+	mt.evictExpiredLocked(now)
+
+	if elem, ok := mt.sessions[sessionID]; ok {
+		tracked := elem.Value.(*trackedSession)
+		if written.GreaterThan(tracked.latestWrite) {
+			tracked.latestWrite = written
+		}
+		tracked.writtenAt = now
+		mt.recentlyWritten.MoveToFront(elem)
+		return nil
+	}
+
+	mt.sessions[sessionID] = mt.recentlyWritten.PushFront(&trackedSession{sessionID, written, now})
+	for mt.maxSessions > 0 && mt.recentlyWritten.Len() > mt.maxSessions {
+		mt.removeLocked(mt.recentlyWritten.Back())
+	}
+	return nil
+}
+
+func (mt *memoryTracker) LatestWrite(_ context.Context, sessionID string) (datastore.Revision, error) {
+	mt.Lock()
+	defer mt.Unlock()
+
+	mt.evictExpiredLocked(mt.now())
+
+	elem, ok := mt.sessions[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return elem.Value.(*trackedSession).latestWrite, nil
+}
+
+func (mt *memoryTracker) evictExpiredLocked(now time.Time) {
+	if mt.ttl <= 0 {
+		return
+	}
+
+	for elem := mt.recentlyWritten.Back(); elem != nil; elem = mt.recentlyWritten.Back() {
+		if now.Sub(elem.Value.(*trackedSession).writtenAt) <= mt.ttl {
+			return
+		}
+		mt.removeLocked(elem)
+	}
+}
+
+func (mt *memoryTracker) removeLocked(elem *list.Element) {
+	tracked := mt.recentlyWritten.Remove(elem).(*trackedSession)
+	delete(mt.sessions, tracked.id)
+}
@@ -154,6 +154,14 @@ func convertToBdd(relation *core.Relation, bdd *rudd.BDD, so *core.SetOperation,
 
 			values = append(values, builder(index, arrowIndex))
 
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			arrowIndex, err := varMap.GetFunctionedArrow(child.FunctionedTupleToUserset)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, builder(index, arrowIndex))
+
 		case *core.SetOperation_Child_XNil:
 			values = append(values, builder(index, varMap.Nil()))
 
@@ -170,7 +178,20 @@ type bddVarMap struct {
 }
 
 func (bvm bddVarMap) GetArrow(tuplesetName string, relName string) (int, error) {
-	key := tuplesetName + "->" + relName
+	key := arrowKey(tuplesetName, relName)
+	index, ok := bvm.varMap[key]
+	if !ok {
+		return -1, spiceerrors.MustBugf("missing arrow key %s in varMap", key)
+	}
+	return index, nil
+}
+
+func (bvm bddVarMap) GetFunctionedArrow(ttu *core.FunctionedTupleToUserset) (int, error) {
+	key, err := functionedArrowKey(ttu)
+	if err != nil {
+		return -1, err
+	}
+
 	index, ok := bvm.varMap[key]
 	if !ok {
 		return -1, spiceerrors.MustBugf("missing arrow key %s in varMap", key)
@@ -212,16 +233,29 @@ func buildBddVarMap(relations []*core.Relation, aliasMap map[string]string) (bdd
 			continue
 		}
 
-		_, err := graph.WalkRewrite(rewrite, func(childOneof *core.SetOperation_Child) interface{} {
+		rerr, err := graph.WalkRewrite(rewrite, func(childOneof *core.SetOperation_Child) interface{} {
 			switch child := childOneof.ChildType.(type) {
 			case *core.SetOperation_Child_TupleToUserset:
-				key := child.TupleToUserset.Tupleset.Relation + "->" + child.TupleToUserset.ComputedUserset.Relation
+				key := arrowKey(child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation)
+				if _, ok := varMap[key]; !ok {
+					varMap[key] = len(varMap)
+				}
+
+			case *core.SetOperation_Child_FunctionedTupleToUserset:
+				key, err := functionedArrowKey(child.FunctionedTupleToUserset)
+				if err != nil {
+					return err
+				}
+
 				if _, ok := varMap[key]; !ok {
 					varMap[key] = len(varMap)
 				}
 			}
 			return nil
 		})
+		if rerr != nil {
+			return bddVarMap{}, rerr.(error)
+		}
 		if err != nil {
 			return bddVarMap{}, err
 		}
@@ -231,3 +265,20 @@ func buildBddVarMap(relations []*core.Relation, aliasMap map[string]string) (bdd
 		varMap:   varMap,
 	}, nil
 }
+
+func arrowKey(tuplesetName string, relName string) string {
+	return tuplesetName + "->" + relName
+}
+
+// functionedArrowKey returns the variable key for the arrow. An arrow with the `any` function
+// has the same key as the plain arrow, as the two are equivalent.
+func functionedArrowKey(ttu *core.FunctionedTupleToUserset) (string, error) {
+	switch ttu.Function {
+	case core.FunctionedTupleToUserset_FUNCTION_ANY:
+		return arrowKey(ttu.Tupleset.Relation, ttu.ComputedUserset.Relation), nil
+	case core.FunctionedTupleToUserset_FUNCTION_ALL:
+		return ttu.Tupleset.Relation + ".all(" + ttu.ComputedUserset.Relation + ")", nil
+	default:
+		return "", spiceerrors.MustBugf("unknown function %v for arrow", ttu.Function)
+	}
+}
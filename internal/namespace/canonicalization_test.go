@@ -501,6 +501,24 @@ func TestCanonicalizationComparison(t *testing.T) {
 			"(owner & nil) & editor",
 			true,
 		},
+		{
+			"arrow and any arrow",
+			"viewer->owner",
+			"viewer.any(owner)",
+			true,
+		},
+		{
+			"arrow and all arrow",
+			"viewer->owner",
+			"viewer.all(owner)",
+			false,
+		},
+		{
+			"all arrow associativity",
+			"viewer.all(owner) + editor",
+			"editor + viewer.all(owner)",
+			true,
+		},
 	}
 
 	for _, tc := range testCases {
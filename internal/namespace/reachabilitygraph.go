@@ -62,6 +62,18 @@ func (re ReachabilityEntrypoint) IsDirectResult() bool {
 	return re.re.ResultStatus == core.ReachabilityEntrypoint_DIRECT_OPERATION_RESULT
 }
 
+// SortKey returns a key for the entrypoint that is stable across calls, for use in ordering
+// entrypoints deterministically.
+func (re ReachabilityEntrypoint) SortKey() string {
+	return fmt.Sprintf("%s|%d|%s|%s|%d",
+		tuple.StringRR(re.parentRelation),
+		re.re.Kind,
+		tuple.StringRR(re.re.TargetRelation),
+		re.re.TuplesetRelation,
+		re.re.ResultStatus,
+	)
+}
+
 // ReachabilityGraphFor returns a reachability graph for the given namespace.
 func ReachabilityGraphFor(ts *ValidatedNamespaceTypeSystem) *ReachabilityGraph {
 	return &ReachabilityGraph{ts.TypeSystem, sync.Map{}, sync.Map{}}
@@ -142,6 +142,50 @@ func TestReachabilityGraph(t *testing.T) {
 				rrt("organization", "admin", true),
 			},
 		},
+		{
+			"permission with arrow from subject relation",
+			`definition user {}
+
+			definition organization {
+				relation admin: user
+			}
+
+			definition document {
+				relation org: organization
+				relation viewer: user
+				permission view = viewer + org->admin
+			}`,
+			rr("document", "view"),
+			rr("organization", "admin"),
+			[]rrtStruct{
+				rrt("document", "view", true),
+			},
+			[]rrtStruct{
+				rrt("document", "view", true),
+			},
+		},
+		{
+			"permission with all arrow from subject relation",
+			`definition user {}
+
+			definition organization {
+				relation admin: user
+			}
+
+			definition document {
+				relation org: organization
+				relation viewer: user
+				permission view = viewer + org.all(admin)
+			}`,
+			rr("document", "view"),
+			rr("organization", "admin"),
+			[]rrtStruct{
+				rrt("document", "view", false),
+			},
+			[]rrtStruct{
+				rrt("document", "view", false),
+			},
+		},
 		{
 			"permission with multi-level arrows",
 			`definition user {}
@@ -326,6 +326,46 @@ func (nts *TypeSystem) AsValidated() *ValidatedNamespaceTypeSystem {
 	return &ValidatedNamespaceTypeSystem{nts}
 }
 
+// validateTuplesetRelation ensures the relation found on the left side of an arrow exists, is
+// not a permission and does not reference a wildcard.
+func (nts *TypeSystem) validateTuplesetRelation(ctx context.Context, relation *core.Relation, childOneof *core.SetOperation_Child, relationName string) error {
+	found, ok := nts.relationMap[relationName]
+	if !ok {
+		return newTypeErrorWithSource(
+			NewRelationNotFoundErr(nts.nsDef.Name, relationName),
+			childOneof,
+			relationName,
+		)
+	}
+
+	if nspkg.GetRelationKind(found) == iv1.RelationMetadata_PERMISSION {
+		return newTypeErrorWithSource(
+			NewPermissionUsedOnLeftOfArrowErr(nts.nsDef.Name, relation.Name, relationName),
+			childOneof, relationName)
+	}
+
+	// Ensure the tupleset relation doesn't itself import wildcard.
+	referencedWildcard, err := nts.ReferencesWildcardType(ctx, relationName)
+	if err != nil {
+		return err
+	}
+
+	if referencedWildcard != nil {
+		return newTypeErrorWithSource(
+			NewWildcardUsedInArrowErr(
+				nts.nsDef.Name,
+				relation.Name,
+				relationName,
+				referencedWildcard.WildcardType.GetNamespace(),
+				tuple.StringRR(referencedWildcard.ReferencingRelation),
+			),
+			childOneof, relationName,
+		)
+	}
+
+	return nil
+}
+
 // Validate runs validation on the type system for the namespace to ensure it is consistent.
 func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSystem, error) {
 	for _, relation := range nts.relationMap {
@@ -356,40 +396,20 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 					return nil
 				}
 
-				relationName := tupleset.GetRelation()
-				found, ok := nts.relationMap[relationName]
-				if !ok {
-					return newTypeErrorWithSource(
-						NewRelationNotFoundErr(nts.nsDef.Name, relationName),
-						childOneof,
-						relationName,
-					)
-				}
+				return nts.validateTuplesetRelation(ctx, relation, childOneof, tupleset.GetRelation())
 
-				if nspkg.GetRelationKind(found) == iv1.RelationMetadata_PERMISSION {
-					return newTypeErrorWithSource(
-						NewPermissionUsedOnLeftOfArrowErr(nts.nsDef.Name, relation.Name, relationName),
-						childOneof, relationName)
+			case *core.SetOperation_Child_FunctionedTupleToUserset:
+				ttu := child.FunctionedTupleToUserset
+				if ttu == nil {
+					return nil
 				}
 
-				// Ensure the tupleset relation doesn't itself import wildcard.
-				referencedWildcard, err := nts.ReferencesWildcardType(ctx, relationName)
-				if err != nil {
-					return err
+				tupleset := ttu.GetTupleset()
+				if tupleset == nil {
+					return nil
 				}
 
-				if referencedWildcard != nil {
-					return newTypeErrorWithSource(
-						NewWildcardUsedInArrowErr(
-							nts.nsDef.Name,
-							relation.Name,
-							relationName,
-							referencedWildcard.WildcardType.GetNamespace(),
-							tuple.StringRR(referencedWildcard.ReferencingRelation),
-						),
-						childOneof, relationName,
-					)
-				}
+				return nts.validateTuplesetRelation(ctx, relation, childOneof, tupleset.GetRelation())
 			}
 			return nil
 		})
@@ -530,6 +550,14 @@ func SourceForAllowedRelation(allowedRelation *core.AllowedRelation) string {
 		caveatStr = " with " + allowedRelation.RequiredCaveat.CaveatName
 	}
 
+	if allowedRelation.GetRequiredExpiration() != nil {
+		if caveatStr == "" {
+			caveatStr = " with expiration"
+		} else {
+			caveatStr += " and expiration"
+		}
+	}
+
 	if allowedRelation.GetPublicWildcard() != nil {
 		return tuple.JoinObjectRef(allowedRelation.Namespace, "*") + caveatStr
 	}
@@ -3,6 +3,8 @@ package relationships
 import (
 	"context"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/authzed/spicedb/internal/namespace"
 	"github.com/authzed/spicedb/pkg/caveats"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -102,34 +104,10 @@ func ValidateRelationshipUpdates(
 		}
 
 		// Validate the subject against the allowed relation(s).
-		var relationToCheck *core.AllowedRelation
-		var caveat *core.AllowedCaveat
-
-		if update.Tuple.Caveat != nil {
-			caveat = ns.AllowedCaveat(update.Tuple.Caveat.CaveatName)
-		}
-
-		if update.Tuple.Subject.ObjectId == tuple.PublicWildcard {
-			relationToCheck = ns.AllowedPublicNamespaceWithCaveat(update.Tuple.Subject.Namespace, caveat)
-		} else {
-			relationToCheck = ns.AllowedRelationWithCaveat(
-				update.Tuple.Subject.Namespace,
-				update.Tuple.Subject.Relation,
-				caveat)
-		}
-
-		isAllowed, err := resourceTS.HasAllowedRelation(
-			update.Tuple.ResourceAndRelation.Relation,
-			relationToCheck,
-		)
-		if err != nil {
+		if err := ValidateAllowedSubject(resourceTS, update); err != nil {
 			return err
 		}
 
-		if isAllowed != namespace.AllowedRelationValid {
-			return NewInvalidSubjectTypeError(update, relationToCheck)
-		}
-
 		// Validate caveat and its context, if applicable.
 		if hasNonEmptyCaveatContext(update) {
 			caveat, ok := referencedCaveatMap[update.Tuple.Caveat.CaveatName]
@@ -153,6 +131,55 @@ func ValidateRelationshipUpdates(
 	return nil
 }
 
+// ValidateAllowedSubject ensures that the subject of the update, along with its caveat and
+// expiration, is allowed on the relation of the resource by the resource's type system.
+func ValidateAllowedSubject(resourceTS *namespace.TypeSystem, update *core.RelationTupleUpdate) error {
+	var relationToCheck *core.AllowedRelation
+	var caveat *core.AllowedCaveat
+
+	if update.Tuple.Caveat != nil {
+		caveat = ns.AllowedCaveat(update.Tuple.Caveat.CaveatName)
+	}
+
+	if update.Tuple.Subject.ObjectId == tuple.PublicWildcard {
+		relationToCheck = ns.AllowedPublicNamespaceWithCaveat(update.Tuple.Subject.Namespace, caveat)
+	} else {
+		relationToCheck = ns.AllowedRelationWithCaveat(
+			update.Tuple.Subject.Namespace,
+			update.Tuple.Subject.Relation,
+			caveat)
+	}
+
+	if update.Tuple.OptionalExpirationTime != nil {
+		relationToCheck = ns.WithExpiration(relationToCheck)
+	}
+
+	isAllowed, err := resourceTS.HasAllowedRelation(
+		update.Tuple.ResourceAndRelation.Relation,
+		relationToCheck,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Deletes do not need to specify the expiration of the relationship being removed.
+	if isAllowed != namespace.AllowedRelationValid && update.Operation == core.RelationTupleUpdate_DELETE && relationToCheck.RequiredExpiration == nil {
+		isAllowed, err = resourceTS.HasAllowedRelation(
+			update.Tuple.ResourceAndRelation.Relation,
+			ns.WithExpiration(proto.Clone(relationToCheck).(*core.AllowedRelation)),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if isAllowed != namespace.AllowedRelationValid {
+		return NewInvalidSubjectTypeError(update, relationToCheck)
+	}
+
+	return nil
+}
+
 func hasNonEmptyCaveatContext(update *core.RelationTupleUpdate) bool {
 	return update.Tuple.Caveat != nil &&
 		update.Tuple.Caveat.CaveatName != "" &&
@@ -7,18 +7,28 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/middleware/session"
 	dispatch_v1 "github.com/authzed/spicedb/internal/services/dispatch/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
-// RegisterGrpcServices registers an internal dispatch service with the specified server.
+// RegisterGrpcServices registers an internal dispatch service with the specified server. If the
+// session tracker is not nil, the sessions it tracks are shared with the other nodes through the
+// session service.
 func RegisterGrpcServices(
 	srv *grpc.Server,
 	d dispatch.Dispatcher,
+	sessionTracker session.Tracker,
 ) {
 	srv.RegisterService(&dispatchv1.DispatchService_ServiceDesc, dispatch_v1.NewDispatchServer(d))
 	healthSrv := grpcutil.NewAuthlessHealthServer()
 	healthSrv.SetServicesHealthy(&dispatchv1.DispatchService_ServiceDesc)
+
+	if sessionTracker != nil {
+		srv.RegisterService(&dispatchv1.SessionService_ServiceDesc, dispatch_v1.NewSessionServer(sessionTracker))
+		healthSrv.SetServicesHealthy(&dispatchv1.SessionService_ServiceDesc)
+	}
+
 	healthpb.RegisterHealthServer(srv, healthSrv)
 	reflection.Register(srv)
 }
@@ -53,9 +53,28 @@ func (ds *dispatchServer) DispatchExpand(ctx context.Context, req *dispatchv1.Di
 	return resp, rewriteGraphError(ctx, err)
 }
 
+// DispatchLookup serves lookups dispatched by nodes running a version without
+// DispatchLookupResources, collecting the streamed resources into a single response.
 func (ds *dispatchServer) DispatchLookup(ctx context.Context, req *dispatchv1.DispatchLookupRequest) (*dispatchv1.DispatchLookupResponse, error) {
-	resp, err := ds.localDispatch.DispatchLookup(ctx, req)
-	return resp, rewriteGraphError(ctx, err)
+	stream := dispatch.NewCollectingDispatchStream[*dispatchv1.DispatchLookupResourcesResponse](ctx)
+	if err := ds.localDispatch.DispatchLookup(req, stream); err != nil {
+		return &dispatchv1.DispatchLookupResponse{Metadata: &dispatchv1.ResponseMeta{}}, rewriteGraphError(ctx, err)
+	}
+
+	resp := &dispatchv1.DispatchLookupResponse{Metadata: &dispatchv1.ResponseMeta{}}
+	for _, result := range stream.Results() {
+		dispatch.AddResponseMetadata(resp.Metadata, result.Metadata)
+		resp.ResolvedResources = append(resp.ResolvedResources, result.ResolvedResource)
+	}
+	return resp, nil
+}
+
+func (ds *dispatchServer) DispatchLookupResources(
+	req *dispatchv1.DispatchLookupRequest,
+	resp dispatchv1.DispatchService_DispatchLookupResourcesServer,
+) error {
+	return ds.localDispatch.DispatchLookup(req,
+		dispatch.WrapGRPCStream[*dispatchv1.DispatchLookupResourcesResponse](resp))
 }
 
 func (ds *dispatchServer) DispatchReachableResources(
@@ -0,0 +1,66 @@
+package dispatch
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/session"
+	"github.com/authzed/spicedb/internal/services/shared"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+type sessionServer struct {
+	dispatchv1.UnimplementedSessionServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	tracker session.Tracker
+}
+
+// NewSessionServer creates a server through which the other nodes of the cluster share the
+// sessions owned by this node. The tracker must be local to the node.
+func NewSessionServer(tracker session.Tracker) dispatchv1.SessionServiceServer {
+	return &sessionServer{
+		tracker: tracker,
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary:  grpcvalidate.UnaryServerInterceptor(true),
+			Stream: grpcvalidate.StreamServerInterceptor(true),
+		},
+	}
+}
+
+func (ss *sessionServer) RecordSessionWrite(ctx context.Context, req *dispatchv1.RecordSessionWriteRequest) (*dispatchv1.RecordSessionWriteResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing session identifier")
+	}
+
+	written, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: req.WrittenAt}, datastoremw.MustFromContext(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid revision: %s", err)
+	}
+
+	if err := ss.tracker.RecordWrite(ctx, req.SessionId, written); err != nil {
+		return nil, err
+	}
+	return &dispatchv1.RecordSessionWriteResponse{}, nil
+}
+
+func (ss *sessionServer) GetSessionWrite(ctx context.Context, req *dispatchv1.GetSessionWriteRequest) (*dispatchv1.GetSessionWriteResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing session identifier")
+	}
+
+	written, err := ss.tracker.LatestWrite(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if written == nil {
+		return &dispatchv1.GetSessionWriteResponse{}, nil
+	}
+	return &dispatchv1.GetSessionWriteResponse{WrittenAt: zedtoken.MustNewFromRevision(written).Token}, nil
+}
@@ -0,0 +1,447 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// RelationshipIssueCause defines why a relationship does not match the schema.
+type RelationshipIssueCause string
+
+const (
+	// UndefinedResourceTypeCause indicates that the resource type of the relationship is not
+	// defined by the schema.
+	UndefinedResourceTypeCause RelationshipIssueCause = "undefined-resource-type"
+
+	// UndefinedRelationCause indicates that the relation of the relationship is not defined on
+	// its resource type.
+	UndefinedRelationCause RelationshipIssueCause = "undefined-relation"
+
+	// PermissionRelationCause indicates that the relationship was written to a permission.
+	PermissionRelationCause RelationshipIssueCause = "relation-is-permission"
+
+	// UndefinedSubjectTypeCause indicates that the subject type of the relationship is not
+	// defined by the schema.
+	UndefinedSubjectTypeCause RelationshipIssueCause = "undefined-subject-type"
+
+	// UndefinedSubjectRelationCause indicates that the relation of the subject of the
+	// relationship is not defined on the subject type.
+	UndefinedSubjectRelationCause RelationshipIssueCause = "undefined-subject-relation"
+
+	// UndefinedCaveatCause indicates that the caveat of the relationship is not defined by the
+	// schema.
+	UndefinedCaveatCause RelationshipIssueCause = "undefined-caveat"
+
+	// DisallowedSubjectCause indicates that the relation does not allow the subject type of the
+	// relationship, along with its caveat and expiration.
+	DisallowedSubjectCause RelationshipIssueCause = "disallowed-subject"
+
+	// InvalidCaveatContextCause indicates that the caveat context of the relationship does not
+	// match the parameters of its caveat.
+	InvalidCaveatContextCause RelationshipIssueCause = "invalid-caveat-context"
+)
+
+// RelationshipIssueGroup is the set of relationships which do not match the schema for the same
+// cause, on the same relation.
+type RelationshipIssueGroup struct {
+	// Cause is why the relationships do not match the schema.
+	Cause RelationshipIssueCause
+
+	// Message is a human-readable description of the issue.
+	Message string
+
+	// DefinitionName and RelationName are the resource type and relation of the relationships.
+	DefinitionName string
+	RelationName   string
+
+	// SubjectType is the type of the subjects of the relationships, if relevant to the cause.
+	SubjectType string
+
+	// CaveatName is the name of the caveat of the relationships, if relevant to the cause.
+	CaveatName string
+
+	// RelationshipCount is the number of relationships in the group.
+	RelationshipCount uint64
+
+	// SampleRelationships holds up to the requested number of the relationships in the group.
+	SampleRelationships []*core.RelationTuple
+}
+
+// IntegrityReport is the result of checking the relationships of a datastore against its schema.
+type IntegrityReport struct {
+	// CheckedRelationships is the number of relationships checked.
+	CheckedRelationships uint64
+
+	// Groups are the groups of invalid relationships, in the order in which they were found.
+	Groups []*RelationshipIssueGroup
+}
+
+// IsValid returns true if every relationship checked matches the schema.
+func (r *IntegrityReport) IsValid() bool {
+	return len(r.Groups) == 0
+}
+
+// InvalidRelationshipCount returns the number of relationships which do not match the schema.
+func (r *IntegrityReport) InvalidRelationshipCount() uint64 {
+	var count uint64
+	for _, group := range r.Groups {
+		count += group.RelationshipCount
+	}
+	return count
+}
+
+// IntegrityCheckOptions are the options of CheckRelationshipIntegrity.
+type IntegrityCheckOptions struct {
+	// AdditionalResourceTypes are the names of resource types which are no longer defined by
+	// the schema, whose relationships should also be checked.
+	AdditionalResourceTypes []string
+
+	// MaxSamples is the maximum number of relationships kept as samples in each group.
+	MaxSamples uint32
+
+	// OnInvalid, if set, is called with each invalid relationship as soon as it is found, along
+	// with its group.
+	OnInvalid func(ctx context.Context, group *RelationshipIssueGroup, rel *core.RelationTuple) error
+}
+
+// CheckRelationshipIntegrity walks all of the relationships of each object definition in the
+// reader, checking each against the schema of the reader, and returns a report of those which do
+// not match it grouped by cause. The report holds only the count and samples of the
+// relationships of each group, so callers which act on every invalid relationship must do so
+// through OnInvalid.
+//
+// Relationships whose resource type is no longer defined cannot be found by walking the object
+// definitions, so the names of such types must be given as AdditionalResourceTypes for their
+// relationships to be reported.
+func CheckRelationshipIntegrity(ctx context.Context, reader datastore.Reader, opts IntegrityCheckOptions) (*IntegrityReport, error) {
+	nsDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &integrityChecker{
+		typeSystems: make(map[string]*namespace.TypeSystem, len(nsDefs)),
+		caveats:     make(map[string]*core.CaveatDefinition, len(caveatDefs)),
+		groups:      make(map[string]*RelationshipIssueGroup),
+		report:      &IntegrityReport{},
+		opts:        opts,
+	}
+
+	resolver := namespace.ResolverForDatastoreReader(reader)
+	resourceTypes := make([]string, 0, len(nsDefs)+len(opts.AdditionalResourceTypes))
+	for _, nsDef := range nsDefs {
+		ts, err := namespace.NewNamespaceTypeSystem(nsDef.Definition, resolver)
+		if err != nil {
+			return nil, err
+		}
+
+		c.typeSystems[nsDef.Definition.Name] = ts
+		resourceTypes = append(resourceTypes, nsDef.Definition.Name)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, caveatDef := range caveatDefs {
+		c.caveats[caveatDef.Definition.Name] = caveatDef.Definition
+	}
+
+	for _, resourceType := range opts.AdditionalResourceTypes {
+		if _, ok := c.typeSystems[resourceType]; !ok {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+
+	for _, resourceType := range resourceTypes {
+		if err := c.checkResourceType(ctx, reader, resourceType); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.report, nil
+}
+
+type integrityChecker struct {
+	typeSystems map[string]*namespace.TypeSystem
+	caveats     map[string]*core.CaveatDefinition
+	groups      map[string]*RelationshipIssueGroup
+	report      *IntegrityReport
+	opts        IntegrityCheckOptions
+}
+
+func (c *integrityChecker) checkResourceType(ctx context.Context, reader datastore.Reader, resourceType string) error {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: resourceType})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		c.report.CheckedRelationships++
+
+		issue, err := c.check(rel)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			continue
+		}
+
+		group := c.addRelationship(issue, rel)
+		if c.opts.OnInvalid != nil {
+			if err := c.opts.OnInvalid(ctx, group, rel.CloneVT()); err != nil {
+				return err
+			}
+		}
+	}
+	return iter.Err()
+}
+
+// check returns the issue of the relationship, or nil if it matches the schema. The returned
+// issue has no relationships; it identifies the group to which the relationship belongs.
+func (c *integrityChecker) check(rel *core.RelationTuple) (*RelationshipIssueGroup, error) {
+	resource := rel.ResourceAndRelation
+	subject := rel.Subject
+
+	resourceTS, ok := c.typeSystems[resource.Namespace]
+	if !ok {
+		return &RelationshipIssueGroup{
+			Cause:          UndefinedResourceTypeCause,
+			Message:        fmt.Sprintf("object definition `%s` is not defined", resource.Namespace),
+			DefinitionName: resource.Namespace,
+		}, nil
+	}
+
+	if !resourceTS.HasRelation(resource.Relation) {
+		return &RelationshipIssueGroup{
+			Cause:          UndefinedRelationCause,
+			Message:        fmt.Sprintf("relation `%s` is not defined on object definition `%s`", resource.Relation, resource.Namespace),
+			DefinitionName: resource.Namespace,
+			RelationName:   resource.Relation,
+		}, nil
+	}
+
+	if resourceTS.IsPermission(resource.Relation) {
+		return &RelationshipIssueGroup{
+			Cause:          PermissionRelationCause,
+			Message:        fmt.Sprintf("`%s#%s` is a permission, to which relationships cannot be written", resource.Namespace, resource.Relation),
+			DefinitionName: resource.Namespace,
+			RelationName:   resource.Relation,
+		}, nil
+	}
+
+	subjectTS, ok := c.typeSystems[subject.Namespace]
+	if !ok {
+		return &RelationshipIssueGroup{
+			Cause:          UndefinedSubjectTypeCause,
+			Message:        fmt.Sprintf("subject type `%s` of relation `%s#%s` is not defined", subject.Namespace, resource.Namespace, resource.Relation),
+			DefinitionName: resource.Namespace,
+			RelationName:   resource.Relation,
+			SubjectType:    subject.Namespace,
+		}, nil
+	}
+
+	if subject.Relation != tuple.Ellipsis && !subjectTS.HasRelation(subject.Relation) {
+		return &RelationshipIssueGroup{
+			Cause:          UndefinedSubjectRelationCause,
+			Message:        fmt.Sprintf("relation `%s` of subject type `%s` is not defined", subject.Relation, subject.Namespace),
+			DefinitionName: resource.Namespace,
+			RelationName:   resource.Relation,
+			SubjectType:    subjectTypeString(rel),
+		}, nil
+	}
+
+	var caveatDef *core.CaveatDefinition
+	if rel.Caveat != nil && rel.Caveat.CaveatName != "" {
+		caveatDef, ok = c.caveats[rel.Caveat.CaveatName]
+		if !ok {
+			return &RelationshipIssueGroup{
+				Cause:          UndefinedCaveatCause,
+				Message:        fmt.Sprintf("caveat `%s` is not defined", rel.Caveat.CaveatName),
+				DefinitionName: resource.Namespace,
+				RelationName:   resource.Relation,
+				CaveatName:     rel.Caveat.CaveatName,
+			}, nil
+		}
+	}
+
+	allowed, err := resourceTS.HasAllowedRelation(resource.Relation, allowedRelationFor(rel))
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed != namespace.AllowedRelationValid {
+		subjectType := subjectTypeString(rel)
+		return &RelationshipIssueGroup{
+			Cause:          DisallowedSubjectCause,
+			Message:        fmt.Sprintf("subject type `%s` is not allowed on relation `%s#%s`", subjectType, resource.Namespace, resource.Relation),
+			DefinitionName: resource.Namespace,
+			RelationName:   resource.Relation,
+			SubjectType:    subjectType,
+		}, nil
+	}
+
+	if caveatDef != nil && rel.Caveat.Context != nil {
+		if _, err := caveats.ConvertContextToParameters(
+			rel.Caveat.Context.AsMap(),
+			caveatDef.ParameterTypes,
+			caveats.ErrorForUnknownParameters,
+		); err != nil {
+			return &RelationshipIssueGroup{
+				Cause:          InvalidCaveatContextCause,
+				Message:        fmt.Sprintf("caveat context does not match the parameters of caveat `%s`", caveatDef.Name),
+				DefinitionName: resource.Namespace,
+				RelationName:   resource.Relation,
+				CaveatName:     caveatDef.Name,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// addRelationship counts the relationship in the group of the issue, keeping it as a sample if
+// the group has fewer than the maximum, and returns the group.
+func (c *integrityChecker) addRelationship(issue *RelationshipIssueGroup, rel *core.RelationTuple) *RelationshipIssueGroup {
+	key := strings.Join([]string{
+		string(issue.Cause),
+		issue.DefinitionName,
+		issue.RelationName,
+		issue.SubjectType,
+		issue.CaveatName,
+	}, "|")
+
+	group, ok := c.groups[key]
+	if !ok {
+		group = issue
+		c.groups[key] = group
+		c.report.Groups = append(c.report.Groups, group)
+	}
+	group.RelationshipCount++
+	if uint32(len(group.SampleRelationships)) < c.opts.MaxSamples {
+		group.SampleRelationships = append(group.SampleRelationships, rel.CloneVT())
+	}
+	return group
+}
+
+// allowedRelationFor returns the allowed relation, with its caveat and expiration, which must be
+// allowed on the relation of the relationship for it to be valid.
+func allowedRelationFor(rel *core.RelationTuple) *core.AllowedRelation {
+	var caveat *core.AllowedCaveat
+	if rel.Caveat != nil && rel.Caveat.CaveatName != "" {
+		caveat = ns.AllowedCaveat(rel.Caveat.CaveatName)
+	}
+
+	var allowed *core.AllowedRelation
+	if rel.Subject.ObjectId == tuple.PublicWildcard {
+		allowed = ns.AllowedPublicNamespaceWithCaveat(rel.Subject.Namespace, caveat)
+	} else {
+		allowed = ns.AllowedRelationWithCaveat(rel.Subject.Namespace, rel.Subject.Relation, caveat)
+	}
+
+	if rel.OptionalExpirationTime != nil {
+		allowed = ns.WithExpiration(allowed)
+	}
+	return allowed
+}
+
+// subjectTypeString returns the subject type of the relationship as it would be written in the
+// allowed types of a relation.
+func subjectTypeString(rel *core.RelationTuple) string {
+	subjectType := rel.Subject.Namespace
+	switch {
+	case rel.Subject.ObjectId == tuple.PublicWildcard:
+		subjectType += ":" + tuple.PublicWildcard
+	case rel.Subject.Relation != tuple.Ellipsis:
+		subjectType += "#" + rel.Subject.Relation
+	}
+
+	if rel.Caveat != nil && rel.Caveat.CaveatName != "" {
+		subjectType += " with " + rel.Caveat.CaveatName
+	}
+	if rel.OptionalExpirationTime != nil {
+		subjectType += " with expiration"
+	}
+	return subjectType
+}
+
+// RelationshipBatchDeleter deletes relationships in transactions of at most a batch size each,
+// so that relationships can be deleted as they are found without being held in memory.
+type RelationshipBatchDeleter struct {
+	ds           datastore.Datastore
+	batchSize    int
+	beforeDelete func(batch []*core.RelationTuple) error
+
+	pending []*core.RelationTuple
+	deleted uint64
+}
+
+// NewRelationshipBatchDeleter creates a deleter of relationships from the datastore. If set,
+// beforeDelete is called with each batch before it is deleted, which is then only deleted if it
+// returns no error.
+func NewRelationshipBatchDeleter(ds datastore.Datastore, batchSize int, beforeDelete func(batch []*core.RelationTuple) error) (*RelationshipBatchDeleter, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be greater than zero")
+	}
+
+	return &RelationshipBatchDeleter{
+		ds:           ds,
+		batchSize:    batchSize,
+		beforeDelete: beforeDelete,
+		pending:      make([]*core.RelationTuple, 0, batchSize),
+	}, nil
+}
+
+// Add adds the relationship to the current batch, deleting the batch once it is full.
+func (d *RelationshipBatchDeleter) Add(ctx context.Context, rel *core.RelationTuple) error {
+	d.pending = append(d.pending, rel)
+	if len(d.pending) < d.batchSize {
+		return nil
+	}
+	return d.Flush(ctx)
+}
+
+// Flush deletes the current batch, if it has any relationships.
+func (d *RelationshipBatchDeleter) Flush(ctx context.Context) error {
+	if len(d.pending) == 0 {
+		return nil
+	}
+
+	if d.beforeDelete != nil {
+		if err := d.beforeDelete(d.pending); err != nil {
+			return err
+		}
+	}
+
+	updates := make([]*core.RelationTupleUpdate, 0, len(d.pending))
+	for _, rel := range d.pending {
+		updates = append(updates, tuple.Delete(rel))
+	}
+
+	if _, err := d.ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, updates)
+	}); err != nil {
+		return err
+	}
+
+	d.deleted += uint64(len(updates))
+	d.pending = d.pending[:0]
+	return nil
+}
+
+// Deleted returns the number of relationships deleted.
+func (d *RelationshipBatchDeleter) Deleted() uint64 {
+	return d.deleted
+}
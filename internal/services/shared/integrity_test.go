@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestCheckRelationshipIntegrity(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition team {
+			relation member: user
+		}
+
+		caveat has_forty_two(value int) {
+			value == 42
+		}
+
+		definition document {
+			relation viewer: user | user with has_forty_two | team#member
+			relation editor: user
+			permission view = viewer + editor
+		}
+	`, []*core.RelationTuple{
+		tuple.MustParse("document:valid#viewer@user:tom"),
+		tuple.MustParse("document:valid#viewer@team:eng#member"),
+		tuple.MustParse(`document:valid#viewer@user:sarah[has_forty_two:{"value":42}]`),
+	}, require)
+
+	invalid := map[RelationshipIssueCause][]string{
+		UndefinedResourceTypeCause:    {"folder:root#viewer@user:tom"},
+		UndefinedRelationCause:        {"document:first#owner@user:tom", "document:second#owner@user:sarah"},
+		PermissionRelationCause:       {"document:first#view@user:tom"},
+		UndefinedSubjectTypeCause:     {"document:first#viewer@group:admins"},
+		UndefinedSubjectRelationCause: {"document:first#viewer@team:eng#admin"},
+		UndefinedCaveatCause:          {"document:first#viewer@user:fred[missing_caveat]"},
+		DisallowedSubjectCause:        {"document:first#editor@team:eng#member", "document:first#editor@user:*"},
+		InvalidCaveatContextCause:     {`document:first#viewer@user:jill[has_forty_two:{"unknown":1}]`},
+	}
+
+	var rels []*core.RelationTuple
+	for _, strs := range invalid {
+		for _, str := range strs {
+			rels = append(rels, tuple.MustParse(str))
+		}
+	}
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, rels...)
+	require.NoError(err)
+
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	reader := ds.SnapshotReader(headRevision)
+
+	// Relationships of an undefined resource type are only found when the type is given.
+	report, err := CheckRelationshipIntegrity(ctx, reader, IntegrityCheckOptions{})
+	require.NoError(err)
+	require.False(report.IsValid())
+	require.Equal(uint64(3+len(rels)-1), report.CheckedRelationships)
+	for _, group := range report.Groups {
+		require.NotEqual(UndefinedResourceTypeCause, group.Cause)
+	}
+
+	// Deleting the invalid relationships as they are found leaves only valid ones.
+	var batches [][]string
+	deleter, err := NewRelationshipBatchDeleter(ds, 2, func(batch []*core.RelationTuple) error {
+		strs := make([]string, 0, len(batch))
+		for _, rel := range batch {
+			strs = append(strs, tuple.MustString(rel))
+		}
+		batches = append(batches, strs)
+		return nil
+	})
+	require.NoError(err)
+
+	found := map[RelationshipIssueCause][]string{}
+	report, err = CheckRelationshipIntegrity(ctx, reader, IntegrityCheckOptions{
+		AdditionalResourceTypes: []string{"folder", "document"},
+		MaxSamples:              1,
+		OnInvalid: func(ctx context.Context, group *RelationshipIssueGroup, rel *core.RelationTuple) error {
+			found[group.Cause] = append(found[group.Cause], tuple.MustString(rel))
+			return deleter.Add(ctx, rel)
+		},
+	})
+	require.NoError(err)
+	require.NoError(deleter.Flush(ctx))
+	require.Equal(uint64(3+len(rels)), report.CheckedRelationships)
+
+	for cause, strs := range invalid {
+		require.ElementsMatch(strs, found[cause], cause)
+	}
+	require.Equal(uint64(len(rels)), report.InvalidRelationshipCount())
+
+	// The two relationships with an undefined relation are grouped together, while the
+	// disallowed subjects differ in type and so are grouped apart.
+	require.Len(report.Groups, len(invalid)+1)
+	for _, group := range report.Groups {
+		require.NotEmpty(group.Message)
+		require.Len(group.SampleRelationships, 1)
+		require.Contains(found[group.Cause], tuple.MustString(group.SampleRelationships[0]))
+	}
+
+	require.Equal(uint64(len(rels)), deleter.Deleted())
+	require.Len(batches, (len(rels)+1)/2)
+	for _, batch := range batches[:len(batches)-1] {
+		require.Len(batch, 2)
+	}
+
+	headRevision, err = ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	report, err = CheckRelationshipIntegrity(ctx, ds.SnapshotReader(headRevision), IntegrityCheckOptions{AdditionalResourceTypes: []string{"folder"}})
+	require.NoError(err)
+	require.True(report.IsValid())
+	require.Equal(uint64(3), report.CheckedRelationships)
+}
+
+func TestRelationshipBatchDeleterRequiresBatchSize(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	_, err = NewRelationshipBatchDeleter(rawDS, 0, nil)
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/authzed/spicedb/internal/caveats"
 	log "github.com/authzed/spicedb/internal/logging"
@@ -297,45 +298,84 @@ func sanityCheckNamespaceChanges(
 	existingDefs map[string]*core.NamespaceDefinition,
 ) (*namespace.Diff, error) {
 	// Ensure that the updated namespace does not break the existing tuple data.
-	existing := existingDefs[nsdef.Name]
-	diff, err := namespace.DiffNamespaces(existing, nsdef)
+	diff, changes, err := breakingNamespaceChanges(existingDefs[nsdef.Name], nsdef)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, change := range changes {
+		qy, qyErr := rwt.QueryRelationships(ctx, change.resources, options.WithLimit(options.LimitOne))
+		err = errorIfTupleIteratorReturnsTuples(ctx, qy, qyErr, "%s, as a relationship %s", change.description, change.resourcesReason)
+		if err != nil {
+			return diff, err
+		}
+
+		if change.subjects == nil {
+			continue
+		}
+
+		// Also check for right sides of tuples.
+		qy, qyErr = rwt.ReverseQueryRelationships(ctx, *change.subjects, options.WithReverseLimit(options.LimitOne))
+		err = errorIfTupleIteratorReturnsTuples(ctx, qy, qyErr, "%s, as a relationship references it", change.description)
+		if err != nil {
+			return diff, err
+		}
+	}
+	return diff, nil
+}
+
+// breakingNamespaceChange is a change to a namespace definition which can only be written if no
+// relationships match its filters.
+type breakingNamespaceChange struct {
+	// issue describes the change in a SchemaValidationReport.
+	issue SchemaIssue
+
+	// description describes the change, as the start of the error returned when writing it.
+	description string
+
+	// resources matches the relationships under the definition which prevent the change, and
+	// resourcesReason completes the error for one of them.
+	resources       datastore.RelationshipsFilter
+	resourcesReason string
+
+	// subjects, if set, matches the relationships referencing the definition which prevent
+	// the change.
+	subjects *datastore.SubjectsFilter
+}
+
+// breakingNamespaceChanges diffs the namespace definition against its existing definition, if
+// any, and returns the changes which existing relationships could prevent.
+func breakingNamespaceChanges(existing *core.NamespaceDefinition, nsdef *core.NamespaceDefinition) (*namespace.Diff, []breakingNamespaceChange, error) {
+	diff, err := namespace.DiffNamespaces(existing, nsdef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []breakingNamespaceChange
 	for _, delta := range diff.Deltas() {
 		switch delta.Type {
 		case namespace.RemovedRelation:
-			qy, qyErr := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
-				ResourceType:             nsdef.Name,
-				OptionalResourceRelation: delta.RelationName,
-			})
-
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qy,
-				qyErr,
-				"cannot delete relation `%s` in object definition `%s`, as a relationship exists under it", delta.RelationName, nsdef.Name)
-			if err != nil {
-				return diff, err
-			}
-
-			// Also check for right sides of tuples.
-			qy, qyErr = rwt.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
-				SubjectType: nsdef.Name,
-				RelationFilter: datastore.SubjectRelationFilter{
-					NonEllipsisRelation: delta.RelationName,
+			description := fmt.Sprintf("cannot delete relation `%s` in object definition `%s`", delta.RelationName, nsdef.Name)
+			changes = append(changes, breakingNamespaceChange{
+				issue: SchemaIssue{
+					Type:           RemovedRelationIssue,
+					Message:        description + ", as relationships exist under or reference it",
+					DefinitionName: nsdef.Name,
+					RelationName:   delta.RelationName,
 				},
-			}, options.WithReverseLimit(options.LimitOne))
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qy,
-				qyErr,
-				"cannot delete relation `%s` in object definition `%s`, as a relationship references it", delta.RelationName, nsdef.Name)
-			qy.Close()
-			if err != nil {
-				return diff, err
-			}
+				description: description,
+				resources: datastore.RelationshipsFilter{
+					ResourceType:             nsdef.Name,
+					OptionalResourceRelation: delta.RelationName,
+				},
+				resourcesReason: "exists under it",
+				subjects: &datastore.SubjectsFilter{
+					SubjectType: nsdef.Name,
+					RelationFilter: datastore.SubjectRelationFilter{
+						NonEllipsisRelation: delta.RelationName,
+					},
+				},
+			})
 
 		case namespace.RelationAllowedTypeRemoved:
 			var optionalSubjectIds []string
@@ -354,9 +394,17 @@ func sanityCheckNamespaceChanges(
 				optionalCaveatName = delta.AllowedType.GetRequiredCaveat().CaveatName
 			}
 
-			qyr, qyrErr := rwt.QueryRelationships(
-				ctx,
-				datastore.RelationshipsFilter{
+			description := fmt.Sprintf("cannot remove allowed type `%s` from relation `%s` in object definition `%s`",
+				namespace.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, nsdef.Name)
+			changes = append(changes, breakingNamespaceChange{
+				issue: SchemaIssue{
+					Type:           RemovedAllowedTypeIssue,
+					Message:        description + ", as relationships exist with it",
+					DefinitionName: nsdef.Name,
+					RelationName:   delta.RelationName,
+				},
+				description: description,
+				resources: datastore.RelationshipsFilter{
 					ResourceType:             nsdef.Name,
 					OptionalResourceRelation: delta.RelationName,
 					OptionalSubjectsSelectors: []datastore.SubjectsSelector{
@@ -368,21 +416,11 @@ func sanityCheckNamespaceChanges(
 					},
 					OptionalCaveatName: optionalCaveatName,
 				},
-				options.WithLimit(options.LimitOne),
-			)
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qyr,
-				qyrErr,
-				"cannot remove allowed type `%s` from relation `%s` in object definition `%s`, as a relationship exists with it",
-				namespace.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, nsdef.Name)
-			qyr.Close()
-			if err != nil {
-				return diff, err
-			}
+				resourcesReason: "exists with it",
+			})
 		}
 	}
-	return diff, nil
+	return diff, changes, nil
 }
 
 // errorIfTupleIteratorReturnsTuples takes a tuple iterator and any error that was generated
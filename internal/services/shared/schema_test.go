@@ -9,8 +9,10 @@ import (
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestApplySchemaChanges(t *testing.T) {
@@ -63,3 +65,78 @@ func TestApplySchemaChanges(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestValidateSchemaChangesAgainstData(t *testing.T) {
+	require := require.New(t)
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition team {
+			relation member: user
+		}
+
+		definition document {
+			relation viewer: user | team#member | user with has_forty_two
+			relation editor: user
+			permission view = viewer + editor
+		}
+
+		caveat has_forty_two(value int) {
+		  value == 42
+		}
+	`, []*core.RelationTuple{
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:first#viewer@team:engineering#member"),
+		tuple.MustParse("document:second#viewer@team:sales#member"),
+		tuple.MustParse("document:third#viewer@user:sarah[has_forty_two:{\"value\":42}]"),
+		tuple.MustParse("document:third#editor@user:fred"),
+		tuple.MustParse("team:engineering#member@user:tom"),
+	}, require)
+
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source: input.Source("schema"),
+		SchemaString: `
+			definition user {}
+
+			definition document {
+				relation viewer: user | user with has_forty_two
+				permission view = viewer
+			}
+
+			caveat has_forty_two(value string) {
+			  value == "42"
+			}
+		`,
+	}, &emptyDefaultPrefix)
+	require.NoError(err)
+
+	validated, err := ValidateSchemaChanges(context.Background(), compiled, false)
+	require.NoError(err)
+
+	report, err := ValidateSchemaChangesAgainstData(context.Background(), ds.SnapshotReader(revision), validated, 1)
+	require.NoError(err)
+	require.False(report.IsValid())
+
+	issuesByType := make(map[SchemaIssueType]SchemaIssue, len(report.Issues))
+	for _, issue := range report.Issues {
+		issuesByType[issue.Type] = issue
+	}
+	require.Len(issuesByType, 4)
+
+	require.Equal(uint64(1), issuesByType[ChangedCaveatParameterIssue].RelationshipCount)
+	require.Equal("value", issuesByType[ChangedCaveatParameterIssue].ParameterName)
+
+	require.Equal(uint64(2), issuesByType[RemovedAllowedTypeIssue].RelationshipCount)
+	require.Len(issuesByType[RemovedAllowedTypeIssue].SampleRelationships, 1)
+
+	require.Equal(uint64(1), issuesByType[RemovedRelationIssue].RelationshipCount)
+	require.Equal("editor", issuesByType[RemovedRelationIssue].RelationName)
+	require.Equal("document:third#editor@user:fred", tuple.MustString(issuesByType[RemovedRelationIssue].SampleRelationships[0]))
+
+	require.Equal(uint64(3), issuesByType[RemovedDefinitionIssue].RelationshipCount)
+	require.Equal("team", issuesByType[RemovedDefinitionIssue].DefinitionName)
+}
@@ -0,0 +1,311 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/authzed/spicedb/internal/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/util"
+)
+
+// SchemaIssueType defines the type of a schema validation issue.
+type SchemaIssueType string
+
+const (
+	// RemovedDefinitionIssue indicates that an object definition is being removed while
+	// relationships still exist under or reference it.
+	RemovedDefinitionIssue SchemaIssueType = "removed-definition"
+
+	// RemovedRelationIssue indicates that a relation is being removed while relationships
+	// still exist under or reference it.
+	RemovedRelationIssue SchemaIssueType = "removed-relation"
+
+	// RemovedAllowedTypeIssue indicates that an allowed type is being removed from a relation
+	// while relationships of that type still exist.
+	RemovedAllowedTypeIssue SchemaIssueType = "removed-allowed-type"
+
+	// RemovedCaveatIssue indicates that a caveat is being removed while relationships still
+	// reference it.
+	RemovedCaveatIssue SchemaIssueType = "removed-caveat"
+
+	// ChangedCaveatParameterIssue indicates that a parameter of a caveat is being removed or
+	// having its type changed. Such changes are always rejected; the relationships counted are
+	// those which provide a value for the parameter in their caveat context.
+	ChangedCaveatParameterIssue SchemaIssueType = "changed-caveat-parameter"
+)
+
+// SchemaIssue is a single problem which would prevent a schema from being written.
+type SchemaIssue struct {
+	// Type is the type of the issue.
+	Type SchemaIssueType
+
+	// Message is a human-readable description of the issue.
+	Message string
+
+	// DefinitionName is the name of the object definition affected, if any.
+	DefinitionName string
+
+	// RelationName is the name of the relation affected, if any.
+	RelationName string
+
+	// CaveatName is the name of the caveat affected, if any.
+	CaveatName string
+
+	// ParameterName is the name of the caveat parameter affected, if any.
+	ParameterName string
+
+	// RelationshipCount is the number of existing relationships invalidated by the change.
+	RelationshipCount uint64
+
+	// SampleRelationships holds up to the requested number of the invalidated relationships.
+	SampleRelationships []*core.RelationTuple
+}
+
+// SchemaValidationReport is the result of validating schema changes against the relationships
+// found in a datastore.
+type SchemaValidationReport struct {
+	// Issues are all of the issues found, in the order in which the definitions were checked.
+	Issues []SchemaIssue
+}
+
+// IsValid returns true if no issues were found and the schema can be written.
+func (r *SchemaValidationReport) IsValid() bool {
+	return len(r.Issues) == 0
+}
+
+// ValidateSchemaChangesAgainstData checks the validated schema changes against the schema and
+// relationships found in the reader, returning a report of every change which would prevent the
+// schema from being written. Unlike ApplySchemaChanges, validation does not stop at the first
+// problem found: all existing relationships are scanned so that the report contains the full
+// count of invalidated relationships, along with up to maxSamples of them per issue.
+func ValidateSchemaChangesAgainstData(
+	ctx context.Context,
+	reader datastore.Reader,
+	validated *ValidatedSchemaChanges,
+	maxSamples uint32,
+) (*SchemaValidationReport, error) {
+	existingCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &schemaDataValidator{
+		reader:     reader,
+		maxSamples: maxSamples,
+		report:     &SchemaValidationReport{},
+	}
+
+	existingCaveatDefMap := make(map[string]*core.CaveatDefinition, len(existingCaveats))
+	existingCaveatDefNames := util.NewSet[string]()
+	for _, existingCaveat := range existingCaveats {
+		existingCaveatDefMap[existingCaveat.Definition.Name] = existingCaveat.Definition
+		existingCaveatDefNames.Add(existingCaveat.Definition.Name)
+	}
+
+	existingObjectDefMap := make(map[string]*core.NamespaceDefinition, len(existingObjectDefs))
+	existingObjectDefNames := util.NewSet[string]()
+	for _, existingDef := range existingObjectDefs {
+		existingObjectDefMap[existingDef.Definition.Name] = existingDef.Definition
+		existingObjectDefNames.Add(existingDef.Definition.Name)
+	}
+
+	for _, caveatDef := range validated.compiled.CaveatDefinitions {
+		if err := v.checkCaveatChanges(ctx, caveatDef, existingCaveatDefMap[caveatDef.Name], existingObjectDefNames); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, nsdef := range validated.compiled.ObjectDefinitions {
+		if err := v.checkNamespaceChanges(ctx, nsdef, existingObjectDefMap[nsdef.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Removed definitions and caveats are only deleted when the schema is not additive-only.
+	if !validated.additiveOnly {
+		if err := existingObjectDefNames.Subtract(validated.newObjectDefNames).ForEach(func(nsdefName string) error {
+			return v.checkRemovedNamespace(ctx, nsdefName)
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := existingCaveatDefNames.Subtract(validated.newCaveatDefNames).ForEach(func(caveatName string) error {
+			return v.checkRemovedCaveat(ctx, caveatName, existingObjectDefNames)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return v.report, nil
+}
+
+type schemaDataValidator struct {
+	reader     datastore.Reader
+	maxSamples uint32
+	report     *SchemaValidationReport
+}
+
+// relationshipCollector counts relationships and collects a limited number of samples.
+type relationshipCollector struct {
+	count      uint64
+	samples    []*core.RelationTuple
+	maxSamples uint32
+
+	// filter, if set, restricts the relationships collected to those for which it returns true.
+	filter func(*core.RelationTuple) bool
+}
+
+func (rc *relationshipCollector) collect(iter datastore.RelationshipIterator, iterErr error) error {
+	if iterErr != nil {
+		return iterErr
+	}
+	defer iter.Close()
+
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		if rc.filter != nil && !rc.filter(rel) {
+			continue
+		}
+
+		rc.count++
+		if uint32(len(rc.samples)) < rc.maxSamples {
+			rc.samples = append(rc.samples, rel.CloneVT())
+		}
+	}
+	return iter.Err()
+}
+
+func (v *schemaDataValidator) newCollector() *relationshipCollector {
+	return &relationshipCollector{maxSamples: v.maxSamples}
+}
+
+// addIssue adds the issue to the report if any relationships were collected or if the change
+// is rejected regardless of the data.
+func (v *schemaDataValidator) addIssue(issue SchemaIssue, rc *relationshipCollector, always bool) {
+	if rc.count == 0 && !always {
+		return
+	}
+
+	issue.RelationshipCount = rc.count
+	issue.SampleRelationships = rc.samples
+	v.report.Issues = append(v.report.Issues, issue)
+}
+
+func (v *schemaDataValidator) checkRemovedNamespace(ctx context.Context, nsdefName string) error {
+	rc := v.newCollector()
+	if err := rc.collect(v.reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: nsdefName,
+	})); err != nil {
+		return err
+	}
+
+	if err := rc.collect(v.reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+		SubjectType: nsdefName,
+	})); err != nil {
+		return err
+	}
+
+	v.addIssue(SchemaIssue{
+		Type:           RemovedDefinitionIssue,
+		Message:        fmt.Sprintf("cannot delete object definition `%s`, as relationships exist under or reference it", nsdefName),
+		DefinitionName: nsdefName,
+	}, rc, false)
+	return nil
+}
+
+func (v *schemaDataValidator) checkRemovedCaveat(ctx context.Context, caveatName string, namespaceNames *util.Set[string]) error {
+	rc, err := v.collectCaveated(ctx, caveatName, namespaceNames, nil)
+	if err != nil {
+		return err
+	}
+
+	v.addIssue(SchemaIssue{
+		Type:       RemovedCaveatIssue,
+		Message:    fmt.Sprintf("cannot delete caveat `%s`, as relationships reference it", caveatName),
+		CaveatName: caveatName,
+	}, rc, false)
+	return nil
+}
+
+func (v *schemaDataValidator) checkCaveatChanges(ctx context.Context, caveatDef *core.CaveatDefinition, existing *core.CaveatDefinition, namespaceNames *util.Set[string]) error {
+	diff, err := caveats.DiffCaveats(existing, caveatDef)
+	if err != nil {
+		return err
+	}
+
+	for _, delta := range diff.Deltas() {
+		var message string
+		switch delta.Type {
+		case caveats.RemovedParameter:
+			message = fmt.Sprintf("cannot remove parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
+
+		case caveats.ParameterTypeChanged:
+			message = fmt.Sprintf("cannot change the type of parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
+
+		default:
+			continue
+		}
+
+		parameterName := delta.ParameterName
+		rc, err := v.collectCaveated(ctx, caveatDef.Name, namespaceNames, func(rel *core.RelationTuple) bool {
+			_, ok := rel.Caveat.GetContext().GetFields()[parameterName]
+			return ok
+		})
+		if err != nil {
+			return err
+		}
+
+		v.addIssue(SchemaIssue{
+			Type:          ChangedCaveatParameterIssue,
+			Message:       message,
+			CaveatName:    caveatDef.Name,
+			ParameterName: parameterName,
+		}, rc, true)
+	}
+
+	return nil
+}
+
+// collectCaveated collects the relationships, under any of the given namespaces, which use the
+// caveat with the given name.
+func (v *schemaDataValidator) collectCaveated(ctx context.Context, caveatName string, namespaceNames *util.Set[string], filter func(*core.RelationTuple) bool) (*relationshipCollector, error) {
+	rc := v.newCollector()
+	rc.filter = filter
+	err := namespaceNames.ForEach(func(nsdefName string) error {
+		return rc.collect(v.reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType:       nsdefName,
+			OptionalCaveatName: caveatName,
+		}))
+	})
+	return rc, err
+}
+
+func (v *schemaDataValidator) checkNamespaceChanges(ctx context.Context, nsdef *core.NamespaceDefinition, existing *core.NamespaceDefinition) error {
+	_, changes, err := breakingNamespaceChanges(existing, nsdef)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		rc := v.newCollector()
+		if err := rc.collect(v.reader.QueryRelationships(ctx, change.resources)); err != nil {
+			return err
+		}
+
+		if change.subjects != nil {
+			if err := rc.collect(v.reader.ReverseQueryRelationships(ctx, *change.subjects)); err != nil {
+				return err
+			}
+		}
+
+		v.addIssue(change.issue, rc, false)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package testserver implements the services specific to the testing server.
+package testserver
+
+import (
+	"context"
+
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/middleware/pertoken"
+	"github.com/authzed/spicedb/internal/services/shared"
+	testserverv1 "github.com/authzed/spicedb/pkg/proto/testserver/v1"
+)
+
+// NewTokenDatastoreServer creates a TokenDatastoreServiceServer administering the datastores of
+// the per-token middleware.
+func NewTokenDatastoreServer(datastores *pertoken.MiddlewareForTesting) testserverv1.TokenDatastoreServiceServer {
+	return &tokenDatastoreServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary:  grpcvalidate.UnaryServerInterceptor(true),
+			Stream: grpcvalidate.StreamServerInterceptor(true),
+		},
+		datastores: datastores,
+	}
+}
+
+type tokenDatastoreServer struct {
+	testserverv1.UnimplementedTokenDatastoreServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	datastores *pertoken.MiddlewareForTesting
+}
+
+func (tds *tokenDatastoreServer) ListTokenDatastores(_ context.Context, _ *testserverv1.ListTokenDatastoresRequest) (*testserverv1.ListTokenDatastoresResponse, error) {
+	listed := tds.datastores.List()
+
+	datastores := make([]*testserverv1.TokenDatastore, 0, len(listed))
+	for _, td := range listed {
+		datastores = append(datastores, toTokenDatastore(td))
+	}
+	return &testserverv1.ListTokenDatastoresResponse{Datastores: datastores}, nil
+}
+
+func (tds *tokenDatastoreServer) ResetTokenDatastore(ctx context.Context, req *testserverv1.ResetTokenDatastoreRequest) (*testserverv1.ResetTokenDatastoreResponse, error) {
+	td, err := tds.datastores.Reset(ctx, req.Token, req.Fixture)
+	if err != nil {
+		return nil, err
+	}
+	return &testserverv1.ResetTokenDatastoreResponse{Datastore: toTokenDatastore(td)}, nil
+}
+
+func (tds *tokenDatastoreServer) DeleteTokenDatastore(_ context.Context, req *testserverv1.DeleteTokenDatastoreRequest) (*testserverv1.DeleteTokenDatastoreResponse, error) {
+	if !tds.datastores.Delete(req.Token) {
+		return nil, status.Errorf(codes.NotFound, "no datastore for token")
+	}
+	return &testserverv1.DeleteTokenDatastoreResponse{}, nil
+}
+
+func toTokenDatastore(td pertoken.TokenDatastore) *testserverv1.TokenDatastore {
+	return &testserverv1.TokenDatastore{
+		Token:      td.Token,
+		Fixture:    td.Fixture,
+		LastUsedAt: timestamppb.New(td.LastUsed),
+	}
+}
@@ -2,15 +2,22 @@ package v0
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	v0 "github.com/authzed/authzed-go/proto/authzed/api/v0"
 	"github.com/authzed/grpcutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/prototext"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/sharederrors"
+	"github.com/authzed/spicedb/pkg/development"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/generator"
 )
 
@@ -102,16 +109,204 @@ func (ds *devServer) LookupShared(ctx context.Context, req *v0.LookupShareReques
 	}, nil
 }
 
-func (ds *devServer) EditCheck(_ context.Context, _ *v0.EditCheckRequest) (*v0.EditCheckResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+func (ds *devServer) EditCheck(ctx context.Context, req *v0.EditCheckRequest) (*v0.EditCheckResponse, error) {
+	operations := make([]*devinterface.Operation, 0, len(req.CheckRelationships))
+	for _, relationship := range req.CheckRelationships {
+		coreRelationship := core.ToCoreRelationTuple(relationship)
+		operations = append(operations, &devinterface.Operation{
+			CheckParameters: &devinterface.CheckOperationParameters{
+				Resource: coreRelationship.ResourceAndRelation,
+				Subject:  coreRelationship.Subject,
+			},
+		})
+	}
+
+	results, requestErrors, err := runDeveloperOperations(ctx, req.Context, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requestErrors) > 0 {
+		return &v0.EditCheckResponse{
+			RequestErrors: requestErrors,
+		}, nil
+	}
+
+	checkResults := make([]*v0.EditCheckResult, 0, len(req.CheckRelationships))
+	for index, relationship := range req.CheckRelationships {
+		result := results.Results[uint64(index)].CheckResult
+
+		// The v0 API has no notion of caveats, so only unconditional membership is reported.
+		checkResults = append(checkResults, &v0.EditCheckResult{
+			Relationship: relationship,
+			IsMember:     result.Membership == devinterface.CheckOperationsResult_MEMBER,
+			Error:        toV0DeveloperError(result.CheckError),
+		})
+	}
+
+	return &v0.EditCheckResponse{
+		CheckResults: checkResults,
+	}, nil
 }
 
-func (ds *devServer) Validate(_ context.Context, _ *v0.ValidateRequest) (*v0.ValidateResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+func (ds *devServer) Validate(ctx context.Context, req *v0.ValidateRequest) (*v0.ValidateResponse, error) {
+	var operations []*devinterface.Operation
+	if req.AssertionsYaml != "" {
+		operations = append(operations, &devinterface.Operation{
+			AssertionsParameters: &devinterface.RunAssertionsParameters{
+				AssertionsYaml: req.AssertionsYaml,
+			},
+		})
+	}
+
+	if req.ValidationYaml != "" || req.UpdateValidationYaml {
+		operations = append(operations, &devinterface.Operation{
+			ValidationParameters: &devinterface.RunValidationParameters{
+				ValidationYaml: req.ValidationYaml,
+			},
+		})
+	}
+
+	results, requestErrors, err := runDeveloperOperations(ctx, req.Context, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &v0.ValidateResponse{
+		RequestErrors: requestErrors,
+	}
+	if len(requestErrors) > 0 {
+		return resp, nil
+	}
+
+	for _, result := range results.Results {
+		if assertionsResult := result.AssertionsResult; assertionsResult != nil {
+			if assertionsResult.InputError != nil {
+				resp.RequestErrors = append(resp.RequestErrors, toV0DeveloperError(assertionsResult.InputError))
+			}
+			resp.ValidationErrors = append(resp.ValidationErrors, toV0DeveloperErrors(assertionsResult.ValidationErrors)...)
+		}
+
+		if validationResult := result.ValidationResult; validationResult != nil {
+			if validationResult.InputError != nil {
+				resp.RequestErrors = append(resp.RequestErrors, toV0DeveloperError(validationResult.InputError))
+			}
+			resp.ValidationErrors = append(resp.ValidationErrors, toV0DeveloperErrors(validationResult.ValidationErrors)...)
+
+			if req.UpdateValidationYaml {
+				resp.UpdatedValidationYaml = validationResult.UpdatedValidationYaml
+			}
+		}
+	}
+
+	return resp, nil
 }
 
-func (ds *devServer) FormatSchema(_ context.Context, _ *v0.FormatSchemaRequest) (*v0.FormatSchemaResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+func (ds *devServer) FormatSchema(ctx context.Context, req *v0.FormatSchemaRequest) (*v0.FormatSchemaResponse, error) {
+	results, requestErrors, err := runDeveloperOperations(ctx, &v0.RequestContext{Schema: req.Schema}, []*devinterface.Operation{
+		{FormatSchemaParameters: &devinterface.FormatSchemaParameters{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requestErrors) > 0 {
+		return &v0.FormatSchemaResponse{
+			Error: requestErrors[0],
+		}, nil
+	}
+
+	return &v0.FormatSchemaResponse{
+		FormattedSchema: results.Results[0].FormatSchemaResult.FormattedSchema,
+	}, nil
+}
+
+// runDeveloperOperations runs the operations natively against the context of a v0 request, in the
+// same manner as the WebAssembly development package. Errors in the schema or relationships of the
+// context are returned as request errors.
+func runDeveloperOperations(ctx context.Context, reqContext *v0.RequestContext, operations []*devinterface.Operation) (*devinterface.OperationsResults, []*v0.DeveloperError, error) {
+	if reqContext == nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "missing required context")
+	}
+
+	devContext, devErrs, err := development.NewDevContext(ctx, &devinterface.RequestContext{
+		Schema:        reqContext.Schema,
+		Relationships: core.ToCoreRelationTuples(reqContext.Relationships),
+	})
+	if err != nil {
+		if devErr := toRelationshipDeveloperError(err); devErr != nil {
+			return nil, []*v0.DeveloperError{devErr}, nil
+		}
+		return nil, nil, err
+	}
+
+	if devErrs != nil && len(devErrs.InputErrors) > 0 {
+		return nil, toV0DeveloperErrors(devErrs.InputErrors), nil
+	}
+	defer devContext.Dispose()
+
+	results := make(map[uint64]*devinterface.OperationResult, len(operations))
+	for index, op := range operations {
+		result, err := development.RunOperation(devContext, op)
+		if err != nil {
+			return nil, nil, err
+		}
+		results[uint64(index)] = result
+	}
+
+	return &devinterface.OperationsResults{Results: results}, nil, nil
+}
+
+// toRelationshipDeveloperError converts an error raised when validating the relationships of a
+// request context into a developer error, or returns nil if the error is not a validation error.
+func toRelationshipDeveloperError(err error) *v0.DeveloperError {
+	var invalidSubjectErr relationships.ErrInvalidSubjectType
+	var permissionErr relationships.ErrCannotWriteToPermission
+	var caveatErr relationships.ErrCaveatNotFound
+	var nsNotFoundErr sharederrors.UnknownNamespaceError
+	var relNotFoundErr sharederrors.UnknownRelationError
+
+	kind := v0.DeveloperError_UNKNOWN_KIND
+	switch {
+	case errors.As(err, &nsNotFoundErr):
+		kind = v0.DeveloperError_UNKNOWN_OBJECT_TYPE
+	case errors.As(err, &relNotFoundErr), errors.As(err, &permissionErr):
+		kind = v0.DeveloperError_UNKNOWN_RELATION
+	case errors.As(err, &invalidSubjectErr), errors.As(err, &caveatErr):
+		// The v0 API has no kind for these errors.
+	default:
+		return nil
+	}
+
+	return &v0.DeveloperError{
+		Message: err.Error(),
+		Source:  v0.DeveloperError_RELATIONSHIP,
+		Kind:    kind,
+	}
+}
+
+func toV0DeveloperErrors(devErrs []*devinterface.DeveloperError) []*v0.DeveloperError {
+	v0Errs := make([]*v0.DeveloperError, 0, len(devErrs))
+	for _, devErr := range devErrs {
+		v0Errs = append(v0Errs, toV0DeveloperError(devErr))
+	}
+	return v0Errs
+}
+
+func toV0DeveloperError(devErr *devinterface.DeveloperError) *v0.DeveloperError {
+	if devErr == nil {
+		return nil
+	}
+
+	return &v0.DeveloperError{
+		Message: devErr.Message,
+		Line:    devErr.Line,
+		Column:  devErr.Column,
+		Source:  v0.DeveloperError_Source(devErr.Source),
+		Kind:    v0.DeveloperError_ErrorKind(devErr.Kind),
+		Path:    devErr.Path,
+		Context: devErr.Context,
+	}
 }
 
 func upgradeSchema(configs []string) (string, error) {
@@ -8,6 +8,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestDeveloperSharing(t *testing.T) {
@@ -74,3 +77,113 @@ func TestDeveloperSharingConverted(t *testing.T) {
 
 	require.Equal("definition foo {}\n\n", lresp.Schema)
 }
+
+const developerTestSchema = `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func v0Relationship(relationship string) *v0.RelationTuple {
+	return core.ToV0RelationTuple(tuple.MustParse(relationship))
+}
+
+func TestDeveloperFormatSchema(t *testing.T) {
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.FormatSchema(context.Background(), &v0.FormatSchemaRequest{
+		Schema: "definition user {}   definition document { relation viewer: user\n permission view = viewer\n}",
+	})
+	require.NoError(err)
+	require.Nil(resp.Error)
+	require.Equal(developerTestSchema, resp.FormattedSchema)
+
+	resp, err = srv.FormatSchema(context.Background(), &v0.FormatSchemaRequest{
+		Schema: "definition user {",
+	})
+	require.NoError(err)
+	require.NotNil(resp.Error)
+	require.Equal(v0.DeveloperError_SCHEMA, resp.Error.Source)
+}
+
+func TestDeveloperEditCheck(t *testing.T) {
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.EditCheck(context.Background(), &v0.EditCheckRequest{
+		Context: &v0.RequestContext{
+			Schema: developerTestSchema,
+			Relationships: []*v0.RelationTuple{
+				v0Relationship("document:somedoc#viewer@user:tom"),
+			},
+		},
+		CheckRelationships: []*v0.RelationTuple{
+			v0Relationship("document:somedoc#view@user:tom"),
+			v0Relationship("document:somedoc#view@user:sarah"),
+			v0Relationship("document:somedoc#unknown@user:tom"),
+		},
+	})
+	require.NoError(err)
+	require.Empty(resp.RequestErrors)
+	require.Len(resp.CheckResults, 3)
+
+	require.True(resp.CheckResults[0].IsMember)
+	require.Nil(resp.CheckResults[0].Error)
+	require.False(resp.CheckResults[1].IsMember)
+	require.Nil(resp.CheckResults[1].Error)
+	require.False(resp.CheckResults[2].IsMember)
+	require.NotNil(resp.CheckResults[2].Error)
+	require.Equal(v0.DeveloperError_UNKNOWN_RELATION, resp.CheckResults[2].Error.Kind)
+}
+
+func TestDeveloperEditCheckInvalidContext(t *testing.T) {
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.EditCheck(context.Background(), &v0.EditCheckRequest{
+		Context: &v0.RequestContext{
+			Schema: developerTestSchema,
+			Relationships: []*v0.RelationTuple{
+				v0Relationship("document:somedoc#view@user:tom"),
+			},
+		},
+	})
+	require.NoError(err)
+	require.Len(resp.RequestErrors, 1)
+	require.Equal(v0.DeveloperError_RELATIONSHIP, resp.RequestErrors[0].Source)
+}
+
+func TestDeveloperValidate(t *testing.T) {
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	reqContext := &v0.RequestContext{
+		Schema: developerTestSchema,
+		Relationships: []*v0.RelationTuple{
+			v0Relationship("document:somedoc#viewer@user:tom"),
+		},
+	}
+
+	resp, err := srv.Validate(context.Background(), &v0.ValidateRequest{
+		Context:              reqContext,
+		AssertionsYaml:       "assertTrue:\n- document:somedoc#view@user:tom\n",
+		ValidationYaml:       "document:somedoc#view:\n- '[user:tom] is <document:somedoc#viewer>'\n",
+		UpdateValidationYaml: true,
+	})
+	require.NoError(err)
+	require.Empty(resp.RequestErrors)
+	require.Empty(resp.ValidationErrors)
+	require.Contains(resp.UpdatedValidationYaml, "document:somedoc#view")
+
+	resp, err = srv.Validate(context.Background(), &v0.ValidateRequest{
+		Context:        reqContext,
+		AssertionsYaml: "assertTrue:\n- document:somedoc#view@user:sarah\n",
+	})
+	require.NoError(err)
+	require.Empty(resp.RequestErrors)
+	require.Len(resp.ValidationErrors, 1)
+	require.Equal(v0.DeveloperError_ASSERTION_FAILED, resp.ValidationErrors[0].Kind)
+	require.Empty(resp.UpdatedValidationYaml)
+}
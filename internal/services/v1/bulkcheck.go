@@ -0,0 +1,247 @@
+package v1
+
+import (
+	"context"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	dispatchpkg "github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// defaultMaxBulkCheckConcurrency is the number of groups of a BulkCheckPermission call that
+// are dispatched concurrently.
+const defaultMaxBulkCheckConcurrency = 10
+
+// bulkChecker performs the checks of a BulkCheckPermission call, grouping the items that share
+// a resource type, permission, subject and caveat context into a single dispatch with multiple
+// resource IDs.
+type bulkChecker struct {
+	dispatch dispatchpkg.Dispatcher
+
+	maxAPIDepth          uint32
+	maxCaveatContextSize int
+	maxChecks            uint16
+	maxConcurrency       uint16
+}
+
+// checkGroup is a set of bulk check items which can be answered by the same dispatch.
+type checkGroup struct {
+	item          *experimental.BulkCheckPermissionRequestItem
+	caveatContext map[string]any
+	resourceIDs   []string
+	itemIndexes   []int
+}
+
+func (bc *bulkChecker) checkBulkPermissions(ctx context.Context, req *experimental.BulkCheckPermissionRequest) (*experimental.BulkCheckPermissionResponse, error) {
+	atRevision, checkedAt, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) > int(bc.maxChecks) {
+		return nil, NewExceedsMaximumChecksErr(uint64(len(req.Items)), uint64(bc.maxChecks))
+	}
+
+	pairs := make([]*experimental.BulkCheckPermissionPair, len(req.Items))
+	setError := func(itemIndex int, err error) {
+		pairs[itemIndex] = &experimental.BulkCheckPermissionPair{
+			Request: req.Items[itemIndex],
+			Response: &experimental.BulkCheckPermissionPair_Error{
+				Error: status.Convert(rewriteError(ctx, err)).Proto(),
+			},
+		}
+	}
+
+	groups := make(map[string]*checkGroup)
+	orderedGroups := make([]*checkGroup, 0)
+	for index, item := range req.Items {
+		caveatContext, err := GetCaveatContext(ctx, item.Context, bc.maxCaveatContextSize)
+		if err != nil {
+			setError(index, err)
+			continue
+		}
+
+		key, err := checkGroupKey(item)
+		if err != nil {
+			setError(index, err)
+			continue
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			group = &checkGroup{
+				item:          item,
+				caveatContext: caveatContext,
+			}
+			groups[key] = group
+			orderedGroups = append(orderedGroups, group)
+		}
+
+		group.itemIndexes = append(group.itemIndexes, index)
+		group.resourceIDs = append(group.resourceIDs, item.Resource.ObjectId)
+	}
+
+	respMetadata := &dispatch.ResponseMeta{}
+	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+
+	var mu sync.Mutex
+	tr, groupCtx := errgroup.WithContext(ctx)
+	tr.SetLimit(int(bc.maxConcurrency))
+
+	for _, group := range orderedGroups {
+		group := group
+		tr.Go(func() error {
+			results, metadata, err := bc.checkGroup(groupCtx, ds, atRevision, group)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if metadata != nil {
+				dispatchpkg.AddResponseMetadata(respMetadata, metadata)
+			}
+
+			for _, index := range group.itemIndexes {
+				if err != nil {
+					setError(index, err)
+					continue
+				}
+
+				pairs[index] = &experimental.BulkCheckPermissionPair{
+					Request: req.Items[index],
+					Response: &experimental.BulkCheckPermissionPair_Item{
+						Item: checkResultToBulkCheckItem(results[req.Items[index].Resource.ObjectId]),
+					},
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := tr.Wait(); err != nil {
+		return nil, err
+	}
+
+	usagemetrics.SetInContext(ctx, respMetadata)
+
+	return &experimental.BulkCheckPermissionResponse{
+		CheckedAt: checkedAt,
+		Pairs:     pairs,
+	}, nil
+}
+
+// checkGroup runs the check for all resources in the group, chunking the resource IDs into
+// dispatches of at most datastore.FilterMaximumIDCount resources each.
+func (bc *bulkChecker) checkGroup(ctx context.Context, ds datastore.Reader, atRevision datastore.Revision, group *checkGroup) (map[string]*dispatch.ResourceCheckResult, *dispatch.ResponseMeta, error) {
+	if err := namespace.CheckNamespaceAndRelation(ctx, group.item.Resource.ObjectType, group.item.Permission, false, ds); err != nil {
+		return nil, nil, err
+	}
+
+	if err := namespace.CheckNamespaceAndRelation(ctx, group.item.Subject.Object.ObjectType, normalizeSubjectRelation(group.item.Subject), true, ds); err != nil {
+		return nil, nil, err
+	}
+
+	params := computed.CheckParameters{
+		ResourceType: &core.RelationReference{
+			Namespace: group.item.Resource.ObjectType,
+			Relation:  group.item.Permission,
+		},
+		Subject: &core.ObjectAndRelation{
+			Namespace: group.item.Subject.Object.ObjectType,
+			ObjectId:  group.item.Subject.Object.ObjectId,
+			Relation:  normalizeSubjectRelation(group.item.Subject),
+		},
+		CaveatContext: group.caveatContext,
+		AtRevision:    atRevision,
+		MaximumDepth:  bc.maxAPIDepth,
+		DebugOption:   computed.NoDebugging,
+	}
+
+	// The same resource may be checked more than once in a single request.
+	seen := make(map[string]struct{}, len(group.resourceIDs))
+	uniqueIDs := make([]string, 0, len(group.resourceIDs))
+	for _, resourceID := range group.resourceIDs {
+		if _, ok := seen[resourceID]; ok {
+			continue
+		}
+		seen[resourceID] = struct{}{}
+		uniqueIDs = append(uniqueIDs, resourceID)
+	}
+
+	results := make(map[string]*dispatch.ResourceCheckResult, len(uniqueIDs))
+	metadata := &dispatch.ResponseMeta{}
+	for start := 0; start < len(uniqueIDs); start += int(datastore.FilterMaximumIDCount) {
+		end := start + int(datastore.FilterMaximumIDCount)
+		if end > len(uniqueIDs) {
+			end = len(uniqueIDs)
+		}
+
+		chunkResults, chunkMetadata, err := computed.ComputeBulkCheck(ctx, bc.dispatch, params, uniqueIDs[start:end])
+		if chunkMetadata != nil {
+			dispatchpkg.AddResponseMetadata(metadata, chunkMetadata)
+		}
+		if err != nil {
+			return nil, metadata, err
+		}
+
+		for resourceID, result := range chunkResults {
+			results[resourceID] = result
+		}
+	}
+
+	return results, metadata, nil
+}
+
+// checkGroupKey returns a key which is the same for all items that can be answered by a
+// single dispatch.
+func checkGroupKey(item *experimental.BulkCheckPermissionRequestItem) (string, error) {
+	subject := tuple.StringONR(&core.ObjectAndRelation{
+		Namespace: item.Subject.Object.ObjectType,
+		ObjectId:  item.Subject.Object.ObjectId,
+		Relation:  normalizeSubjectRelation(item.Subject),
+	})
+
+	key := item.Resource.ObjectType + "#" + item.Permission + "@" + subject
+	if item.Context == nil {
+		return key, nil
+	}
+
+	serializedContext, err := proto.MarshalOptions{Deterministic: true}.Marshal(item.Context)
+	if err != nil {
+		return "", err
+	}
+	return key + "/" + string(serializedContext), nil
+}
+
+func checkResultToBulkCheckItem(result *dispatch.ResourceCheckResult) *experimental.BulkCheckPermissionResponseItem {
+	item := &experimental.BulkCheckPermissionResponseItem{
+		Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION,
+	}
+	if result == nil {
+		return item
+	}
+
+	switch result.Membership {
+	case dispatch.ResourceCheckResult_MEMBER:
+		item.Permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	case dispatch.ResourceCheckResult_CAVEATED_MEMBER:
+		item.Permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION
+		item.PartialCaveatInfo = &v1.PartialCaveatInfo{
+			MissingRequiredContext: result.MissingExprFields,
+		}
+	}
+	return item
+}
@@ -0,0 +1,254 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// exportBulkRelationshipsCallHash is recorded in export cursors in place of a hash of the
+// request, as an export has no parameters which affect the results.
+const exportBulkRelationshipsCallHash = "ebr"
+
+// bulkLoadAdapter adapts the stream of an ImportBulkRelationships call into a source of
+// relationships for a datastore bulk load, validating each received batch before any of its
+// relationships are handed to the datastore.
+type bulkLoadAdapter struct {
+	stream experimental.ExperimentalService_ImportBulkRelationshipsServer
+	reader datastore.Reader
+
+	currentBatch []*core.RelationTupleUpdate
+	numSent      int
+}
+
+func (a *bulkLoadAdapter) Next(ctx context.Context) (*core.RelationTuple, error) {
+	for a.numSent == len(a.currentBatch) {
+		batch, err := a.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updates := make([]*core.RelationTupleUpdate, 0, len(batch.Relationships))
+		for _, rel := range batch.Relationships {
+			updates = append(updates, tuple.Create(tuple.MustFromRelationship(rel)))
+		}
+
+		for _, expiration := range batch.Expirations {
+			index := int(expiration.RelationshipIndex)
+			if index >= len(updates) {
+				return nil, status.Errorf(codes.InvalidArgument, "expiration given for relationship %d, but the batch contains %d relationships", index, len(updates))
+			}
+			if updates[index].Tuple.OptionalExpirationTime != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "more than one expiration given for relationship %d", index)
+			}
+			updates[index].Tuple.OptionalExpirationTime = expiration.ExpiresAt
+		}
+
+		if err := relationships.ValidateRelationshipUpdates(ctx, a.reader, updates); err != nil {
+			return nil, err
+		}
+
+		a.currentBatch = updates
+		a.numSent = 0
+	}
+
+	next := a.currentBatch[a.numSent].Tuple
+	a.numSent++
+	return next, nil
+}
+
+func importBulkRelationships(stream experimental.ExperimentalService_ImportBulkRelationshipsServer) (*experimental.ImportBulkRelationshipsResponse, error) {
+	ctx := stream.Context()
+	ds := datastoremw.MustFromContext(ctx)
+
+	adapter := &bulkLoadAdapter{stream: stream}
+
+	var numWritten uint64
+	if _, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		// The datastore may retry the transaction, but the relationships already received
+		// from the stream cannot be replayed, so the import must instead be restarted by
+		// the caller.
+		if adapter.reader != nil {
+			return status.Errorf(codes.Aborted, "the import transaction was retried by the datastore; please restart the import")
+		}
+		adapter.reader = rwt
+
+		loadedCount, err := rwt.BulkLoad(ctx, adapter)
+		numWritten = loadedCount
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: 1,
+	})
+
+	return &experimental.ImportBulkRelationshipsResponse{
+		NumLoaded: numWritten,
+	}, nil
+}
+
+func exportBulkRelationships(req *experimental.ExportBulkRelationshipsRequest, resp experimental.ExperimentalService_ExportBulkRelationshipsServer, maxBatchSize uint64) error {
+	ctx := resp.Context()
+	atRevision, _, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	limit := maxBatchSize
+	if req.OptionalLimit > 0 && uint64(req.OptionalLimit) < limit {
+		limit = uint64(req.OptionalLimit)
+	}
+
+	// The cursor holds the namespace being exported and the last relationship exported
+	// within it. If a cursor was given, the export resumes at the revision at which it began.
+	var currentNamespace string
+	var currentCursor options.Cursor
+	if req.OptionalCursor != "" {
+		decoded, cursorRevision, err := cursor.DecodeToDispatchCursor(req.OptionalCursor, exportBulkRelationshipsCallHash, datastoremw.MustFromContext(ctx))
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+
+		if len(decoded.Sections) != 2 {
+			return status.Errorf(codes.InvalidArgument, "invalid cursor: expected 2 sections, found %d", len(decoded.Sections))
+		}
+
+		currentNamespace = decoded.Sections[0]
+		afterTuple := tuple.Parse(decoded.Sections[1])
+		if afterTuple == nil {
+			return status.Errorf(codes.InvalidArgument, "invalid cursor: malformed relationship")
+		}
+		currentCursor = afterTuple
+		atRevision = cursorRevision
+	}
+
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Namespaces are exported in name order, so that the namespace recorded in the cursor
+	// determines which have already been exported.
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Definition.Name < namespaces[j].Definition.Name
+	})
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: 1,
+	})
+
+	relsArray := make([]v1.Relationship, limit)
+	objArray := make([]v1.ObjectReference, limit)
+	subArray := make([]v1.SubjectReference, limit)
+	subObjArray := make([]v1.ObjectReference, limit)
+	caveatArray := make([]v1.ContextualizedCaveat, limit)
+	for i := range relsArray {
+		relsArray[i].Resource = &objArray[i]
+		relsArray[i].Subject = &subArray[i]
+		relsArray[i].Subject.Object = &subObjArray[i]
+	}
+
+	emptyRels := make([]*v1.Relationship, limit)
+	for _, ns := range namespaces {
+		nsName := ns.Definition.Name
+		if nsName < currentNamespace {
+			continue
+		}
+
+		// Only the namespace named in the cursor resumes partway through.
+		if nsName != currentNamespace {
+			currentCursor = nil
+		}
+
+		for {
+			iter, err := reader.QueryRelationships(
+				ctx,
+				datastore.RelationshipsFilter{ResourceType: nsName},
+				options.WithLimit(&limit),
+				options.WithAfter(currentCursor),
+				options.WithSort(options.ByResource),
+			)
+			if err != nil {
+				return err
+			}
+
+			rels := emptyRels[:0]
+			var expirations []*experimental.RelationshipExpiration
+			var lastTuple *core.RelationTuple
+			for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				offset := len(rels)
+				rels = append(rels, &relsArray[offset])
+
+				var caveat *v1.ContextualizedCaveat
+				if tpl.Caveat != nil {
+					caveat = &caveatArray[offset]
+				}
+				tuple.MustToRelationshipMutating(tpl, rels[offset], caveat)
+				if tpl.OptionalExpirationTime != nil {
+					expirations = append(expirations, &experimental.RelationshipExpiration{
+						RelationshipIndex: uint32(offset),
+						ExpiresAt:         tpl.OptionalExpirationTime,
+					})
+				}
+				lastTuple = tpl
+			}
+			if iter.Err() != nil {
+				iter.Close()
+				return iter.Err()
+			}
+
+			if len(rels) == 0 {
+				iter.Close()
+				break
+			}
+
+			encoded, err := cursor.EncodeFromDispatchCursor(&dispatchv1.Cursor{
+				Sections: []string{nsName, tuple.StringWithoutCaveat(lastTuple)},
+			}, exportBulkRelationshipsCallHash, atRevision)
+			iter.Close()
+			if err != nil {
+				return err
+			}
+
+			if err := resp.Send(&experimental.ExportBulkRelationshipsResponse{
+				AfterResultCursor: encoded,
+				Relationships:     rels,
+				Expirations:       expirations,
+			}); err != nil {
+				return err
+			}
+
+			if uint64(len(rels)) < limit {
+				break
+			}
+
+			currentCursor = lastTuple
+		}
+	}
+
+	return nil
+}
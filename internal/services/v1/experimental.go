@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/services/shared"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+)
+
+// NewExperimentalServer creates an ExperimentalServiceServer instance.
+func NewExperimentalServer(dispatch dispatch.Dispatcher, config PermissionsServerConfig) experimental.ExperimentalServiceServer {
+	return &experimentalServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(true),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+			Stream: middleware.ChainStreamServer(
+				grpcvalidate.StreamServerInterceptor(true),
+				usagemetrics.StreamServerInterceptor(),
+			),
+		},
+		permissionServer: newPermissionServer(dispatch, config),
+		permissionExplainer: &permissionExplainer{
+			dispatch:             dispatch,
+			maxAPIDepth:          defaultIfZero(config.MaximumAPIDepth, 50),
+			maxCaveatContextSize: config.MaxCaveatContextSize,
+		},
+		maxExportBatchSize: defaultIfZero(config.MaxDatastoreReadPageSize, 1_000),
+	}
+}
+
+type experimentalServer struct {
+	experimental.UnimplementedExperimentalServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	permissionServer    *permissionServer
+	permissionExplainer *permissionExplainer
+	maxExportBatchSize  uint64
+}
+
+// BulkCheckPermission is served by the permissions server, as the v1 PermissionsService does
+// not yet define it.
+func (es *experimentalServer) BulkCheckPermission(ctx context.Context, req *experimental.BulkCheckPermissionRequest) (*experimental.BulkCheckPermissionResponse, error) {
+	return es.permissionServer.BulkCheckPermission(ctx, req)
+}
+
+func (es *experimentalServer) ImportBulkRelationships(stream experimental.ExperimentalService_ImportBulkRelationshipsServer) error {
+	res, err := importBulkRelationships(stream)
+	if err != nil {
+		return rewriteError(stream.Context(), err)
+	}
+
+	return stream.SendAndClose(res)
+}
+
+func (es *experimentalServer) ExportBulkRelationships(req *experimental.ExportBulkRelationshipsRequest, resp experimental.ExperimentalService_ExportBulkRelationshipsServer) error {
+	if err := exportBulkRelationships(req, resp, es.maxExportBatchSize); err != nil {
+		return rewriteError(resp.Context(), err)
+	}
+
+	return nil
+}
+
+func (es *experimentalServer) DryRunWriteSchema(ctx context.Context, req *experimental.DryRunWriteSchemaRequest) (*experimental.DryRunWriteSchemaResponse, error) {
+	res, err := dryRunWriteSchema(ctx, req)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return res, nil
+}
+
+func (es *experimentalServer) ExplainPermission(ctx context.Context, req *experimental.ExplainPermissionRequest) (*experimental.ExplainPermissionResponse, error) {
+	res, err := es.permissionExplainer.explainPermission(ctx, req)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return res, nil
+}
+
+func (es *experimentalServer) VerifyRelationships(ctx context.Context, req *experimental.VerifyRelationshipsRequest) (*experimental.VerifyRelationshipsResponse, error) {
+	res, err := verifyRelationships(ctx, req)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return res, nil
+}
+
+// WatchWithSchemaChanges watches for changes as the v1 Watch API does, with the schema changes
+// made at each revision.
+func (es *experimentalServer) WatchWithSchemaChanges(req *experimental.WatchWithSchemaChangesRequest, stream experimental.ExperimentalService_WatchWithSchemaChangesServer) error {
+	return watchChanges(stream.Context(), req.GetOptionalObjectTypes(), req.OptionalStartCursor, true,
+		func(updates []*v1.RelationshipUpdate, schemaChanges *experimental.WatchSchemaChanges, changesThrough *v1.ZedToken) error {
+			return stream.Send(&experimental.WatchWithSchemaChangesResponse{
+				Updates:        updates,
+				ChangesThrough: changesThrough,
+				SchemaChanges:  schemaChanges,
+			})
+		})
+}
@@ -0,0 +1,657 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func TestBulkCheckPermission(t *testing.T) {
+	type bulkCheckTest struct {
+		resource       *v1.ObjectReference
+		permission     string
+		subject        *v1.SubjectReference
+		context        map[string]any
+		expected       v1.CheckPermissionResponse_Permissionship
+		missingContext []string
+		expectedError  codes.Code
+	}
+
+	testCases := []struct {
+		name       string
+		dsInitFunc func(datastore.Datastore, *require.Assertions) (datastore.Datastore, datastore.Revision)
+		items      []bulkCheckTest
+	}{
+		{
+			"same subject and permission",
+			tf.StandardDatastoreWithData,
+			[]bulkCheckTest{
+				{obj("document", "masterplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("document", "healthplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil, codes.OK},
+				{obj("document", "companyplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil, codes.OK},
+			},
+		},
+		{
+			"different subjects",
+			tf.StandardDatastoreWithData,
+			[]bulkCheckTest{
+				{obj("document", "healthplan"), "view", sub("user", "chief_financial_officer", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("document", "healthplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil, codes.OK},
+				{obj("document", "masterplan"), "view", sub("user", "auditor", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+			},
+		},
+		{
+			"duplicate items",
+			tf.StandardDatastoreWithData,
+			[]bulkCheckTest{
+				{obj("document", "masterplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("document", "masterplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+			},
+		},
+		{
+			"errors are returned per item",
+			tf.StandardDatastoreWithData,
+			[]bulkCheckTest{
+				{obj("document", "masterplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("fake", "masterplan"), "view", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, nil, codes.FailedPrecondition},
+				{obj("document", "masterplan"), "fakeperm", sub("user", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, nil, codes.FailedPrecondition},
+				{obj("document", "masterplan"), "view", sub("fake", "eng_lead", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, nil, codes.FailedPrecondition},
+				{obj("document", "healthplan"), "view", sub("user", "chief_financial_officer", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+			},
+		},
+		{
+			"caveated items grouped by context",
+			tf.StandardDatastoreWithCaveatedData,
+			[]bulkCheckTest{
+				{obj("document", "companyplan"), "view", sub("user", "owner", ""), map[string]any{"secret": "1234"}, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("document", "masterplan"), "view", sub("user", "owner", ""), map[string]any{"secret": "1234"}, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil, codes.OK},
+				{obj("document", "healthplan"), "view", sub("user", "owner", ""), map[string]any{"secret": "1234"}, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil, codes.OK},
+				{obj("document", "companyplan"), "view", sub("user", "owner", ""), map[string]any{"secret": "4321"}, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil, codes.OK},
+				{obj("document", "companyplan"), "view", sub("user", "owner", ""), nil, v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION, []string{"secret"}, codes.OK},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tc.dsInitFunc)
+			client := experimental.NewExperimentalServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			req := &experimental.BulkCheckPermissionRequest{
+				Consistency: &v1.Consistency{
+					Requirement: &v1.Consistency_AtLeastAsFresh{
+						AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+					},
+				},
+			}
+
+			for _, item := range tc.items {
+				var caveatContext *structpb.Struct
+				if item.context != nil {
+					converted, err := structpb.NewStruct(item.context)
+					require.NoError(err)
+					caveatContext = converted
+				}
+
+				req.Items = append(req.Items, &experimental.BulkCheckPermissionRequestItem{
+					Resource:   item.resource,
+					Permission: item.permission,
+					Subject:    item.subject,
+					Context:    caveatContext,
+				})
+			}
+
+			resp, err := client.BulkCheckPermission(context.Background(), req)
+			require.NoError(err)
+			require.NotNil(resp.CheckedAt)
+			require.Len(resp.Pairs, len(tc.items))
+
+			for index, item := range tc.items {
+				pair := resp.Pairs[index]
+				require.Equal(item.resource.ObjectId, pair.Request.Resource.ObjectId)
+				require.Equal(item.permission, pair.Request.Permission)
+
+				if item.expectedError != codes.OK {
+					require.NotNil(pair.GetError(), "expected error for item %d", index)
+					require.Equal(int32(item.expectedError), pair.GetError().Code)
+					continue
+				}
+
+				require.Nil(pair.GetError(), "unexpected error for item %d: %v", index, pair.GetError())
+				require.Equal(item.expected, pair.GetItem().Permissionship, "mismatch for item %d", index)
+				if item.missingContext != nil {
+					require.Equal(item.missingContext, pair.GetItem().PartialCaveatInfo.MissingRequiredContext)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkCheckPermissionOverLimit(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount: 1000,
+			MaxUpdatesPerWrite:    1000,
+			MaxBulkCheckItems:     1,
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := experimental.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	_, err := client.BulkCheckPermission(context.Background(), &experimental.BulkCheckPermissionRequest{
+		Items: []*experimental.BulkCheckPermissionRequestItem{
+			{Resource: obj("document", "masterplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			{Resource: obj("document", "healthplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+		},
+	})
+
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	require.Contains(err.Error(), "check count of 2 is greater than maximum allowed of 1")
+}
+
+func TestImportExportBulkRelationships(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithSchema)
+	client := experimental.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := context.Background()
+
+	const numRelationships = 250
+	const batchSize = 100
+
+	expected := make(map[string]struct{}, numRelationships)
+	writer, err := client.ImportBulkRelationships(ctx)
+	require.NoError(err)
+
+	var batch []*v1.Relationship
+	for i := 0; i < numRelationships; i++ {
+		// Alternate the resource type, so that the export must span multiple namespaces.
+		resourceType := "document"
+		if i%2 == 0 {
+			resourceType = "folder"
+		}
+
+		relationship := rel(resourceType, fmt.Sprintf("resource%d", i), "viewer", "user", fmt.Sprintf("user%d", i), "")
+		expected[tuple.StringRelationshipWithoutCaveat(relationship)] = struct{}{}
+
+		batch = append(batch, relationship)
+		if len(batch) == batchSize || i == numRelationships-1 {
+			require.NoError(writer.Send(&experimental.ImportBulkRelationshipsRequest{Relationships: batch}))
+			batch = nil
+		}
+	}
+
+	resp, err := writer.CloseAndRecv()
+	require.NoError(err)
+	require.Equal(uint64(numRelationships), resp.NumLoaded)
+
+	// Export the relationships in small pages, restarting the export from the cursor of
+	// every page to ensure it can be resumed.
+	found := make(map[string]struct{}, numRelationships)
+	var cursor string
+	for {
+		pageCtx, cancel := context.WithCancel(ctx)
+		stream, err := client.ExportBulkRelationships(pageCtx, &experimental.ExportBulkRelationshipsRequest{
+			Consistency:    &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			OptionalLimit:  37,
+			OptionalCursor: cursor,
+		})
+		require.NoError(err)
+
+		page, err := stream.Recv()
+		cancel()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		require.LessOrEqual(len(page.Relationships), 37)
+
+		for _, relationship := range page.Relationships {
+			relString := tuple.StringRelationshipWithoutCaveat(relationship)
+			_, ok := found[relString]
+			require.False(ok, "relationship %s exported twice", relString)
+			found[relString] = struct{}{}
+		}
+
+		require.NotEmpty(page.AfterResultCursor)
+		cursor = page.AfterResultCursor
+	}
+
+	require.Equal(expected, found)
+}
+
+func TestImportBulkRelationshipsErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		relationships []*v1.Relationship
+		expectedCode  codes.Code
+	}{
+		{
+			"unknown relation",
+			[]*v1.Relationship{rel("document", "doc1", "fakerel", "user", "user1", "")},
+			codes.FailedPrecondition,
+		},
+		{
+			"writing to a permission",
+			[]*v1.Relationship{rel("document", "doc1", "view", "user", "user1", "")},
+			codes.InvalidArgument,
+		},
+		{
+			"duplicate relationship",
+			[]*v1.Relationship{
+				rel("document", "doc1", "viewer", "user", "user1", ""),
+				rel("document", "doc1", "viewer", "user", "user1", ""),
+			},
+			codes.AlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithSchema)
+			client := experimental.NewExperimentalServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			writer, err := client.ImportBulkRelationships(context.Background())
+			require.NoError(err)
+
+			require.NoError(writer.Send(&experimental.ImportBulkRelationshipsRequest{Relationships: tc.relationships}))
+
+			_, err = writer.CloseAndRecv()
+			grpcutil.RequireStatus(t, tc.expectedCode, err)
+		})
+	}
+}
+
+const expirationSchema = `definition user {}
+
+definition document {
+	relation viewer: user | user with expiration
+}`
+
+func TestImportExportBulkRelationshipsExpiration(t *testing.T) {
+	req := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true,
+		func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			return tf.DatastoreFromSchemaAndTestRelationships(ds, expirationSchema, nil, require)
+		})
+	client := experimental.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := context.Background()
+	expiresAt := timestamppb.New(time.Now().Add(time.Hour).Truncate(time.Second))
+
+	writer, err := client.ImportBulkRelationships(ctx)
+	req.NoError(err)
+	req.NoError(writer.Send(&experimental.ImportBulkRelationshipsRequest{
+		Relationships: []*v1.Relationship{
+			rel("document", "doc1", "viewer", "user", "tom", ""),
+			rel("document", "doc2", "viewer", "user", "fred", ""),
+		},
+		Expirations: []*experimental.RelationshipExpiration{
+			{RelationshipIndex: 1, ExpiresAt: expiresAt},
+		},
+	}))
+	resp, err := writer.CloseAndRecv()
+	req.NoError(err)
+	req.Equal(uint64(2), resp.NumLoaded)
+
+	stream, err := client.ExportBulkRelationships(ctx, &experimental.ExportBulkRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	})
+	req.NoError(err)
+
+	page, err := stream.Recv()
+	req.NoError(err)
+	req.Len(page.Relationships, 2)
+	req.Len(page.Expirations, 1)
+
+	expired := page.Relationships[page.Expirations[0].RelationshipIndex]
+	req.Equal("document:doc2#viewer@user:fred", tuple.StringRelationshipWithoutCaveat(expired))
+	req.True(expiresAt.AsTime().Equal(page.Expirations[0].ExpiresAt.AsTime()))
+}
+
+func TestImportBulkRelationshipsInvalidExpiration(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expirations []*experimental.RelationshipExpiration
+	}{
+		{
+			"index out of range",
+			[]*experimental.RelationshipExpiration{
+				{RelationshipIndex: 1, ExpiresAt: timestamppb.Now()},
+			},
+		},
+		{
+			"duplicate index",
+			[]*experimental.RelationshipExpiration{
+				{RelationshipIndex: 0, ExpiresAt: timestamppb.Now()},
+				{RelationshipIndex: 0, ExpiresAt: timestamppb.Now()},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := require.New(t)
+			conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true,
+				func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+					return tf.DatastoreFromSchemaAndTestRelationships(ds, expirationSchema, nil, require)
+				})
+			client := experimental.NewExperimentalServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			writer, err := client.ImportBulkRelationships(context.Background())
+			req.NoError(err)
+			req.NoError(writer.Send(&experimental.ImportBulkRelationshipsRequest{
+				Relationships: []*v1.Relationship{rel("document", "doc1", "viewer", "user", "tom", "")},
+				Expirations:   tc.expirations,
+			}))
+
+			_, err = writer.CloseAndRecv()
+			grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+		})
+	}
+}
+
+func TestExportBulkRelationshipsInvalidCursor(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := experimental.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	stream, err := client.ExportBulkRelationships(context.Background(), &experimental.ExportBulkRelationshipsRequest{
+		OptionalCursor: "invalid",
+	})
+	require.NoError(err)
+
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+const explainSchema = `definition user {}
+
+definition group {
+	relation member: user
+}
+
+caveat on_day(day string) {
+	day == "tuesday"
+}
+
+definition document {
+	relation viewer: user | user with on_day | group#member
+	relation banned: user
+	permission view = viewer - banned
+}`
+
+func TestExplainPermission(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		resourceID            string
+		subjectID             string
+		context               map[string]any
+		expected              v1.CheckPermissionResponse_Permissionship
+		expectedRelationships []string
+		expectedText          []string
+		unexpectedText        []string
+	}{
+		{
+			"granted through a group",
+			"doc1",
+			"tom",
+			nil,
+			v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+			[]string{"document:doc1#viewer@group:eng#member", "group:eng#member@user:tom"},
+			[]string{
+				"document:doc1#view (permission) at line 14",
+				"    document:doc1#viewer (relation) at line 12",
+				"      document:doc1#viewer@group:eng#member: granted",
+				"        group:eng#member (relation) at line 4",
+				"          group:eng#member@user:tom: granted",
+				"    document:doc1#banned (relation) at line 13",
+			},
+			nil,
+		},
+		{
+			"excluded",
+			"doc1",
+			"fred",
+			nil,
+			v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION,
+			nil,
+			[]string{
+				"      document:doc1#banned@user:fred: granted",
+			},
+			nil,
+		},
+		{
+			"missing caveat context",
+			"doc2",
+			"tom",
+			nil,
+			v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION,
+			[]string{"document:doc2#viewer@user:tom[on_day]"},
+			[]string{"document:doc2#viewer@user:tom: conditionally granted (caveat on_day", "is missing context: day)"},
+			nil,
+		},
+		{
+			"caveat false",
+			"doc2",
+			"tom",
+			map[string]any{"day": "monday"},
+			v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION,
+			nil,
+			[]string{"document:doc2#viewer@user:tom: not granted (caveat on_day", "is false)"},
+			nil,
+		},
+		{
+			"unrelated subject",
+			"doc1",
+			"sarah",
+			nil,
+			v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION,
+			nil,
+			[]string{"        group:eng#member (relation) at line 4"},
+			[]string{"document:doc1#banned (relation)"},
+		},
+	}
+
+	relationships := []*core.RelationTuple{
+		tuple.MustParse("document:doc1#viewer@group:eng#member"),
+		tuple.MustParse("document:doc1#banned@user:fred"),
+		tuple.MustParse("group:eng#member@user:tom"),
+		tuple.MustParse("group:eng#member@user:fred"),
+		tuple.MustParse("document:doc2#viewer@user:tom[on_day]"),
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := require.New(t)
+
+			conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true,
+				func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+					return tf.DatastoreFromSchemaAndTestRelationships(ds, explainSchema, relationships, require)
+				})
+			t.Cleanup(cleanup)
+			client := experimental.NewExperimentalServiceClient(conn)
+
+			var caveatContext *structpb.Struct
+			if tc.context != nil {
+				var err error
+				caveatContext, err = structpb.NewStruct(tc.context)
+				req.NoError(err)
+			}
+
+			resp, err := client.ExplainPermission(context.Background(), &experimental.ExplainPermissionRequest{
+				Consistency: &v1.Consistency{
+					Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+				},
+				Resource:   obj("document", tc.resourceID),
+				Permission: "view",
+				Subject:    sub("user", tc.subjectID, ""),
+				Context:    caveatContext,
+			})
+			req.NoError(err)
+			req.Equal(tc.expected, resp.Permissionship)
+
+			relationships := make([]string, 0, len(resp.Relationships))
+			for _, rel := range resp.Relationships {
+				relationships = append(relationships, tuple.MustStringRelationship(rel))
+			}
+			req.ElementsMatch(tc.expectedRelationships, relationships)
+
+			for _, line := range tc.expectedText {
+				req.Contains(resp.ExplanationText, line)
+			}
+			for _, line := range tc.unexpectedText {
+				req.NotContains(resp.ExplanationText, line)
+			}
+		})
+	}
+}
+
+func TestVerifyRelationships(t *testing.T) {
+	req := require.New(t)
+
+	invalid := []*core.RelationTuple{
+		tuple.MustParse("document:doc1#view@user:tom"),
+		tuple.MustParse("document:doc1#banned@group:eng#member"),
+		tuple.MustParse("document:doc2#banned@group:eng#member"),
+	}
+
+	conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true,
+		func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			ds, _ = tf.DatastoreFromSchemaAndTestRelationships(ds, explainSchema, []*core.RelationTuple{
+				tuple.MustParse("document:doc1#viewer@group:eng#member"),
+				tuple.MustParse("group:eng#member@user:tom"),
+			}, require)
+
+			revision, err := common.WriteTuples(context.Background(), ds, core.RelationTupleUpdate_CREATE, invalid...)
+			req.NoError(err)
+			return ds, revision
+		})
+	t.Cleanup(cleanup)
+	client := experimental.NewExperimentalServiceClient(conn)
+
+	resp, err := client.VerifyRelationships(context.Background(), &experimental.VerifyRelationshipsRequest{
+		OptionalMaxSamples: 1,
+	})
+	req.NoError(err)
+	req.Equal(uint64(5), resp.CheckedRelationshipCount)
+	req.Len(resp.Issues, 2)
+	req.Zero(resp.RepairedRelationshipCount)
+
+	causes := map[experimental.RelationshipIntegrityIssue_Cause]uint64{}
+	for _, issue := range resp.Issues {
+		causes[issue.Cause] = issue.RelationshipCount
+		req.Len(issue.SampleRelationships, 1)
+	}
+	req.Equal(map[experimental.RelationshipIntegrityIssue_Cause]uint64{
+		experimental.RelationshipIntegrityIssue_CAUSE_RELATION_IS_PERMISSION: 1,
+		experimental.RelationshipIntegrityIssue_CAUSE_DISALLOWED_SUBJECT:     2,
+	}, causes)
+
+	// Each call quarantines at most one batch, so quarantining all of the invalid relationships
+	// takes two calls.
+	var quarantined []string
+	for _, expectedCount := range []int{2, 1} {
+		resp, err = client.VerifyRelationships(context.Background(), &experimental.VerifyRelationshipsRequest{
+			Repair:            experimental.VerifyRelationshipsRequest_REPAIR_QUARANTINE,
+			OptionalBatchSize: 2,
+		})
+		req.NoError(err)
+		req.Equal(uint64(expectedCount), resp.RepairedRelationshipCount)
+		req.Len(resp.QuarantinedRelationships, expectedCount)
+		quarantined = append(quarantined, resp.QuarantinedRelationships...)
+	}
+
+	expected := make([]string, 0, len(invalid))
+	for _, rel := range invalid {
+		expected = append(expected, tuple.MustString(rel))
+	}
+	req.ElementsMatch(expected, quarantined)
+
+	resp, err = client.VerifyRelationships(context.Background(), &experimental.VerifyRelationshipsRequest{})
+	req.NoError(err)
+	req.Equal(uint64(2), resp.CheckedRelationshipCount)
+	req.Empty(resp.Issues)
+}
+
+func TestWatchWithSchemaChanges(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := experimental.NewExperimentalServiceClient(conn).WatchWithSchemaChanges(ctx, &experimental.WatchWithSchemaChangesRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	schemaClient := v1.NewSchemaServiceClient(conn)
+	existing, err := schemaClient.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(err)
+
+	_, err = schemaClient.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: existing.SchemaText + `
+
+definition team {
+	relation member: user
+}`,
+	})
+	require.NoError(err)
+
+	for {
+		resp, err := stream.Recv()
+		require.NoError(err)
+		if resp.SchemaChanges == nil {
+			continue
+		}
+
+		require.Empty(resp.Updates)
+		require.NotNil(resp.ChangesThrough)
+		require.Contains(resp.SchemaChanges.ChangedDefinitionNames, "team")
+		require.Contains(resp.SchemaChanges.ChangedSchema, "definition team")
+		require.Empty(resp.SchemaChanges.DeletedDefinitionNames)
+		return
+	}
+}
@@ -0,0 +1,643 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"golang.org/x/sync/errgroup"
+
+	cexpr "github.com/authzed/spicedb/internal/caveats"
+	dispatchpkg "github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// maxExplainedFailures is the number of relationships kept under a relation or arrow in the
+// explanation of a permission which is not granted.
+const maxExplainedFailures = 10
+
+// maxExplainedRelationships is the number of relationships read for a relation or arrow on a
+// resource. A relation with more relationships is explained from the first of them, so its
+// explanation may not find a grant which the check found; the permissionship of the response
+// is always that of the check.
+const maxExplainedRelationships uint64 = 1000
+
+// permissionExplainer answers ExplainPermission calls. The permission is checked by dispatch,
+// exactly as CheckPermission does, and then explained by walking the schema and the
+// relationships at the same revision.
+type permissionExplainer struct {
+	dispatch dispatchpkg.Dispatcher
+
+	maxAPIDepth          uint32
+	maxCaveatContextSize int
+}
+
+func (pe *permissionExplainer) explainPermission(ctx context.Context, req *experimental.ExplainPermissionRequest) (*experimental.ExplainPermissionResponse, error) {
+	atRevision, checkedAt, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+
+	caveatContext, err := GetCaveatContext(ctx, req.Context, pe.maxCaveatContextSize)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := &core.ObjectAndRelation{
+		Namespace: req.Subject.Object.ObjectType,
+		ObjectId:  req.Subject.Object.ObjectId,
+		Relation:  normalizeSubjectRelation(req.Subject),
+	}
+
+	errG, checksCtx := errgroup.WithContext(ctx)
+	errG.Go(func() error {
+		return namespace.CheckNamespaceAndRelation(checksCtx, req.Resource.ObjectType, req.Permission, false, ds)
+	})
+	errG.Go(func() error {
+		return namespace.CheckNamespaceAndRelation(checksCtx, subject.Namespace, subject.Relation, true, ds)
+	})
+	if err := errG.Wait(); err != nil {
+		return nil, err
+	}
+
+	cr, metadata, err := computed.ComputeCheck(ctx, pe.dispatch,
+		computed.CheckParameters{
+			ResourceType: &core.RelationReference{
+				Namespace: req.Resource.ObjectType,
+				Relation:  req.Permission,
+			},
+			Subject:       subject,
+			CaveatContext: caveatContext,
+			AtRevision:    atRevision,
+			MaximumDepth:  pe.maxAPIDepth,
+			DebugOption:   computed.NoDebugging,
+		},
+		req.Resource.ObjectId,
+	)
+	usagemetrics.SetInContext(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+	switch cr.Membership {
+	case dispatch.ResourceCheckResult_MEMBER:
+		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	case dispatch.ResourceCheckResult_CAVEATED_MEMBER:
+		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION
+	}
+
+	eb := &explanationBuilder{
+		reader:        ds,
+		subject:       subject,
+		caveatContext: caveatContext,
+		maxDepth:      pe.maxAPIDepth,
+	}
+	explanation, err := eb.explainRelation(ctx, tuple.ObjectAndRelation(req.Resource.ObjectType, req.Resource.ObjectId, req.Permission), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var relationships []*v1.Relationship
+	if explanation.Result != experimental.PermissionExplanation_RESULT_NOT_GRANTED {
+		relationships = grantingRelationships(explanation, relationships)
+	}
+
+	var text strings.Builder
+	renderExplanation(&text, explanation, 0)
+
+	return &experimental.ExplainPermissionResponse{
+		CheckedAt:       checkedAt,
+		Permissionship:  permissionship,
+		Explanation:     explanation,
+		Relationships:   relationships,
+		ExplanationText: text.String(),
+	}, nil
+}
+
+// explanationBuilder builds the explanation of whether a single subject has a permission.
+type explanationBuilder struct {
+	reader        datastore.Reader
+	subject       *core.ObjectAndRelation
+	caveatContext map[string]any
+	maxDepth      uint32
+}
+
+func (eb *explanationBuilder) explainRelation(ctx context.Context, resource *core.ObjectAndRelation, depth uint32) (*experimental.PermissionExplanation, error) {
+	if depth >= eb.maxDepth {
+		return nil, dispatchpkg.ErrMaxDepth
+	}
+
+	_, relation, err := namespace.ReadNamespaceAndRelation(ctx, resource.Namespace, resource.Relation, eb.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if relation.UsersetRewrite == nil {
+		node, err := eb.explainDirect(ctx, resource, depth)
+		if err != nil {
+			return nil, err
+		}
+		node.SourcePosition = toSourcePosition(relation.SourcePosition)
+		return node, nil
+	}
+
+	rewrite, err := eb.explainRewrite(ctx, resource, relation.UsersetRewrite, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &experimental.PermissionExplanation{
+		Kind:           experimental.PermissionExplanation_KIND_PERMISSION,
+		Result:         rewrite.Result,
+		Resource:       objectReference(resource),
+		Relation:       resource.Relation,
+		SourcePosition: toSourcePosition(relation.SourcePosition),
+		Children:       []*experimental.PermissionExplanation{rewrite},
+	}, nil
+}
+
+func (eb *explanationBuilder) explainRewrite(ctx context.Context, resource *core.ObjectAndRelation, rewrite *core.UsersetRewrite, depth uint32) (*experimental.PermissionExplanation, error) {
+	node := &experimental.PermissionExplanation{
+		SourcePosition: toSourcePosition(rewrite.SourcePosition),
+	}
+
+	var setOperation *core.SetOperation
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		node.Kind = experimental.PermissionExplanation_KIND_UNION
+		setOperation = rw.Union
+	case *core.UsersetRewrite_Intersection:
+		node.Kind = experimental.PermissionExplanation_KIND_INTERSECTION
+		setOperation = rw.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		node.Kind = experimental.PermissionExplanation_KIND_EXCLUSION
+		setOperation = rw.Exclusion
+	default:
+		return nil, fmt.Errorf("unknown userset rewrite operation %T", rewrite.RewriteOperation)
+	}
+
+	// Children after the one which decides the result are not explained.
+	children := make([]*experimental.PermissionExplanation, 0, len(setOperation.Child))
+	for index, child := range setOperation.Child {
+		explained, err := eb.explainSetOperationChild(ctx, resource, child, depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, explained)
+
+		if decidesResult(node.Kind, index, explained.Result) {
+			break
+		}
+	}
+
+	switch node.Kind {
+	case experimental.PermissionExplanation_KIND_UNION:
+		node.Result = anyResult(children)
+		node.Children = pruneAny(children, node.Result, len(children))
+
+	case experimental.PermissionExplanation_KIND_INTERSECTION:
+		node.Result = allResult(children)
+		node.Children = children
+		if node.Result == experimental.PermissionExplanation_RESULT_NOT_GRANTED {
+			node.Children = withResult(children, experimental.PermissionExplanation_RESULT_NOT_GRANTED)
+		}
+
+	case experimental.PermissionExplanation_KIND_EXCLUSION:
+		results := make([]*experimental.PermissionExplanation, 0, len(children))
+		results = append(results, children[0])
+		for _, excluded := range children[1:] {
+			results = append(results, &experimental.PermissionExplanation{Result: negateResult(excluded.Result)})
+		}
+		node.Result = allResult(results)
+		node.Children = children
+	}
+
+	return node, nil
+}
+
+func (eb *explanationBuilder) explainSetOperationChild(ctx context.Context, resource *core.ObjectAndRelation, child *core.SetOperation_Child, depth uint32) (*experimental.PermissionExplanation, error) {
+	switch c := child.ChildType.(type) {
+	case *core.SetOperation_Child_XThis:
+		node, err := eb.explainDirect(ctx, resource, depth)
+		if err != nil {
+			return nil, err
+		}
+		node.SourcePosition = toSourcePosition(child.SourcePosition)
+		return node, nil
+
+	case *core.SetOperation_Child_ComputedUserset:
+		computedONR := tuple.ObjectAndRelation(resource.Namespace, resource.ObjectId, c.ComputedUserset.Relation)
+		return eb.explainRelation(ctx, computedONR, depth+1)
+
+	case *core.SetOperation_Child_TupleToUserset:
+		ttu := c.TupleToUserset
+		return eb.explainArrow(ctx, resource, ttu.Tupleset.Relation, ttu.ComputedUserset.Relation, ttu.SourcePosition, false, depth)
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		ttu := c.FunctionedTupleToUserset
+		switch ttu.Function {
+		case core.FunctionedTupleToUserset_FUNCTION_ANY:
+			return eb.explainArrow(ctx, resource, ttu.Tupleset.Relation, ttu.ComputedUserset.Relation, ttu.SourcePosition, false, depth)
+		case core.FunctionedTupleToUserset_FUNCTION_ALL:
+			return eb.explainArrow(ctx, resource, ttu.Tupleset.Relation, ttu.ComputedUserset.Relation, ttu.SourcePosition, true, depth)
+		default:
+			return nil, fmt.Errorf("unknown function %v for arrow", ttu.Function)
+		}
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		return eb.explainRewrite(ctx, resource, c.UsersetRewrite, depth)
+
+	case *core.SetOperation_Child_XNil:
+		return &experimental.PermissionExplanation{
+			Kind:           experimental.PermissionExplanation_KIND_NIL,
+			Result:         experimental.PermissionExplanation_RESULT_NOT_GRANTED,
+			SourcePosition: toSourcePosition(child.SourcePosition),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown set operation child %T", child.ChildType)
+	}
+}
+
+// explainDirect explains the relationships of the relation on the resource, up to the first
+// which grants it. Relationships whose subject neither is the subject being checked nor could
+// contain it are left out.
+func (eb *explanationBuilder) explainDirect(ctx context.Context, resource *core.ObjectAndRelation, depth uint32) (*experimental.PermissionExplanation, error) {
+	var children []*experimental.PermissionExplanation
+	err := eb.forEachRelationship(ctx, resource, resource.Relation, func(relationship *core.RelationTuple) (bool, error) {
+		subject := relationship.Subject
+		matchesSubject := eb.matchesSubject(subject)
+		if !matchesSubject && subject.Relation == tuple.Ellipsis {
+			return true, nil
+		}
+
+		node, err := eb.explainRelationship(ctx, relationship)
+		if err != nil {
+			return false, err
+		}
+
+		if !matchesSubject {
+			member, err := eb.explainRelation(ctx, subject, depth+1)
+			if err != nil {
+				return false, err
+			}
+			node.Result = allResult([]*experimental.PermissionExplanation{node, member})
+			node.Children = []*experimental.PermissionExplanation{member}
+		}
+
+		children = append(children, node)
+		return node.Result != experimental.PermissionExplanation_RESULT_GRANTED, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := anyResult(children)
+	return &experimental.PermissionExplanation{
+		Kind:     experimental.PermissionExplanation_KIND_RELATION,
+		Result:   result,
+		Resource: objectReference(resource),
+		Relation: resource.Relation,
+		Children: pruneAny(children, result, maxExplainedFailures),
+	}, nil
+}
+
+// explainArrow explains the relationships of the tupleset relation on the resource, checking
+// the computed relation on each of their subjects. If all is set, the computed relation must be
+// granted on every subject, as for the `.all()` arrow, rather than on any one of them. The
+// relationships are explained up to the first which decides the result of the arrow.
+func (eb *explanationBuilder) explainArrow(ctx context.Context, resource *core.ObjectAndRelation, tuplesetRelation string, computedRelation string, sourcePosition *core.SourcePosition, all bool, depth uint32) (*experimental.PermissionExplanation, error) {
+	var children []*experimental.PermissionExplanation
+	err := eb.forEachRelationship(ctx, resource, tuplesetRelation, func(relationship *core.RelationTuple) (bool, error) {
+		computedONR := tuple.ObjectAndRelation(relationship.Subject.Namespace, relationship.Subject.ObjectId, computedRelation)
+
+		// As in check, subjects whose type does not have the computed relation are skipped, or
+		// fail the arrow if it requires all of them.
+		hasRelation := true
+		err := namespace.CheckNamespaceAndRelation(ctx, computedONR.Namespace, computedONR.Relation, false, eb.reader)
+		if err != nil {
+			if !errors.As(err, &namespace.ErrRelationNotFound{}) {
+				return false, err
+			}
+			if !all {
+				return true, nil
+			}
+			hasRelation = false
+		}
+
+		node, err := eb.explainRelationship(ctx, relationship)
+		if err != nil {
+			return false, err
+		}
+
+		if hasRelation {
+			member, err := eb.explainRelation(ctx, computedONR, depth+1)
+			if err != nil {
+				return false, err
+			}
+			node.Result = allResult([]*experimental.PermissionExplanation{node, member})
+			node.Children = []*experimental.PermissionExplanation{member}
+		} else {
+			node.Result = experimental.PermissionExplanation_RESULT_NOT_GRANTED
+		}
+
+		children = append(children, node)
+		if all {
+			return node.Result != experimental.PermissionExplanation_RESULT_NOT_GRANTED, nil
+		}
+		return node.Result != experimental.PermissionExplanation_RESULT_GRANTED, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	node := &experimental.PermissionExplanation{
+		Kind:             experimental.PermissionExplanation_KIND_ARROW,
+		Resource:         objectReference(resource),
+		Relation:         tuplesetRelation,
+		ComputedRelation: computedRelation,
+		SourcePosition:   toSourcePosition(sourcePosition),
+	}
+
+	if !all {
+		node.Result = anyResult(children)
+		node.Children = pruneAny(children, node.Result, maxExplainedFailures)
+		return node, nil
+	}
+
+	node.Kind = experimental.PermissionExplanation_KIND_ALL_ARROW
+	node.Result = experimental.PermissionExplanation_RESULT_NOT_GRANTED
+	node.Children = children
+	if len(children) > 0 {
+		node.Result = allResult(children)
+	}
+	if node.Result == experimental.PermissionExplanation_RESULT_NOT_GRANTED {
+		node.Children = pruneAny(withResult(children, node.Result), node.Result, maxExplainedFailures)
+	}
+	return node, nil
+}
+
+// forEachRelationship calls fn with each of the first maxExplainedRelationships relationships of
+// the relation on the resource, until fn returns false or an error.
+func (eb *explanationBuilder) forEachRelationship(ctx context.Context, resource *core.ObjectAndRelation, relation string, fn func(*core.RelationTuple) (bool, error)) error {
+	limit := maxExplainedRelationships
+	it, err := eb.reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             resource.Namespace,
+		OptionalResourceIds:      []string{resource.ObjectId},
+		OptionalResourceRelation: relation,
+	}, options.WithLimit(&limit))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		more, err := fn(tpl)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return it.Err()
+}
+
+// explainRelationship returns the node for a relationship, with the result of its caveat.
+func (eb *explanationBuilder) explainRelationship(ctx context.Context, relationship *core.RelationTuple) (*experimental.PermissionExplanation, error) {
+	node := &experimental.PermissionExplanation{
+		Kind:         experimental.PermissionExplanation_KIND_RELATIONSHIP,
+		Result:       experimental.PermissionExplanation_RESULT_GRANTED,
+		Relationship: tuple.ToRelationship(relationship),
+	}
+	if relationship.Caveat == nil {
+		return node, nil
+	}
+
+	result, err := cexpr.RunCaveatExpression(ctx, cexpr.CaveatAsExpr(relationship.Caveat), eb.caveatContext, eb.reader, cexpr.RunCaveatExpressionWithDebugInformation)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, err := result.ExpressionString()
+	if err != nil {
+		return nil, err
+	}
+
+	node.Caveat = &experimental.CaveatExplanation{
+		CaveatName: relationship.Caveat.CaveatName,
+		Expression: expression,
+	}
+
+	switch {
+	case result.Value():
+		node.Caveat.Result = v1.CaveatEvalInfo_RESULT_TRUE
+	case result.IsPartial():
+		missing, _ := result.MissingVarNames()
+		node.Caveat.Result = v1.CaveatEvalInfo_RESULT_MISSING_SOME_CONTEXT
+		node.Caveat.MissingContext = missing
+		node.Result = experimental.PermissionExplanation_RESULT_CONDITIONAL
+	default:
+		node.Caveat.Result = v1.CaveatEvalInfo_RESULT_FALSE
+		node.Result = experimental.PermissionExplanation_RESULT_NOT_GRANTED
+	}
+
+	return node, nil
+}
+
+func (eb *explanationBuilder) matchesSubject(subject *core.ObjectAndRelation) bool {
+	if subject.Namespace != eb.subject.Namespace || subject.Relation != eb.subject.Relation {
+		return false
+	}
+	return subject.ObjectId == eb.subject.ObjectId || subject.ObjectId == tuple.PublicWildcard
+}
+
+func resultRank(result experimental.PermissionExplanation_Result) int {
+	switch result {
+	case experimental.PermissionExplanation_RESULT_GRANTED:
+		return 2
+	case experimental.PermissionExplanation_RESULT_CONDITIONAL:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// anyResult returns the best result of the nodes, as for a union.
+func anyResult(nodes []*experimental.PermissionExplanation) experimental.PermissionExplanation_Result {
+	result := experimental.PermissionExplanation_RESULT_NOT_GRANTED
+	for _, node := range nodes {
+		if resultRank(node.Result) > resultRank(result) {
+			result = node.Result
+		}
+	}
+	return result
+}
+
+// allResult returns the worst result of the nodes, as for an intersection.
+func allResult(nodes []*experimental.PermissionExplanation) experimental.PermissionExplanation_Result {
+	result := experimental.PermissionExplanation_RESULT_GRANTED
+	for _, node := range nodes {
+		if resultRank(node.Result) < resultRank(result) {
+			result = node.Result
+		}
+	}
+	return result
+}
+
+// decidesResult returns whether the result of the child at the index decides the result of a
+// set operation of the kind, whatever the results of the children after it.
+func decidesResult(kind experimental.PermissionExplanation_Kind, index int, result experimental.PermissionExplanation_Result) bool {
+	switch kind {
+	case experimental.PermissionExplanation_KIND_UNION:
+		return result == experimental.PermissionExplanation_RESULT_GRANTED
+	case experimental.PermissionExplanation_KIND_INTERSECTION:
+		return result == experimental.PermissionExplanation_RESULT_NOT_GRANTED
+	case experimental.PermissionExplanation_KIND_EXCLUSION:
+		if index == 0 {
+			return result == experimental.PermissionExplanation_RESULT_NOT_GRANTED
+		}
+		return result == experimental.PermissionExplanation_RESULT_GRANTED
+	default:
+		return false
+	}
+}
+
+func negateResult(result experimental.PermissionExplanation_Result) experimental.PermissionExplanation_Result {
+	switch result {
+	case experimental.PermissionExplanation_RESULT_GRANTED:
+		return experimental.PermissionExplanation_RESULT_NOT_GRANTED
+	case experimental.PermissionExplanation_RESULT_CONDITIONAL:
+		return experimental.PermissionExplanation_RESULT_CONDITIONAL
+	default:
+		return experimental.PermissionExplanation_RESULT_GRANTED
+	}
+}
+
+// pruneAny returns the nodes which explain the result of a union of the nodes: the first
+// granting node, all of the conditional nodes, or up to limit of the failing nodes.
+func pruneAny(nodes []*experimental.PermissionExplanation, result experimental.PermissionExplanation_Result, limit int) []*experimental.PermissionExplanation {
+	switch result {
+	case experimental.PermissionExplanation_RESULT_GRANTED:
+		return withResult(nodes, result)[:1]
+	case experimental.PermissionExplanation_RESULT_CONDITIONAL:
+		return withResult(nodes, result)
+	default:
+		if len(nodes) > limit {
+			return nodes[:limit]
+		}
+		return nodes
+	}
+}
+
+func withResult(nodes []*experimental.PermissionExplanation, result experimental.PermissionExplanation_Result) []*experimental.PermissionExplanation {
+	filtered := make([]*experimental.PermissionExplanation, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Result == result {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+// grantingRelationships appends the relationships of the nodes which grant the permission.
+func grantingRelationships(node *experimental.PermissionExplanation, relationships []*v1.Relationship) []*v1.Relationship {
+	if node.Relationship != nil {
+		relationships = append(relationships, node.Relationship)
+	}
+
+	for _, child := range node.Children {
+		if child.Result != experimental.PermissionExplanation_RESULT_NOT_GRANTED {
+			relationships = grantingRelationships(child, relationships)
+		}
+	}
+	return relationships
+}
+
+func objectReference(onr *core.ObjectAndRelation) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		ObjectType: onr.Namespace,
+		ObjectId:   onr.ObjectId,
+	}
+}
+
+func toSourcePosition(position *core.SourcePosition) *experimental.SourcePosition {
+	if position == nil {
+		return nil
+	}
+
+	return &experimental.SourcePosition{
+		Line:   position.ZeroIndexedLineNumber + 1,
+		Column: position.ZeroIndexedColumnPosition + 1,
+	}
+}
+
+var explanationResultText = map[experimental.PermissionExplanation_Result]string{
+	experimental.PermissionExplanation_RESULT_GRANTED:     "granted",
+	experimental.PermissionExplanation_RESULT_NOT_GRANTED: "not granted",
+	experimental.PermissionExplanation_RESULT_CONDITIONAL: "conditionally granted",
+}
+
+// renderExplanation writes the explanation as an indented tree, one node per line.
+func renderExplanation(sb *strings.Builder, node *experimental.PermissionExplanation, indent int) {
+	sb.WriteString(strings.Repeat("  ", indent))
+
+	switch node.Kind {
+	case experimental.PermissionExplanation_KIND_PERMISSION:
+		fmt.Fprintf(sb, "%s#%s (permission)", tuple.StringObjectRef(node.Resource), node.Relation)
+	case experimental.PermissionExplanation_KIND_RELATION:
+		fmt.Fprintf(sb, "%s#%s (relation)", tuple.StringObjectRef(node.Resource), node.Relation)
+	case experimental.PermissionExplanation_KIND_UNION:
+		sb.WriteString("union")
+	case experimental.PermissionExplanation_KIND_INTERSECTION:
+		sb.WriteString("intersection")
+	case experimental.PermissionExplanation_KIND_EXCLUSION:
+		sb.WriteString("exclusion")
+	case experimental.PermissionExplanation_KIND_ARROW:
+		fmt.Fprintf(sb, "%s#%s->%s", tuple.StringObjectRef(node.Resource), node.Relation, node.ComputedRelation)
+	case experimental.PermissionExplanation_KIND_ALL_ARROW:
+		fmt.Fprintf(sb, "%s#%s.all(%s)", tuple.StringObjectRef(node.Resource), node.Relation, node.ComputedRelation)
+	case experimental.PermissionExplanation_KIND_RELATIONSHIP:
+		sb.WriteString(tuple.StringRelationshipWithoutCaveat(node.Relationship))
+	case experimental.PermissionExplanation_KIND_NIL:
+		sb.WriteString("nil")
+	}
+
+	if node.SourcePosition != nil {
+		fmt.Fprintf(sb, " at line %d, column %d", node.SourcePosition.Line, node.SourcePosition.Column)
+	}
+
+	fmt.Fprintf(sb, ": %s", explanationResultText[node.Result])
+
+	if caveat := node.Caveat; caveat != nil {
+		switch caveat.Result {
+		case v1.CaveatEvalInfo_RESULT_MISSING_SOME_CONTEXT:
+			fmt.Fprintf(sb, " (caveat %s `%s` is missing context: %s)", caveat.CaveatName, caveat.Expression, strings.Join(caveat.MissingContext, ", "))
+		case v1.CaveatEvalInfo_RESULT_FALSE:
+			fmt.Fprintf(sb, " (caveat %s `%s` is false)", caveat.CaveatName, caveat.Expression)
+		default:
+			fmt.Fprintf(sb, " (caveat %s `%s` is true)", caveat.CaveatName, caveat.Expression)
+		}
+	}
+
+	sb.WriteString("\n")
+
+	for _, child := range node.Children {
+		renderExplanation(sb, child, indent+1)
+	}
+}
@@ -2,7 +2,10 @@ package v1
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 
@@ -25,10 +28,13 @@ import (
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
@@ -139,6 +145,17 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 	}, nil
 }
 
+// BulkCheckPermission checks each of the items of the request, batching the items which share a
+// resource type, permission, subject and caveat context into a single dispatch.
+func (ps *permissionServer) BulkCheckPermission(ctx context.Context, req *experimental.BulkCheckPermissionRequest) (*experimental.BulkCheckPermissionResponse, error) {
+	res, err := ps.bulkChecker.checkBulkPermissions(ctx, req)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return res, nil
+}
+
 func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
 	atRevision, expandedAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
@@ -329,6 +346,34 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		return rewriteError(ctx, err)
 	}
 
+	// The limit and the cursor are not (yet) part of the API request, so they are read from
+	// the request metadata.
+	limit, err := lookupResourcesLimit(ctx)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	requestHash, err := computeLookupResourcesRequestHash(req)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	// If a cursor was given, the lookup resumes at the revision at which it began.
+	var dispatchCursor *dispatch.Cursor
+	if encodedCursor, ok := requestMetadataValue(ctx, cursor.LookupResourcesCursor); ok {
+		decodedCursor, cursorRevision, err := cursor.DecodeToDispatchCursor(encodedCursor, requestHash, datastoremw.MustFromContext(ctx))
+		if err != nil {
+			return rewriteError(ctx, status.Errorf(codes.InvalidArgument, "%s", err))
+		}
+
+		dispatchCursor = decodedCursor
+		atRevision = cursorRevision
+		revisionReadAt, err = zedtoken.NewFromRevision(cursorRevision)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+	}
+
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
 
 	// Perform our preflight checks in parallel
@@ -355,30 +400,25 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		return rewriteError(ctx, err)
 	}
 
-	// TODO(jschorr): Change the internal dispatched lookup to also be streamed.
-	lookupResp, err := ps.dispatch.DispatchLookup(ctx, &dispatch.DispatchLookupRequest{
-		Metadata: &dispatch.ResolverMeta{
-			AtRevision:     atRevision.String(),
-			DepthRemaining: ps.config.MaximumAPIDepth,
-		},
-		ObjectRelation: &core.RelationReference{
-			Namespace: req.ResourceObjectType,
-			Relation:  req.Permission,
-		},
-		Subject: &core.ObjectAndRelation{
-			Namespace: req.Subject.Object.ObjectType,
-			ObjectId:  req.Subject.Object.ObjectId,
-			Relation:  normalizeSubjectRelation(req.Subject),
-		},
-		Context: req.Context,
-		Limit:   ^uint32(0), // Set no limit for now
-	})
-	usagemetrics.SetInContext(ctx, lookupResp.Metadata)
-	if err != nil {
-		return rewriteError(ctx, err)
+	respMetadata := &dispatch.ResponseMeta{
+		DispatchCount:       0,
+		CachedDispatchCount: 0,
+		DepthRequired:       0,
+		DebugInfo:           nil,
 	}
+	usagemetrics.SetInContext(ctx, respMetadata)
+
+	var currentCursor *dispatch.Cursor
+	sentCount := uint32(0)
+
+	stream := dispatchpkg.NewHandlingDispatchStream(ctx, func(result *dispatch.DispatchLookupResourcesResponse) error {
+		dispatchpkg.AddResponseMetadata(respMetadata, result.Metadata)
+
+		found := result.ResolvedResource
+		if found == nil {
+			return nil
+		}
 
-	for _, found := range lookupResp.ResolvedResources {
 		var partial *v1.PartialCaveatInfo
 		permissionship := v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION
 		if found.Permissionship == dispatch.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
@@ -397,10 +437,98 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		if err != nil {
 			return err
 		}
+
+		currentCursor = result.AfterResponseCursor
+		sentCount++
+		return nil
+	})
+
+	err = ps.dispatch.DispatchLookup(
+		&dispatch.DispatchLookupRequest{
+			Metadata: &dispatch.ResolverMeta{
+				AtRevision:     atRevision.String(),
+				DepthRemaining: ps.config.MaximumAPIDepth,
+			},
+			ObjectRelation: &core.RelationReference{
+				Namespace: req.ResourceObjectType,
+				Relation:  req.Permission,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: req.Subject.Object.ObjectType,
+				ObjectId:  req.Subject.Object.ObjectId,
+				Relation:  normalizeSubjectRelation(req.Subject),
+			},
+			Context:        req.Context,
+			OptionalLimit:  limit,
+			OptionalCursor: dispatchCursor,
+		},
+		stream)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	// If the limit was reached, return the cursor at which to resume in the trailer.
+	if limit > 0 && sentCount >= limit && currentCursor != nil {
+		encodedCursor, err := cursor.EncodeFromDispatchCursor(currentCursor, requestHash, atRevision)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+
+		resp.SetTrailer(metadata.Pairs(string(cursor.LookupResourcesAfterResultCursor), encodedCursor))
 	}
+
 	return nil
 }
 
+// lookupResourcesLimit returns the limit for a LookupResources call found in the request
+// metadata, if any, or zero for no limit.
+func lookupResourcesLimit(ctx context.Context) (uint32, error) {
+	value, ok := requestMetadataValue(ctx, cursor.LookupResourcesLimit)
+	if !ok {
+		return 0, nil
+	}
+
+	limit, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid value for `%s`: %s", cursor.LookupResourcesLimit, value)
+	}
+
+	return uint32(limit), nil
+}
+
+// requestMetadataValue returns the value for the given key in the request metadata, if any.
+func requestMetadataValue(ctx context.Context, key requestmeta.RequestMetadataHeaderKey) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := md.Get(string(key))
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// computeLookupResourcesRequestHash computes a hash of the parameters of the LookupResources
+// call, to ensure that a cursor is only used to resume the call that created it. The consistency
+// is excluded, as a cursor always resumes at the revision of the original call.
+func computeLookupResourcesRequestHash(req *v1.LookupResourcesRequest) (string, error) {
+	marshalled, err := proto.MarshalOptions{Deterministic: true}.Marshal(&v1.LookupResourcesRequest{
+		ResourceObjectType: req.ResourceObjectType,
+		Permission:         req.Permission,
+		Subject:            req.Subject,
+		Context:            req.Context,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hashed := sha256.Sum256(marshalled)
+	return "lr:" + hex.EncodeToString(hashed[:]), nil
+}
+
 func (ps *permissionServer) LookupSubjects(req *v1.LookupSubjectsRequest, resp v1.PermissionsService_LookupSubjectsServer) error {
 	ctx := resp.Context()
 	atRevision, revisionReadAt, err := consistency.RevisionFromContext(ctx)
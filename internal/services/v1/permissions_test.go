@@ -7,10 +7,12 @@ import (
 	"io"
 	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
 
 	"github.com/authzed/authzed-go/pkg/requestmeta"
@@ -1083,6 +1085,96 @@ func TestLookupResourcesWithCaveats(t *testing.T) {
 	require.Equal(t, v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION, responses[1].Permissionship)
 }
 
+func TestLookupResourcesWithLimitAndCursor(t *testing.T) {
+	for _, limit := range []int{1, 2, 5} {
+		limit := limit
+		t.Run(fmt.Sprintf("limit-%d", limit), func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+			client := v1.NewPermissionsServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			request := &v1.LookupResourcesRequest{
+				Consistency: &v1.Consistency{
+					Requirement: &v1.Consistency_AtLeastAsFresh{
+						AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+					},
+				},
+				ResourceObjectType: "document",
+				Permission:         "view",
+				Subject:            sub("user", "chief_financial_officer", ""),
+			}
+
+			found := map[string]struct{}{}
+			var currentCursor string
+			for i := 0; i < 10; i++ {
+				headers := map[requestmeta.RequestMetadataHeaderKey]string{
+					cursor.LookupResourcesLimit: strconv.Itoa(limit),
+				}
+				if currentCursor != "" {
+					headers[cursor.LookupResourcesCursor] = currentCursor
+				}
+				ctx := requestmeta.SetRequestHeaders(context.Background(), headers)
+
+				var trailer metadata.MD
+				cli, err := client.LookupResources(ctx, request, grpc.Trailer(&trailer))
+				require.NoError(err)
+
+				resultCount := 0
+				for {
+					res, err := cli.Recv()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					require.NoError(err)
+					found[res.ResourceObjectId] = struct{}{}
+					resultCount++
+				}
+				require.LessOrEqual(resultCount, limit)
+
+				nextCursor, err := responsemeta.GetResponseTrailerMetadataOrNil(trailer, cursor.LookupResourcesAfterResultCursor)
+				require.NoError(err)
+				if nextCursor == nil {
+					break
+				}
+
+				require.Equal(limit, resultCount)
+				currentCursor = *nextCursor
+			}
+
+			require.Equal(map[string]struct{}{"masterplan": {}, "healthplan": {}}, found)
+		})
+	}
+}
+
+func TestLookupResourcesWithInvalidCursor(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := requestmeta.SetRequestHeaders(context.Background(), map[requestmeta.RequestMetadataHeaderKey]string{
+		cursor.LookupResourcesLimit:  "1",
+		cursor.LookupResourcesCursor: "notavalidcursor",
+	})
+
+	cli, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+			},
+		},
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            sub("user", "chief_financial_officer", ""),
+	})
+	require.NoError(err)
+
+	_, err = cli.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 type byIDAndPermission []*v1.LookupResourcesResponse
 
 func (a byIDAndPermission) Len() int { return len(a) }
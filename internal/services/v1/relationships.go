@@ -10,6 +10,9 @@ import (
 	"github.com/jzelinskie/stringz"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/middleware"
@@ -24,6 +27,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore/pagination"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/util"
@@ -62,6 +66,10 @@ type PermissionsServerConfig struct {
 	// MaxDatastoreReadPageSize defines the maximum number of relationships loaded from the
 	// datastore in one query.
 	MaxDatastoreReadPageSize uint64
+
+	// MaxBulkCheckItems holds the maximum number of items allowed per
+	// BulkCheckPermission call.
+	MaxBulkCheckItems uint16
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -69,6 +77,10 @@ func NewPermissionsServer(
 	dispatch dispatch.Dispatcher,
 	config PermissionsServerConfig,
 ) v1.PermissionsServiceServer {
+	return newPermissionServer(dispatch, config)
+}
+
+func newPermissionServer(dispatch dispatch.Dispatcher, config PermissionsServerConfig) *permissionServer {
 	configWithDefaults := PermissionsServerConfig{
 		MaxPreconditionsCount:    defaultIfZero(config.MaxPreconditionsCount, 1000),
 		MaxUpdatesPerWrite:       defaultIfZero(config.MaxUpdatesPerWrite, 1000),
@@ -76,11 +88,19 @@ func NewPermissionsServer(
 		StreamingAPITimeout:      defaultIfZero(config.StreamingAPITimeout, 30*time.Second),
 		MaxCaveatContextSize:     config.MaxCaveatContextSize,
 		MaxDatastoreReadPageSize: defaultIfZero(config.MaxDatastoreReadPageSize, 1_000),
+		MaxBulkCheckItems:        defaultIfZero(config.MaxBulkCheckItems, 1000),
 	}
 
 	return &permissionServer{
 		dispatch: dispatch,
 		config:   configWithDefaults,
+		bulkChecker: &bulkChecker{
+			dispatch:             dispatch,
+			maxAPIDepth:          configWithDefaults.MaximumAPIDepth,
+			maxCaveatContextSize: configWithDefaults.MaxCaveatContextSize,
+			maxChecks:            configWithDefaults.MaxBulkCheckItems,
+			maxConcurrency:       defaultMaxBulkCheckConcurrency,
+		},
 		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
 			Unary: middleware.ChainUnaryServer(
 				grpcvalidate.UnaryServerInterceptor(true),
@@ -103,6 +123,8 @@ type permissionServer struct {
 
 	dispatch dispatch.Dispatcher
 	config   PermissionsServerConfig
+
+	bulkChecker *bulkChecker
 }
 
 func (ps *permissionServer) checkFilterComponent(ctx context.Context, objectType, optionalRelation string, ds datastore.Reader) error {
@@ -216,6 +238,10 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 
 	// Execute the write operation(s).
 	tupleUpdates := tuple.UpdateFromRelationshipUpdates(req.Updates)
+	if err := applyWriteExpiration(ctx, tupleUpdates); err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
 	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
 		// Validate the preconditions.
 		for _, precond := range req.OptionalPreconditions {
@@ -294,3 +320,35 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		DeletedAt: zedtoken.MustNewFromRevision(revision),
 	}, nil
 }
+
+// applyWriteExpiration sets the expiration times found in the request metadata, if any, on the
+// created or touched relationships. An expiration given for a single update takes precedence over
+// the expiration given for all updates.
+func applyWriteExpiration(ctx context.Context, updates []*core.RelationTupleUpdate) error {
+	if value, ok := requestMetadataValue(ctx, tuple.WriteRelationshipsExpiration); ok {
+		expiration, err := tuple.ParseExpiration(value)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid value for `%s`: %s", tuple.WriteRelationshipsExpiration, value)
+		}
+
+		for _, update := range updates {
+			if update.Operation != core.RelationTupleUpdate_DELETE {
+				update.Tuple.OptionalExpirationTime = expiration
+			}
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	expirations, err := tuple.ParseUpdateExpirations(md.Get(string(tuple.WriteRelationshipsUpdateExpiration)), len(updates))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid value for `%s`: %s", tuple.WriteRelationshipsUpdateExpiration, err)
+	}
+
+	for index, expiration := range expirations {
+		if updates[index].Operation == core.RelationTupleUpdate_DELETE {
+			return status.Errorf(codes.InvalidArgument, "invalid value for `%s`: update %d is a delete", tuple.WriteRelationshipsUpdateExpiration, index)
+		}
+		updates[index].Tuple.OptionalExpirationTime = expiration
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -19,7 +20,9 @@ import (
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
@@ -1122,6 +1125,61 @@ func TestWriteRelationshipsUpdatesOverLimit(t *testing.T) {
 	require.Contains(err.Error(), "update count of 2 is greater than maximum allowed of 1")
 }
 
+func TestWriteRelationshipsUpdateExpiration(t *testing.T) {
+	req := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true,
+		func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			return tf.DatastoreFromSchemaAndTestRelationships(ds, expirationSchema, nil, require)
+		})
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		string(tuple.WriteRelationshipsUpdateExpiration), "1="+expiresAt.Format(time.RFC3339))
+
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+				Relationship: rel("document", "doc1", "viewer", "user", "tom", ""),
+			},
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+				Relationship: rel("document", "doc2", "viewer", "user", "fred", ""),
+			},
+		},
+	})
+	req.NoError(err)
+
+	stream, err := experimental.NewExperimentalServiceClient(conn).ExportBulkRelationships(context.Background(), &experimental.ExportBulkRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	})
+	req.NoError(err)
+
+	page, err := stream.Recv()
+	req.NoError(err)
+	req.Len(page.Relationships, 2)
+	req.Len(page.Expirations, 1)
+
+	expired := page.Relationships[page.Expirations[0].RelationshipIndex]
+	req.Equal("document:doc2#viewer@user:fred", tuple.StringRelationshipWithoutCaveat(expired))
+	req.True(expiresAt.Equal(page.Expirations[0].ExpiresAt.AsTime()))
+
+	// An expiration cannot be given for a delete.
+	ctx = metadata.AppendToOutgoingContext(context.Background(),
+		string(tuple.WriteRelationshipsUpdateExpiration), "0="+expiresAt.Format(time.RFC3339))
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+				Relationship: rel("document", "doc1", "viewer", "user", "tom", ""),
+			},
+		},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func readAll(require *require.Assertions, client v1.PermissionsServiceClient, token *v1.ZedToken) map[string]struct{} {
 	got := make(map[string]struct{})
 	namespaces := []string{"document", "folder"}
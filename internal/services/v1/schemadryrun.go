@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/services/shared"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// defaultMaxSchemaIssueSamples is the number of sample relationships returned for each issue
+// found by DryRunWriteSchema when the request does not specify one.
+const defaultMaxSchemaIssueSamples = 10
+
+var schemaIssueTypes = map[shared.SchemaIssueType]experimental.SchemaValidationIssue_Type{
+	shared.RemovedDefinitionIssue:      experimental.SchemaValidationIssue_TYPE_REMOVED_DEFINITION,
+	shared.RemovedRelationIssue:        experimental.SchemaValidationIssue_TYPE_REMOVED_RELATION,
+	shared.RemovedAllowedTypeIssue:     experimental.SchemaValidationIssue_TYPE_REMOVED_ALLOWED_TYPE,
+	shared.RemovedCaveatIssue:          experimental.SchemaValidationIssue_TYPE_REMOVED_CAVEAT,
+	shared.ChangedCaveatParameterIssue: experimental.SchemaValidationIssue_TYPE_CHANGED_CAVEAT_PARAMETER,
+}
+
+func dryRunWriteSchema(ctx context.Context, req *experimental.DryRunWriteSchemaRequest) (*experimental.DryRunWriteSchemaResponse, error) {
+	ds := datastoremw.MustFromContext(ctx)
+
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: req.GetSchema(),
+	}, &emptyDefaultPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schema is always written at the head revision, so it is validated there too.
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSamples := req.OptionalMaxSamples
+	if maxSamples == 0 {
+		maxSamples = defaultMaxSchemaIssueSamples
+	}
+
+	report, err := shared.ValidateSchemaChangesAgainstData(ctx, ds.SnapshotReader(headRevision), validated, maxSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]*experimental.SchemaValidationIssue, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		samples := make([]*v1.Relationship, 0, len(issue.SampleRelationships))
+		for _, rel := range issue.SampleRelationships {
+			samples = append(samples, tuple.ToRelationship(rel))
+		}
+
+		issues = append(issues, &experimental.SchemaValidationIssue{
+			Type:                schemaIssueTypes[issue.Type],
+			Message:             issue.Message,
+			DefinitionName:      issue.DefinitionName,
+			RelationName:        issue.RelationName,
+			CaveatName:          issue.CaveatName,
+			ParameterName:       issue.ParameterName,
+			RelationshipCount:   issue.RelationshipCount,
+			SampleRelationships: samples,
+		})
+	}
+
+	return &experimental.DryRunWriteSchemaResponse{
+		ReadAt: zedtoken.MustNewFromRevision(headRevision),
+		Issues: issues,
+	}, nil
+}
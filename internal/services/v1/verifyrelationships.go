@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/services/shared"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+const (
+	// defaultMaxIntegrityIssueSamples is the number of sample relationships returned for each
+	// issue found by VerifyRelationships when the request does not specify one.
+	defaultMaxIntegrityIssueSamples = 10
+
+	// defaultRepairBatchSize is the number of relationships deleted in each transaction when
+	// VerifyRelationships repairs and the request does not specify a batch size.
+	defaultRepairBatchSize = 1000
+)
+
+var relationshipIssueCauses = map[shared.RelationshipIssueCause]experimental.RelationshipIntegrityIssue_Cause{
+	shared.UndefinedResourceTypeCause:    experimental.RelationshipIntegrityIssue_CAUSE_UNDEFINED_RESOURCE_TYPE,
+	shared.UndefinedRelationCause:        experimental.RelationshipIntegrityIssue_CAUSE_UNDEFINED_RELATION,
+	shared.PermissionRelationCause:       experimental.RelationshipIntegrityIssue_CAUSE_RELATION_IS_PERMISSION,
+	shared.UndefinedSubjectTypeCause:     experimental.RelationshipIntegrityIssue_CAUSE_UNDEFINED_SUBJECT_TYPE,
+	shared.UndefinedSubjectRelationCause: experimental.RelationshipIntegrityIssue_CAUSE_UNDEFINED_SUBJECT_RELATION,
+	shared.UndefinedCaveatCause:          experimental.RelationshipIntegrityIssue_CAUSE_UNDEFINED_CAVEAT,
+	shared.DisallowedSubjectCause:        experimental.RelationshipIntegrityIssue_CAUSE_DISALLOWED_SUBJECT,
+	shared.InvalidCaveatContextCause:     experimental.RelationshipIntegrityIssue_CAUSE_INVALID_CAVEAT_CONTEXT,
+}
+
+// verifyRelationships checks the relationships against the schema, deleting the invalid ones as
+// they are found when repairing. A call which quarantines deletes at most one batch of
+// relationships, so that every relationship it deletes can be returned; callers quarantine the
+// rest by calling again until no issues remain.
+func verifyRelationships(ctx context.Context, req *experimental.VerifyRelationshipsRequest) (*experimental.VerifyRelationshipsResponse, error) {
+	ds := datastoremw.MustFromContext(ctx)
+
+	// Relationships are checked against the current schema, so both are read at the head.
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSamples := req.OptionalMaxSamples
+	if maxSamples == 0 {
+		maxSamples = defaultMaxIntegrityIssueSamples
+	}
+
+	batchSize := int(req.OptionalBatchSize)
+	if batchSize == 0 {
+		batchSize = defaultRepairBatchSize
+	}
+
+	resp := &experimental.VerifyRelationshipsResponse{
+		ReadAt: zedtoken.MustNewFromRevision(headRevision),
+	}
+
+	opts := shared.IntegrityCheckOptions{
+		AdditionalResourceTypes: req.AdditionalResourceTypes,
+		MaxSamples:              maxSamples,
+	}
+
+	var deleter *shared.RelationshipBatchDeleter
+	if req.Repair != experimental.VerifyRelationshipsRequest_REPAIR_UNSPECIFIED {
+		quarantine := req.Repair == experimental.VerifyRelationshipsRequest_REPAIR_QUARANTINE
+
+		var beforeDelete func([]*core.RelationTuple) error
+		if quarantine {
+			resp.QuarantinedRelationships = make([]string, 0, batchSize)
+			beforeDelete = func(batch []*core.RelationTuple) error {
+				for _, rel := range batch {
+					resp.QuarantinedRelationships = append(resp.QuarantinedRelationships, tuple.MustString(rel))
+				}
+				return nil
+			}
+		}
+
+		deleter, err = shared.NewRelationshipBatchDeleter(ds, batchSize, beforeDelete)
+		if err != nil {
+			return nil, err
+		}
+
+		queued := 0
+		opts.OnInvalid = func(ctx context.Context, _ *shared.RelationshipIssueGroup, rel *core.RelationTuple) error {
+			if quarantine && queued >= batchSize {
+				return nil
+			}
+
+			queued++
+			return deleter.Add(ctx, rel)
+		}
+	}
+
+	report, err := shared.CheckRelationshipIntegrity(ctx, ds.SnapshotReader(headRevision), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.CheckedRelationshipCount = report.CheckedRelationships
+	resp.Issues = make([]*experimental.RelationshipIntegrityIssue, 0, len(report.Groups))
+	for _, group := range report.Groups {
+		samples := make([]*v1.Relationship, 0, len(group.SampleRelationships))
+		for _, rel := range group.SampleRelationships {
+			samples = append(samples, tuple.ToRelationship(rel))
+		}
+
+		resp.Issues = append(resp.Issues, &experimental.RelationshipIntegrityIssue{
+			Cause:               relationshipIssueCauses[group.Cause],
+			Message:             group.Message,
+			DefinitionName:      group.DefinitionName,
+			RelationName:        group.RelationName,
+			SubjectType:         group.SubjectType,
+			CaveatName:          group.CaveatName,
+			RelationshipCount:   group.RelationshipCount,
+			SampleRelationships: samples,
+		})
+	}
+
+	if deleter != nil {
+		if err := deleter.Flush(ctx); err != nil {
+			return nil, err
+		}
+		resp.RepairedRelationshipCount = deleter.Deleted()
+	}
+
+	return resp, nil
+}
@@ -1,11 +1,14 @@
 package v1
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
@@ -14,7 +17,11 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimental "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/watch"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -34,24 +41,55 @@ func NewWatchServer() v1.WatchServiceServer {
 }
 
 func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchServer) error {
-	ctx := stream.Context()
+	return watchChanges(stream.Context(), req.GetOptionalObjectTypes(), req.OptionalStartCursor, false,
+		func(updates []*v1.RelationshipUpdate, _ *experimental.WatchSchemaChanges, changesThrough *v1.ZedToken) error {
+			return stream.Send(&v1.WatchResponse{
+				Updates:        updates,
+				ChangesThrough: changesThrough,
+			})
+		})
+}
+
+// watchSender sends the changes made at a revision to the watch stream. The schema changes are
+// only set when they were requested and the revision changed the schema.
+type watchSender func(updates []*v1.RelationshipUpdate, schemaChanges *experimental.WatchSchemaChanges, changesThrough *v1.ZedToken) error
+
+// watchChanges watches for the changes made after the start cursor, or after the current revision
+// if none is given, sending those which match the object types and the relationship filters
+// found in the request metadata until the watch fails or is canceled.
+func watchChanges(ctx context.Context, optionalObjectTypes []string, optionalStartCursor *v1.ZedToken, includeSchemaChanges bool, send watchSender) error {
 	ds := datastoremw.MustFromContext(ctx)
 
 	objectTypesMap := make(map[string]struct{})
-	for _, objectType := range req.GetOptionalObjectTypes() {
+	for _, objectType := range optionalObjectTypes {
 		objectTypesMap[objectType] = struct{}{}
 	}
 
+	// The relationship filters and checkpoint interval are not (yet) part of the API request, so
+	// they are read from the request metadata.
+	filters, err := watchRelationshipFilters(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(filters) > 0 && len(objectTypesMap) > 0 {
+		return status.Errorf(codes.InvalidArgument, "cannot specify both object types and relationship filters")
+	}
+
+	checkpointInterval, err := watchCheckpointInterval(ctx)
+	if err != nil {
+		return err
+	}
+
 	var afterRevision datastore.Revision
-	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
-		decodedRevision, err := zedtoken.DecodeRevision(req.OptionalStartCursor, ds)
+	if optionalStartCursor != nil && optionalStartCursor.Token != "" {
+		decodedRevision, err := zedtoken.DecodeRevision(optionalStartCursor, ds)
 		if err != nil {
 			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
 		}
 
 		afterRevision = decodedRevision
 	} else {
-		var err error
 		afterRevision, err = ds.OptimizedRevision(ctx)
 		if err != nil {
 			return status.Errorf(codes.Unavailable, "failed to start watch: %s", err)
@@ -62,21 +100,51 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 		DispatchCount: 1,
 	})
 
+	// A checkpoint carries the last revision received from the datastore, whether or not any of
+	// its changes matched, so that quiet or heavily filtered streams can still be resumed without
+	// replaying the revisions already processed.
+	var checkpoints <-chan time.Time
+	if checkpointInterval > 0 {
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+		checkpoints = ticker.C
+	}
+
+	lastRevision := afterRevision
+	sentSinceCheckpoint := false
+
 	updates, errchan := ds.Watch(ctx, afterRevision)
 	for {
 		select {
 		case update, ok := <-updates:
 			if ok {
-				filtered := filterUpdates(objectTypesMap, update.Changes)
-				if len(filtered) > 0 {
-					if err := stream.Send(&v1.WatchResponse{
-						Updates:        filtered,
-						ChangesThrough: zedtoken.MustNewFromRevision(update.Revision),
-					}); err != nil {
+				lastRevision = update.Revision
+				filtered := filterUpdates(objectTypesMap, filters, update.Changes)
+
+				var schemaChanges *experimental.WatchSchemaChanges
+				if includeSchemaChanges {
+					schemaChanges, err = newWatchSchemaChanges(update)
+					if err != nil {
+						return status.Errorf(codes.Internal, "watch error: %s", err)
+					}
+				}
+
+				if len(filtered) > 0 || schemaChanges != nil {
+					if err := send(filtered, schemaChanges, zedtoken.MustNewFromRevision(update.Revision)); err != nil {
 						return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
 					}
+					sentSinceCheckpoint = true
 				}
 			}
+		case <-checkpoints:
+			if sentSinceCheckpoint {
+				sentSinceCheckpoint = false
+				continue
+			}
+
+			if err := send(nil, nil, zedtoken.MustNewFromRevision(lastRevision)); err != nil {
+				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+			}
 		case err := <-errchan:
 			switch {
 			case errors.As(err, &datastore.ErrWatchCanceled{}):
@@ -90,15 +158,25 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 	}
 }
 
-func filterUpdates(objectTypes map[string]struct{}, candidates []*core.RelationTupleUpdate) []*v1.RelationshipUpdate {
+func filterUpdates(objectTypes map[string]struct{}, filters []watch.RelationshipFilter, candidates []*core.RelationTupleUpdate) []*v1.RelationshipUpdate {
 	updates := tuple.UpdatesToRelationshipUpdates(candidates)
 
-	if len(objectTypes) == 0 {
+	if len(objectTypes) == 0 && len(filters) == 0 {
 		return updates
 	}
 
 	var filtered []*v1.RelationshipUpdate
 	for _, update := range updates {
+		if len(filters) > 0 {
+			for _, filter := range filters {
+				if filter.Matches(update.GetRelationship()) {
+					filtered = append(filtered, update)
+					break
+				}
+			}
+			continue
+		}
+
 		objectType := update.GetRelationship().GetResource().GetObjectType()
 
 		if _, ok := objectTypes[objectType]; ok {
@@ -108,3 +186,79 @@ func filterUpdates(objectTypes map[string]struct{}, candidates []*core.RelationT
 
 	return filtered
 }
+
+// watchRelationshipFilters returns the relationship filters found in the request metadata, if any.
+func watchRelationshipFilters(ctx context.Context) ([]watch.RelationshipFilter, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	values := md.Get(string(watch.RelationshipFilters))
+	filters := make([]watch.RelationshipFilter, 0, len(values))
+	for _, value := range values {
+		filter, err := watch.DecodeRelationshipFilter(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid value for `%s`: %s", watch.RelationshipFilters, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// watchCheckpointInterval returns the checkpoint interval found in the request metadata, if any,
+// or zero if checkpoints were not requested.
+func watchCheckpointInterval(ctx context.Context) (time.Duration, error) {
+	value, ok := requestMetadataValue(ctx, watch.CheckpointInterval)
+	if !ok {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid value for `%s`: %s", watch.CheckpointInterval, value)
+	}
+
+	if interval < watch.MinimumCheckpointInterval {
+		return 0, status.Errorf(codes.InvalidArgument, "`%s` must be at least %s", watch.CheckpointInterval, watch.MinimumCheckpointInterval)
+	}
+
+	return interval, nil
+}
+
+// newWatchSchemaChanges converts the schema changes found in the revision changes, returning
+// nil if the revision did not change the schema.
+func newWatchSchemaChanges(changes *datastore.RevisionChanges) (*experimental.WatchSchemaChanges, error) {
+	if !changes.HasSchemaChanges() {
+		return nil, nil
+	}
+
+	schemaChanges := &experimental.WatchSchemaChanges{
+		DeletedDefinitionNames: changes.DeletedNamespaces,
+		DeletedCaveatNames:     changes.DeletedCaveats,
+	}
+
+	definitions := make([]compiler.SchemaDefinition, 0, len(changes.ChangedDefinitions))
+	for _, def := range changes.ChangedDefinitions {
+		switch t := def.(type) {
+		case *core.NamespaceDefinition:
+			schemaChanges.ChangedDefinitionNames = append(schemaChanges.ChangedDefinitionNames, t.Name)
+			definitions = append(definitions, t)
+
+		case *core.CaveatDefinition:
+			schemaChanges.ChangedCaveatNames = append(schemaChanges.ChangedCaveatNames, t.Name)
+			definitions = append(definitions, t)
+		}
+	}
+
+	if len(definitions) > 0 {
+		schemaText, _, err := generator.GenerateSchema(definitions)
+		if err != nil {
+			return nil, err
+		}
+		schemaChanges.ChangedSchema = schemaText
+	}
+
+	return schemaChanges, nil
+}
@@ -14,12 +14,14 @@ import (
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/watch"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -51,12 +53,13 @@ func update(
 
 func TestWatch(t *testing.T) {
 	testCases := []struct {
-		name              string
-		objectTypesFilter []string
-		startCursor       *v1.ZedToken
-		mutations         []*v1.RelationshipUpdate
-		expectedCode      codes.Code
-		expectedUpdates   []*v1.RelationshipUpdate
+		name                string
+		objectTypesFilter   []string
+		relationshipFilters []string
+		startCursor         *v1.ZedToken
+		mutations           []*v1.RelationshipUpdate
+		expectedCode        codes.Code
+		expectedUpdates     []*v1.RelationshipUpdate
 	}{
 		{
 			name:         "unfiltered watch",
@@ -86,6 +89,35 @@ func TestWatch(t *testing.T) {
 				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "viewer", "user", "user1"),
 			},
 		},
+		{
+			name:         "watch with relationship filters",
+			expectedCode: codes.OK,
+			relationshipFilters: []string{
+				`{"resourceType":"document","optionalResourceIdPrefix":"doc-a"}`,
+				`{"optionalSubjectType":"user","optionalSubjectId":"auditor"}`,
+			},
+			mutations: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "doc-a1", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "doc-b1", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_DELETE, "folder", "auditors", "viewer", "user", "auditor"),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "doc-a1", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_DELETE, "folder", "auditors", "viewer", "user", "auditor"),
+			},
+		},
+		{
+			name:                "empty relationship filter",
+			relationshipFilters: []string{`{}`},
+			expectedCode:        codes.InvalidArgument,
+		},
+		{
+			name:                "object types and relationship filters",
+			objectTypesFilter:   []string{"document"},
+			relationshipFilters: []string{`{"resourceType":"document"}`},
+			expectedCode:        codes.InvalidArgument,
+		},
 		{
 			name:         "invalid zedtoken",
 			startCursor:  &v1.ZedToken{Token: "bad-token"},
@@ -115,6 +147,10 @@ func TestWatch(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			for _, filter := range tc.relationshipFilters {
+				ctx = metadata.AppendToOutgoingContext(ctx, string(watch.RelationshipFilters), filter)
+			}
+
 			stream, err := client.Watch(ctx, &v1.WatchRequest{
 				OptionalObjectTypes: tc.objectTypesFilter,
 				OptionalStartCursor: cursor,
@@ -175,6 +211,72 @@ func TestWatch(t *testing.T) {
 	}
 }
 
+func TestWatchCheckpoints(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter, err := watch.EncodeRelationshipFilter(watch.RelationshipFilter{ResourceType: "document"})
+	require.NoError(err)
+
+	ctx = metadata.AppendToOutgoingContext(ctx,
+		string(watch.RelationshipFilters), filter,
+		string(watch.CheckpointInterval), "1s",
+	)
+
+	stream, err := client.Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	// With no changes, a checkpoint is sent at the starting revision.
+	resp, err := stream.Recv()
+	require.NoError(err)
+	require.Empty(resp.Updates)
+	require.Equal(zedtoken.MustNewFromRevision(revision).Token, resp.ChangesThrough.Token)
+
+	// A change which does not match the filter moves the checkpoint forward.
+	written, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "user", "user1"),
+		},
+	})
+	require.NoError(err)
+
+	for {
+		resp, err := stream.Recv()
+		require.NoError(err)
+		require.Empty(resp.Updates)
+		if resp.ChangesThrough.Token == written.WrittenAt.Token {
+			break
+		}
+	}
+}
+
+func TestWatchInvalidCheckpointInterval(t *testing.T) {
+	for _, interval := range []string{"invalid", "10ms"} {
+		interval := interval
+		t.Run(interval, func(t *testing.T) {
+			require := require.New(t)
+
+			conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+			t.Cleanup(cleanup)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), string(watch.CheckpointInterval), interval)
+			stream, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{})
+			require.NoError(err)
+
+			_, err = stream.Recv()
+			grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+		})
+	}
+}
+
 func sortUpdates(in []*v1.RelationshipUpdate) []*v1.RelationshipUpdate {
 	out := make([]*v1.RelationshipUpdate, 0, len(in))
 	out = append(out, in...)
@@ -1,6 +1,13 @@
 package testfixtures
 
-import "math/rand"
+import (
+	"context"
+	"math/rand"
+	"strconv"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
 
 const (
 	FirstLetters      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890_"
@@ -18,3 +25,41 @@ func RandomObjectID(length uint8) string {
 	}
 	return string(b)
 }
+
+// NewBulkTupleGenerator returns a source of relationships for BulkLoad which produces the
+// given number of relationships, reusing the same tuple for every call to Next.
+func NewBulkTupleGenerator(objectType, relation, subjectType string, count int) *BulkTupleGenerator {
+	return &BulkTupleGenerator{
+		count,
+		core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{
+				Namespace: objectType,
+				Relation:  relation,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: subjectType,
+				Relation:  datastore.Ellipsis,
+			},
+		},
+	}
+}
+
+// BulkTupleGenerator is a datastore.BulkWriteRelationshipSource which generates relationships
+// with sequential object IDs.
+type BulkTupleGenerator struct {
+	remaining int
+	current   core.RelationTuple
+}
+
+func (btg *BulkTupleGenerator) Next(_ context.Context) (*core.RelationTuple, error) {
+	if btg.remaining <= 0 {
+		return nil, nil
+	}
+	btg.remaining--
+	btg.current.ResourceAndRelation.ObjectId = strconv.Itoa(btg.remaining)
+	btg.current.Subject.ObjectId = strconv.Itoa(btg.remaining)
+
+	return &btg.current, nil
+}
+
+var _ datastore.BulkWriteRelationshipSource = &BulkTupleGenerator{}
@@ -227,6 +227,26 @@ func (vrwt validatingReadWriteTransaction) DeleteCaveats(ctx context.Context, na
 	return vrwt.delegate.DeleteCaveats(ctx, names)
 }
 
+func (vrwt validatingReadWriteTransaction) BulkLoad(ctx context.Context, source datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return vrwt.delegate.BulkLoad(ctx, validatingBulkSource{source})
+}
+
+type validatingBulkSource struct {
+	delegate datastore.BulkWriteRelationshipSource
+}
+
+func (vbs validatingBulkSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	tpl, err := vbs.delegate.Next(ctx)
+	if err != nil || tpl == nil {
+		return tpl, err
+	}
+
+	if err := validateUpdatesToWrite(tuple.Create(tpl)); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
 // validateUpdatesToWrite performs basic validation on relationship updates going into datastores.
 func validateUpdatesToWrite(updates ...*core.RelationTupleUpdate) error {
 	for _, update := range updates {
@@ -103,7 +103,7 @@ func (r *crdbTester) NewDatabase(t testing.TB) string {
 func (r *crdbTester) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Datastore {
 	connectStr := r.NewDatabase(t)
 
-	migrationDriver, err := crdbmigrations.NewCRDBDriver(connectStr)
+	migrationDriver, err := crdbmigrations.NewCRDBDriver(connectStr, "")
 	require.NoError(t, err)
 	require.NoError(t, crdbmigrations.CRDBMigrations.Run(context.Background(), migrationDriver, migrate.Head, migrate.LiveRun))
 
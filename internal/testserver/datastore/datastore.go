@@ -67,6 +67,9 @@ func RunDatastoreEngineWithBridge(t testing.TB, engine string, bridgeNetworkName
 		return RunMySQLForTesting(t, bridgeNetworkName)
 	case "spanner":
 		return RunSpannerForTesting(t, bridgeNetworkName)
+	case "sqlite":
+		require.Equal(t, "", bridgeNetworkName, "sqlite datastore does not support bridge networking")
+		return RunSQLiteForTesting(t)
 	default:
 		t.Fatalf("found missing engine for RunDatastoreEngine: %s", engine)
 		return nil
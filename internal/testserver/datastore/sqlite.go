@@ -0,0 +1,39 @@
+//go:build docker
+// +build docker
+
+package datastore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/sqlite/migrations"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+type sqliteTest struct{}
+
+// RunSQLiteForTesting returns a RunningEngineForTest for the embedded SQLite driver, which keeps
+// each of its databases in a file of a temporary directory of the test.
+func RunSQLiteForTesting(_ testing.TB) RunningEngineForTest {
+	return &sqliteTest{}
+}
+
+func (b *sqliteTest) NewDatabase(t testing.TB) string {
+	return filepath.Join(t.TempDir(), "spicedb.db")
+}
+
+func (b *sqliteTest) NewDatastore(t testing.TB, initFunc InitFunc) datastore.Datastore {
+	path := b.NewDatabase(t)
+
+	migrationDriver, err := migrations.NewSQLiteDriver(path)
+	require.NoError(t, err)
+	require.NoError(t, migrations.DatabaseMigrations.Run(context.Background(), migrationDriver, migrate.Head, migrate.LiveRun))
+	require.NoError(t, migrationDriver.Close(context.Background()))
+
+	return initFunc("sqlite", path)
+}
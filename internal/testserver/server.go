@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/authzed/spicedb/internal/middleware/schemavalidation"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
 
 	"github.com/stretchr/testify/require"
@@ -23,6 +24,7 @@ import (
 type ServerConfig struct {
 	MaxUpdatesPerWrite    uint16
 	MaxPreconditionsCount uint16
+	MaxBulkCheckItems     uint16
 }
 
 // NewTestServer creates a new test server, using defaults for the config.
@@ -36,6 +38,7 @@ func NewTestServer(require *require.Assertions,
 		ServerConfig{
 			MaxUpdatesPerWrite:    1000,
 			MaxPreconditionsCount: 1000,
+			MaxBulkCheckItems:     1000,
 		},
 		dsInitFunc)
 }
@@ -58,6 +61,7 @@ func NewTestServerWithConfig(require *require.Assertions,
 		server.WithDispatchMaxDepth(50),
 		server.WithMaximumPreconditionCount(config.MaxPreconditionsCount),
 		server.WithMaximumUpdatesPerWrite(config.MaxUpdatesPerWrite),
+		server.WithMaxBulkCheckItems(config.MaxBulkCheckItems),
 		server.WithMaxCaveatContextSize(4096),
 		server.WithGRPCServer(util.GRPCServerConfig{
 			Network: util.BufferedNetwork,
@@ -71,33 +75,7 @@ func NewTestServerWithConfig(require *require.Assertions,
 		server.WithDashboardAPI(util.HTTPServerConfig{Enabled: false}),
 		server.WithMetricsAPI(util.HTTPServerConfig{Enabled: false}),
 		server.WithDispatchServer(util.GRPCServerConfig{Enabled: false}),
-		server.SetMiddlewareModification([]server.MiddlewareModification{
-			{
-				Operation: server.OperationReplaceAllUnsafe,
-				Middlewares: []server.ReferenceableMiddleware{
-					{
-						Name:                "logging",
-						UnaryMiddleware:     logging.UnaryServerInterceptor(),
-						StreamingMiddleware: logging.StreamServerInterceptor(),
-					},
-					{
-						Name:                "datastore",
-						UnaryMiddleware:     datastoremw.UnaryServerInterceptor(ds),
-						StreamingMiddleware: datastoremw.StreamServerInterceptor(ds),
-					},
-					{
-						Name:                "consistency",
-						UnaryMiddleware:     consistency.UnaryServerInterceptor(),
-						StreamingMiddleware: consistency.StreamServerInterceptor(),
-					},
-					{
-						Name:                "servicespecific",
-						UnaryMiddleware:     servicespecific.UnaryServerInterceptor,
-						StreamingMiddleware: servicespecific.StreamServerInterceptor,
-					},
-				},
-			},
-		}),
+		server.SetMiddlewareModification(Middleware(ds)),
 	).Complete(ctx)
 	require.NoError(err)
 
@@ -115,3 +93,40 @@ func NewTestServerWithConfig(require *require.Assertions,
 		cancel()
 	}, ds, revision
 }
+
+// Middleware returns the middleware of test servers backed by the datastore. Unlike the default
+// middleware of the server, it does not authenticate requests, dispatch or record metrics.
+func Middleware(ds datastore.Datastore) []server.MiddlewareModification {
+	return []server.MiddlewareModification{
+		{
+			Operation: server.OperationReplaceAllUnsafe,
+			Middlewares: []server.ReferenceableMiddleware{
+				{
+					Name:                "logging",
+					UnaryMiddleware:     logging.UnaryServerInterceptor(),
+					StreamingMiddleware: logging.StreamServerInterceptor(),
+				},
+				{
+					Name:                "datastore",
+					UnaryMiddleware:     datastoremw.UnaryServerInterceptor(ds),
+					StreamingMiddleware: datastoremw.StreamServerInterceptor(ds),
+				},
+				{
+					Name:                "consistency",
+					UnaryMiddleware:     consistency.UnaryServerInterceptor(),
+					StreamingMiddleware: consistency.StreamServerInterceptor(),
+				},
+				{
+					Name:                "schemavalidation",
+					UnaryMiddleware:     schemavalidation.UnaryServerInterceptor(true),
+					StreamingMiddleware: schemavalidation.StreamServerInterceptor(true),
+				},
+				{
+					Name:                "servicespecific",
+					UnaryMiddleware:     servicespecific.UnaryServerInterceptor,
+					StreamingMiddleware: servicespecific.StreamServerInterceptor,
+				},
+			},
+		},
+	}
+}
@@ -2,16 +2,25 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/copier"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	dspkg "github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func RegisterDatastoreRootFlags(_ *cobra.Command) {
@@ -36,6 +45,24 @@ func NewDatastoreCommand(_ string) (*cobra.Command, error) {
 	}
 	datastoreCmd.AddCommand(gcCmd)
 
+	sourceCfg := datastore.Config{}
+	destinationCfg := datastore.Config{}
+
+	copyCmd := NewCopyDatastoreCommand(datastoreCmd.Use, &sourceCfg, &destinationCfg)
+	if err := RegisterCopyDatastoreFlags(copyCmd, &sourceCfg, &destinationCfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(copyCmd)
+
+	verifyCfg := datastore.Config{}
+	verifyOpts := verifyDatastoreConfig{}
+
+	verifyCmd := NewVerifyDatastoreCommand(datastoreCmd.Use, &verifyCfg, &verifyOpts)
+	if err := RegisterVerifyDatastoreFlags(verifyCmd, &verifyCfg, &verifyOpts); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(verifyCmd)
+
 	return datastoreCmd, nil
 }
 
@@ -80,3 +107,321 @@ func NewGCDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Com
 		},
 	}
 }
+
+// copyProgressInterval is the minimum amount of time between the progress messages of a copy.
+const copyProgressInterval = 5 * time.Second
+
+func RegisterCopyDatastoreFlags(cmd *cobra.Command, sourceCfg, destinationCfg *datastore.Config) error {
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(cmd.Flags(), "source", sourceCfg); err != nil {
+		return err
+	}
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(cmd.Flags(), "destination", destinationCfg); err != nil {
+		return err
+	}
+
+	cmd.Flags().Uint64("batch-size", 1000, "number of relationships written to the destination datastore in each transaction")
+	cmd.Flags().String("checkpoint-file", "", "file in which to record the progress of the copy, from which an interrupted copy is resumed")
+	cmd.Flags().Bool("dry-run", false, "read everything that would be copied from the source datastore, without writing to the destination datastore")
+	return nil
+}
+
+func NewCopyDatastoreCommand(programName string, sourceCfg, destinationCfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "copy",
+		Short: "copies data between datastores",
+		Long: "Copies the schema and relationships of the source datastore, as of a single revision, into the destination datastore.\n" +
+			"The destination datastore must be migrated and empty, unless the copy is resumed from a checkpoint file.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			batchSize := cobrautil.MustGetUint64(cmd, "batch-size")
+			checkpointFile := cobrautil.MustGetStringExpanded(cmd, "checkpoint-file")
+			dryRun := cobrautil.MustGetBool(cmd, "dry-run")
+
+			// Disable background GC and hedging, and never write to the source. Metrics are
+			// disabled as both datastores would register the same collectors when they use the
+			// same engine.
+			for _, cfg := range []*datastore.Config{sourceCfg, destinationCfg} {
+				cfg.GCInterval = -1 * time.Hour
+				cfg.RequestHedgingEnabled = false
+				cfg.EnableDatastoreMetrics = false
+			}
+			sourceCfg.ReadOnly = true
+
+			source, err := datastore.NewDatastore(ctx, sourceCfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create source datastore: %w", err)
+			}
+			defer source.Close()
+
+			destination, err := datastore.NewDatastore(ctx, destinationCfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create destination datastore: %w", err)
+			}
+			defer destination.Close()
+
+			checkpoint, err := loadCopyCheckpoint(checkpointFile)
+			if err != nil {
+				return err
+			}
+			if checkpoint != nil {
+				log.Ctx(ctx).Info().
+					Str("revision", checkpoint.Revision).
+					Uint64("copied", checkpoint.CopiedRelationships).
+					Msg("resuming copy from checkpoint")
+			}
+
+			var lastProgress time.Time
+			result, err := copier.Copy(ctx, source, destination, copier.Options{
+				BatchSize:  batchSize,
+				DryRun:     dryRun,
+				Checkpoint: checkpoint,
+				SaveCheckpoint: func(checkpoint copier.Checkpoint) error {
+					return saveCopyCheckpoint(checkpointFile, checkpoint)
+				},
+				OnProgress: func(progress copier.Progress) {
+					if time.Since(lastProgress) < copyProgressInterval {
+						return
+					}
+					lastProgress = time.Now()
+					log.Ctx(ctx).Info().
+						Str("namespace", progress.Namespace).
+						Uint64("copied", progress.CopiedRelationships).
+						Msg("copying relationships")
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				log.Ctx(ctx).Info().
+					Stringer("revision", result.Revision).
+					Int("namespaces", result.Namespaces).
+					Int("caveats", result.Caveats).
+					Uint64("relationships", result.Relationships).
+					Msg("dry run completed, nothing was written to the destination datastore")
+				return nil
+			}
+
+			log.Ctx(ctx).Info().Msg("verifying the destination datastore...")
+			if err := copier.Verify(ctx, source, result.Revision, destination); err != nil {
+				return err
+			}
+
+			if checkpointFile != "" {
+				if err := os.Remove(checkpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("unable to remove checkpoint file: %w", err)
+				}
+			}
+
+			log.Ctx(ctx).Info().
+				Stringer("revision", result.Revision).
+				Int("namespaces", result.Namespaces).
+				Int("caveats", result.Caveats).
+				Uint64("relationships", result.Relationships).
+				Msg("copy completed")
+			return nil
+		},
+	}
+}
+
+// loadCopyCheckpoint reads the checkpoint of a previous copy, if there is one.
+func loadCopyCheckpoint(path string) (*copier.Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint file: %w", err)
+	}
+
+	var checkpoint copier.Checkpoint
+	if err := json.Unmarshal(contents, &checkpoint); err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint file: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// saveCopyCheckpoint replaces the checkpoint file, such that an interruption leaves either the
+// previous or the new checkpoint in place.
+func saveCopyCheckpoint(path string, checkpoint copier.Checkpoint) error {
+	if path == "" {
+		return nil
+	}
+
+	contents, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path+".tmp", contents, 0o600); err != nil {
+		return fmt.Errorf("unable to write checkpoint file: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("unable to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+const (
+	verifyRepairNone       = "none"
+	verifyRepairDelete     = "delete"
+	verifyRepairQuarantine = "quarantine"
+)
+
+// verifyMaxSamples is the number of invalid relationships printed for each issue found by the
+// verify command.
+const verifyMaxSamples = 10
+
+type verifyDatastoreConfig struct {
+	additionalResourceTypes []string
+	repair                  string
+	quarantineFile          string
+	batchSize               int
+}
+
+func RegisterVerifyDatastoreFlags(cmd *cobra.Command, cfg *datastore.Config, verifyCfg *verifyDatastoreConfig) error {
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(cmd.Flags(), "", cfg); err != nil {
+		return err
+	}
+
+	cmd.Flags().StringSliceVar(&verifyCfg.additionalResourceTypes, "additional-resource-type", nil, "resource type no longer defined by the schema whose relationships should also be checked")
+	cmd.Flags().StringVar(&verifyCfg.repair, "repair", verifyRepairNone, `what to do with invalid relationships: "none", "delete", or "quarantine" to write them to the quarantine file before deleting them`)
+	cmd.Flags().StringVar(&verifyCfg.quarantineFile, "quarantine-file", "", "file to which quarantined relationships are written, one per line")
+	cmd.Flags().IntVar(&verifyCfg.batchSize, "batch-size", 1000, "number of invalid relationships deleted in each transaction")
+	return nil
+}
+
+func NewVerifyDatastoreCommand(programName string, cfg *datastore.Config, verifyCfg *verifyDatastoreConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "verifies relationships against the schema",
+		Long: "Checks every relationship in the datastore against the schema, reporting those which do not match it grouped by cause.\n" +
+			"Invalid relationships can optionally be deleted, or written to a quarantine file and then deleted.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			switch verifyCfg.repair {
+			case verifyRepairNone, verifyRepairDelete:
+			case verifyRepairQuarantine:
+				if verifyCfg.quarantineFile == "" {
+					return errors.New("--quarantine-file is required to quarantine relationships")
+				}
+			default:
+				return fmt.Errorf("unknown repair mode %q", verifyCfg.repair)
+			}
+
+			// Disable background GC and hedging, and only write when repairing.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+			if verifyCfg.repair == verifyRepairNone {
+				cfg.ReadOnly = true
+			}
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+			defer ds.Close()
+
+			headRevision, err := ds.HeadRevision(ctx)
+			if err != nil {
+				return err
+			}
+
+			opts := shared.IntegrityCheckOptions{
+				AdditionalResourceTypes: verifyCfg.additionalResourceTypes,
+				MaxSamples:              verifyMaxSamples,
+			}
+
+			// Invalid relationships are deleted as they are found, after being written to the
+			// quarantine file if requested, so that they are never all held in memory.
+			var deleter *shared.RelationshipBatchDeleter
+			if verifyCfg.repair != verifyRepairNone {
+				var beforeDelete func([]*core.RelationTuple) error
+				if verifyCfg.repair == verifyRepairQuarantine {
+					beforeDelete = func(batch []*core.RelationTuple) error {
+						return writeQuarantineFile(verifyCfg.quarantineFile, batch)
+					}
+				}
+
+				deleter, err = shared.NewRelationshipBatchDeleter(ds, verifyCfg.batchSize, beforeDelete)
+				if err != nil {
+					return err
+				}
+
+				opts.OnInvalid = func(ctx context.Context, _ *shared.RelationshipIssueGroup, rel *core.RelationTuple) error {
+					return deleter.Add(ctx, rel)
+				}
+			}
+
+			report, err := shared.CheckRelationshipIntegrity(ctx, ds.SnapshotReader(headRevision), opts)
+			if err == nil && deleter != nil {
+				err = deleter.Flush(ctx)
+			}
+			if err != nil {
+				if deleter != nil {
+					return fmt.Errorf("deleted %d invalid relationships: %w", deleter.Deleted(), err)
+				}
+				return err
+			}
+
+			if report.IsValid() {
+				fmt.Printf("all %d relationships are valid\n", report.CheckedRelationships)
+				return nil
+			}
+
+			for _, group := range report.Groups {
+				fmt.Printf("%s: %s (%d relationships)\n", group.Cause, group.Message, group.RelationshipCount)
+				for _, rel := range group.SampleRelationships {
+					fmt.Printf("\t%s\n", tuple.MustString(rel))
+				}
+				if remaining := group.RelationshipCount - uint64(len(group.SampleRelationships)); remaining > 0 {
+					fmt.Printf("\t... and %d more\n", remaining)
+				}
+			}
+
+			if deleter == nil {
+				return fmt.Errorf("%d of %d relationships are invalid", report.InvalidRelationshipCount(), report.CheckedRelationships)
+			}
+
+			log.Ctx(ctx).Info().
+				Uint64("checked", report.CheckedRelationships).
+				Uint64("deleted", deleter.Deleted()).
+				Str("repair", verifyCfg.repair).
+				Msg("invalid relationships repaired")
+			return nil
+		},
+	}
+}
+
+// writeQuarantineFile writes the relationships to the file, one per line, before they are
+// deleted. The file is synced so that no relationship is deleted without having been kept.
+func writeQuarantineFile(path string, rels []*core.RelationTuple) error {
+	var sb strings.Builder
+	for _, rel := range rels {
+		sb.WriteString(tuple.MustString(rel))
+		sb.WriteString("\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open quarantine file: %w", err)
+	}
+
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write quarantine file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write quarantine file: %w", err)
+	}
+	return f.Close()
+}
@@ -42,9 +42,14 @@ func (c *Config) ToOption() ConfigOption {
 		to.OverlapStrategy = c.OverlapStrategy
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
+		to.ReadReplicaURIs = c.ReadReplicaURIs
+		to.WatchMode = c.WatchMode
+		to.PostgresSchema = c.PostgresSchema
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
 		to.SpannerEmulatorHost = c.SpannerEmulatorHost
 		to.TablePrefix = c.TablePrefix
+		to.MemorySnapshotPath = c.MemorySnapshotPath
+		to.MemorySnapshotInterval = c.MemorySnapshotInterval
 		to.WatchBufferLength = c.WatchBufferLength
 		to.MigrationPhase = c.MigrationPhase
 	}
@@ -247,6 +252,34 @@ func WithGCMaxOperationTime(gCMaxOperationTime time.Duration) ConfigOption {
 	}
 }
 
+// WithReadReplicaURIs returns an option that can append ReadReplicaURIss to Config.ReadReplicaURIs
+func WithReadReplicaURIs(readReplicaURIs string) ConfigOption {
+	return func(c *Config) {
+		c.ReadReplicaURIs = append(c.ReadReplicaURIs, readReplicaURIs)
+	}
+}
+
+// SetReadReplicaURIs returns an option that can set ReadReplicaURIs on a Config
+func SetReadReplicaURIs(readReplicaURIs []string) ConfigOption {
+	return func(c *Config) {
+		c.ReadReplicaURIs = readReplicaURIs
+	}
+}
+
+// WithWatchMode returns an option that can set WatchMode on a Config
+func WithWatchMode(watchMode string) ConfigOption {
+	return func(c *Config) {
+		c.WatchMode = watchMode
+	}
+}
+
+// WithPostgresSchema returns an option that can set PostgresSchema on a Config
+func WithPostgresSchema(postgresSchema string) ConfigOption {
+	return func(c *Config) {
+		c.PostgresSchema = postgresSchema
+	}
+}
+
 // WithSpannerCredentialsFile returns an option that can set SpannerCredentialsFile on a Config
 func WithSpannerCredentialsFile(spannerCredentialsFile string) ConfigOption {
 	return func(c *Config) {
@@ -268,6 +301,20 @@ func WithTablePrefix(tablePrefix string) ConfigOption {
 	}
 }
 
+// WithMemorySnapshotPath returns an option that can set MemorySnapshotPath on a Config
+func WithMemorySnapshotPath(memorySnapshotPath string) ConfigOption {
+	return func(c *Config) {
+		c.MemorySnapshotPath = memorySnapshotPath
+	}
+}
+
+// WithMemorySnapshotInterval returns an option that can set MemorySnapshotInterval on a Config
+func WithMemorySnapshotInterval(memorySnapshotInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MemorySnapshotInterval = memorySnapshotInterval
+	}
+}
+
 // WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
 func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	return func(c *Config) {
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/lsp"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+)
+
+func RegisterLSPFlags(cmd *cobra.Command) {}
+
+func NewLSPCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "lsp",
+		Short:   "runs a language server for schemas",
+		Long:    "Runs a language server for schema files over stdio, providing diagnostics, go-to-definition, hover, completion and formatting to editors",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    lspRun,
+		Args:    cobra.ExactArgs(0),
+	}
+}
+
+func lspRun(cmd *cobra.Command, _ []string) error {
+	// The protocol is spoken over stdout, so logs are written to stderr.
+	log.SetGlobalLogger(log.Logger.Output(os.Stderr))
+
+	return lsp.NewServer().Run(cmd.Context(), os.Stdin, os.Stdout)
+}
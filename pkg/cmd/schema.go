@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func RegisterSchemaRootFlags(_ *cobra.Command) {
+}
+
+func NewSchemaCommand(_ string) (*cobra.Command, error) {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "schema operations",
+		Long:  "Operations on schema against the configured datastore",
+	}
+
+	cfg := datastore.Config{}
+
+	validateCfg := validateSchemaConfig{}
+
+	validateCmd := NewValidateSchemaCommand(schemaCmd.Use, &cfg, &validateCfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(validateCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	validateCmd.Flags().Uint32Var(&validateCfg.maxSamples, "max-samples", 10, "maximum number of invalidated relationships to print for each issue")
+	validateCmd.Flags().BoolVar(&validateCfg.additiveOnly, "additive-only", false, "validate as if the schema service is running in additive-only mode")
+	schemaCmd.AddCommand(validateCmd)
+
+	return schemaCmd, nil
+}
+
+type validateSchemaConfig struct {
+	maxSamples   uint32
+	additiveOnly bool
+}
+
+func NewValidateSchemaCommand(programName string, cfg *datastore.Config, validateCfg *validateSchemaConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:     "validate <schema file>",
+		Short:   "validates a schema against the datastore",
+		Long:    "Validates that a schema could be written to the datastore, reporting every change which would invalidate existing relationships",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			schemaBytes, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read schema file: %w", err)
+			}
+
+			emptyDefaultPrefix := ""
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source(args[0]),
+				SchemaString: string(schemaBytes),
+			}, &emptyDefaultPrefix, compiler.WithImportResolver(compiler.FilesystemImportResolver{}))
+			if err != nil {
+				return err
+			}
+
+			validated, err := shared.ValidateSchemaChanges(ctx, compiled, validateCfg.additiveOnly)
+			if err != nil {
+				return err
+			}
+
+			// Disable background GC and hedging.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+			defer ds.Close()
+
+			headRevision, err := ds.HeadRevision(ctx)
+			if err != nil {
+				return err
+			}
+
+			report, err := shared.ValidateSchemaChangesAgainstData(ctx, ds.SnapshotReader(headRevision), validated, validateCfg.maxSamples)
+			if err != nil {
+				return err
+			}
+
+			if report.IsValid() {
+				fmt.Println("schema is valid")
+				return nil
+			}
+
+			for _, issue := range report.Issues {
+				fmt.Printf("%s: %s (%d relationships)\n", issue.Type, issue.Message, issue.RelationshipCount)
+				for _, rel := range issue.SampleRelationships {
+					fmt.Printf("\t%s\n", tuple.MustString(rel))
+				}
+			}
+
+			return errors.New("schema cannot be written")
+		},
+	}
+}
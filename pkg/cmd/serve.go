@@ -68,10 +68,17 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
 	}
 	server.RegisterCacheFlags(cmd.Flags(), "ns-cache", &config.NamespaceCacheConfig, namespaceCacheDefaults)
+	cmd.Flags().BoolVar(&config.NamespaceCacheWatchEnabled, "ns-cache-watch-enabled", true, "watch the datastore for schema changes, sharing cached definitions across revisions until the schema is changed by any node")
 
 	// Flags for parsing and validating schemas.
 	cmd.Flags().BoolVar(&config.SchemaPrefixesRequired, "schema-prefixes-required", false, "require prefixes on all object definitions in schemas")
 
+	// Flags for session consistency
+	cmd.Flags().BoolVar(&config.SessionConsistencyEnabled, "session-consistency-enabled", false, "read at least as fresh as the latest write of the session for requests carrying a session identifier, chosen by the client, in their `io.spicedb.session` metadata")
+	cmd.Flags().IntVar(&config.SessionConsistencyMaxSessions, "session-consistency-max-sessions", 100_000, "maximum number of sessions tracked by each node, forgetting the least recently written beyond it; 0 for no limit")
+	cmd.Flags().DurationVar(&config.SessionConsistencyTTL, "session-consistency-ttl", 10*time.Minute, "amount of time without writes after which a session is forgotten; 0 to keep sessions until evicted by the maximum")
+	cmd.Flags().BoolVar(&config.SessionConsistencyPeerPropagationEnabled, "session-consistency-peer-propagation-enabled", false, "share sessions with the other nodes through the dispatch ring, so that reads through any node observe the writes of the session made through other nodes within a second (requires --dispatch-upstream-addr)")
+
 	// Flags for HTTP gateway
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.HTTPGateway, "http", "gateway", ":8443", false)
 	cmd.Flags().StringVar(&config.HTTPGatewayUpstreamAddr, "http-upstream-override-addr", "", "Override the upstream to point to a different gRPC server")
@@ -115,8 +122,10 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	// Flags for configuring API behavior
 	cmd.Flags().BoolVar(&config.DisableV1SchemaAPI, "disable-v1-schema-api", false, "disables the V1 schema API")
 	cmd.Flags().BoolVar(&config.DisableVersionResponse, "disable-version-response", false, "disables version response support in the API")
+	cmd.Flags().BoolVar(&config.DisableSchemaValidation, "disable-schema-validation", false, "disables validating the schema references of API requests before they reach the services")
 	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
 	cmd.Flags().Uint16Var(&config.MaximumPreconditionCount, "update-relationships-max-preconditions-per-call", 1000, "maximum number of preconditions allowed for WriteRelationships and DeleteRelationships calls")
+	cmd.Flags().Uint16Var(&config.MaxBulkCheckItems, "bulk-check-permission-max-items-per-call", 1000, "maximum number of items allowed for BulkCheckPermission calls")
 	cmd.Flags().IntVar(&config.MaxCaveatContextSize, "max-caveat-context-size", 4096, "maximum allowed size of request caveat context in bytes. A value of zero or less means no limit")
 
 	cmd.Flags().BoolVar(&config.V1SchemaAdditiveOnly, "testing-only-schema-additive-writes", false, "append new definitions to the existing schema, rather than overwriting it")
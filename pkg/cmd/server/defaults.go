@@ -26,7 +26,9 @@ import (
 	consistencymw "github.com/authzed/spicedb/internal/middleware/consistency"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	dispatchmw "github.com/authzed/spicedb/internal/middleware/dispatcher"
+	"github.com/authzed/spicedb/internal/middleware/schemavalidation"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/session"
 	"github.com/authzed/spicedb/pkg/datastore"
 	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
@@ -115,14 +117,16 @@ const (
 	DefaultMiddlewareGRPCProm      = "grpcprom"
 	DefaultMiddlewareServerVersion = "serverversion"
 
-	DefaultInternalMiddlewareDispatch       = "dispatch"
-	DefaultInternalMiddlewareDatastore      = "datastore"
-	DefaultInternalMiddlewareConsistency    = "consistency"
-	DefaultInternalMiddlewareServerSpecific = "servicespecific"
+	DefaultInternalMiddlewareDispatch         = "dispatch"
+	DefaultInternalMiddlewareDatastore        = "datastore"
+	DefaultInternalMiddlewareSession          = "session"
+	DefaultInternalMiddlewareConsistency      = "consistency"
+	DefaultInternalMiddlewareSchemaValidation = "schemavalidation"
+	DefaultInternalMiddlewareServerSpecific   = "servicespecific"
 )
 
 // DefaultMiddleware generates the default middleware chain used for the public SpiceDB gRPC API
-func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enableVersionResponse bool, dispatcher dispatch.Dispatcher, ds datastore.Datastore) (*MiddlewareChain, error) {
+func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enableVersionResponse bool, enableSchemaValidation bool, dispatcher dispatch.Dispatcher, ds datastore.Datastore, sessionTracker session.Tracker) (*MiddlewareChain, error) {
 	chain, err := NewMiddlewareChain([]ReferenceableMiddleware{
 		{
 			Name:                DefaultMiddlewareRequestID,
@@ -171,12 +175,24 @@ func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enable
 			UnaryMiddleware:     datastoremw.UnaryServerInterceptor(ds),
 			StreamingMiddleware: datastoremw.StreamServerInterceptor(ds),
 		},
+		{
+			Name:                DefaultInternalMiddlewareSession,
+			Internal:            true,
+			UnaryMiddleware:     session.UnaryServerInterceptor(sessionTracker),
+			StreamingMiddleware: session.StreamServerInterceptor(sessionTracker),
+		},
 		{
 			Name:                DefaultInternalMiddlewareConsistency,
 			Internal:            true,
 			UnaryMiddleware:     consistencymw.UnaryServerInterceptor(),
 			StreamingMiddleware: consistencymw.StreamServerInterceptor(),
 		},
+		{
+			Name:                DefaultInternalMiddlewareSchemaValidation,
+			Internal:            true,
+			UnaryMiddleware:     schemavalidation.UnaryServerInterceptor(enableSchemaValidation),
+			StreamingMiddleware: schemavalidation.StreamServerInterceptor(enableSchemaValidation),
+		},
 		{
 			Name:                DefaultInternalMiddlewareServerSpecific,
 			Internal:            true,
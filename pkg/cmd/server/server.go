@@ -30,6 +30,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/gateway"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/session"
 	"github.com/authzed/spicedb/internal/services"
 	dispatchSvc "github.com/authzed/spicedb/internal/services/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
@@ -39,6 +40,7 @@ import (
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
 const (
@@ -55,11 +57,12 @@ var ConsistentHashringPicker = balancer.NewConsistentHashringPickerBuilder(
 //go:generate go run github.com/ecordell/optgen -output zz_generated.options.go . Config
 type Config struct {
 	// API config
-	GRPCServer             util.GRPCServerConfig
-	GRPCAuthFunc           grpc_auth.AuthFunc
-	PresharedKey           []string
-	ShutdownGracePeriod    time.Duration
-	DisableVersionResponse bool
+	GRPCServer              util.GRPCServerConfig
+	GRPCAuthFunc            grpc_auth.AuthFunc
+	PresharedKey            []string
+	ShutdownGracePeriod     time.Duration
+	DisableVersionResponse  bool
+	DisableSchemaValidation bool
 
 	// GRPC Gateway config
 	HTTPGateway                    util.HTTPServerConfig
@@ -76,7 +79,8 @@ type Config struct {
 	MaxCaveatContextSize int
 
 	// Namespace cache
-	NamespaceCacheConfig CacheConfig
+	NamespaceCacheConfig       CacheConfig
+	NamespaceCacheWatchEnabled bool
 
 	// Schema options
 	SchemaPrefixesRequired bool
@@ -106,6 +110,13 @@ type Config struct {
 	MaximumUpdatesPerWrite   uint16
 	MaximumPreconditionCount uint16
 	MaxDatastoreReadPageSize uint64
+	MaxBulkCheckItems        uint16
+
+	// Session consistency
+	SessionConsistencyEnabled                bool
+	SessionConsistencyMaxSessions            int
+	SessionConsistencyTTL                    time.Duration
+	SessionConsistencyPeerPropagationEnabled bool
 
 	// Additional Services
 	DashboardAPI util.HTTPServerConfig
@@ -212,7 +223,20 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	}
 	log.Ctx(ctx).Info().EmbedObject(nscc).Msg("configured namespace cache")
 
-	ds = proxy.NewCachingDatastoreProxy(ds, nscc)
+	datastoreFeatures, err := ds.Features(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error determining datastore features: %w", err)
+	}
+
+	switch {
+	case !c.NamespaceCacheWatchEnabled:
+		ds = proxy.NewCachingDatastoreProxy(ds, nscc)
+	case !datastoreFeatures.Watch.Enabled:
+		log.Ctx(ctx).Warn().Str("reason", datastoreFeatures.Watch.Reason).Msg("namespace cache watch disabled; underlying datastore does not support it")
+		ds = proxy.NewCachingDatastoreProxy(ds, nscc)
+	default:
+		ds = proxy.NewWatchingCachingDatastoreProxy(ds, nscc)
+	}
 	ds = proxy.NewObservableDatastoreProxy(ds)
 	closeables.AddWithError(ds.Close)
 
@@ -227,11 +251,6 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		closeables.AddWithoutError(cc.Close)
 		log.Ctx(ctx).Info().EmbedObject(cc).Msg("configured dispatch cache")
 
-		dispatchPresharedKey := ""
-		if len(c.PresharedKey) > 0 {
-			dispatchPresharedKey = c.PresharedKey[0]
-		}
-
 		specificConcurrencyLimits := c.DispatchConcurrencyLimits
 		concurrencyLimits := specificConcurrencyLimits.WithOverallDefaultLimit(c.GlobalDispatchConcurrencyLimit)
 
@@ -245,19 +264,12 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			ConsistentHashringPicker.MustSpread(c.DispatchHashringSpread)
 		}
 
-		dispatcher, err = combineddispatch.NewDispatcher(
-			combineddispatch.UpstreamAddr(c.DispatchUpstreamAddr),
-			combineddispatch.UpstreamCAPath(c.DispatchUpstreamCAPath),
-			combineddispatch.GrpcPresharedKey(dispatchPresharedKey),
-			combineddispatch.GrpcDialOpts(
-				grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
-				grpc.WithDefaultServiceConfig(balancer.BalancerServiceConfig),
-			),
+		dispatcher, err = combineddispatch.NewDispatcher(append(c.dispatchUpstreamOptions(),
 			combineddispatch.MetricsEnabled(c.DispatchClientMetricsEnabled),
 			combineddispatch.PrometheusSubsystem(c.DispatchClientMetricsPrefix),
 			combineddispatch.Cache(cc),
 			combineddispatch.ConcurrencyLimits(concurrencyLimits),
-		)
+		)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
 		}
@@ -295,9 +307,15 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		closeables.AddWithError(cachingClusterDispatch.Close)
 	}
 
+	sessionTracker, sharedSessionTracker, sessionCloser, err := c.initializeSessionTracking(ctx)
+	if err != nil {
+		return nil, err
+	}
+	closeables.AddCloser(sessionCloser)
+
 	dispatchGrpcServer, err := c.DispatchServer.Complete(zerolog.InfoLevel,
 		func(server *grpc.Server) {
-			dispatchSvc.RegisterGrpcServices(server, cachingClusterDispatch)
+			dispatchSvc.RegisterGrpcServices(server, cachingClusterDispatch, sharedSessionTracker)
 		},
 		grpc.ChainUnaryInterceptor(c.DispatchUnaryMiddleware...),
 		grpc.ChainStreamInterceptor(c.DispatchStreamingMiddleware...),
@@ -307,11 +325,6 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	}
 	closeables.AddWithoutError(dispatchGrpcServer.GracefulStop)
 
-	datastoreFeatures, err := ds.Features(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error determining datastore features: %w", err)
-	}
-
 	v1SchemaServiceOption := services.V1SchemaServiceEnabled
 	if c.DisableV1SchemaAPI {
 		v1SchemaServiceOption = services.V1SchemaServiceDisabled
@@ -325,7 +338,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		watchServiceOption = services.WatchServiceDisabled
 	}
 
-	defaultMiddlewareChain, err := DefaultMiddleware(log.Logger, c.GRPCAuthFunc, !c.DisableVersionResponse, dispatcher, ds)
+	defaultMiddlewareChain, err := DefaultMiddleware(log.Logger, c.GRPCAuthFunc, !c.DisableVersionResponse, !c.DisableSchemaValidation, dispatcher, ds, sessionTracker)
 	if err != nil {
 		return nil, fmt.Errorf("error building default middleware: %w", err)
 	}
@@ -341,6 +354,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		MaximumAPIDepth:          c.DispatchMaxDepth,
 		MaxCaveatContextSize:     c.MaxCaveatContextSize,
 		MaxDatastoreReadPageSize: c.MaxDatastoreReadPageSize,
+		MaxBulkCheckItems:        c.MaxBulkCheckItems,
 	}
 
 	healthManager := health.NewHealthManager(dispatcher, ds)
@@ -480,6 +494,51 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 	return gatewayServer, closeableGatewayHandler, nil
 }
 
+// dispatchUpstreamOptions returns the options for connecting to the dispatch upstream.
+func (c *Config) dispatchUpstreamOptions() []combineddispatch.Option {
+	dispatchPresharedKey := ""
+	if len(c.PresharedKey) > 0 {
+		dispatchPresharedKey = c.PresharedKey[0]
+	}
+
+	return []combineddispatch.Option{
+		combineddispatch.UpstreamAddr(c.DispatchUpstreamAddr),
+		combineddispatch.UpstreamCAPath(c.DispatchUpstreamCAPath),
+		combineddispatch.GrpcPresharedKey(dispatchPresharedKey),
+		combineddispatch.GrpcDialOpts(
+			grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+			grpc.WithDefaultServiceConfig(balancer.BalancerServiceConfig),
+		),
+	}
+}
+
+// initializeSessionTracking configures the tracker of the sessions used by the API, along with
+// the tracker shared with the other nodes through the dispatch server, if peer propagation is
+// enabled. Both are nil if session consistency is disabled.
+func (c *Config) initializeSessionTracking(ctx context.Context) (session.Tracker, session.Tracker, io.Closer, error) {
+	if !c.SessionConsistencyEnabled {
+		return nil, nil, nil, nil
+	}
+
+	localTracker := session.NewMemoryTracker(c.SessionConsistencyMaxSessions, c.SessionConsistencyTTL)
+	if !c.SessionConsistencyPeerPropagationEnabled {
+		log.Ctx(ctx).Info().Msg("session consistency enabled")
+		return localTracker, nil, nil, nil
+	}
+
+	if c.DispatchUpstreamAddr == "" || !c.DispatchServer.Enabled {
+		return nil, nil, nil, fmt.Errorf("session consistency peer propagation requires a dispatch upstream and the dispatch server")
+	}
+
+	conn, err := combineddispatch.DialUpstream(c.dispatchUpstreamOptions()...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to dispatch upstream for session consistency: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Str("upstream", c.DispatchUpstreamAddr).Msg("session consistency enabled with peer propagation")
+	return session.NewPeerTracker(localTracker, dispatchv1.NewSessionServiceClient(conn)), localTracker, conn, nil
+}
+
 // RunnableServer is a spicedb service set ready to run
 type RunnableServer interface {
 	Run(ctx context.Context) error
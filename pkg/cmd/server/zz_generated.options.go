@@ -31,6 +31,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.PresharedKey = c.PresharedKey
 		to.ShutdownGracePeriod = c.ShutdownGracePeriod
 		to.DisableVersionResponse = c.DisableVersionResponse
+		to.DisableSchemaValidation = c.DisableSchemaValidation
 		to.HTTPGateway = c.HTTPGateway
 		to.HTTPGatewayUpstreamAddr = c.HTTPGatewayUpstreamAddr
 		to.HTTPGatewayUpstreamTLSCertPath = c.HTTPGatewayUpstreamTLSCertPath
@@ -40,6 +41,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.Datastore = c.Datastore
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
 		to.NamespaceCacheConfig = c.NamespaceCacheConfig
+		to.NamespaceCacheWatchEnabled = c.NamespaceCacheWatchEnabled
 		to.SchemaPrefixesRequired = c.SchemaPrefixesRequired
 		to.DispatchServer = c.DispatchServer
 		to.DispatchMaxDepth = c.DispatchMaxDepth
@@ -62,6 +64,11 @@ func (c *Config) ToOption() ConfigOption {
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxDatastoreReadPageSize = c.MaxDatastoreReadPageSize
+		to.MaxBulkCheckItems = c.MaxBulkCheckItems
+		to.SessionConsistencyEnabled = c.SessionConsistencyEnabled
+		to.SessionConsistencyMaxSessions = c.SessionConsistencyMaxSessions
+		to.SessionConsistencyTTL = c.SessionConsistencyTTL
+		to.SessionConsistencyPeerPropagationEnabled = c.SessionConsistencyPeerPropagationEnabled
 		to.DashboardAPI = c.DashboardAPI
 		to.MetricsAPI = c.MetricsAPI
 		to.MiddlewareModification = c.MiddlewareModification
@@ -124,6 +131,13 @@ func WithDisableVersionResponse(disableVersionResponse bool) ConfigOption {
 	}
 }
 
+// WithDisableSchemaValidation returns an option that can set DisableSchemaValidation on a Config
+func WithDisableSchemaValidation(disableSchemaValidation bool) ConfigOption {
+	return func(c *Config) {
+		c.DisableSchemaValidation = disableSchemaValidation
+	}
+}
+
 // WithHTTPGateway returns an option that can set HTTPGateway on a Config
 func WithHTTPGateway(hTTPGateway util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -194,6 +208,13 @@ func WithNamespaceCacheConfig(namespaceCacheConfig CacheConfig) ConfigOption {
 	}
 }
 
+// WithNamespaceCacheWatchEnabled returns an option that can set NamespaceCacheWatchEnabled on a Config
+func WithNamespaceCacheWatchEnabled(namespaceCacheWatchEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceCacheWatchEnabled = namespaceCacheWatchEnabled
+	}
+}
+
 // WithSchemaPrefixesRequired returns an option that can set SchemaPrefixesRequired on a Config
 func WithSchemaPrefixesRequired(schemaPrefixesRequired bool) ConfigOption {
 	return func(c *Config) {
@@ -348,6 +369,41 @@ func WithMaxDatastoreReadPageSize(maxDatastoreReadPageSize uint64) ConfigOption
 	}
 }
 
+// WithMaxBulkCheckItems returns an option that can set MaxBulkCheckItems on a Config
+func WithMaxBulkCheckItems(maxBulkCheckItems uint16) ConfigOption {
+	return func(c *Config) {
+		c.MaxBulkCheckItems = maxBulkCheckItems
+	}
+}
+
+// WithSessionConsistencyEnabled returns an option that can set SessionConsistencyEnabled on a Config
+func WithSessionConsistencyEnabled(sessionConsistencyEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.SessionConsistencyEnabled = sessionConsistencyEnabled
+	}
+}
+
+// WithSessionConsistencyMaxSessions returns an option that can set SessionConsistencyMaxSessions on a Config
+func WithSessionConsistencyMaxSessions(sessionConsistencyMaxSessions int) ConfigOption {
+	return func(c *Config) {
+		c.SessionConsistencyMaxSessions = sessionConsistencyMaxSessions
+	}
+}
+
+// WithSessionConsistencyTTL returns an option that can set SessionConsistencyTTL on a Config
+func WithSessionConsistencyTTL(sessionConsistencyTTL time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SessionConsistencyTTL = sessionConsistencyTTL
+	}
+}
+
+// WithSessionConsistencyPeerPropagationEnabled returns an option that can set SessionConsistencyPeerPropagationEnabled on a Config
+func WithSessionConsistencyPeerPropagationEnabled(sessionConsistencyPeerPropagationEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.SessionConsistencyPeerPropagationEnabled = sessionConsistencyPeerPropagationEnabled
+	}
+}
+
 // WithDashboardAPI returns an option that can set DashboardAPI on a Config
 func WithDashboardAPI(dashboardAPI util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -18,6 +18,9 @@ func RegisterTestingFlags(cmd *cobra.Command, config *testserver.Config) {
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.ReadOnlyHTTPGateway, "readonly-http", "read-only HTTP", ":8082", false)
 
 	cmd.Flags().StringSliceVar(&config.LoadConfigs, "load-configs", []string{}, "configuration yaml files to load")
+	cmd.Flags().StringToStringVar(&config.Fixtures, "load-fixtures", map[string]string{}, "named configuration yaml files (name=path) from which the datastore of a token is loaded instead, when the io.spicedb.testing.fixture request metadata names them")
+	cmd.Flags().DurationVar(&config.TokenDatastoreIdleTTL, "token-datastore-idle-ttl", 0, "amount of time without requests after which the datastore of a token is discarded; 0 keeps it until the server stops")
+	cmd.Flags().IntVar(&config.MaxTokenDatastores, "max-token-datastores", 0, "maximum number of token datastores kept, discarding the least recently used beyond it; 0 for no limit")
 
 	// Flags for API behavior
 	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
@@ -3,6 +3,7 @@ package testserver
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
@@ -18,9 +19,11 @@ import (
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
 	"github.com/authzed/spicedb/internal/services"
 	"github.com/authzed/spicedb/internal/services/health"
+	testserversvc "github.com/authzed/spicedb/internal/services/testserver"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
+	testserverv1 "github.com/authzed/spicedb/pkg/proto/testserver/v1"
 )
 
 const maxDepth = 50
@@ -32,6 +35,9 @@ type Config struct {
 	HTTPGateway              util.HTTPServerConfig
 	ReadOnlyHTTPGateway      util.HTTPServerConfig
 	LoadConfigs              []string
+	Fixtures                 map[string]string
+	TokenDatastoreIdleTTL    time.Duration
+	MaxTokenDatastores       int
 	MaximumUpdatesPerWrite   uint16
 	MaximumPreconditionCount uint16
 	MaxCaveatContextSize     int
@@ -52,7 +58,11 @@ func (dr datastoreReady) ReadyState(_ context.Context) (datastore.ReadyState, er
 func (c *Config) Complete() (RunnableTestServer, error) {
 	dispatcher := graph.NewLocalOnlyDispatcher(10)
 
-	datastoreMiddleware := pertoken.NewMiddleware(c.LoadConfigs)
+	datastoreMiddleware := pertoken.NewMiddleware(c.LoadConfigs,
+		pertoken.WithFixtures(c.Fixtures),
+		pertoken.WithIdleTTL(c.TokenDatastoreIdleTTL),
+		pertoken.WithMaxDatastores(c.MaxTokenDatastores),
+	)
 
 	healthManager := health.NewHealthManager(dispatcher, &datastoreReady{})
 
@@ -71,7 +81,13 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 			},
 		)
 	}
-	gRPCSrv, err := c.GRPCServer.Complete(zerolog.InfoLevel, registerServices,
+	// The datastores can only be administered through the read-write server.
+	registerServicesWithAdmin := func(srv *grpc.Server) {
+		registerServices(srv)
+		testserverv1.RegisterTokenDatastoreServiceServer(srv, testserversvc.NewTokenDatastoreServer(datastoreMiddleware))
+		healthManager.RegisterReportedService(testserverv1.TokenDatastoreService_ServiceDesc.ServiceName)
+	}
+	gRPCSrv, err := c.GRPCServer.Complete(zerolog.InfoLevel, registerServicesWithAdmin,
 		grpc.ChainUnaryInterceptor(
 			datastoreMiddleware.UnaryServerInterceptor(),
 			dispatchmw.UnaryServerInterceptor(dispatcher),
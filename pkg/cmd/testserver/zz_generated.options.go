@@ -1,7 +1,10 @@
 // Code generated by github.com/ecordell/optgen. DO NOT EDIT.
 package testserver
 
-import util "github.com/authzed/spicedb/pkg/cmd/util"
+import (
+	util "github.com/authzed/spicedb/pkg/cmd/util"
+	"time"
+)
 
 type ConfigOption func(c *Config)
 
@@ -22,6 +25,9 @@ func (c *Config) ToOption() ConfigOption {
 		to.HTTPGateway = c.HTTPGateway
 		to.ReadOnlyHTTPGateway = c.ReadOnlyHTTPGateway
 		to.LoadConfigs = c.LoadConfigs
+		to.Fixtures = c.Fixtures
+		to.TokenDatastoreIdleTTL = c.TokenDatastoreIdleTTL
+		to.MaxTokenDatastores = c.MaxTokenDatastores
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
@@ -78,6 +84,34 @@ func SetLoadConfigs(loadConfigs []string) ConfigOption {
 	}
 }
 
+// WithFixtures returns an option that can append Fixturess to Config.Fixtures
+func WithFixtures(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.Fixtures[key] = value
+	}
+}
+
+// SetFixtures returns an option that can set Fixtures on a Config
+func SetFixtures(fixtures map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.Fixtures = fixtures
+	}
+}
+
+// WithTokenDatastoreIdleTTL returns an option that can set TokenDatastoreIdleTTL on a Config
+func WithTokenDatastoreIdleTTL(tokenDatastoreIdleTTL time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.TokenDatastoreIdleTTL = tokenDatastoreIdleTTL
+	}
+}
+
+// WithMaxTokenDatastores returns an option that can set MaxTokenDatastores on a Config
+func WithMaxTokenDatastores(maxTokenDatastores int) ConfigOption {
+	return func(c *Config) {
+		c.MaxTokenDatastores = maxTokenDatastores
+	}
+}
+
 // WithMaximumUpdatesPerWrite returns an option that can set MaximumUpdatesPerWrite on a Config
 func WithMaximumUpdatesPerWrite(maximumUpdatesPerWrite uint16) ConfigOption {
 	return func(c *Config) {
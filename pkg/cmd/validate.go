@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/development"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+func RegisterValidateFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("update-validation", false, "rewrite the validation block of each file with the relations found, instead of failing on differences")
+}
+
+func NewValidateCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "validate <validation file>...",
+		Short:   "validates validation files",
+		Long:    "Loads the schema and relationships of each validation file, then runs its assertions and checks its expected relations",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    validateRun,
+		Args:    cobra.MinimumNArgs(1),
+	}
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	updateValidation := cobrautil.MustGetBool(cmd, "update-validation")
+
+	failed := false
+	for _, filePath := range args {
+		valid, err := validateFile(cmd.Context(), filePath, updateValidation)
+		if err != nil {
+			return fmt.Errorf("failed to validate %s: %w", filePath, err)
+		}
+
+		if valid {
+			fmt.Printf("%s: validation succeeded\n", filePath)
+			continue
+		}
+		failed = true
+	}
+
+	if failed {
+		return errors.New("validation failed")
+	}
+	return nil
+}
+
+// validateFile runs the assertions and expected relations of the validation file, printing any
+// errors found. The returned error is only for failures to run the validation.
+func validateFile(ctx context.Context, filePath string, updateValidation bool) (bool, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return false, err
+	}
+
+	parsed, err := validationfile.DecodeValidationFile(contents)
+	if err != nil {
+		printFileError(filePath, err)
+		return false, nil
+	}
+
+	if err := parsed.ResolveSchemaFile(filePath); err != nil {
+		printFileError(filePath, err)
+		return false, nil
+	}
+
+	relationships := make([]*core.RelationTuple, 0, len(parsed.Relationships.Relationships))
+	for _, relationship := range parsed.Relationships.Relationships {
+		relationships = append(relationships, tuple.MustFromRelationship(relationship))
+	}
+
+	devContext, devErrs, err := development.NewDevContext(ctx, &devinterface.RequestContext{
+		Schema:        parsed.Schema.Schema,
+		Relationships: relationships,
+	})
+	if err != nil {
+		printFileError(filePath, err)
+		return false, nil
+	}
+
+	if devErrs != nil && len(devErrs.InputErrors) > 0 {
+		for _, devErr := range devErrs.InputErrors {
+			printDeveloperError(filePath, contents, parsed, devErr)
+		}
+		return false, nil
+	}
+	defer devContext.Dispose()
+
+	failures, err := development.RunAllAssertions(devContext, &parsed.Assertions)
+	if err != nil {
+		return false, err
+	}
+
+	membershipSet, validationFailures, err := development.RunValidation(devContext, &parsed.ExpectedRelations)
+	if err != nil {
+		return false, err
+	}
+
+	// When updating, the expected relations are replaced by those found, so differences from
+	// them are not failures.
+	if updateValidation && len(parsed.ExpectedRelations.ValidationMap) > 0 {
+		generated, err := development.GenerateValidation(membershipSet)
+		if err != nil {
+			return false, err
+		}
+
+		if err := updateValidationBlock(filePath, contents, generated); err != nil {
+			return false, fmt.Errorf("failed to update validation block: %w", err)
+		}
+		fmt.Printf("%s: updated validation block\n", filePath)
+	} else {
+		failures = append(failures, validationFailures...)
+	}
+
+	for _, devErr := range failures {
+		printDeveloperError(filePath, contents, parsed, devErr)
+	}
+
+	return len(failures) == 0, nil
+}
+
+func printFileError(filePath string, err error) {
+	if errWithSource, ok := spiceerrors.AsErrorWithSource(err); ok && errWithSource.LineNumber > 0 {
+		fmt.Printf("%s:%d:%d: %s\n", filePath, errWithSource.LineNumber, errWithSource.ColumnPosition, err.Error())
+		return
+	}
+
+	fmt.Printf("%s: %s\n", filePath, err.Error())
+}
+
+// printDeveloperError prints the error found for the validation file at its position in the
+// file, if known.
+func printDeveloperError(filePath string, contents []byte, parsed *validationfile.ValidationFile, devErr *devinterface.DeveloperError) {
+	line := devErr.Line
+	column := devErr.Column
+
+	switch devErr.Source {
+	case devinterface.DeveloperError_SCHEMA:
+		// Positions in a schema file do not survive its regeneration, so only the file is reported.
+		if parsed.SchemaFile != "" {
+			fmt.Printf("%s: %s\n", parsed.SchemaFile, devErr.Message)
+			return
+		}
+
+		// Positions in the schema are relative to the schema block, which starts after its key
+		// and is indented.
+		if line > 0 {
+			line += uint32(parsed.Schema.SourcePosition.LineNumber)
+			column += blockIndentation(contents, parsed.Schema.SourcePosition.LineNumber)
+		}
+
+	case devinterface.DeveloperError_RELATIONSHIP:
+		// Errors in relationships have no position, so the relationship is found in the file.
+		if line == 0 && devErr.Context != "" {
+			for index, fileLine := range strings.Split(string(contents), "\n") {
+				if index+1 >= parsed.Relationships.SourcePosition.LineNumber && strings.TrimSpace(fileLine) == devErr.Context {
+					line = uint32(index + 1)
+					column = uint32(strings.Index(fileLine, devErr.Context) + 1)
+					break
+				}
+			}
+		}
+	}
+
+	if line == 0 {
+		fmt.Printf("%s: %s\n", filePath, devErr.Message)
+		return
+	}
+
+	fmt.Printf("%s:%d:%d: %s\n", filePath, line, column, devErr.Message)
+}
+
+// blockIndentation returns the indentation of the block scalar whose key is on the (1-indexed)
+// line, which is that of its first non-empty line.
+func blockIndentation(contents []byte, keyLine int) uint32 {
+	lines := strings.Split(string(contents), "\n")
+	for index := keyLine; index < len(lines); index++ {
+		trimmed := strings.TrimLeft(lines[index], " ")
+		if trimmed != "" {
+			return uint32(len(lines[index]) - len(trimmed))
+		}
+	}
+	return 0
+}
+
+// updateValidationBlock replaces the validation block of the file with the generated block.
+func updateValidationBlock(filePath string, contents []byte, generated string) error {
+	updated, err := spliceValidationBlock(contents, generated)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, updated, info.Mode())
+}
+
+// spliceValidationBlock returns the contents with the value of the top-level validation key
+// replaced by the generated block. Only the bytes of the value are replaced, so the comments and
+// formatting of the rest of the file are kept.
+func spliceValidationBlock(contents []byte, generated string) ([]byte, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(contents, &document); err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 || document.Content[0].Kind != yamlv3.MappingNode {
+		return nil, errors.New("expected a document")
+	}
+
+	var key, value, nextKey *yamlv3.Node
+	root := document.Content[0]
+	for index := 0; index+1 < len(root.Content); index += 2 {
+		if key != nil {
+			nextKey = root.Content[index]
+			break
+		}
+		if root.Content[index].Value == "validation" {
+			key, value = root.Content[index], root.Content[index+1]
+		}
+	}
+
+	if key == nil {
+		return nil, errors.New("missing validation block")
+	}
+
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+	lineOffset := func(line int) int {
+		offset := 0
+		for _, l := range lines[:line-1] {
+			offset += len(l)
+		}
+		return offset
+	}
+
+	// The value ends before the next top-level key, or at the end of the file. Blank lines and
+	// unindented comments before the next key belong to it, so are kept.
+	end := len(contents)
+	if nextKey != nil {
+		endLine := nextKey.Line
+		for endLine-1 > key.Line {
+			previous := lines[endLine-2]
+			if trimmed := bytes.TrimSpace(previous); len(trimmed) > 0 && (trimmed[0] != '#' || previous[0] != '#') {
+				break
+			}
+			endLine--
+		}
+		end = lineOffset(endLine)
+	}
+
+	// A value on the line of its key, such as a flow mapping, is moved to the following lines.
+	var replacement bytes.Buffer
+	start := lineOffset(key.Line + 1)
+	indentation := 2
+	if value.Line == key.Line {
+		start = lineOffset(key.Line) + value.Column - 1
+		for contents[start-1] == ' ' {
+			start--
+		}
+		replacement.WriteString("\n")
+	} else if value.Kind == yamlv3.MappingNode {
+		indentation = value.Column - 1
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(generated, "\n"), "\n") {
+		if line != "" {
+			replacement.WriteString(strings.Repeat(" ", indentation))
+			replacement.WriteString(line)
+		}
+		replacement.WriteString("\n")
+	}
+
+	updated := make([]byte, 0, len(contents)-(end-start)+replacement.Len())
+	updated = append(updated, contents[:start]...)
+	updated = append(updated, replacement.Bytes()...)
+	updated = append(updated, contents[end:]...)
+	return updated, nil
+}
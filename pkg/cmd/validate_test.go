@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validValidationFile = `# The schema under test.
+schema: >-
+  definition user {}
+
+  definition document {
+    relation reader: user
+    permission view = reader
+  }
+
+relationships: >-
+  document:firstdoc#reader@user:tom
+
+# The expected relations.
+validation:
+  document:firstdoc#view:
+    - "[user:tom] is <document:firstdoc#reader>"
+
+assertions:
+  assertTrue:
+    - document:firstdoc#view@user:tom
+  assertFalse:
+    - document:firstdoc#view@user:fred
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		contents       string
+		expectedError  string
+		expectedOutput string
+	}{
+		{
+			"valid",
+			validValidationFile,
+			"",
+			"validation.yaml: validation succeeded\n",
+		},
+		{
+			"failing assertion",
+			strings.Replace(validValidationFile, "assertTrue:\n    - document:firstdoc#view@user:tom", "assertTrue:\n    - document:firstdoc#view@user:fred", 1),
+			"validation failed",
+			"validation.yaml:20:7: Expected relation or permission document:firstdoc#view@user:fred to exist\n",
+		},
+		{
+			"invalid relationship",
+			strings.Replace(validValidationFile, "document:firstdoc#reader@user:tom\n", "document:firstdoc#reader@unknown:tom\n", 1),
+			"validation failed",
+			"validation.yaml:11:3: ",
+		},
+		{
+			"different expected relations",
+			strings.Replace(validValidationFile, `"[user:tom] is <document:firstdoc#reader>"`, `"[user:fred] is <document:firstdoc#reader>"`, 1),
+			"validation failed",
+			"validation.yaml:16:7: ",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeValidationFile(t, tt.contents)
+
+			output, err := runValidate(t, filePath)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Contains(t, output, strings.Replace(tt.expectedOutput, "validation.yaml", filePath, 1))
+		})
+	}
+}
+
+func TestValidateUpdateValidation(t *testing.T) {
+	contents := strings.Replace(validValidationFile, `"[user:tom] is <document:firstdoc#reader>"`, `"[user:fred] is <document:firstdoc#reader>"`, 1)
+	filePath := writeValidationFile(t, contents)
+
+	output, err := runValidate(t, filePath, "--update-validation")
+	require.NoError(t, err)
+	require.Contains(t, output, filePath+": updated validation block\n")
+
+	updated, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	// Only the validation block is rewritten.
+	before, _, _ := strings.Cut(validValidationFile, "validation:\n")
+	_, after, _ := strings.Cut(validValidationFile, "\n\nassertions:")
+	require.True(t, strings.HasPrefix(string(updated), before+"validation:\n"))
+	require.True(t, strings.HasSuffix(string(updated), "\n\nassertions:"+after))
+	require.Contains(t, string(updated), "[user:tom] is <document:firstdoc#reader>")
+	require.NotContains(t, string(updated), "[user:fred]")
+
+	// The updated file now validates without updating.
+	_, err = runValidate(t, filePath)
+	require.NoError(t, err)
+}
+
+func TestSpliceValidationBlock(t *testing.T) {
+	generated := "document:firstdoc#view:\n- '[user:tom] is <document:firstdoc#reader>'\n"
+
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			"block at end of file",
+			"schema: >-\n  definition user {}\n\nvalidation:\n  document:firstdoc#view: []\n",
+			"schema: >-\n  definition user {}\n\nvalidation:\n  document:firstdoc#view:\n  - '[user:tom] is <document:firstdoc#reader>'\n",
+		},
+		{
+			"block followed by comment and key",
+			"validation: # expected\n    document:firstdoc#view: []\n\n# The assertions.\nassertions: {}\n",
+			"validation: # expected\n    document:firstdoc#view:\n    - '[user:tom] is <document:firstdoc#reader>'\n\n# The assertions.\nassertions: {}\n",
+		},
+		{
+			"flow mapping on key line",
+			"validation: {}\nassertions: {}\n",
+			"validation:\n  document:firstdoc#view:\n  - '[user:tom] is <document:firstdoc#reader>'\nassertions: {}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := spliceValidationBlock([]byte(tt.contents), generated)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(updated))
+		})
+	}
+}
+
+func writeValidationFile(t *testing.T, contents string) string {
+	filePath := filepath.Join(t.TempDir(), "validation.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(contents), 0o600))
+	return filePath
+}
+
+// runValidate runs the validate command for the arguments, returning what it printed.
+func runValidate(t *testing.T, args ...string) (string, error) {
+	cmd := NewValidateCommand("spicedb")
+	RegisterValidateFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs(args)
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	output := make(chan string)
+	go func() {
+		read, _ := io.ReadAll(reader)
+		output <- string(read)
+	}()
+
+	err = cmd.ExecuteContext(context.Background())
+	require.NoError(t, writer.Close())
+	return <-output, err
+}
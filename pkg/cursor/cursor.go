@@ -0,0 +1,124 @@
+// Package cursor converts dispatch cursors to the opaque cursors returned by the API and vice versa
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	impl "github.com/authzed/spicedb/pkg/proto/impl/v1"
+)
+
+const (
+	// LookupResourcesLimit is the key in the request header metadata for the maximum number
+	// of results to be returned by a LookupResources call.
+	LookupResourcesLimit requestmeta.RequestMetadataHeaderKey = "io.spicedb.lookupresources.limit"
+
+	// LookupResourcesCursor is the key in the request header metadata for the cursor at which
+	// a LookupResources call should resume.
+	LookupResourcesCursor requestmeta.RequestMetadataHeaderKey = "io.spicedb.lookupresources.cursor"
+
+	// LookupResourcesAfterResultCursor is the key in the response trailer metadata for the cursor
+	// at which to resume after the last result returned by a LookupResources call. It is only set
+	// if the limit of the call was reached.
+	LookupResourcesAfterResultCursor responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.lookupresources.cursor"
+)
+
+// Public facing errors
+const (
+	errEncodeError = "error encoding cursor: %w"
+	errDecodeError = "error decoding cursor: %w"
+)
+
+// ErrNilCursor is returned as the base error when nil is provided as the
+// cursor argument to Decode
+var ErrNilCursor = errors.New("cursor pointer was nil")
+
+// ErrHashMismatch is returned as the base error when a cursor is provided to an API call
+// with arguments differing from those of the API call that created the cursor.
+var ErrHashMismatch = errors.New("the cursor provided does not have the same arguments as the original API call; please ensure you are making the same API call, with the exact same parameters (besides the cursor)")
+
+// Encode converts a decoded cursor to its opaque version.
+func Encode(decoded *impl.DecodedCursor) (string, error) {
+	marshalled, err := decoded.MarshalVT()
+	if err != nil {
+		return "", fmt.Errorf(errEncodeError, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(marshalled), nil
+}
+
+// Decode converts an encoded cursor to its decoded version.
+func Decode(encoded string) (*impl.DecodedCursor, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf(errDecodeError, ErrNilCursor)
+	}
+
+	decodedBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf(errDecodeError, err)
+	}
+
+	decoded := &impl.DecodedCursor{}
+	if err := decoded.UnmarshalVT(decodedBytes); err != nil {
+		return nil, fmt.Errorf(errDecodeError, err)
+	}
+
+	return decoded, nil
+}
+
+// EncodeFromDispatchCursor encodes an internal dispatching cursor into an opaque cursor for
+// the API, recording the revision at which the call was made, as well as a hash of the call and
+// its parameters.
+func EncodeFromDispatchCursor(dispatchCursor *dispatch.Cursor, callAndParameterHash string, revision datastore.Revision) (string, error) {
+	if dispatchCursor == nil {
+		return "", fmt.Errorf(errEncodeError, ErrNilCursor)
+	}
+
+	return Encode(&impl.DecodedCursor{
+		VersionOneof: &impl.DecodedCursor_V1{
+			V1: &impl.V1Cursor{
+				Revision:               revision.String(),
+				DispatchCursorSections: dispatchCursor.Sections,
+				CallAndParametersHash:  callAndParameterHash,
+			},
+		},
+	})
+}
+
+// DecodeToDispatchCursor decodes an opaque cursor from the API into an internal dispatching
+// cursor, along with the revision at which the call that created the cursor was made. The hash
+// of the call and its parameters must match that recorded in the cursor.
+func DecodeToDispatchCursor(encoded string, callAndParameterHash string, ds revisionDecoder) (*dispatch.Cursor, datastore.Revision, error) {
+	decoded, err := Decode(encoded)
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	v1decoded := decoded.GetV1()
+	if v1decoded == nil {
+		return nil, datastore.NoRevision, fmt.Errorf(errDecodeError, fmt.Errorf("unknown cursor version: %T", decoded.VersionOneof))
+	}
+
+	if v1decoded.CallAndParametersHash != callAndParameterHash {
+		return nil, datastore.NoRevision, fmt.Errorf(errDecodeError, ErrHashMismatch)
+	}
+
+	revision, err := ds.RevisionFromString(v1decoded.Revision)
+	if err != nil {
+		return nil, datastore.NoRevision, fmt.Errorf(errDecodeError, err)
+	}
+
+	return &dispatch.Cursor{
+		Sections: v1decoded.DispatchCursorSections,
+	}, revision, nil
+}
+
+type revisionDecoder interface {
+	RevisionFromString(string) (datastore.Revision, error)
+}
@@ -0,0 +1,87 @@
+package cursor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sections []string
+		revision decimal.Decimal
+		hash     string
+	}{
+		{
+			"empty",
+			nil,
+			decimal.NewFromInt(1),
+			"somehash",
+		},
+		{
+			"basic",
+			[]string{"a", "b", "c"},
+			decimal.NewFromInt(1234),
+			"another",
+		},
+		{
+			"basic with different revision",
+			[]string{"a", "b", "c"},
+			decimal.NewFromInt(4567),
+			"another",
+		},
+		{
+			"with empty section",
+			[]string{"1", "", "foo"},
+			decimal.New(12345, -2),
+			"",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			encoded, err := EncodeFromDispatchCursor(&dispatch.Cursor{
+				Sections: tc.sections,
+			}, tc.hash, revision.NewFromDecimal(tc.revision))
+			require.NoError(err)
+			require.NotEmpty(encoded)
+
+			decoded, rev, err := DecodeToDispatchCursor(encoded, tc.hash, revision.DecimalDecoder{})
+			require.NoError(err)
+			require.Equal(len(tc.sections), len(decoded.Sections))
+			for index, section := range tc.sections {
+				require.Equal(section, decoded.Sections[index])
+			}
+			require.True(revision.NewFromDecimal(tc.revision).Equal(rev))
+
+			// Decoding with a different hash must fail.
+			_, _, err = DecodeToDispatchCursor(encoded, "differenthash", revision.DecimalDecoder{})
+			require.Error(err)
+			require.True(errors.Is(err, ErrHashMismatch))
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"invalid base64", "!!!"},
+		{"invalid proto", "YWJj"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := DecodeToDispatchCursor(tc.encoded, "somehash", revision.DecimalDecoder{})
+			require.Error(t, err)
+		})
+	}
+}
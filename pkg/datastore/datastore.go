@@ -47,6 +47,21 @@ const FilterMaximumIDCount uint16 = 100
 type RevisionChanges struct {
 	Revision Revision
 	Changes  []*core.RelationTupleUpdate
+
+	// ChangedDefinitions are the namespace and caveat definitions written in the transaction.
+	ChangedDefinitions []SchemaDefinition
+
+	// DeletedNamespaces are the names of the namespaces deleted in the transaction.
+	DeletedNamespaces []string
+
+	// DeletedCaveats are the names of the caveats deleted in the transaction.
+	DeletedCaveats []string
+}
+
+// HasSchemaChanges returns true if any namespace or caveat definitions were written or
+// deleted in the transaction.
+func (rc RevisionChanges) HasSchemaChanges() bool {
+	return len(rc.ChangedDefinitions) > 0 || len(rc.DeletedNamespaces) > 0 || len(rc.DeletedCaveats) > 0
 }
 
 // RelationshipsFilter is a filter for relationships.
@@ -257,6 +272,25 @@ type ReadWriteTransaction interface {
 
 	// DeleteNamespaces deletes namespaces including associated relationships.
 	DeleteNamespaces(ctx context.Context, nsNames ...string) error
+
+	// BulkLoad takes a relationship source iterator, and writes all of the
+	// relationships to the backing datastore in an optimized fashion. This
+	// method can and will omit checks and otherwise cut corners in the
+	// interest of performance, and should not be relied upon for OLTP-style
+	// workloads. All relationships are written as creates: loading a
+	// relationship which already exists will fail the transaction.
+	BulkLoad(ctx context.Context, iter BulkWriteRelationshipSource) (uint64, error)
+}
+
+// BulkWriteRelationshipSource is an interface for transferring relationships
+// to a backing datastore with a zero-copy methodology.
+type BulkWriteRelationshipSource interface {
+	// Next Returns a pointer to a relation tuple if one is available, or nil if
+	// there are no more or there was an error.
+	//
+	// Note: sources may re-use the same memory address for every tuple, data
+	// may change on every call to next even if the pointer has not changed.
+	Next(ctx context.Context) (*core.RelationTuple, error)
 }
 
 // TxUserFunc is a type for the function that users supply when they invoke a read-write transaction.
@@ -297,7 +331,7 @@ type Datastore interface {
 	// used by the specific datastore implementation.
 	RevisionFromString(serialized string) (Revision, error)
 
-	// Watch notifies the caller about all changes to tuples.
+	// Watch notifies the caller about all changes to tuples, namespaces and caveats.
 	//
 	// All events following afterRevision will be sent to the caller.
 	Watch(ctx context.Context, afterRevision Revision) (<-chan *RevisionChanges, <-chan error)
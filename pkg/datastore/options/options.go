@@ -36,6 +36,9 @@ type QueryOptions struct {
 type ReverseQueryOptions struct {
 	ReverseLimit *uint64
 	ResRelation  *ResourceRelation
+
+	SortForReverse  SortOrder
+	AfterForReverse Cursor
 }
 
 // ResourceRelation combines a resource object type and relation.
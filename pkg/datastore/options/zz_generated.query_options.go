@@ -83,6 +83,8 @@ func (r *ReverseQueryOptions) ToOption() ReverseQueryOptionsOption {
 	return func(to *ReverseQueryOptions) {
 		to.ReverseLimit = r.ReverseLimit
 		to.ResRelation = r.ResRelation
+		to.SortForReverse = r.SortForReverse
+		to.AfterForReverse = r.AfterForReverse
 	}
 }
 
@@ -107,3 +109,17 @@ func WithResRelation(resRelation *ResourceRelation) ReverseQueryOptionsOption {
 		r.ResRelation = resRelation
 	}
 }
+
+// WithSortForReverse returns an option that can set SortForReverse on a ReverseQueryOptions
+func WithSortForReverse(sortForReverse SortOrder) ReverseQueryOptionsOption {
+	return func(r *ReverseQueryOptions) {
+		r.SortForReverse = sortForReverse
+	}
+}
+
+// WithAfterForReverse returns an option that can set AfterForReverse on a ReverseQueryOptions
+func WithAfterForReverse(afterForReverse Cursor) ReverseQueryOptionsOption {
+	return func(r *ReverseQueryOptions) {
+		r.AfterForReverse = afterForReverse
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// BulkUploadTest tests whether relationships written with BulkLoad can be read back from a
+// datastore.
+func BulkUploadTest(t *testing.T, tester DatastoreTester) {
+	testCases := []int{0, 1, 10, 100, 1_000, 10_000}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(strconv.Itoa(tc), func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+			require.NoError(err)
+			defer rawDS.Close()
+
+			ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+			bulkSource := testfixtures.NewBulkTupleGenerator(
+				testfixtures.DocumentNS.Name,
+				"viewer",
+				testfixtures.UserNS.Name,
+				tc,
+			)
+
+			var loaded uint64
+			lastRevision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+				var err error
+				loaded, err = rwt.BulkLoad(ctx, bulkSource)
+				return err
+			})
+			require.NoError(err)
+			require.Equal(uint64(tc), loaded)
+
+			iter, err := ds.SnapshotReader(lastRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+				ResourceType: testfixtures.DocumentNS.Name,
+			})
+			require.NoError(err)
+			defer iter.Close()
+
+			seen := make(map[string]struct{}, tc)
+			for found := iter.Next(); found != nil; found = iter.Next() {
+				require.Equal("viewer", found.ResourceAndRelation.Relation)
+				require.Equal(found.ResourceAndRelation.ObjectId, found.Subject.ObjectId)
+				seen[found.ResourceAndRelation.ObjectId] = struct{}{}
+			}
+			require.NoError(iter.Err())
+			require.Len(seen, tc)
+		})
+	}
+}
+
+// BulkUploadErrorsTest tests that BulkLoad fails the transaction when asked to load a
+// relationship which already exists.
+func BulkUploadErrorsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer rawDS.Close()
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, testfixtures.NewBulkTupleGenerator(
+			testfixtures.DocumentNS.Name,
+			"viewer",
+			testfixtures.UserNS.Name,
+			10,
+		))
+		return err
+	})
+	require.NoError(err)
+
+	// Loading an overlapping set of relationships must fail and write nothing.
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, testfixtures.NewBulkTupleGenerator(
+			testfixtures.DocumentNS.Name,
+			"viewer",
+			testfixtures.UserNS.Name,
+			20,
+		))
+		return err
+	})
+	require.Error(err)
+
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	iter, err := ds.SnapshotReader(headRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testfixtures.DocumentNS.Name,
+	})
+	require.NoError(err)
+	defer iter.Close()
+
+	var count int
+	for found := iter.Next(); found != nil; found = iter.Next() {
+		count++
+	}
+	require.NoError(iter.Err())
+	require.Equal(10, count)
+}
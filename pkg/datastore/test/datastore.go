@@ -52,14 +52,20 @@ func AllExceptWatch(t *testing.T, tester DatastoreTester) {
 	t.Run("TestWriteDeleteWrite", func(t *testing.T) { WriteDeleteWriteTest(t, tester) })
 	t.Run("TestCreateAlreadyExisting", func(t *testing.T) { CreateAlreadyExistingTest(t, tester) })
 	t.Run("TestTouchAlreadyExisting", func(t *testing.T) { TouchAlreadyExistingTest(t, tester) })
+	t.Run("TestExpiredRelationships", func(t *testing.T) { ExpiredRelationshipsTest(t, tester) })
+	t.Run("TestExpirationAtRevision", func(t *testing.T) { ExpirationAtRevisionTest(t, tester) })
 	t.Run("TestUsersets", func(t *testing.T) { UsersetsTest(t, tester) })
 	t.Run("TestMultipleReadsInRWT", func(t *testing.T) { MultipleReadsInRWTTest(t, tester) })
 	t.Run("TestConcurrentWriteSerialization", func(t *testing.T) { ConcurrentWriteSerializationTest(t, tester) })
 
+	t.Run("TestBulkUpload", func(t *testing.T) { BulkUploadTest(t, tester) })
+	t.Run("TestBulkUploadErrors", func(t *testing.T) { BulkUploadErrorsTest(t, tester) })
+
 	t.Run("TestOrdering", func(t *testing.T) { OrderingTest(t, tester) })
 	t.Run("TestLimit", func(t *testing.T) { LimitTest(t, tester) })
 	t.Run("TestOrderedLimit", func(t *testing.T) { OrderedLimitTest(t, tester) })
 	t.Run("TestResume", func(t *testing.T) { ResumeTest(t, tester) })
+	t.Run("TestReverseQueryResume", func(t *testing.T) { ReverseQueryResumeTest(t, tester) })
 	t.Run("TestCursorErrors", func(t *testing.T) { CursorErrorsTest(t, tester) })
 
 	t.Run("TestRevisionQuantization", func(t *testing.T) { RevisionQuantizationTest(t, tester) })
@@ -82,6 +88,7 @@ func All(t *testing.T, tester DatastoreTester) {
 	t.Run("TestWatch", func(t *testing.T) { WatchTest(t, tester) })
 	t.Run("TestWatchCancel", func(t *testing.T) { WatchCancelTest(t, tester) })
 	t.Run("TestCaveatedRelationshipWatch", func(t *testing.T) { CaveatedRelationshipWatchTest(t, tester) })
+	t.Run("TestWatchSchema", func(t *testing.T) { WatchSchemaTest(t, tester) })
 }
 
 var testResourceNS = namespace.Namespace(
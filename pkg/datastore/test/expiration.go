@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+func makeExpiringTestTuple(resourceID, userID string, expiration time.Time) *core.RelationTuple {
+	tpl := makeTestTuple(resourceID, userID)
+	tpl.OptionalExpirationTime = timestamppb.New(expiration)
+	return tpl
+}
+
+// ExpiredRelationshipsTest tests that expired relationships are not returned by reads and
+// do not prevent the same relationship from being created again.
+func ExpiredRelationshipsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+	tRequire := testfixtures.TupleChecker{Require: require, DS: ds}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := makeExpiringTestTuple("foo", "tom", now.Add(-1*time.Hour))
+	unexpired := makeExpiringTestTuple("foo", "sarah", now.Add(1*time.Hour))
+	permanent := makeTestTuple("foo", "fred")
+
+	revision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, expired, unexpired, permanent)
+	require.NoError(err)
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+	tRequire.VerifyIteratorResults(iter, unexpired, permanent)
+
+	iter, err = ds.SnapshotReader(revision).ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+		SubjectType:        testUserNamespace,
+		OptionalSubjectIds: []string{"tom"},
+	})
+	require.NoError(err)
+	tRequire.VerifyIteratorResults(iter)
+
+	// Creating the expired relationship again must succeed.
+	recreated := makeTestTuple("foo", "tom")
+	revision, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, recreated)
+	require.NoError(err)
+
+	iter, err = ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+	tRequire.VerifyIteratorResults(iter, recreated, unexpired, permanent)
+}
+
+// ExpirationAtRevisionTest tests that expiration is evaluated at the time of the revision being
+// read, rather than at the time of the read.
+func ExpirationAtRevisionTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+	tRequire := testfixtures.TupleChecker{Require: require, DS: ds}
+
+	expiration := time.Now().UTC().Add(1 * time.Second)
+	expiring := makeExpiringTestTuple("foo", "tom", expiration)
+	beforeExpiration, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, expiring)
+	require.NoError(err)
+
+	time.Sleep(time.Until(expiration) + 100*time.Millisecond)
+
+	permanent := makeTestTuple("foo", "fred")
+	afterExpiration, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, permanent)
+	require.NoError(err)
+
+	// The relationship remains visible at the revision from before it expired.
+	iter, err := ds.SnapshotReader(beforeExpiration).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+	tRequire.VerifyIteratorResults(iter, expiring)
+
+	iter, err = ds.SnapshotReader(afterExpiration).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+	tRequire.VerifyIteratorResults(iter, permanent)
+}
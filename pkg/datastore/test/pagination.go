@@ -263,6 +263,78 @@ func ResumeTest(t *testing.T, tester DatastoreTester) {
 	}
 }
 
+func ReverseQueryResumeTest(t *testing.T, tester DatastoreTester) {
+	testCases := []struct {
+		subjectType  string
+		resourceType string
+		relation     string
+	}{
+		{testfixtures.UserNS.Name, testfixtures.FolderNS.Name, "viewer"},
+		{testfixtures.UserNS.Name, testfixtures.DocumentNS.Name, "owner"},
+		{testfixtures.FolderNS.Name, testfixtures.DocumentNS.Name, "parent"},
+	}
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(t, err)
+
+	ds, rev := testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+	tRequire := testfixtures.TupleChecker{Require: require.New(t), DS: ds}
+
+	for _, tc := range testCases {
+		expected := lo.Filter(sortedStandardData(tc.resourceType, options.ByResource), func(item *core.RelationTuple, _ int) bool {
+			return item.ResourceAndRelation.Relation == tc.relation && item.Subject.Namespace == tc.subjectType
+		})
+
+		for batchSize := 1; batchSize <= len(expected); batchSize++ {
+			testLimit := uint64(batchSize)
+
+			t.Run(fmt.Sprintf("%s-%s#%s-batches-%d", tc.subjectType, tc.resourceType, tc.relation, batchSize), func(t *testing.T) {
+				require := require.New(t)
+				ctx := context.Background()
+
+				foreachTxType(ctx, ds, rev, func(reader datastore.Reader) {
+					subjectsFilter := datastore.SubjectsFilter{SubjectType: tc.subjectType}
+					resRelation := options.WithResRelation(&options.ResourceRelation{
+						Namespace: tc.resourceType,
+						Relation:  tc.relation,
+					})
+
+					// Test that if you ask for resume without an order we error
+					_, err := reader.ReverseQueryRelationships(ctx, subjectsFilter, resRelation,
+						options.WithReverseLimit(&testLimit), options.WithAfterForReverse(&core.RelationTuple{}))
+					require.ErrorIs(err, datastore.ErrCursorsWithoutSorting)
+
+					cursor := options.Cursor(nil)
+					for offset := 0; offset <= len(expected); offset += batchSize {
+						iter, err := reader.ReverseQueryRelationships(ctx, subjectsFilter, resRelation,
+							options.WithSortForReverse(options.ByResource),
+							options.WithReverseLimit(&testLimit),
+							options.WithAfterForReverse(cursor),
+						)
+						require.NoError(err)
+						defer iter.Close()
+
+						upperBound := offset + batchSize
+						if upperBound > len(expected) {
+							upperBound = len(expected)
+						}
+						tRequire.VerifyOrderedIteratorResults(iter, expected[offset:upperBound]...)
+
+						cursor, err = iter.Cursor()
+						if upperBound-offset > 0 {
+							require.NotEmpty(cursor)
+							require.NoError(err)
+						} else {
+							require.Empty(cursor)
+							require.ErrorIs(err, datastore.ErrCursorEmpty)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
 func CursorErrorsTest(t *testing.T, tester DatastoreTester) {
 	testCases := []struct {
 		order              options.SortOrder
@@ -215,3 +215,90 @@ func WatchCancelTest(t *testing.T, tester DatastoreTester) {
 		}
 	}
 }
+
+// WatchSchemaTest tests whether or not namespace and caveat changes are
+// reported by watch for a particular datastore.
+func WatchSchemaTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	skipIfNotCaveatStorer(t, ds)
+
+	startWatchRevision := setupDatastore(ds, require)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, errchan := ds.Watch(ctx, startWatchRevision)
+	require.Zero(len(errchan))
+
+	coreCaveat := createCoreCaveat(t)
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx, testNamespace); err != nil {
+			return err
+		}
+		return rwt.WriteCaveats(ctx, []*core.CaveatDefinition{coreCaveat})
+	})
+	require.NoError(err)
+
+	written := waitForSchemaChanges(t, changes, errchan, func(changed, _ *strset.Set) bool {
+		return changed.Has(testNamespace.Name) && changed.Has(coreCaveat.Name)
+	})
+	for _, def := range written {
+		switch def := def.(type) {
+		case *core.NamespaceDefinition:
+			require.Empty(cmp.Diff(testNamespace, def, protocmp.Transform()))
+		case *core.CaveatDefinition:
+			require.Empty(cmp.Diff(coreCaveat, def, protocmp.Transform()))
+		}
+	}
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.DeleteNamespaces(ctx, testNamespace.Name); err != nil {
+			return err
+		}
+		return rwt.DeleteCaveats(ctx, []string{coreCaveat.Name})
+	})
+	require.NoError(err)
+
+	waitForSchemaChanges(t, changes, errchan, func(_, deleted *strset.Set) bool {
+		return deleted.Has(testNamespace.Name) && deleted.Has(coreCaveat.Name)
+	})
+}
+
+// waitForSchemaChanges reads from the watch until done returns true for the names of the
+// definitions changed and deleted so far, returning the changed definitions.
+func waitForSchemaChanges(
+	t *testing.T,
+	changes <-chan *datastore.RevisionChanges,
+	errchan <-chan error,
+	done func(changed, deleted *strset.Set) bool,
+) []datastore.SchemaDefinition {
+	changed := strset.New()
+	deleted := strset.New()
+
+	var definitions []datastore.SchemaDefinition
+	changeWait := time.NewTimer(waitForChangesTimeout)
+	defer changeWait.Stop()
+
+	for !done(changed, deleted) {
+		select {
+		case change, ok := <-changes:
+			require.True(t, ok, "watch channel closed unexpectedly")
+			for _, def := range change.ChangedDefinitions {
+				changed.Add(def.GetName())
+				definitions = append(definitions, def)
+			}
+			deleted.Add(change.DeletedNamespaces...)
+			deleted.Add(change.DeletedCaveats...)
+		case err := <-errchan:
+			require.Failf(t, "error received from watch", "%v", err)
+		case <-changeWait.C:
+			require.Fail(t, "timed out waiting for schema changes")
+		}
+	}
+
+	return definitions
+}
@@ -140,3 +140,51 @@ definition document {
 
 	shutdown()
 }
+
+func TestRunDeveloperRequest(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	resp := RunDeveloperRequest(context.Background(), &devinterface.DeveloperRequest{
+		Context: &devinterface.RequestContext{
+			Schema: `definition user {}
+
+definition document {
+	relation viewer: user
+}`,
+			Relationships: []*core.RelationTuple{
+				tuple.MustParse("document:somedoc#viewer@user:someuser"),
+			},
+		},
+		Operations: []*devinterface.Operation{
+			{
+				CheckParameters: &devinterface.CheckOperationParameters{
+					Resource: tuple.ParseONR("document:somedoc#viewer"),
+					Subject:  tuple.ParseSubjectONR("user:someuser"),
+				},
+			},
+			{
+				FormatSchemaParameters: &devinterface.FormatSchemaParameters{},
+			},
+		},
+	})
+
+	require.Empty(t, resp.InternalError)
+	require.Nil(t, resp.DeveloperErrors)
+	require.Len(t, resp.OperationsResults.Results, 2)
+	require.Equal(t, devinterface.CheckOperationsResult_MEMBER, resp.OperationsResults.Results[0].CheckResult.Membership)
+	require.Equal(t, "definition user {}\n\ndefinition document {\n\trelation viewer: user\n}", resp.OperationsResults.Results[1].FormatSchemaResult.FormattedSchema)
+}
+
+func TestRunDeveloperRequestInputErrors(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	resp := RunDeveloperRequest(context.Background(), &devinterface.DeveloperRequest{
+		Context: &devinterface.RequestContext{
+			Schema: `definition user {`,
+		},
+	})
+
+	require.Empty(t, resp.InternalError)
+	require.Len(t, resp.DeveloperErrors.InputErrors, 1)
+	require.Equal(t, devinterface.DeveloperError_SCHEMA, resp.DeveloperErrors.InputErrors[0].Source)
+}
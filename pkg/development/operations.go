@@ -1,13 +1,10 @@
-//go:build wasm
-// +build wasm
-
-package main
+package development
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
-	"github.com/authzed/spicedb/pkg/development"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
@@ -15,7 +12,55 @@ import (
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
-func runOperation(devContext *development.DevContext, operation *devinterface.Operation) (*devinterface.OperationResult, error) {
+// RunDeveloperRequest builds the developer context for the request and runs each of its operations,
+// returning the results or the errors encountered in the form of a DeveloperResponse. It is shared
+// by the WebAssembly development package and the developer service.
+func RunDeveloperRequest(ctx context.Context, devRequest *devinterface.DeveloperRequest) *devinterface.DeveloperResponse {
+	if devRequest.Context == nil {
+		return &devinterface.DeveloperResponse{
+			InternalError: "missing required context",
+		}
+	}
+
+	devContext, devErrors, err := NewDevContext(ctx, devRequest.Context)
+	if err != nil {
+		return &devinterface.DeveloperResponse{
+			InternalError: err.Error(),
+		}
+	}
+
+	if devErrors != nil && len(devErrors.InputErrors) > 0 {
+		return &devinterface.DeveloperResponse{
+			DeveloperErrors: &devinterface.DeveloperErrors{
+				InputErrors: devErrors.InputErrors,
+			},
+		}
+	}
+	defer devContext.Dispose()
+
+	results := make(map[uint64]*devinterface.OperationResult, len(devRequest.Operations))
+	for index, op := range devRequest.Operations {
+		result, err := RunOperation(devContext, op)
+		if err != nil {
+			return &devinterface.DeveloperResponse{
+				InternalError: err.Error(),
+			}
+		}
+
+		results[uint64(index)] = result
+	}
+
+	return &devinterface.DeveloperResponse{
+		OperationsResults: &devinterface.OperationsResults{
+			Results: results,
+		},
+	}
+}
+
+// RunOperation runs a single developer operation against the developer context. Errors in the
+// operation's input are returned in its result; the error is returned only if an internal error
+// occurred.
+func RunOperation(devContext *DevContext, operation *devinterface.Operation) (*devinterface.OperationResult, error) {
 	switch {
 	case operation.FormatSchemaParameters != nil:
 		formatted, _, err := generator.GenerateSchema(devContext.CompiledSchema.OrderedDefinitions)
@@ -36,14 +81,14 @@ func runOperation(devContext *development.DevContext, operation *devinterface.Op
 			caveatContext = operation.CheckParameters.CaveatContext.AsMap()
 		}
 
-		cr, err := development.RunCheck(
+		cr, err := RunCheck(
 			devContext,
 			operation.CheckParameters.Resource,
 			operation.CheckParameters.Subject,
 			caveatContext,
 		)
 		if err != nil {
-			devErr, wireErr := development.DistinguishGraphError(
+			devErr, wireErr := DistinguishGraphError(
 				devContext,
 				err,
 				devinterface.DeveloperError_CHECK_WATCH,
@@ -83,7 +128,7 @@ func runOperation(devContext *development.DevContext, operation *devinterface.Op
 		}, nil
 
 	case operation.AssertionsParameters != nil:
-		assertions, devErr := development.ParseAssertionsYAML(operation.AssertionsParameters.AssertionsYaml)
+		assertions, devErr := ParseAssertionsYAML(operation.AssertionsParameters.AssertionsYaml)
 		if devErr != nil {
 			return &devinterface.OperationResult{
 				AssertionsResult: &devinterface.RunAssertionsResult{
@@ -92,7 +137,7 @@ func runOperation(devContext *development.DevContext, operation *devinterface.Op
 			}, nil
 		}
 
-		validationErrors, err := development.RunAllAssertions(devContext, assertions)
+		validationErrors, err := RunAllAssertions(devContext, assertions)
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +149,7 @@ func runOperation(devContext *development.DevContext, operation *devinterface.Op
 		}, nil
 
 	case operation.ValidationParameters != nil:
-		validation, devErr := development.ParseExpectedRelationsYAML(operation.ValidationParameters.ValidationYaml)
+		validation, devErr := ParseExpectedRelationsYAML(operation.ValidationParameters.ValidationYaml)
 		if devErr != nil {
 			return &devinterface.OperationResult{
 				ValidationResult: &devinterface.RunValidationResult{
@@ -113,14 +158,14 @@ func runOperation(devContext *development.DevContext, operation *devinterface.Op
 			}, nil
 		}
 
-		membershipSet, validationErrors, err := development.RunValidation(devContext, validation)
+		membershipSet, validationErrors, err := RunValidation(devContext, validation)
 		if err != nil {
 			return nil, err
 		}
 
 		updatedValidationYaml := ""
 		if membershipSet != nil {
-			generatedValidationYaml, gerr := development.GenerateValidation(membershipSet)
+			generatedValidationYaml, gerr := GenerateValidation(membershipSet)
 			if gerr != nil {
 				return nil, gerr
 			}
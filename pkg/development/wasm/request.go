@@ -41,36 +41,7 @@ func runDeveloperRequest(this js.Value, args []js.Value) any {
 		return respErr(fmt.Errorf("could not decode developer request: %w", err))
 	}
 
-	if devRequest.Context == nil {
-		return respErr(fmt.Errorf("missing required context"))
-	}
-
-	// Construct the developer context.
-	devContext, devErrors, err := development.NewDevContext(context.Background(), devRequest.Context)
-	if err != nil {
-		return respErr(err)
-	}
-
-	if devErrors != nil && len(devErrors.InputErrors) > 0 {
-		return respInputErr(devErrors.InputErrors)
-	}
-
-	// Run operations.
-	results := make(map[uint64]*devinterface.OperationResult, len(devRequest.Operations))
-	for index, op := range devRequest.Operations {
-		result, err := runOperation(devContext, op)
-		if err != nil {
-			return respErr(err)
-		}
-
-		results[uint64(index)] = result
-	}
-
-	return encode(&devinterface.DeveloperResponse{
-		OperationsResults: &devinterface.OperationsResults{
-			Results: results,
-		},
-	})
+	return encode(development.RunDeveloperRequest(context.Background(), devRequest))
 }
 
 func encode(response *devinterface.DeveloperResponse) js.Value {
@@ -87,11 +58,3 @@ func respErr(err error) js.Value {
 		InternalError: err.Error(),
 	})
 }
-
-func respInputErr(inputErrors []*devinterface.DeveloperError) js.Value {
-	return encode(&devinterface.DeveloperResponse{
-		DeveloperErrors: &devinterface.DeveloperErrors{
-			InputErrors: inputErrors,
-		},
-	})
-}
@@ -153,6 +153,12 @@ func AllowedPublicNamespaceWithCaveat(namespaceName string, withCaveat *core.All
 	}
 }
 
+// WithExpiration marks the allowed relation as requiring the expiration trait, returning it.
+func WithExpiration(allowedRelation *core.AllowedRelation) *core.AllowedRelation {
+	allowedRelation.RequiredExpiration = &core.ExpirationTrait{}
+	return allowedRelation
+}
+
 // RelationReference creates a relation reference.
 func RelationReference(namespaceName string, relationName string) *core.RelationReference {
 	return &core.RelationReference{
@@ -233,6 +239,26 @@ func TupleToUserset(tuplesetRelation, usersetRelation string) *core.SetOperation
 	}
 }
 
+// FunctionedTupleToUserset creates a child which first loads all tuples with the specific relation,
+// and then applies the function over the usersets found by following a relation on those loaded
+// tuples: FUNCTION_ANY unions them, while FUNCTION_ALL intersects them.
+func FunctionedTupleToUserset(tuplesetRelation string, function core.FunctionedTupleToUserset_Function, usersetRelation string) *core.SetOperation_Child {
+	return &core.SetOperation_Child{
+		ChildType: &core.SetOperation_Child_FunctionedTupleToUserset{
+			FunctionedTupleToUserset: &core.FunctionedTupleToUserset{
+				Function: function,
+				Tupleset: &core.FunctionedTupleToUserset_Tupleset{
+					Relation: tuplesetRelation,
+				},
+				ComputedUserset: &core.ComputedUserset{
+					Relation: usersetRelation,
+					Object:   core.ComputedUserset_TUPLE_USERSET_OBJECT,
+				},
+			},
+		},
+	}
+}
+
 // Rewrite wraps a rewrite as a set operation child of another rewrite.
 func Rewrite(rewrite *core.UsersetRewrite) *core.SetOperation_Child {
 	return &core.SetOperation_Child{
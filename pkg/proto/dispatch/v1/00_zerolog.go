@@ -43,7 +43,7 @@ func (lr *DispatchLookupRequest) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("object", tuple.StringRR(lr.ObjectRelation))
 	e.Str("subject", tuple.StringONR(lr.Subject))
 	e.Interface("context", lr.Context)
-	e.Uint32("limit", lr.Limit)
+	e.Uint32("limit", lr.OptionalLimit)
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
@@ -76,6 +76,11 @@ func (cr *DispatchLookupResponse) MarshalZerologObject(e *zerolog.Event) {
 	e.Object("metadata", cr.Metadata)
 }
 
+// MarshalZerologObject implements zerolog object marshalling.
+func (cr *DispatchLookupResourcesResponse) MarshalZerologObject(e *zerolog.Event) {
+	e.Object("metadata", cr.Metadata)
+}
+
 // MarshalZerologObject implements zerolog object marshalling.
 func (cs *DispatchLookupSubjectsResponse) MarshalZerologObject(e *zerolog.Event) {
 	e.Object("metadata", cs.Metadata)
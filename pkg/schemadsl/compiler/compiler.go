@@ -10,6 +10,7 @@ import (
 	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 	"github.com/authzed/spicedb/pkg/schemadsl/parser"
+	"github.com/authzed/spicedb/pkg/util"
 )
 
 // InputSchema defines the input for a Compile.
@@ -41,20 +42,81 @@ type CompiledSchema struct {
 	OrderedDefinitions []SchemaDefinition
 }
 
+// Option is an option for Compile.
+type Option func(*compilation)
+
+// WithImportResolver sets the resolver used to load the files imported by the schema. Without
+// a resolver, schemas containing imports fail to compile.
+func WithImportResolver(resolver ImportResolver) Option {
+	return func(c *compilation) {
+		c.resolver = resolver
+	}
+}
+
 // Compile compilers the input schema into a set of namespace definition protos.
-func Compile(schema InputSchema, objectTypePrefix *string) (*CompiledSchema, error) {
+func Compile(schema InputSchema, objectTypePrefix *string, opts ...Option) (*CompiledSchema, error) {
+	c := &compilation{
+		objectTypePrefix: objectTypePrefix,
+		compiled:         &CompiledSchema{},
+		names:            util.NewSet[string](),
+		included:         util.NewSet[input.Source](),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.compileFile(schema); err != nil {
+		return nil, err
+	}
+
+	return c.compiled, nil
+}
+
+// compilation holds the state of a Compile call across the imported files.
+type compilation struct {
+	objectTypePrefix *string
+	resolver         ImportResolver
+
+	compiled *CompiledSchema
+	names    *util.Set[string]
+
+	// included holds the files already compiled, and importing the chain of files currently
+	// being imported.
+	included  *util.Set[input.Source]
+	importing []input.Source
+}
+
+// compileFile compiles the schema, after the schemas it imports which have not yet been
+// compiled.
+func (c *compilation) compileFile(schema InputSchema) error {
+	c.included.Add(schema.Source)
+	c.importing = append(c.importing, schema.Source)
+	defer func() {
+		c.importing = c.importing[:len(c.importing)-1]
+	}()
+
 	mapper := newPositionMapper(schema)
 	root := parser.Parse(createAstNode, schema.Source, schema.SchemaString).(*dslNode)
 	errs := root.FindAll(dslshape.NodeTypeError)
 	if len(errs) > 0 {
-		err := errorNodeToError(errs[0], mapper)
-		return nil, err
+		return errorNodeToError(errs[0], mapper)
+	}
+
+	for _, importNode := range root.GetChildren() {
+		if importNode.GetType() != dslshape.NodeTypeImport {
+			continue
+		}
+
+		if err := c.compileImport(schema.Source, importNode, mapper); err != nil {
+			return err
+		}
 	}
 
 	compiled, err := translate(translationContext{
-		objectTypePrefix: objectTypePrefix,
+		objectTypePrefix: c.objectTypePrefix,
 		mapper:           mapper,
 		schemaString:     schema.SchemaString,
+		names:            c.names,
 	}, root)
 	if err != nil {
 		var errorWithNode errorWithNode
@@ -62,10 +124,49 @@ func Compile(schema InputSchema, objectTypePrefix *string) (*CompiledSchema, err
 			err = toContextError(errorWithNode.error.Error(), errorWithNode.errorSourceCode, errorWithNode.node, mapper)
 		}
 
-		return nil, err
+		return err
+	}
+
+	c.compiled.ObjectDefinitions = append(c.compiled.ObjectDefinitions, compiled.ObjectDefinitions...)
+	c.compiled.CaveatDefinitions = append(c.compiled.CaveatDefinitions, compiled.CaveatDefinitions...)
+	c.compiled.OrderedDefinitions = append(c.compiled.OrderedDefinitions, compiled.OrderedDefinitions...)
+	return nil
+}
+
+func (c *compilation) compileImport(importingSource input.Source, importNode *dslNode, mapper input.PositionMapper) error {
+	importPath, err := importNode.GetString(dslshape.NodeImportPredicatePath)
+	if err != nil {
+		return fmt.Errorf("missing path for import: %w", err)
+	}
+
+	if c.resolver == nil {
+		return toContextError("imports are not supported when compiling this schema", importPath, importNode, mapper)
+	}
+
+	imported, err := c.resolver.ResolveImport(importingSource, importPath)
+	if err != nil {
+		return toContextError(fmt.Sprintf("could not import `%s`: %s", importPath, err), importPath, importNode, mapper)
+	}
+
+	for i, source := range c.importing {
+		if source != imported.Source {
+			continue
+		}
+
+		cycle := ""
+		for _, importing := range c.importing[i:] {
+			cycle += fmt.Sprintf("`%s` -> ", importing)
+		}
+		cycle += fmt.Sprintf("`%s`", imported.Source)
+		return toContextError("import cycle found: "+cycle, importPath, importNode, mapper)
+	}
+
+	// Files imported more than once are only compiled the first time.
+	if c.included.Has(imported.Source) {
+		return nil
 	}
 
-	return compiled, nil
+	return c.compileFile(imported)
 }
 
 func errorNodeToError(node *dslNode, mapper input.PositionMapper) error {
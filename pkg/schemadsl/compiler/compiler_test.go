@@ -190,6 +190,23 @@ func TestCompile(t *testing.T) {
 				),
 			},
 		},
+		{
+			"relation with expiration",
+			&someTenant,
+			`definition simple {
+				relation viewer: user with expiration | user:* with somecaveat and expiration
+			}`,
+			"",
+			[]SchemaDefinition{
+				namespace.Namespace("sometenant/simple",
+					namespace.MustRelation("viewer", nil,
+						namespace.WithExpiration(namespace.AllowedRelation("sometenant/user", "...")),
+						namespace.WithExpiration(namespace.AllowedPublicNamespaceWithCaveat("sometenant/user",
+							namespace.AllowedCaveat("somecaveat"))),
+					),
+				),
+			},
+		},
 		{
 			"simple permission",
 			&someTenant,
@@ -343,6 +360,33 @@ func TestCompile(t *testing.T) {
 				),
 			},
 		},
+		{
+			"arrow function permissions",
+			&someTenant,
+			`definition arrowed {
+				permission foos = bars.any(bazs) & bars.all(mehs)
+			}`,
+			"",
+			[]SchemaDefinition{
+				namespace.Namespace("sometenant/arrowed",
+					namespace.MustRelation("foos",
+						namespace.Intersection(
+							namespace.FunctionedTupleToUserset("bars", core.FunctionedTupleToUserset_FUNCTION_ANY, "bazs"),
+							namespace.FunctionedTupleToUserset("bars", core.FunctionedTupleToUserset_FUNCTION_ALL, "mehs"),
+						),
+					),
+				),
+			},
+		},
+		{
+			"unknown arrow function",
+			&someTenant,
+			`definition arrowed {
+				permission foos = bars.some(bazs)
+			}`,
+			"parse error in `unknown arrow function`, line 2, column 32: Expected function name `any` or `all` for arrow, found: some",
+			[]SchemaDefinition{},
+		},
 
 		{
 			"multiarrow permission",
@@ -0,0 +1,63 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// ImportResolver loads the schema files referenced by `import` statements.
+type ImportResolver interface {
+	// ResolveImport returns the schema found at the import path, as written in the schema
+	// whose source is given. The Source of the returned schema identifies the file in errors
+	// and must be the same for every import of the same file.
+	ResolveImport(importingSource input.Source, importPath string) (InputSchema, error)
+}
+
+// FilesystemImportResolver resolves import paths against the filesystem, relative to the
+// directory of the importing file. The Source of the root schema must be its file path.
+type FilesystemImportResolver struct{}
+
+// ResolveImport implements ImportResolver.
+func (FilesystemImportResolver) ResolveImport(importingSource input.Source, importPath string) (InputSchema, error) {
+	resolved := filepath.FromSlash(importPath)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(string(importingSource)), resolved)
+	}
+
+	contents, err := os.ReadFile(resolved)
+	if err != nil {
+		return InputSchema{}, err
+	}
+
+	return InputSchema{
+		Source:       input.Source(filepath.Clean(resolved)),
+		SchemaString: string(contents),
+	}, nil
+}
+
+// MapImportResolver resolves import paths against a map of slash-separated file paths to their
+// contents, relative to the path of the importing file. It is used where there is no
+// filesystem, such as in the development package compiled to WASM.
+type MapImportResolver map[string]string
+
+// ResolveImport implements ImportResolver.
+func (mir MapImportResolver) ResolveImport(importingSource input.Source, importPath string) (InputSchema, error) {
+	resolved := importPath
+	if !path.IsAbs(resolved) {
+		resolved = path.Join(path.Dir(string(importingSource)), resolved)
+	}
+
+	contents, ok := mir[resolved]
+	if !ok {
+		return InputSchema{}, fmt.Errorf("file `%s` not found", resolved)
+	}
+
+	return InputSchema{
+		Source:       input.Source(resolved),
+		SchemaString: contents,
+	}, nil
+}
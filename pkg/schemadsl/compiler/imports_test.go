@@ -0,0 +1,133 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestCompileWithImports(t *testing.T) {
+	files := MapImportResolver{
+		"common/user.zed": `definition user {}`,
+		"teams/group.zed": `
+			import "../common/user.zed"
+
+			definition group {
+				relation member: user | group#member
+			}`,
+		"cycle/a.zed":   `import "b.zed"`,
+		"cycle/b.zed":   `import "a.zed"`,
+		"broken.zed":    `definition broken {`,
+		"duplicate.zed": `definition document {}`,
+	}
+
+	tests := []struct {
+		name          string
+		schema        string
+		expectedNames []string
+		expectedError string
+	}{
+		{
+			"no imports",
+			`definition document {}`,
+			[]string{"document"},
+			"",
+		},
+		{
+			"nested and repeated imports",
+			`import "teams/group.zed"
+			import "common/user.zed"
+
+			definition document {
+				relation viewer: user | group#member
+			}`,
+			[]string{"user", "group", "document"},
+			"",
+		},
+		{
+			"missing file",
+			`import "common/missing.zed"`,
+			nil,
+			"parse error in `root.zed`, line 1, column 1: could not import `common/missing.zed`: file `common/missing.zed` not found",
+		},
+		{
+			"import cycle",
+			`import "cycle/a.zed"`,
+			nil,
+			"import cycle found: `cycle/a.zed` -> `cycle/b.zed` -> `cycle/a.zed`",
+		},
+		{
+			"parse error in imported file",
+			`import "broken.zed"`,
+			nil,
+			"parse error in `broken.zed`, line 1",
+		},
+		{
+			"duplicate definition across files",
+			`import "duplicate.zed"
+			definition document {}`,
+			nil,
+			"parse error in `root.zed`, line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			empty := ""
+			compiled, err := Compile(InputSchema{
+				Source:       input.Source("root.zed"),
+				SchemaString: tt.schema,
+			}, &empty, WithImportResolver(files))
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(compiled.OrderedDefinitions))
+			for _, def := range compiled.OrderedDefinitions {
+				names = append(names, def.GetName())
+			}
+			require.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestCompileImportWithoutResolver(t *testing.T) {
+	empty := ""
+	_, err := Compile(InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: `import "user.zed"`,
+	}, &empty)
+	require.ErrorContains(t, err, "imports are not supported")
+}
+
+func TestFilesystemImportResolver(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "common"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "user.zed"), []byte(`definition user {}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "group.zed"), []byte(`
+		import "user.zed"
+		definition group {
+			relation member: user
+		}`), 0o600))
+
+	empty := ""
+	compiled, err := Compile(InputSchema{
+		Source: input.Source(filepath.Join(dir, "schema.zed")),
+		SchemaString: `
+			import "common/group.zed"
+			definition document {
+				relation viewer: group#member
+			}`,
+	}, &empty, WithImportResolver(FilesystemImportResolver{}))
+	require.NoError(t, err)
+	require.Len(t, compiled.ObjectDefinitions, 3)
+	require.Equal(t, "user", compiled.ObjectDefinitions[0].Name)
+}
@@ -23,6 +23,9 @@ type translationContext struct {
 	objectTypePrefix *string
 	mapper           input.PositionMapper
 	schemaString     string
+
+	// names holds the names of the definitions and caveats found in all files compiled.
+	names *util.Set[string]
 }
 
 func (tctx translationContext) prefixedPath(definitionName string) (string, error) {
@@ -49,12 +52,19 @@ func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error)
 	var objectDefinitions []*core.NamespaceDefinition
 	var caveatDefinitions []*core.CaveatDefinition
 
-	names := util.NewSet[string]()
+	names := tctx.names
+	if names == nil {
+		names = util.NewSet[string]()
+	}
 
 	for _, definitionNode := range root.GetChildren() {
 		var definition SchemaDefinition
 
 		switch definitionNode.GetType() {
+		case dslshape.NodeTypeImport:
+			// Imports are compiled before the file importing them.
+			continue
+
 		case dslshape.NodeTypeCaveatDefinition:
 			def, err := translateCaveatDefinition(tctx, definitionNode)
 			if err != nil {
@@ -515,7 +525,23 @@ func translateExpressionOperationDirect(tctx translationContext, expressionOpNod
 			return nil, err
 		}
 
-		return namespace.TupleToUserset(tuplesetRelation, usersetRelation), nil
+		if !expressionOpNode.Has(dslshape.NodeArrowExpressionFunctionName) {
+			return namespace.TupleToUserset(tuplesetRelation, usersetRelation), nil
+		}
+
+		functionName, err := expressionOpNode.GetString(dslshape.NodeArrowExpressionFunctionName)
+		if err != nil {
+			return nil, err
+		}
+
+		switch functionName {
+		case "any":
+			return namespace.FunctionedTupleToUserset(tuplesetRelation, core.FunctionedTupleToUserset_FUNCTION_ANY, usersetRelation), nil
+		case "all":
+			return namespace.FunctionedTupleToUserset(tuplesetRelation, core.FunctionedTupleToUserset_FUNCTION_ALL, usersetRelation), nil
+		default:
+			return nil, expressionOpNode.Errorf("unknown function `%s` for arrow", functionName)
+		}
 
 	case dslshape.NodeTypeUnionExpression:
 		fallthrough
@@ -585,6 +611,11 @@ func translateSpecificTypeReference(tctx translationContext, typeRefNode *dslNod
 			return nil, typeRefNode.Errorf("invalid caveat: %w", err)
 		}
 
+		err = addWithTraits(tctx, typeRefNode, ref)
+		if err != nil {
+			return nil, typeRefNode.Errorf("invalid trait: %w", err)
+		}
+
 		err = ref.Validate()
 		if err != nil {
 			return nil, typeRefNode.Errorf("invalid type relation: %w", err)
@@ -614,6 +645,11 @@ func translateSpecificTypeReference(tctx translationContext, typeRefNode *dslNod
 		return nil, typeRefNode.Errorf("invalid caveat: %w", err)
 	}
 
+	err = addWithTraits(tctx, typeRefNode, ref)
+	if err != nil {
+		return nil, typeRefNode.Errorf("invalid trait: %w", err)
+	}
+
 	err = ref.Validate()
 	if err != nil {
 		return nil, typeRefNode.Errorf("invalid type relation: %w", err)
@@ -643,3 +679,21 @@ func addWithCaveats(_ translationContext, typeRefNode *dslNode, ref *core.Allowe
 	}
 	return nil
 }
+
+func addWithTraits(_ translationContext, typeRefNode *dslNode, ref *core.AllowedRelation) error {
+	for _, traitNode := range typeRefNode.List(dslshape.NodeSpecificReferencePredicateTrait) {
+		name, err := traitNode.GetString(dslshape.NodeTraitPredicateTrait)
+		if err != nil {
+			return err
+		}
+
+		switch name {
+		case "expiration":
+			ref.RequiredExpiration = &core.ExpirationTrait{}
+
+		default:
+			return fmt.Errorf("unknown trait `%s`", name)
+		}
+	}
+	return nil
+}
@@ -35,6 +35,10 @@ const (
 	NodeTypeNilExpression // A nil keyword
 
 	NodeTypeCaveatTypeReference // A type reference for a caveat parameter.
+
+	NodeTypeTraitReference // A trait reference under a type.
+
+	NodeTypeImport // An import of another schema file.
 )
 
 const (
@@ -78,6 +82,13 @@ const (
 	// The name of the definition
 	NodeDefinitionPredicateName = "definition-name"
 
+	//
+	// NodeTypeImport
+	//
+
+	// The path of the imported file, as written in the schema.
+	NodeImportPredicatePath = "import-path"
+
 	//
 	// NodeTypeCaveatDefinition
 	//
@@ -155,6 +166,9 @@ const (
 	// A caveat under a type reference.
 	NodeSpecificReferencePredicateCaveat = "caveat"
 
+	// A trait under a type reference.
+	NodeSpecificReferencePredicateTrait = "trait"
+
 	//
 	// NodeTypeCaveatReference
 	//
@@ -162,6 +176,13 @@ const (
 	// The caveat name under the caveat.
 	NodeCaveatPredicateCaveat = "caveat-name"
 
+	//
+	// NodeTypeTraitReference
+	//
+
+	// The name of the trait.
+	NodeTraitPredicateTrait = "trait-name"
+
 	//
 	// NodeTypePermission
 	//
@@ -181,4 +202,11 @@ const (
 	//
 	NodeExpressionPredicateLeftExpr  = "left-expr"
 	NodeExpressionPredicateRightExpr = "right-expr"
+
+	//
+	// NodeTypeArrowExpression
+	//
+
+	// The name of the function applied over the arrow, if any: `any` or `all`.
+	NodeArrowExpressionFunctionName = "function-name"
 )
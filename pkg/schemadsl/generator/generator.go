@@ -219,6 +219,13 @@ func (sg *sourceGenerator) emitAllowedRelation(allowedRelation *core.AllowedRela
 		sg.append(" with ")
 		sg.append(allowedRelation.RequiredCaveat.CaveatName)
 	}
+	if allowedRelation.GetRequiredExpiration() != nil {
+		if allowedRelation.GetRequiredCaveat() != nil {
+			sg.append(" and expiration")
+		} else {
+			sg.append(" with expiration")
+		}
+	}
 }
 
 func (sg *sourceGenerator) emitRewrite(rewrite *core.UsersetRewrite) {
@@ -286,6 +293,20 @@ func (sg *sourceGenerator) emitSetOpChild(setOpChild *core.SetOperation_Child) {
 		sg.append(child.TupleToUserset.Tupleset.Relation)
 		sg.append("->")
 		sg.append(child.TupleToUserset.ComputedUserset.Relation)
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		sg.append(child.FunctionedTupleToUserset.Tupleset.Relation)
+		switch child.FunctionedTupleToUserset.Function {
+		case core.FunctionedTupleToUserset_FUNCTION_ANY:
+			sg.append(".any(")
+		case core.FunctionedTupleToUserset_FUNCTION_ALL:
+			sg.append(".all(")
+		default:
+			sg.appendIssue("unknown function for arrow")
+			return
+		}
+		sg.append(child.FunctionedTupleToUserset.ComputedUserset.Relation)
+		sg.append(")")
 	}
 }
 
@@ -151,6 +151,24 @@ func TestGenerateNamespace(t *testing.T) {
 			),
 			`definition foos/test {
 	permission someperm = (rela - relb - rely->relz - nil) + relc
+}`,
+			true,
+		},
+		{
+			"permission with arrow functions",
+			namespace.Namespace("foos/test",
+				namespace.MustRelation("someperm", namespace.Union(
+					namespace.FunctionedTupleToUserset("rela", core.FunctionedTupleToUserset_FUNCTION_ANY, "relb"),
+					namespace.Rewrite(
+						namespace.Intersection(
+							namespace.FunctionedTupleToUserset("rela", core.FunctionedTupleToUserset_FUNCTION_ALL, "relb"),
+							namespace.ComputedUserset("relc"),
+						),
+					),
+				)),
+			),
+			`definition foos/test {
+	permission someperm = rela.any(relb) + (rela.all(relb) & relc)
 }`,
 			true,
 		},
@@ -180,6 +198,20 @@ func TestGenerateNamespace(t *testing.T) {
 			false,
 		},
 
+		{
+			"expiration",
+			namespace.Namespace("foos/document",
+				namespace.MustRelation("viewer", nil,
+					namespace.WithExpiration(namespace.AllowedRelation("foos/user", "...")),
+					namespace.WithExpiration(namespace.AllowedRelationWithCaveat("foos/group", "member", namespace.AllowedCaveat("somecaveat"))),
+				),
+			),
+			`definition foos/document {
+	relation viewer: foos/user with expiration | foos/group#member with somecaveat and expiration
+}`,
+			true,
+		},
+
 		{
 			"full example",
 			namespace.WithComment("foos/document", `/**
@@ -351,6 +383,17 @@ definition foos/document {
 	permission read = reader + writer + another
 	permission write = writer
 	permission minus = (rela - relb) - relc
+}`,
+		},
+		{
+			"arrow functions",
+			`definition foos/document {
+				relation parent: foos/folder
+				permission view = parent.all(view) + parent.any(edit)
+			}`,
+			`definition foos/document {
+	relation parent: foos/folder
+	permission view = parent.all(view) + parent.any(edit)
 }`,
 		},
 	}
@@ -2,6 +2,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 	"github.com/authzed/spicedb/pkg/schemadsl/lexer"
@@ -49,11 +51,15 @@ Loop:
 			break Loop
 		}
 
-		// The top level of the DSL is a set of definitions and caveats:
+		// The top level of the DSL is a set of imports, definitions and caveats:
+		// import "path/to/file.zed"
 		// definition foobar { ... }
 		// caveat somecaveat (...) { ... }
 
 		switch {
+		case p.isIdentifier("import"):
+			rootNode.Connect(dslshape.NodePredicateChild, p.consumeImport())
+
 		case p.isKeyword("definition"):
 			rootNode.Connect(dslshape.NodePredicateChild, p.consumeDefinition())
 
@@ -69,6 +75,38 @@ Loop:
 	return rootNode
 }
 
+// consumeImport attempts to consume an import of another schema file. `import` is not a
+// keyword, so that it remains usable as the name of a relation or permission.
+// ```import "path/to/file.zed"```
+func (p *sourceParser) consumeImport() AstNode {
+	importNode := p.startNode(dslshape.NodeTypeImport)
+	defer p.mustFinishNode()
+
+	// import ...
+	p.tryConsumeIdentifier("import")
+
+	pathToken, ok := p.consume(lexer.TokenTypeString)
+	if !ok {
+		return importNode
+	}
+
+	// Only single-line strings are accepted, with their quotes removed.
+	quoted := pathToken.Value
+	if len(quoted) < 2 || strings.HasPrefix(quoted, `"""`) || strings.HasPrefix(quoted, `'''`) {
+		p.emitErrorf("Expected single-line string for import path")
+		return importNode
+	}
+
+	importPath := quoted[1 : len(quoted)-1]
+	if importPath == "" {
+		p.emitErrorf("Import path cannot be empty")
+		return importNode
+	}
+
+	importNode.MustDecorate(dslshape.NodeImportPredicatePath, importPath)
+	return importNode
+}
+
 // consumeCaveat attempts to consume a single caveat definition.
 // ```caveat somecaveat(param1 type, param2 type) { ... }```
 func (p *sourceParser) consumeCaveat() AstNode {
@@ -319,38 +357,59 @@ func (p *sourceParser) consumeTypeReference() AstNode {
 	return refNode
 }
 
-// consumeSpecificType consumes an identifier as a specific type reference, with optional caveat.
+// consumeSpecificTypeWithCaveat consumes an identifier as a specific type reference, with optional
+// caveat and/or expiration trait.
+// ```sometype with somecaveat and expiration```
 func (p *sourceParser) consumeSpecificTypeWithCaveat() AstNode {
 	specificNode := p.consumeSpecificType()
 
-	caveatNode, ok := p.tryConsumeWithCaveat()
-	if ok {
+	withToken := p.currentToken
+	if !p.tryConsumeKeyword("with") {
+		return specificNode
+	}
+
+	if !p.isIdentifier("expiration") {
+		caveatNode := p.consumeCaveatReference(withToken)
 		specificNode.Connect(dslshape.NodeSpecificReferencePredicateCaveat, caveatNode)
+
+		if !p.tryConsumeIdentifier("and") {
+			return specificNode
+		}
 	}
 
+	specificNode.Connect(dslshape.NodeSpecificReferencePredicateTrait, p.consumeExpirationTrait())
 	return specificNode
 }
 
-// tryConsumeWithCaveat tries to consume a caveat `with` expression.
-func (p *sourceParser) tryConsumeWithCaveat() (AstNode, bool) {
-	if !p.isKeyword("with") {
-		return nil, false
-	}
-
-	caveatNode := p.startNode(dslshape.NodeTypeCaveatReference)
+// consumeCaveatReference consumes the caveat name of a `with` expression, whose `with` keyword
+// has already been consumed as the given token.
+func (p *sourceParser) consumeCaveatReference(withToken commentedLexeme) AstNode {
+	caveatNode := p.createNode(dslshape.NodeTypeCaveatReference)
+	p.decorateStartRuneAndComments(caveatNode, withToken)
+	p.nodes.push(caveatNode)
 	defer p.mustFinishNode()
 
-	if ok := p.consumeKeyword("with"); !ok {
-		return nil, ok
-	}
-
 	consumed, ok := p.consumeTypePath()
 	if !ok {
-		return caveatNode, true
+		return caveatNode
 	}
 
 	caveatNode.MustDecorate(dslshape.NodeCaveatPredicateCaveat, consumed)
-	return caveatNode, true
+	return caveatNode
+}
+
+// consumeExpirationTrait consumes the `expiration` trait of a `with` expression.
+func (p *sourceParser) consumeExpirationTrait() AstNode {
+	traitNode := p.startNode(dslshape.NodeTypeTraitReference)
+	defer p.mustFinishNode()
+
+	if !p.tryConsumeIdentifier("expiration") {
+		p.emitErrorf("Expected expiration, found token %v", p.currentToken.Kind)
+		return traitNode
+	}
+
+	traitNode.MustDecorate(dslshape.NodeTraitPredicateTrait, "expiration")
+	return traitNode
 }
 
 // consumeSpecificType consumes an identifier as a specific type reference.
@@ -473,20 +532,52 @@ func (p *sourceParser) tryConsumeComputeExpression(subTryExprFn tryParserFn, bin
 
 // tryConsumeArrowExpression attempts to consume an arrow expression.
 // ```foo->bar->baz->meh```
+// ```foo.all(bar)```
 func (p *sourceParser) tryConsumeArrowExpression() (AstNode, bool) {
 	rightNodeBuilder := func(leftNode AstNode, operatorToken lexer.Lexeme) (AstNode, bool) {
+		// Create the expression node representing the binary expression.
+		exprNode := p.createNode(dslshape.NodeTypeArrowExpression)
+		exprNode.Connect(dslshape.NodeExpressionPredicateLeftExpr, leftNode)
+
+		// A period is followed by the function applied over the arrow.
+		if operatorToken.Kind == lexer.TokenTypePeriod {
+			functionName, ok := p.consumeIdentifier()
+			if !ok {
+				return nil, false
+			}
+
+			if functionName != "any" && functionName != "all" {
+				p.emitErrorf("Expected function name `any` or `all` for arrow, found: %s", functionName)
+			}
+
+			exprNode.MustDecorate(dslshape.NodeArrowExpressionFunctionName, functionName)
+
+			if _, ok := p.consume(lexer.TokenTypeLeftParen); !ok {
+				return nil, false
+			}
+
+			rightNode, ok := p.tryConsumeIdentifierLiteral()
+			if !ok {
+				return nil, false
+			}
+
+			if _, ok := p.consume(lexer.TokenTypeRightParen); !ok {
+				return nil, false
+			}
+
+			exprNode.Connect(dslshape.NodeExpressionPredicateRightExpr, rightNode)
+			return exprNode, true
+		}
+
 		rightNode, ok := p.tryConsumeBaseExpression()
 		if !ok {
 			return nil, false
 		}
 
-		// Create the expression node representing the binary expression.
-		exprNode := p.createNode(dslshape.NodeTypeArrowExpression)
-		exprNode.Connect(dslshape.NodeExpressionPredicateLeftExpr, leftNode)
 		exprNode.Connect(dslshape.NodeExpressionPredicateRightExpr, rightNode)
 		return exprNode, true
 	}
-	return p.performLeftRecursiveParsing(p.tryConsumeIdentifierLiteral, rightNodeBuilder, nil, lexer.TokenTypeRightArrow)
+	return p.performLeftRecursiveParsing(p.tryConsumeIdentifierLiteral, rightNodeBuilder, nil, lexer.TokenTypeRightArrow, lexer.TokenTypePeriod)
 }
 
 // tryConsumeBaseExpression attempts to consume base compute expressions (identifiers, parenthesis).
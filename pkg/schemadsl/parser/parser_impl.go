@@ -168,6 +168,11 @@ func (p *sourceParser) isKeyword(keyword string) bool {
 	return p.isToken(lexer.TokenTypeKeyword) && p.currentToken.Value == keyword
 }
 
+// isIdentifier returns true if the current token is an identifier matching that given.
+func (p *sourceParser) isIdentifier(identifier string) bool {
+	return p.isToken(lexer.TokenTypeIdentifier) && p.currentToken.Value == identifier
+}
+
 // emitErrorf creates a new error node and attachs it as a child of the current
 // node.
 func (p *sourceParser) emitErrorf(format string, args ...interface{}) {
@@ -197,6 +202,16 @@ func (p *sourceParser) tryConsumeKeyword(keyword string) bool {
 	return true
 }
 
+// tryConsumeIdentifier attempts to consume an expected identifier token.
+func (p *sourceParser) tryConsumeIdentifier(identifier string) bool {
+	if !p.isIdentifier(identifier) {
+		return false
+	}
+
+	p.consumeToken()
+	return true
+}
+
 // cosumeIdentifier consumes an expected identifier token or adds an error node.
 func (p *sourceParser) consumeIdentifier() (string, bool) {
 	token, ok := p.tryConsume(lexer.TokenTypeIdentifier)
@@ -94,6 +94,8 @@ func TestParser(t *testing.T) {
 		{"basic definition test", "basic"},
 		{"doc comments test", "doccomments"},
 		{"arrow test", "arrow"},
+		{"arrow functions test", "arrowfunctions"},
+		{"broken arrow function test", "brokenarrowfunction"},
 		{"multiple definition test", "multidef"},
 		{"broken test", "broken"},
 		{"relation missing type test", "relation_missing_type"},
@@ -113,6 +115,10 @@ func TestParser(t *testing.T) {
 		{"empty caveat test", "emptycaveat"},
 		{"unclosed caveat test", "unclosedcaveat"},
 		{"invalid caveat expr test", "invalidcaveatexpr"},
+		{"expiration test", "expiration"},
+		{"broken expiration test", "brokenexpiration"},
+		{"import test", "import"},
+		{"broken import test", "brokenimport"},
 	}
 
 	for _, test := range parserTests {
@@ -0,0 +1,306 @@
+// Package testharness starts in-process SpiceDB servers for the tests of services using
+// SpiceDB, backed by any of the datastore engines.
+//
+// Reset replaces the data of the whole datastore, so tests sharing a server, or servers sharing a
+// database, must run serially. Parallel tests should each start their own server, which by default
+// has its own in-memory datastore.
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authzed "github.com/authzed/authzed-go/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/testserver"
+	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/util"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+// Server is a SpiceDB server running in the test process, reachable over an in-memory
+// connection.
+type Server struct {
+	ds       datastore.Datastore
+	conn     *grpc.ClientConn
+	client   *authzed.Client
+	snapshot *snapshot
+}
+
+// New starts a server for the test, loaded with the schema and relationships of the options. The
+// loaded data is snapshotted, so that Reset returns the server to it. The server is stopped when
+// the test completes.
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	c := defaultConfig()
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ds, err := datastorecfg.NewDatastore(ctx, c.datastoreOptions...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, ds.Close())
+	})
+
+	if len(c.validationFiles) > 0 {
+		_, _, err := validationfile.PopulateFromFiles(ctx, ds, c.validationFiles)
+		require.NoError(t, err)
+	}
+
+	srv, err := server.NewConfigWithOptions(
+		server.WithDatastore(ds),
+		server.WithDispatcher(graph.NewLocalOnlyDispatcher(10)),
+		server.WithDispatchMaxDepth(50),
+		server.WithMaximumPreconditionCount(c.maxPreconditionsCount),
+		server.WithMaximumUpdatesPerWrite(c.maxUpdatesPerWrite),
+		server.WithMaxCaveatContextSize(c.maxCaveatContextSize),
+		server.WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		server.WithSchemaPrefixesRequired(c.schemaPrefixRequired),
+		server.WithGRPCAuthFunc(func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		}),
+		server.WithHTTPGateway(util.HTTPServerConfig{Enabled: false}),
+		server.WithDashboardAPI(util.HTTPServerConfig{Enabled: false}),
+		server.WithMetricsAPI(util.HTTPServerConfig{Enabled: false}),
+		server.WithDispatchServer(util.GRPCServerConfig{Enabled: false}),
+		server.SetMiddlewareModification(testserver.Middleware(ds)),
+	).Complete(ctx)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	conn, err := srv.GRPCDialContext(ctx, grpc.WithBlock())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	s := &Server{
+		ds:   ds,
+		conn: conn,
+		client: &authzed.Client{
+			SchemaServiceClient:      v1.NewSchemaServiceClient(conn),
+			PermissionsServiceClient: v1.NewPermissionsServiceClient(conn),
+			WatchServiceClient:       v1.NewWatchServiceClient(conn),
+		},
+	}
+
+	require.NoError(t, s.load(ctx, c))
+	require.NoError(t, s.Snapshot(ctx))
+	return s
+}
+
+// load writes the schema and relationships of the config through the API, so that they are
+// validated as they would be for any client.
+func (s *Server) load(ctx context.Context, c *config) error {
+	if c.schema != "" {
+		if _, err := s.client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: c.schema}); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+	}
+
+	updates := make([]*v1.RelationshipUpdate, 0, len(c.relationships))
+	for _, relString := range c.relationships {
+		rel := tuple.ParseRel(relString)
+		if rel == nil {
+			return fmt.Errorf("failed to parse relationship `%s`", relString)
+		}
+
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel,
+		})
+	}
+
+	batchSize := int(c.maxUpdatesPerWrite)
+	for start := 0; start < len(updates); start += batchSize {
+		end := start + batchSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+
+		if _, err := s.client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates[start:end]}); err != nil {
+			return fmt.Errorf("failed to write relationships: %w", err)
+		}
+	}
+	return nil
+}
+
+// Client returns a client of the v1 API of the server.
+func (s *Server) Client() *authzed.Client {
+	return s.client
+}
+
+// Conn returns the connection to the server, for clients of other APIs.
+func (s *Server) Conn() *grpc.ClientConn {
+	return s.conn
+}
+
+// Datastore returns the datastore backing the server.
+func (s *Server) Datastore() datastore.Datastore {
+	return s.ds
+}
+
+// snapshot holds the schema and relationships of the datastore at a revision.
+type snapshot struct {
+	namespaces    []*core.NamespaceDefinition
+	caveats       []*core.CaveatDefinition
+	relationships []*core.RelationTuple
+}
+
+// Snapshot records the current schema and relationships of the server, replacing those
+// recorded by New or a previous call, so that Reset returns the server to them.
+func (s *Server) Snapshot(ctx context.Context) error {
+	revision, err := s.ds.HeadRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	reader := s.ds.SnapshotReader(revision)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	caveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return err
+	}
+
+	snap := &snapshot{
+		namespaces: datastore.DefinitionsOf(namespaces),
+		caveats:    datastore.DefinitionsOf(caveats),
+	}
+	for _, ns := range snap.namespaces {
+		relationships, err := readRelationships(ctx, reader, ns.Name)
+		if err != nil {
+			return err
+		}
+		snap.relationships = append(snap.relationships, relationships...)
+	}
+
+	s.snapshot = snap
+	return nil
+}
+
+func readRelationships(ctx context.Context, reader datastore.Reader, resourceType string) ([]*core.RelationTuple, error) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: resourceType})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var relationships []*core.RelationTuple
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		relationships = append(relationships, tpl)
+	}
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+	return relationships, nil
+}
+
+// Reset returns the server to the schema and relationships of its last snapshot, in a single
+// transaction. Tests sharing a server can reset it to start from the same data, but must not run
+// in parallel, as Reset replaces the data of the whole datastore.
+func (s *Server) Reset(ctx context.Context) error {
+	snap := s.snapshot
+
+	_, err := s.ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		namespaces, err := rwt.ListAllNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		caveats, err := rwt.ListAllCaveats(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces {
+			if err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: ns.Definition.Name}); err != nil {
+				return err
+			}
+		}
+
+		// Definitions added since the snapshot are removed, while the others are rewritten.
+		snapshotted := make(map[string]struct{}, len(snap.namespaces)+len(snap.caveats))
+		for _, ns := range snap.namespaces {
+			snapshotted[ns.Name] = struct{}{}
+		}
+		for _, caveat := range snap.caveats {
+			snapshotted[caveat.Name] = struct{}{}
+		}
+
+		var addedNamespaces []string
+		for _, ns := range namespaces {
+			if _, ok := snapshotted[ns.Definition.Name]; !ok {
+				addedNamespaces = append(addedNamespaces, ns.Definition.Name)
+			}
+		}
+		if len(addedNamespaces) > 0 {
+			if err := rwt.DeleteNamespaces(ctx, addedNamespaces...); err != nil {
+				return err
+			}
+		}
+
+		var addedCaveats []string
+		for _, caveat := range caveats {
+			if _, ok := snapshotted[caveat.Definition.Name]; !ok {
+				addedCaveats = append(addedCaveats, caveat.Definition.Name)
+			}
+		}
+		if len(addedCaveats) > 0 {
+			if err := rwt.DeleteCaveats(ctx, addedCaveats); err != nil {
+				return err
+			}
+		}
+
+		if len(snap.caveats) > 0 {
+			if err := rwt.WriteCaveats(ctx, snap.caveats); err != nil {
+				return err
+			}
+		}
+
+		if len(snap.namespaces) > 0 {
+			if err := rwt.WriteNamespaces(ctx, snap.namespaces...); err != nil {
+				return err
+			}
+		}
+
+		if len(snap.relationships) == 0 {
+			return nil
+		}
+
+		updates := make([]*core.RelationTupleUpdate, 0, len(snap.relationships))
+		for _, tpl := range snap.relationships {
+			updates = append(updates, tuple.Touch(tpl))
+		}
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	return err
+}
@@ -0,0 +1,141 @@
+package testharness
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func checkView(t *testing.T, srv *Server, rel string) v1.CheckPermissionResponse_Permissionship {
+	t.Helper()
+
+	parsed := tuple.ParseRel(rel)
+	require.NotNil(t, parsed)
+
+	resp, err := srv.Client().CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		Resource:   parsed.Resource,
+		Permission: parsed.Relation,
+		Subject:    parsed.Subject,
+	})
+	require.NoError(t, err)
+	return resp.Permissionship
+}
+
+func TestSchemaAndRelationships(t *testing.T) {
+	srv := New(t,
+		WithSchema(testSchema),
+		WithRelationships("document:firstdoc#viewer@user:tom"),
+	)
+
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:sarah"))
+}
+
+func TestValidationFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validation.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`schema: |-
+  definition user {}
+
+  definition document {
+    relation viewer: user
+    permission view = viewer
+  }
+relationships: |-
+  document:firstdoc#viewer@user:tom
+`), 0o600))
+
+	srv := New(t, WithValidationFiles(path))
+
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:tom"))
+}
+
+func TestInvalidRelationship(t *testing.T) {
+	srv := New(t, WithSchema(testSchema))
+
+	err := srv.load(context.Background(), &config{
+		relationships:      []string{"document:firstdoc#viewer"},
+		maxUpdatesPerWrite: 1000,
+	})
+	require.ErrorContains(t, err, "failed to parse relationship")
+}
+
+func TestSnapshotAndReset(t *testing.T) {
+	ctx := context.Background()
+	srv := New(t,
+		WithSchema(testSchema),
+		WithRelationships("document:firstdoc#viewer@user:tom"),
+	)
+
+	_, err := srv.Client().WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: testSchema + `
+
+definition folder {
+	relation viewer: user
+}`})
+	require.NoError(t, err)
+
+	_, err = srv.Client().WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+				Relationship: tuple.ParseRel("document:firstdoc#viewer@user:tom"),
+			},
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+				Relationship: tuple.ParseRel("document:firstdoc#viewer@user:sarah"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:tom"))
+
+	// Reset returns to the data loaded by New.
+	require.NoError(t, srv.Reset(ctx))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:sarah"))
+
+	schema, err := srv.Client().ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.NotContains(t, schema.SchemaText, "folder")
+
+	// Reset returns to the data of the last snapshot.
+	_, err = srv.Client().WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+				Relationship: tuple.ParseRel("document:firstdoc#viewer@user:sarah"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, srv.Snapshot(ctx))
+
+	_, err = srv.Client().WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{
+				Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+				Relationship: tuple.ParseRel("document:firstdoc#viewer@user:sarah"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, srv.Reset(ctx))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkView(t, srv, "document:firstdoc#view@user:sarah"))
+}
@@ -0,0 +1,95 @@
+package testharness
+
+import (
+	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
+)
+
+type config struct {
+	datastoreOptions      []datastorecfg.ConfigOption
+	schema                string
+	relationships         []string
+	validationFiles       []string
+	maxUpdatesPerWrite    uint16
+	maxPreconditionsCount uint16
+	maxCaveatContextSize  int
+	schemaPrefixRequired  bool
+}
+
+func defaultConfig() *config {
+	return &config{
+		datastoreOptions: []datastorecfg.ConfigOption{
+			datastorecfg.WithEngine(datastorecfg.MemoryEngine),
+			datastorecfg.WithRevisionQuantization(0),
+			datastorecfg.WithRequestHedgingEnabled(false),
+		},
+		maxUpdatesPerWrite:    1000,
+		maxPreconditionsCount: 1000,
+		maxCaveatContextSize:  4096,
+	}
+}
+
+// Option is an option for the test server.
+type Option func(*config)
+
+// WithDatastoreOptions configures the datastore backing the server, such as its engine and URI.
+// The options are applied over the defaults of the harness: an in-memory datastore without
+// revision quantization or request hedging, so that reads observe writes right away.
+//
+// Datastores other than the in-memory one must already be migrated. Servers of tests running in
+// parallel must not share a database.
+func WithDatastoreOptions(options ...datastorecfg.ConfigOption) Option {
+	return func(c *config) {
+		c.datastoreOptions = append(c.datastoreOptions, options...)
+	}
+}
+
+// WithSchema writes the schema to the server once it has started.
+func WithSchema(schema string) Option {
+	return func(c *config) {
+		c.schema = schema
+	}
+}
+
+// WithRelationships writes the relationships, in the form `document:firstdoc#viewer@user:tom`,
+// to the server once it has started. Relationships which already exist are left as is.
+func WithRelationships(relationships ...string) Option {
+	return func(c *config) {
+		c.relationships = append(c.relationships, relationships...)
+	}
+}
+
+// WithValidationFiles loads the schema and relationships of the validation files into the
+// datastore before the server starts.
+func WithValidationFiles(paths ...string) Option {
+	return func(c *config) {
+		c.validationFiles = append(c.validationFiles, paths...)
+	}
+}
+
+// WithMaxUpdatesPerWrite sets the maximum number of updates allowed in a single write.
+func WithMaxUpdatesPerWrite(maxUpdatesPerWrite uint16) Option {
+	return func(c *config) {
+		c.maxUpdatesPerWrite = maxUpdatesPerWrite
+	}
+}
+
+// WithMaxPreconditionsCount sets the maximum number of preconditions allowed in a single write.
+func WithMaxPreconditionsCount(maxPreconditionsCount uint16) Option {
+	return func(c *config) {
+		c.maxPreconditionsCount = maxPreconditionsCount
+	}
+}
+
+// WithMaxCaveatContextSize sets the maximum size, in bytes, of the context of a caveat.
+func WithMaxCaveatContextSize(maxCaveatContextSize int) Option {
+	return func(c *config) {
+		c.maxCaveatContextSize = maxCaveatContextSize
+	}
+}
+
+// WithSchemaPrefixRequired requires the definitions of the schema to be prefixed.
+func WithSchemaPrefixRequired(schemaPrefixRequired bool) Option {
+	return func(c *config) {
+		c.schemaPrefixRequired = schemaPrefixRequired
+	}
+}
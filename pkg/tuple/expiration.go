@@ -0,0 +1,69 @@
+package tuple
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WriteRelationshipsExpiration is the key in the request header metadata for the RFC 3339
+// expiration time applied to all relationships created or touched by a WriteRelationships call.
+const WriteRelationshipsExpiration requestmeta.RequestMetadataHeaderKey = "io.spicedb.writerelationships.expiration"
+
+// WriteRelationshipsUpdateExpiration is the key in the request header metadata for the expiration
+// time of a single update of a WriteRelationships call. Each value is the index of the update in
+// the request, followed by `=` and the RFC 3339 expiration time, and takes precedence over
+// WriteRelationshipsExpiration for that update.
+const WriteRelationshipsUpdateExpiration requestmeta.RequestMetadataHeaderKey = "io.spicedb.writerelationships.update-expiration"
+
+// ParseExpiration parses an RFC 3339 expiration time, as found in the string form of a tuple.
+func ParseExpiration(value string) (*timestamppb.Timestamp, error) {
+	expiration, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration time: %w", err)
+	}
+
+	return timestamppb.New(expiration), nil
+}
+
+// ParseUpdateExpirations parses the values of WriteRelationshipsUpdateExpiration into the expiration
+// times of the updates, keyed by the index of the update.
+func ParseUpdateExpirations(values []string, updateCount int) (map[int]*timestamppb.Timestamp, error) {
+	expirations := make(map[int]*timestamppb.Timestamp, len(values))
+	for _, value := range values {
+		indexString, expirationString, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid update expiration `%s`: must be an update index and an expiration time separated by `=`", value)
+		}
+
+		index, err := strconv.Atoi(indexString)
+		if err != nil || index < 0 || index >= updateCount {
+			return nil, fmt.Errorf("invalid update expiration `%s`: no update with index `%s`", value, indexString)
+		}
+
+		if _, ok := expirations[index]; ok {
+			return nil, fmt.Errorf("invalid update expiration `%s`: update %d has more than one expiration", value, index)
+		}
+
+		expiration, err := ParseExpiration(expirationString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid update expiration `%s`: %w", value, err)
+		}
+		expirations[index] = expiration
+	}
+
+	return expirations, nil
+}
+
+// StringExpiration converts an expiration time to a string. If the expiration is nil, returns empty string.
+func StringExpiration(expiration *timestamppb.Timestamp) string {
+	if expiration == nil {
+		return ""
+	}
+
+	return "[expiration:" + expiration.AsTime().UTC().Format(time.RFC3339Nano) + "]"
+}
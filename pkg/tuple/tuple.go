@@ -9,6 +9,7 @@ import (
 	"github.com/jzelinskie/stringz"
 	"golang.org/x/exp/maps"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
@@ -46,6 +47,8 @@ var subjectExpr = fmt.Sprintf(
 
 var caveatExpr = fmt.Sprintf(`\[(?P<caveatName>(%s))(:(?P<caveatContext>(\{(.+)\})))?\]`, caveatNameExpr)
 
+var expirationExpr = `\[expiration:(?P<expirationTime>[0-9\-\.:TZ+]+)\]`
+
 var (
 	onrRegex        = regexp.MustCompile(fmt.Sprintf("^%s$", onrExpr))
 	subjectRegex    = regexp.MustCompile(fmt.Sprintf("^%s$", subjectExpr))
@@ -55,10 +58,11 @@ var (
 
 var parserRegex = regexp.MustCompile(
 	fmt.Sprintf(
-		`^%s@%s(%s)?$`,
+		`^%s@%s(%s)?(%s)?$`,
 		onrExpr,
 		subjectExpr,
 		caveatExpr,
+		expirationExpr,
 	),
 )
 
@@ -106,7 +110,7 @@ func String(tpl *core.RelationTuple) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s@%s%s", StringONR(tpl.ResourceAndRelation), StringONR(tpl.Subject), caveatString), nil
+	return fmt.Sprintf("%s@%s%s%s", StringONR(tpl.ResourceAndRelation), StringONR(tpl.Subject), caveatString, StringExpiration(tpl.OptionalExpirationTime)), nil
 }
 
 // StringWithoutCaveat converts a tuple to a string, without its caveat included.
@@ -207,6 +211,17 @@ func Parse(tpl string) *core.RelationTuple {
 		}
 	}
 
+	var optionalExpiration *timestamppb.Timestamp
+	expirationString := groups[stringz.SliceIndex(parserRegex.SubexpNames(), "expirationTime")]
+	if len(expirationString) > 0 {
+		expiration, err := ParseExpiration(expirationString)
+		if err != nil {
+			return nil
+		}
+
+		optionalExpiration = expiration
+	}
+
 	resourceID := groups[stringz.SliceIndex(parserRegex.SubexpNames(), "resourceID")]
 	if err := ValidateResourceID(resourceID); err != nil {
 		return nil
@@ -228,7 +243,8 @@ func Parse(tpl string) *core.RelationTuple {
 			ObjectId:  subjectID,
 			Relation:  subjectRelation,
 		},
-		Caveat: optionalCaveat,
+		Caveat:                 optionalCaveat,
+		OptionalExpirationTime: optionalExpiration,
 	}
 }
 
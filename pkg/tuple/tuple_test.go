@@ -3,10 +3,12 @@ package tuple
 import (
 	"strings"
 	"testing"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/testutil"
@@ -384,6 +386,86 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestExpiration(t *testing.T) {
+	expiration := time.Date(2023, time.March, 14, 15, 9, 26, 535000000, time.UTC)
+
+	withExpiration := makeTuple(
+		ObjectAndRelation("document", "foo", "viewer"),
+		ObjectAndRelation("user", "tom", "..."),
+	)
+	withExpiration.OptionalExpirationTime = timestamppb.New(expiration)
+
+	caveatedWithExpiration := MustWithCaveat(withExpiration, "somecaveat", map[string]any{"hi": "there"})
+
+	testCases := []struct {
+		input          string
+		expectedOutput string
+		tupleFormat    *core.RelationTuple
+	}{
+		{
+			"document:foo#viewer@user:tom[expiration:2023-03-14T15:09:26.535Z]",
+			"document:foo#viewer@user:tom[expiration:2023-03-14T15:09:26.535Z]",
+			withExpiration,
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:2023-03-14T16:09:26.535+01:00]",
+			"document:foo#viewer@user:tom[expiration:2023-03-14T15:09:26.535Z]",
+			withExpiration,
+		},
+		{
+			`document:foo#viewer@user:tom[somecaveat:{"hi":"there"}][expiration:2023-03-14T15:09:26.535Z]`,
+			`document:foo#viewer@user:tom[somecaveat:{"hi":"there"}][expiration:2023-03-14T15:09:26.535Z]`,
+			caveatedWithExpiration,
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:tomorrow]",
+			"",
+			nil,
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:2023-13-14T15:09:26Z]",
+			"",
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			parsed := Parse(tc.input)
+			testutil.RequireProtoEqual(t, tc.tupleFormat, parsed, "found difference in parsed tuple")
+			if parsed == nil {
+				return
+			}
+
+			require.Equal(t, tc.expectedOutput, strings.Replace(MustString(parsed), " ", "", -1))
+			require.NotContains(t, StringWithoutCaveat(parsed), "expiration")
+		})
+	}
+}
+
+func TestParseUpdateExpirations(t *testing.T) {
+	expirations, err := ParseUpdateExpirations([]string{
+		"0=2023-03-14T15:09:26.535Z",
+		"2=2023-03-14T16:09:26.535+01:00",
+	}, 3)
+	require.NoError(t, err)
+	require.Len(t, expirations, 2)
+	require.Equal(t, time.Date(2023, time.March, 14, 15, 9, 26, 535000000, time.UTC), expirations[0].AsTime())
+	require.Equal(t, expirations[0].AsTime(), expirations[2].AsTime())
+
+	for _, invalid := range [][]string{
+		{"2023-03-14T15:09:26Z"},
+		{"3=2023-03-14T15:09:26Z"},
+		{"-1=2023-03-14T15:09:26Z"},
+		{"0=tomorrow"},
+		{"0=2023-03-14T15:09:26Z", "0=2023-03-14T16:09:26Z"},
+	} {
+		_, err := ParseUpdateExpirations(invalid, 3)
+		require.Error(t, err, invalid)
+	}
+}
+
 func TestConvert(t *testing.T) {
 	for _, tc := range testCases {
 		tc := tc
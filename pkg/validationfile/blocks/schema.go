@@ -3,10 +3,12 @@ package blocks
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	yamlv3 "gopkg.in/yaml.v3"
 
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
@@ -30,31 +32,76 @@ func (ps *ParsedSchema) UnmarshalYAML(node *yamlv3.Node) error {
 		return convertYamlError(err)
 	}
 
+	// Report errors at their line in the file, which for block scalars starts on the line after
+	// the key.
+	lineOffset := uint64(node.Line - 1)
+	if node.Style&(yamlv3.LiteralStyle|yamlv3.FoldedStyle) != 0 {
+		lineOffset = uint64(node.Line)
+	}
+
+	compiled, err := compileSchema(input.Source("schema"), ps.Schema, lineOffset)
+	if err != nil {
+		return err
+	}
+
+	ps.CompiledSchema = compiled
+	ps.SourcePosition = spiceerrors.SourcePosition{LineNumber: node.Line, ColumnPosition: node.Column}
+	return nil
+}
+
+// ParseSchemaFile reads and compiles the schema file found at the path, along with the schema
+// files it imports. The Schema of the result is generated from the compiled definitions, so
+// that it does not depend on the imported files.
+func ParseSchemaFile(filePath string) (*ParsedSchema, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading schema file: %w", err)
+	}
+
 	empty := ""
 	compiled, err := compiler.Compile(compiler.InputSchema{
-		Source:       input.Source("schema"),
-		SchemaString: ps.Schema,
+		Source:       input.Source(filePath),
+		SchemaString: string(contents),
+	}, &empty, compiler.WithImportResolver(compiler.FilesystemImportResolver{}))
+	if err != nil {
+		return nil, fmt.Errorf("error when parsing schema file: %w", err)
+	}
+
+	generated, _, err := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating schema from file: %w", err)
+	}
+
+	return &ParsedSchema{
+		Schema:         generated,
+		CompiledSchema: compiled,
+	}, nil
+}
+
+func compileSchema(source input.Source, schema string, lineOffset uint64) (*compiler.CompiledSchema, error) {
+	empty := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       source,
+		SchemaString: schema,
 	}, &empty)
 	if err != nil {
 		var errWithContext compiler.ErrorWithContext
 		if errors.As(err, &errWithContext) {
 			line, col, lerr := errWithContext.SourceRange.Start().LineAndColumn()
 			if lerr != nil {
-				return lerr
+				return nil, lerr
 			}
 
-			return spiceerrors.NewErrorWithSource(
+			return nil, spiceerrors.NewErrorWithSource(
 				fmt.Errorf("error when parsing schema: %s", errWithContext.BaseMessage),
 				errWithContext.ErrorSourceCode,
-				uint64(line+1), // source line is 0-indexed
-				uint64(col+1),  // source col is 0-indexed
+				uint64(line+1)+lineOffset, // source line is 0-indexed
+				uint64(col+1),             // source col is 0-indexed
 			)
 		}
 
-		return fmt.Errorf("error when parsing schema: %w", err)
+		return nil, fmt.Errorf("error when parsing schema: %w", err)
 	}
 
-	ps.CompiledSchema = compiled
-	ps.SourcePosition = spiceerrors.SourcePosition{LineNumber: node.Line, ColumnPosition: node.Column}
-	return nil
+	return compiled, nil
 }
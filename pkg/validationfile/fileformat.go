@@ -1,6 +1,9 @@
 package validationfile
 
 import (
+	"fmt"
+	"path/filepath"
+
 	yamlv3 "gopkg.in/yaml.v3"
 
 	"github.com/authzed/spicedb/pkg/validationfile/blocks"
@@ -22,6 +25,10 @@ type ValidationFile struct {
 	// Schema is the schema.
 	Schema blocks.ParsedSchema `yaml:"schema"`
 
+	// SchemaFile is the path of a schema file to use instead of an inline schema, relative
+	// to the validation file. The schema file may import other schema files.
+	SchemaFile string `yaml:"schemaFile"`
+
 	// Relationships are the relationships specified in the validation file.
 	Relationships blocks.ParsedRelationships `yaml:"relationships"`
 
@@ -41,6 +48,32 @@ type ValidationFile struct {
 	ValidationTuples []string `yaml:"validation_tuples"`
 }
 
+// ResolveSchemaFile loads the schema file referenced by the validation file, if any, into its
+// Schema. The validationFilePath is the path of the validation file itself, against which the
+// schema file path is resolved.
+func (vf *ValidationFile) ResolveSchemaFile(validationFilePath string) error {
+	if vf.SchemaFile == "" {
+		return nil
+	}
+
+	if vf.Schema.Schema != "" {
+		return fmt.Errorf("only one of `schema` and `schemaFile` can be specified")
+	}
+
+	schemaFilePath := filepath.FromSlash(vf.SchemaFile)
+	if !filepath.IsAbs(schemaFilePath) {
+		schemaFilePath = filepath.Join(filepath.Dir(validationFilePath), schemaFilePath)
+	}
+
+	parsed, err := blocks.ParseSchemaFile(schemaFilePath)
+	if err != nil {
+		return err
+	}
+
+	vf.Schema = *parsed
+	return nil
+}
+
 // ParseAssertionsBlock parses the given contents as an assertions block.
 func ParseAssertionsBlock(contents []byte) (*blocks.Assertions, error) {
 	return blocks.ParseAssertionsBlock(contents)
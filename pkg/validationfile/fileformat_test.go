@@ -101,6 +101,26 @@ validation:
 	}
 }
 
+func TestDecodeSchemaErrorLineNumber(t *testing.T) {
+	_, err := DecodeValidationFile([]byte(`relationships: >-
+  document:firstdoc#writer@user:tom
+
+schema: |-
+  definition user {}
+
+  definition document {
+    relation writer: user
+    permission view = writer +
+  }
+`))
+
+	errWithSource, ok := spiceerrors.AsErrorWithSource(err)
+	require.True(t, ok)
+
+	require.Contains(t, err.Error(), "error when parsing schema")
+	require.Equal(t, uint64(10), errWithSource.LineNumber)
+}
+
 func TestDecodeRelationshipsErrorLineNumber(t *testing.T) {
 	_, err := DecodeValidationFile([]byte(`schema: >-
   definition user {}
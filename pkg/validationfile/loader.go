@@ -75,6 +75,10 @@ func PopulateFromFilesContents(ctx context.Context, ds datastore.Datastore, file
 			return nil, datastore.NoRevision, fmt.Errorf("error when parsing config file %s: %w", filePath, err)
 		}
 
+		if err := parsed.ResolveSchemaFile(filePath); err != nil {
+			return nil, datastore.NoRevision, fmt.Errorf("error when loading schema file for config file %s: %w", filePath, err)
+		}
+
 		files = append(files, *parsed)
 
 		// Disallow legacy sections.
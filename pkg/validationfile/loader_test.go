@@ -64,6 +64,28 @@ func TestPopulateFromFiles(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name:      "schema file",
+			filePaths: []string{"testdata/schema_file.yaml"},
+			want: []string{
+				"example/project:pied_piper#owner@example/user:milburga",
+				"example/project:pied_piper#reader@example/user:tarben",
+				"example/project:pied_piper#writer@example/user:freyja",
+			},
+			expectedError: "",
+		},
+		{
+			name:          "schema file with missing import",
+			filePaths:     []string{"testdata/broken_schema_file.yaml"},
+			want:          nil,
+			expectedError: "could not import `common/missing.zed`",
+		},
+		{
+			name:          "schema and schema file",
+			filePaths:     []string{"testdata/schema_and_schema_file.yaml"},
+			want:          nil,
+			expectedError: "only one of `schema` and `schemaFile` can be specified",
+		},
 		{
 			name:          "missing schema",
 			filePaths:     []string{"testdata/just_rels.yaml"},
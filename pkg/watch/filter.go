@@ -0,0 +1,119 @@
+// Package watch defines the options of the Watch API which are not (yet) part of its request.
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// RelationshipFilters is the key in the request header metadata for the filters applied
+	// to the relationship updates returned by a Watch call. Each value of the key is a single
+	// JSON-encoded RelationshipFilter; updates matching any of the filters are returned.
+	RelationshipFilters requestmeta.RequestMetadataHeaderKey = "io.spicedb.watch.relationshipfilters"
+
+	// CheckpointInterval is the key in the request header metadata for the interval at which a
+	// Watch call sends checkpoints, as a Go duration string (e.g. "30s").
+	CheckpointInterval requestmeta.RequestMetadataHeaderKey = "io.spicedb.watch.checkpointinterval"
+)
+
+// MinimumCheckpointInterval is the smallest checkpoint interval that may be requested.
+const MinimumCheckpointInterval = 1 * time.Second
+
+// RelationshipFilter selects the relationship updates returned by a Watch call. All fields
+// are optional, but at least one must be set; a relationship must match every field set.
+type RelationshipFilter struct {
+	// ResourceType is the type of the resource.
+	ResourceType string `json:"resourceType,omitempty"`
+
+	// OptionalResourceID is the exact ID of the resource.
+	OptionalResourceID string `json:"optionalResourceId,omitempty"`
+
+	// OptionalResourceIDPrefix is a prefix of the ID of the resource.
+	OptionalResourceIDPrefix string `json:"optionalResourceIdPrefix,omitempty"`
+
+	// OptionalRelation is the relation on the resource.
+	OptionalRelation string `json:"optionalRelation,omitempty"`
+
+	// OptionalSubjectType is the type of the subject.
+	OptionalSubjectType string `json:"optionalSubjectType,omitempty"`
+
+	// OptionalSubjectID is the exact ID of the subject.
+	OptionalSubjectID string `json:"optionalSubjectId,omitempty"`
+
+	// OptionalSubjectRelation is the relation of the subject. `...` matches subjects without
+	// a relation.
+	OptionalSubjectRelation string `json:"optionalSubjectRelation,omitempty"`
+}
+
+// ErrEmptyFilter is returned when decoding a filter which has no fields set.
+var ErrEmptyFilter = errors.New("relationship filter must specify at least one field")
+
+// EncodeRelationshipFilter encodes a filter into a header value.
+func EncodeRelationshipFilter(filter RelationshipFilter) (string, error) {
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return "", fmt.Errorf("error encoding relationship filter: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// DecodeRelationshipFilter decodes a filter from a header value.
+func DecodeRelationshipFilter(value string) (RelationshipFilter, error) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	var filter RelationshipFilter
+	if err := decoder.Decode(&filter); err != nil {
+		return RelationshipFilter{}, fmt.Errorf("error decoding relationship filter: %w", err)
+	}
+
+	if filter == (RelationshipFilter{}) {
+		return RelationshipFilter{}, ErrEmptyFilter
+	}
+
+	if filter.OptionalResourceID != "" && filter.OptionalResourceIDPrefix != "" {
+		return RelationshipFilter{}, errors.New("relationship filter cannot specify both a resource ID and a resource ID prefix")
+	}
+
+	return filter, nil
+}
+
+// Matches returns true if the relationship matches every field set on the filter.
+func (rf RelationshipFilter) Matches(rel *v1.Relationship) bool {
+	resource := rel.GetResource()
+	subject := rel.GetSubject()
+
+	switch {
+	case rf.ResourceType != "" && resource.GetObjectType() != rf.ResourceType:
+		return false
+	case rf.OptionalResourceID != "" && resource.GetObjectId() != rf.OptionalResourceID:
+		return false
+	case rf.OptionalResourceIDPrefix != "" && !strings.HasPrefix(resource.GetObjectId(), rf.OptionalResourceIDPrefix):
+		return false
+	case rf.OptionalRelation != "" && rel.GetRelation() != rf.OptionalRelation:
+		return false
+	case rf.OptionalSubjectType != "" && subject.GetObject().GetObjectType() != rf.OptionalSubjectType:
+		return false
+	case rf.OptionalSubjectID != "" && subject.GetObject().GetObjectId() != rf.OptionalSubjectID:
+		return false
+	}
+
+	if rf.OptionalSubjectRelation != "" {
+		subjectRelation := subject.GetOptionalRelation()
+		if subjectRelation == "" {
+			subjectRelation = tuple.Ellipsis
+		}
+		return subjectRelation == rf.OptionalSubjectRelation
+	}
+
+	return true
+}
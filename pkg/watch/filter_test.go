@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestDecodeRelationshipFilter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		value         string
+		expected      RelationshipFilter
+		expectedError string
+	}{
+		{
+			name:     "resource type",
+			value:    `{"resourceType":"document"}`,
+			expected: RelationshipFilter{ResourceType: "document"},
+		},
+		{
+			name:  "all fields",
+			value: `{"resourceType":"document","optionalResourceIdPrefix":"doc","optionalRelation":"viewer","optionalSubjectType":"group","optionalSubjectId":"eng","optionalSubjectRelation":"member"}`,
+			expected: RelationshipFilter{
+				ResourceType:             "document",
+				OptionalResourceIDPrefix: "doc",
+				OptionalRelation:         "viewer",
+				OptionalSubjectType:      "group",
+				OptionalSubjectID:        "eng",
+				OptionalSubjectRelation:  "member",
+			},
+		},
+		{
+			name:          "empty",
+			value:         `{}`,
+			expectedError: "must specify at least one field",
+		},
+		{
+			name:          "unknown field",
+			value:         `{"resourceType":"document","unknown":"field"}`,
+			expectedError: "unknown field",
+		},
+		{
+			name:          "invalid json",
+			value:         `document`,
+			expectedError: "error decoding relationship filter",
+		},
+		{
+			name:          "id and prefix",
+			value:         `{"optionalResourceId":"doc1","optionalResourceIdPrefix":"doc"}`,
+			expectedError: "cannot specify both",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := DecodeRelationshipFilter(tc.value)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, filter)
+
+			encoded, err := EncodeRelationshipFilter(filter)
+			require.NoError(t, err)
+
+			decoded, err := DecodeRelationshipFilter(encoded)
+			require.NoError(t, err)
+			require.Equal(t, filter, decoded)
+		})
+	}
+}
+
+func TestRelationshipFilterMatches(t *testing.T) {
+	testCases := []struct {
+		filter   RelationshipFilter
+		rel      string
+		expected bool
+	}{
+		{RelationshipFilter{ResourceType: "document"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{ResourceType: "document"}, "folder:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalResourceID: "doc1"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalResourceID: "doc"}, "document:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalResourceIDPrefix: "doc"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalResourceIDPrefix: "doc2"}, "document:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalRelation: "viewer"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalRelation: "editor"}, "document:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalSubjectType: "user"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalSubjectType: "group"}, "document:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalSubjectID: "tom"}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalSubjectID: "fred"}, "document:doc1#viewer@user:tom", false},
+		{RelationshipFilter{OptionalSubjectRelation: "..."}, "document:doc1#viewer@user:tom", true},
+		{RelationshipFilter{OptionalSubjectRelation: "..."}, "document:doc1#viewer@group:eng#member", false},
+		{RelationshipFilter{OptionalSubjectRelation: "member"}, "document:doc1#viewer@group:eng#member", true},
+		{RelationshipFilter{ResourceType: "document", OptionalSubjectType: "group"}, "document:doc1#viewer@user:tom", false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.rel, func(t *testing.T) {
+			rel := tuple.MustToRelationship(tuple.MustParse(tc.rel))
+			require.Equal(t, tc.expected, tc.filter.Matches(rel))
+		})
+	}
+}